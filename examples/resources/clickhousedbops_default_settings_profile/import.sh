@@ -0,0 +1,7 @@
+# The default settings profile can be imported by specifying the UUID of the settings profile applied to all users and roles.
+# Find the ID of the settings profile by checking system.settings_profiles table.
+terraform import clickhousedbops_default_settings_profile.example xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
+
+# IMPORTANT: if you have a multi node cluster, you need to specify the cluster name!
+
+terraform import clickhousedbops_default_settings_profile.example cluster:xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
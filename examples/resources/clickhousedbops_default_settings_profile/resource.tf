@@ -0,0 +1,3 @@
+resource "clickhousedbops_default_settings_profile" "default" {
+  settings_profile_id = clickhousedbops_settings_profile.baseline.id
+}
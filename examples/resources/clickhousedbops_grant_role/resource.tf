@@ -3,3 +3,9 @@ resource "clickhousedbops_grant_role" "role_to_user" {
   role_name         = "myrole"
   grantee_user_name = "myuser"
 }
+
+resource "clickhousedbops_grant_role" "role_and_profile_to_user" {
+  role_name                = "analyst"
+  grantee_user_name        = "myuser"
+  grantee_settings_profile = "analyst_limits"
+}
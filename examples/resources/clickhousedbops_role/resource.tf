@@ -1,4 +1,13 @@
 resource "clickhousedbops_role" "writer" {
   cluster_name = "cluster"
   name         = "writer"
+  comment      = "owner: team-data, managed by terraform"
+
+  settings = [
+    {
+      name        = "max_memory_usage"
+      value       = "1000000"
+      writability = "CONST"
+    },
+  ]
 }
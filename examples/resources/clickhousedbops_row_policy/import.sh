@@ -0,0 +1,7 @@
+# Row policies can be imported by specifying the ID.
+# Find the ID of the row policy by checking system.row_policies table.
+terraform import clickhousedbops_row_policy.example xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
+
+# IMPORTANT: if you have a multi node cluster, you need to specify the cluster name!
+
+terraform import clickhousedbops_row_policy.example cluster:xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
@@ -0,0 +1,9 @@
+resource "clickhousedbops_row_policy" "tenant" {
+  cluster_name     = "cluster"
+  name             = "tenant"
+  database         = "default"
+  table            = "events"
+  kind             = "restrictive"
+  using_expression = "tenant_id = 1"
+  apply_to         = ["reader"]
+}
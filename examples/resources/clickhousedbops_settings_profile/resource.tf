@@ -1,4 +1,14 @@
 resource "clickhousedbops_settings_profile" "profile1" {
   cluster_name = "cluster"
   name = "profile1"
+
+  settings = [
+    {
+      name        = "max_memory_usage"
+      value       = "1000000"
+      min         = "0"
+      max         = "2000000"
+      writability = "CONST"
+    },
+  ]
 }
@@ -1,10 +1,32 @@
 resource "clickhousedbops_user" "john" {
   cluster_name = "cluster"
   name = "john"
-  # Option 1: password-based auth
-  # password_sha256_hash_wo = sha256("test")
-  # password_sha256_hash_wo_version = 4
 
-  # Option 2: SSL certificate CN auth (mutually exclusive with password)
-  ssl_certificate_cn = "john"
-}
\ No newline at end of file
+  # The user can log in with either a password or an SSL certificate.
+  authentication = [
+    {
+      type                    = "sha256_password"
+      password_sha256_hash_wo = sha256("test")
+    },
+    {
+      type               = "ssl_certificate"
+      ssl_certificate_cn = "john"
+    },
+  ]
+  # Bump to set the password again.
+  password_sha256_hash_wo_version = 4
+
+  # Optional: only allow connections from the given hosts
+  host = [
+    {
+      type = "LOCAL"
+    },
+    {
+      type   = "IP"
+      values = ["10.0.0.0/8"]
+    },
+  ]
+
+  # Optional: only allow granting privileges and roles to the given users and roles
+  grantees = ["reader"]
+}
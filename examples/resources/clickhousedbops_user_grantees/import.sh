@@ -0,0 +1,7 @@
+# The grantees of a user can be imported by specifying the UUID or the name of the user.
+# Find the ID of the user by checking system.users table.
+terraform import clickhousedbops_user_grantees.example xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
+
+# IMPORTANT: if you have a multi node cluster, you need to specify the cluster name!
+
+terraform import clickhousedbops_user_grantees.example cluster:xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
@@ -0,0 +1,5 @@
+resource "clickhousedbops_user_grantees" "john" {
+  user_id         = clickhousedbops_user.john.id
+  grantees_any    = true
+  grantees_except = [clickhousedbops_user.admin.name]
+}
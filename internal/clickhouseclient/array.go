@@ -0,0 +1,79 @@
+package clickhouseclient
+
+import (
+	"strings"
+
+	"github.com/pingcap/errors"
+)
+
+// parseStringArray parses an Array(String) value in the ClickHouse text format, e.g. ['a','b\'c'].
+func parseStringArray(value string) ([]string, error) {
+	ret := make([]string, 0)
+
+	if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+		return nil, errors.Errorf("invalid array %q: missing square brackets", value)
+	}
+
+	input := []rune(value[1 : len(value)-1])
+	for pos := 0; pos < len(input); {
+		if input[pos] != '\'' {
+			return nil, errors.Errorf("invalid array %q: expected quote at position %d", value, pos+1)
+		}
+		pos++
+
+		var item strings.Builder
+		closed := false
+		for pos < len(input) && !closed {
+			c := input[pos]
+			pos++
+
+			switch c {
+			case '\'':
+				closed = true
+			case '\\':
+				if pos == len(input) {
+					return nil, errors.Errorf("invalid array %q: unterminated escape sequence", value)
+				}
+				item.WriteRune(unescape(input[pos]))
+				pos++
+			default:
+				item.WriteRune(c)
+			}
+		}
+
+		if !closed {
+			return nil, errors.Errorf("invalid array %q: unterminated string", value)
+		}
+
+		ret = append(ret, item.String())
+
+		if pos < len(input) {
+			if input[pos] != ',' {
+				return nil, errors.Errorf("invalid array %q: expected comma at position %d", value, pos+1)
+			}
+			pos++
+		}
+	}
+
+	return ret, nil
+}
+
+// unescape returns the character represented by the escape sequence made of a backslash followed by c.
+func unescape(c rune) rune {
+	switch c {
+	case 'b':
+		return '\b'
+	case 'f':
+		return '\f'
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case '0':
+		return 0
+	default:
+		return c
+	}
+}
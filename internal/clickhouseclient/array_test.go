@@ -0,0 +1,67 @@
+package clickhouseclient
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseStringArray(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "Empty array",
+			value: "[]",
+			want:  []string{},
+		},
+		{
+			name:  "Simple values",
+			value: "['reader','writer']",
+			want:  []string{"reader", "writer"},
+		},
+		{
+			name:  "Value with comma",
+			value: "['team,ops','reader']",
+			want:  []string{"team,ops", "reader"},
+		},
+		{
+			name:  "Value with escaped quote and backslash",
+			value: `['bob\'s-role','back\\slash']`,
+			want:  []string{"bob's-role", `back\slash`},
+		},
+		{
+			name:  "Empty string",
+			value: "['']",
+			want:  []string{""},
+		},
+		{
+			name:    "Missing brackets",
+			value:   "'reader'",
+			wantErr: true,
+		},
+		{
+			name:    "Unterminated string",
+			value:   "['reader]",
+			wantErr: true,
+		},
+		{
+			name:    "Missing comma",
+			value:   "['a''b']",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseStringArray(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseStringArray() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseStringArray() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
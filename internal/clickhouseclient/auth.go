@@ -1,5 +1,9 @@
 package clickhouseclient
 
+import (
+	"crypto/tls"
+)
+
 type UserPasswordAuth struct {
 	Username string
 	Password string
@@ -28,3 +32,36 @@ func (b *BasicAuth) ValidateConfig() (bool, []string) {
 
 	return len(errors) == 0, errors
 }
+
+// ClientCertAuth authenticates Username with a TLS client certificate, for users identified WITH ssl_certificate.
+type ClientCertAuth struct {
+	Username    string
+	Database    string
+	Certificate tls.Certificate
+}
+
+func (c *ClientCertAuth) ValidateConfig() (bool, []string) {
+	errors := make([]string, 0)
+	if c.Username == "" {
+		errors = append(errors, "Username must be set")
+	}
+	if len(c.Certificate.Certificate) == 0 {
+		errors = append(errors, "Certificate must be set")
+	}
+
+	return len(errors) == 0, errors
+}
+
+// JWTAuth authenticates with a JSON Web Token sent as a bearer token, as supported by ClickHouse Cloud.
+type JWTAuth struct {
+	AccessToken string
+}
+
+func (j *JWTAuth) ValidateConfig() (bool, []string) {
+	errors := make([]string, 0)
+	if j.AccessToken == "" {
+		errors = append(errors, "AccessToken must be set")
+	}
+
+	return len(errors) == 0, errors
+}
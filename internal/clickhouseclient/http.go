@@ -9,15 +9,35 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/pingcap/errors"
 )
 
+// Connection pool defaults of the HTTP client, matching the ones of clickhouse-go for the native protocol.
+const (
+	defaultHTTPMaxIdleConns    = 5
+	defaultHTTPConnMaxLifetime = time.Hour
+)
+
 type httpClient struct {
-	client  *http.Client
-	baseUrl url.URL
+	client    *http.Client
+	baseUrl   url.URL
+	userAgent string
+	// certUser is the user authenticated with a client certificate, if any.
+	certUser string
+	// accessToken is the JWT sent as a bearer token, if any.
+	accessToken string
+	// acceptEncoding is the compression method the server is asked to compress responses with, if any.
+	acceptEncoding string
+	// preStatements are run before every Exec, in the same session.
+	preStatements []string
+	// headers are added to every request, before the headers set by the client itself.
+	headers http.Header
 }
 
 type HTTPClientConfig struct {
@@ -25,7 +45,56 @@ type HTTPClientConfig struct {
 	Host      string
 	Port      uint16
 	BasicAuth *BasicAuth
+	// ClientCertAuth authenticates with a TLS client certificate instead of basic auth. It requires the https protocol.
+	ClientCertAuth *ClientCertAuth
+	// JWTAuth authenticates with a bearer token instead of basic auth. It requires the https protocol.
+	JWTAuth   *JWTAuth
 	TLSConfig *tls.Config
+	// UserAgent is sent as the User-Agent header of every request, if set.
+	UserAgent string
+	// MaxRetries is the number of times a query failing with a network error or a 503 response is retried.
+	// Exec is only retried when the query never reached the server. Zero disables retries.
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry, doubled after each attempt.
+	RetryBackoff time.Duration
+	// QueryTimeout is the deadline of every query. Zero means no deadline.
+	QueryTimeout time.Duration
+	// MaxOpenConns is the maximum number of connections opened to the server. Zero means MaxIdleConns + 5.
+	MaxOpenConns int
+	// MaxIdleConns is the maximum number of connections kept open while unused. Zero means 5.
+	MaxIdleConns int
+	// ConnMaxLifetime is how long an unused connection is kept open for, since HTTP connections can't be given a
+	// maximum lifetime. Zero means one hour.
+	ConnMaxLifetime time.Duration
+	// Database is the default database of the queries. Empty means the default database of the user.
+	Database string
+	// Settings are sent as query parameters of every request, so that they apply to the session of the query.
+	Settings map[string]string
+	// ProxyURL is the URL of the proxy requests are sent through. Empty means no proxy.
+	ProxyURL string
+	// ProxyUsername and ProxyPassword are the basic auth credentials of the proxy, distinct from the ones of ClickHouse.
+	ProxyUsername string
+	ProxyPassword string
+	// Compression is the name of the compression method of the responses (none, gzip, lz4 or zstd). Empty means
+	// no compression.
+	Compression string
+	// PreStatements are run before every Exec, such as SET statements enabling experimental features. They share an
+	// HTTP session with the query, so every request of an Exec must reach the same server.
+	PreStatements []string
+	// Headers are added to every request, e.g. to authenticate with a gateway in front of the server. An
+	// Authorization header replaces the basic auth credentials. The headers set by the client itself, such as
+	// User-Agent or the bearer token of JWTAuth, take precedence.
+	Headers map[string]string
+}
+
+// httpStatusError is returned when the server answers with a status other than 200.
+type httpStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *httpStatusError) Error() string {
+	return e.Body
 }
 
 func NewHTTPClient(config HTTPClientConfig) (ClickhouseClient, error) {
@@ -35,13 +104,25 @@ func NewHTTPClient(config HTTPClientConfig) (ClickhouseClient, error) {
 	if config.Port == 0 {
 		return nil, errors.New("Port is required")
 	}
-	if config.BasicAuth == nil {
+	methods := 0
+	for _, set := range []bool{config.BasicAuth != nil, config.ClientCertAuth != nil, config.JWTAuth != nil} {
+		if set {
+			methods++
+		}
+	}
+	if methods != 1 {
 		return nil, errors.New("Exactly one authentication method is required")
 	}
 	protocol := "http"
 	if config.Protocol != "" {
 		protocol = config.Protocol
 	}
+	if config.ClientCertAuth != nil && protocol != "https" {
+		return nil, errors.New("Client certificate authentication requires the https protocol")
+	}
+	if config.JWTAuth != nil && protocol != "https" {
+		return nil, errors.New("JWT authentication requires the https protocol")
+	}
 
 	urlStr := fmt.Sprintf("%s://%s", protocol, config.Host)
 
@@ -55,6 +136,7 @@ func NewHTTPClient(config HTTPClientConfig) (ClickhouseClient, error) {
 	}
 
 	baseUrl.Path = "/"
+	baseUrl.RawQuery = sessionQuery(config).Encode()
 
 	if config.BasicAuth != nil {
 		if config.BasicAuth.Password == "" {
@@ -64,18 +146,137 @@ func NewHTTPClient(config HTTPClientConfig) (ClickhouseClient, error) {
 		}
 	}
 
-	return &httpClient{
-		baseUrl: *baseUrl,
+	tlsConfig := config.TLSConfig
+	certUser := ""
+	if config.ClientCertAuth != nil {
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{} //nolint:gosec
+		} else {
+			tlsConfig = tlsConfig.Clone()
+		}
+		tlsConfig.Certificates = []tls.Certificate{config.ClientCertAuth.Certificate}
+		certUser = config.ClientCertAuth.Username
+	}
+
+	transport := newHTTPTransport(config, tlsConfig)
+	if config.ProxyURL != "" {
+		proxy, err := proxyURL(config)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	accessToken := ""
+	if config.JWTAuth != nil {
+		accessToken = config.JWTAuth.AccessToken
+	}
+
+	acceptEncoding, err := httpCompression(config.Compression)
+	if err != nil {
+		return nil, err
+	}
+	if acceptEncoding != "" {
+		query := baseUrl.Query()
+		query.Set("enable_http_compression", "1")
+		baseUrl.RawQuery = query.Encode()
+	}
+
+	client := &httpClient{
+		baseUrl:        *baseUrl,
+		userAgent:      config.UserAgent,
+		certUser:       certUser,
+		accessToken:    accessToken,
+		acceptEncoding: acceptEncoding,
+		preStatements:  config.PreStatements,
+		headers:        httpHeaders(config.Headers),
 		client: &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: config.TLSConfig,
-			},
+			Transport: transport,
 		},
-	}, nil
+	}
+
+	return withRetry(withQueryTimeout(withRedactedErrors(client), config.QueryTimeout), config.MaxRetries, config.RetryBackoff), nil
+}
+
+// sessionQuery returns the query parameters setting the database and settings of the session.
+func sessionQuery(config HTTPClientConfig) url.Values {
+	query := url.Values{}
+	for name, value := range config.Settings {
+		query.Set(name, value)
+	}
+	if config.Database != "" {
+		query.Set("database", config.Database)
+	}
+
+	return query
+}
+
+// httpHeaders returns the given headers with their canonical names.
+func httpHeaders(headers map[string]string) http.Header {
+	ret := http.Header{}
+	for name, value := range headers {
+		ret.Set(name, value)
+	}
+
+	return ret
+}
+
+// redactedHeaders returns the names of the given headers with their values redacted, so that they can be logged.
+func redactedHeaders(headers http.Header) map[string]string {
+	ret := make(map[string]string)
+	for name := range headers {
+		ret[name] = "<redacted>"
+	}
+
+	return ret
+}
+
+// proxyURL returns the URL of the proxy, holding the proxy credentials so that they are sent in the
+// Proxy-Authorization header.
+func proxyURL(config HTTPClientConfig) (*url.URL, error) {
+	proxy, err := url.Parse(config.ProxyURL)
+	if err != nil {
+		return nil, errors.WithMessage(err, "cannot parse proxy URL")
+	}
+	if (proxy.Scheme != "http" && proxy.Scheme != "https") || proxy.Host == "" {
+		return nil, errors.New(fmt.Sprintf("invalid proxy URL %q, expected http://host:port or https://host:port", config.ProxyURL))
+	}
+
+	if config.ProxyUsername != "" {
+		proxy.User = url.UserPassword(config.ProxyUsername, config.ProxyPassword)
+	} else if config.ProxyPassword != "" {
+		return nil, errors.New("ProxyPassword requires ProxyUsername")
+	}
+
+	return proxy, nil
+}
+
+// newHTTPTransport returns a transport bounding the connections to the server like the native client does.
+func newHTTPTransport(config HTTPClientConfig, tlsConfig *tls.Config) *http.Transport {
+	maxIdleConns := defaultHTTPMaxIdleConns
+	if config.MaxIdleConns > 0 {
+		maxIdleConns = config.MaxIdleConns
+	}
+	maxOpenConns := maxIdleConns + 5
+	if config.MaxOpenConns > 0 {
+		maxOpenConns = config.MaxOpenConns
+	}
+	connMaxLifetime := defaultHTTPConnMaxLifetime
+	if config.ConnMaxLifetime > 0 {
+		connMaxLifetime = config.ConnMaxLifetime
+	}
+
+	return &http.Transport{
+		TLSClientConfig:     tlsConfig,
+		MaxConnsPerHost:     maxOpenConns,
+		MaxIdleConns:        maxIdleConns,
+		MaxIdleConnsPerHost: maxIdleConns,
+		IdleConnTimeout:     connMaxLifetime,
+	}
 }
 
 func (i *httpClient) Select(ctx context.Context, qry string, callback func(Row) error) error {
-	body, err := i.runQuery(ctx, qry)
+	body, err := i.runQuery(ctx, qry, "")
 	if err != nil {
 		return errors.WithMessage(err, "error running query")
 	}
@@ -98,7 +299,19 @@ func (i *httpClient) Select(ctx context.Context, qry string, callback func(Row)
 }
 
 func (i *httpClient) Exec(ctx context.Context, qry string) error {
-	_, err := i.runQuery(ctx, qry)
+	sessionID := ""
+	if len(i.preStatements) > 0 {
+		// Requests with the same session_id share their settings, so that SET statements apply to the query.
+		sessionID = uuid.NewString()
+		for _, statement := range i.preStatements {
+			_, err := i.runQuery(ctx, statement, sessionID)
+			if err != nil {
+				return errors.WithMessage(err, "error running pre statement")
+			}
+		}
+	}
+
+	_, err := i.runQuery(ctx, qry, sessionID)
 	if err != nil {
 		return errors.WithMessage(err, "error running query")
 	}
@@ -106,15 +319,46 @@ func (i *httpClient) Exec(ctx context.Context, qry string) error {
 	return nil
 }
 
-func (i *httpClient) runQuery(ctx context.Context, qry string) (string, error) {
-	ctx = tflog.SetField(ctx, "Query", qry)
+// runQuery runs qry in the session sessionID, or without a session when it is empty.
+func (i *httpClient) runQuery(ctx context.Context, qry string, sessionID string) (string, error) {
+	ctx = tflog.SetField(ctx, "Query", RedactSecrets(qry))
+	if len(i.headers) > 0 {
+		ctx = tflog.SetField(ctx, "Headers", redactedHeaders(i.headers))
+	}
+
+	queryUrl := i.baseUrl
+	if sessionID != "" {
+		query := queryUrl.Query()
+		query.Set("session_id", sessionID)
+		queryUrl.RawQuery = query.Encode()
+	}
 
-	req, err := http.NewRequest(http.MethodPost, i.baseUrl.String(), strings.NewReader(qry))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, queryUrl.String(), strings.NewReader(qry))
 	if err != nil {
 		return "", errors.WithMessage(err, "error preparing HTTP request")
 	}
 
-	req.Header.Add("X-ClickHouse-Format", "JSONCompactStrings")
+	// Custom headers come first, so that the ones below can't be overridden. The basic auth credentials of the URL are
+	// only sent when there is no Authorization header.
+	for name, values := range i.headers {
+		req.Header[name] = slices.Clone(values)
+	}
+	req.Header.Set("X-ClickHouse-Format", "JSONCompactStrings")
+	if i.userAgent != "" {
+		req.Header.Set("User-Agent", i.userAgent)
+	}
+	if i.accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+i.accessToken)
+	}
+	if i.certUser != "" {
+		// The server checks the common name of the client certificate against the one of the user.
+		req.Header.Set("X-ClickHouse-User", i.certUser)
+		req.Header.Set("X-ClickHouse-SSL-Certificate-Auth", "on")
+	}
+	if i.acceptEncoding != "" {
+		// Setting Accept-Encoding disables the transparent decompression of the transport, responses are decoded below.
+		req.Header.Set("Accept-Encoding", i.acceptEncoding)
+	}
 
 	resp, err := i.client.Do(req)
 	if err != nil {
@@ -125,7 +369,14 @@ func (i *httpClient) runQuery(ctx context.Context, qry string) (string, error) {
 		// best-effort close; ignore close error to avoid shadowing the main error path.
 		_ = resp.Body.Close()
 	}()
-	body, err := io.ReadAll(resp.Body)
+	reader, err := decompressBody(resp.Body, resp.Header.Get("Content-Encoding"))
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	body, err := io.ReadAll(reader)
 	if err != nil {
 		return "", errors.WithMessage(err, "error reading response")
 	}
@@ -137,10 +388,10 @@ func (i *httpClient) runQuery(ctx context.Context, qry string) (string, error) {
 		body = buf.Bytes()
 	}
 
-	ctx = tflog.SetField(ctx, "QueryResult", string(body))
+	ctx = tflog.SetField(ctx, "QueryResult", RedactSecrets(string(body)))
 
 	if resp.StatusCode != http.StatusOK {
-		return "", errors.New(string(body))
+		return "", errors.WithStack(&httpStatusError{StatusCode: resp.StatusCode, Body: string(body)})
 	}
 
 	tflog.Debug(ctx, "Run Query")
@@ -0,0 +1,130 @@
+package clickhouseclient
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_newHTTPTransport(t *testing.T) {
+	tests := []struct {
+		name                string
+		config              HTTPClientConfig
+		wantMaxConnsPerHost int
+		wantMaxIdleConns    int
+		wantIdleConnTimeout time.Duration
+	}{
+		{
+			name:                "Defaults",
+			config:              HTTPClientConfig{},
+			wantMaxConnsPerHost: 10,
+			wantMaxIdleConns:    5,
+			wantIdleConnTimeout: time.Hour,
+		},
+		{
+			name:                "Custom pool",
+			config:              HTTPClientConfig{MaxOpenConns: 4, MaxIdleConns: 2, ConnMaxLifetime: 5 * time.Minute},
+			wantMaxConnsPerHost: 4,
+			wantMaxIdleConns:    2,
+			wantIdleConnTimeout: 5 * time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newHTTPTransport(tt.config, nil)
+			if got.MaxConnsPerHost != tt.wantMaxConnsPerHost {
+				t.Errorf("MaxConnsPerHost = %d, want %d", got.MaxConnsPerHost, tt.wantMaxConnsPerHost)
+			}
+			if got.MaxIdleConns != tt.wantMaxIdleConns || got.MaxIdleConnsPerHost != tt.wantMaxIdleConns {
+				t.Errorf("MaxIdleConns = %d, MaxIdleConnsPerHost = %d, want %d", got.MaxIdleConns, got.MaxIdleConnsPerHost, tt.wantMaxIdleConns)
+			}
+			if got.IdleConnTimeout != tt.wantIdleConnTimeout {
+				t.Errorf("IdleConnTimeout = %s, want %s", got.IdleConnTimeout, tt.wantIdleConnTimeout)
+			}
+		})
+	}
+}
+
+func Test_httpClient_headers(t *testing.T) {
+	tests := []struct {
+		name              string
+		headers           map[string]string
+		wantGateway       string
+		wantAuthorization string
+	}{
+		{
+			name:              "No custom headers",
+			headers:           nil,
+			wantAuthorization: "Basic " + base64.StdEncoding.EncodeToString([]byte("default:secret")),
+		},
+		{
+			name:              "Custom header kept along basic auth",
+			headers:           map[string]string{"x-gateway-token": "abc"},
+			wantGateway:       "abc",
+			wantAuthorization: "Basic " + base64.StdEncoding.EncodeToString([]byte("default:secret")),
+		},
+		{
+			name:              "Authorization explicitly overridden",
+			headers:           map[string]string{"Authorization": "Gateway abc"},
+			wantAuthorization: "Gateway abc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got http.Header
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Clone()
+			}))
+			defer server.Close()
+
+			serverURL, err := url.Parse(server.URL)
+			if err != nil {
+				t.Fatalf("url.Parse() error = %v", err)
+			}
+			port, err := strconv.Atoi(serverURL.Port())
+			if err != nil {
+				t.Fatalf("strconv.Atoi() error = %v", err)
+			}
+
+			client, err := NewHTTPClient(HTTPClientConfig{
+				Host:      serverURL.Hostname(),
+				Port:      uint16(port),
+				BasicAuth: &BasicAuth{Username: "default", Password: "secret"},
+				Headers:   tt.headers,
+			})
+			if err != nil {
+				t.Fatalf("NewHTTPClient() error = %v", err)
+			}
+
+			err = client.Exec(context.Background(), "SELECT 1")
+			if err != nil {
+				t.Fatalf("Exec() error = %v", err)
+			}
+
+			if got.Get("X-Gateway-Token") != tt.wantGateway {
+				t.Errorf("X-Gateway-Token got = %q, want %q", got.Get("X-Gateway-Token"), tt.wantGateway)
+			}
+			if got.Get("Authorization") != tt.wantAuthorization {
+				t.Errorf("Authorization got = %q, want %q", got.Get("Authorization"), tt.wantAuthorization)
+			}
+			if got.Get("X-ClickHouse-Format") != "JSONCompactStrings" {
+				t.Errorf("X-ClickHouse-Format got = %q, want %q", got.Get("X-ClickHouse-Format"), "JSONCompactStrings")
+			}
+		})
+	}
+}
+
+func Test_redactedHeaders(t *testing.T) {
+	got := redactedHeaders(httpHeaders(map[string]string{"x-gateway-token": "abc"}))
+
+	want := map[string]string{"X-Gateway-Token": "<redacted>"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("redactedHeaders() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,52 @@
+package clickhouseclient
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"github.com/pingcap/errors"
+)
+
+// httpCompressionMethods are the compression methods supported by the http protocol, sent as Accept-Encoding.
+var httpCompressionMethods = []string{"none", "gzip", "lz4", "zstd"}
+
+// httpCompression returns the Accept-Encoding to send for the given compression method, or an empty string when
+// responses shouldn't be compressed.
+func httpCompression(method string) (string, error) {
+	if !slices.Contains(httpCompressionMethods, method) && method != "" {
+		return "", errors.New(fmt.Sprintf("unsupported compression method %q", method))
+	}
+	if method == "none" {
+		return "", nil
+	}
+
+	return method, nil
+}
+
+// decompressBody returns a reader of body decoded with the given Content-Encoding.
+func decompressBody(body io.Reader, contentEncoding string) (io.ReadCloser, error) {
+	switch contentEncoding {
+	case "":
+		return io.NopCloser(body), nil
+	case "gzip":
+		reader, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, errors.WithMessage(err, "error reading gzip response")
+		}
+		return reader, nil
+	case "lz4":
+		return io.NopCloser(lz4.NewReader(body)), nil
+	case "zstd":
+		decoder, err := zstd.NewReader(body)
+		if err != nil {
+			return nil, errors.WithMessage(err, "error reading zstd response")
+		}
+		return decoder.IOReadCloser(), nil
+	default:
+		return nil, errors.New(fmt.Sprintf("unsupported response encoding %q", contentEncoding))
+	}
+}
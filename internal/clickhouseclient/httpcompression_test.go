@@ -0,0 +1,144 @@
+package clickhouseclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+func Test_NewHTTPClient_compression(t *testing.T) {
+	const response = `{"meta":[{"name":"name","type":"String"}],"data":[["john"]]}`
+
+	compress := map[string]func(w io.Writer) io.WriteCloser{
+		"gzip": func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		"lz4":  func(w io.Writer) io.WriteCloser { return lz4.NewWriter(w) },
+		"zstd": func(w io.Writer) io.WriteCloser {
+			encoder, _ := zstd.NewWriter(w)
+			return encoder
+		},
+	}
+
+	tests := []struct {
+		name               string
+		compression        string
+		wantAcceptEncoding string
+		wantEnabled        string
+	}{
+		{
+			name:               "Not set",
+			compression:        "",
+			wantAcceptEncoding: "",
+			wantEnabled:        "",
+		},
+		{
+			name:               "None",
+			compression:        "none",
+			wantAcceptEncoding: "",
+			wantEnabled:        "",
+		},
+		{
+			name:               "Gzip",
+			compression:        "gzip",
+			wantAcceptEncoding: "gzip",
+			wantEnabled:        "1",
+		},
+		{
+			name:               "LZ4",
+			compression:        "lz4",
+			wantAcceptEncoding: "lz4",
+			wantEnabled:        "1",
+		},
+		{
+			name:               "ZSTD",
+			compression:        "zstd",
+			wantAcceptEncoding: "zstd",
+			wantEnabled:        "1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotAcceptEncoding, gotEnabled string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotEnabled = r.URL.Query().Get("enable_http_compression")
+				gotAcceptEncoding = r.Header.Get("Accept-Encoding")
+
+				newWriter, ok := compress[gotAcceptEncoding]
+				if !ok || gotEnabled != "1" {
+					_, _ = w.Write([]byte(response))
+					return
+				}
+
+				var buf bytes.Buffer
+				writer := newWriter(&buf)
+				_, _ = writer.Write([]byte(response))
+				_ = writer.Close()
+
+				w.Header().Set("Content-Encoding", gotAcceptEncoding)
+				_, _ = w.Write(buf.Bytes())
+			}))
+			defer server.Close()
+
+			serverURL, err := url.Parse(server.URL)
+			if err != nil {
+				t.Fatalf("url.Parse() error = %v", err)
+			}
+			port, err := strconv.Atoi(serverURL.Port())
+			if err != nil {
+				t.Fatalf("strconv.Atoi() error = %v", err)
+			}
+
+			client, err := NewHTTPClient(HTTPClientConfig{
+				Protocol:    "http",
+				Host:        serverURL.Hostname(),
+				Port:        uint16(port),
+				BasicAuth:   &BasicAuth{Username: "default"},
+				Compression: tt.compression,
+			})
+			if err != nil {
+				t.Fatalf("NewHTTPClient() error = %v", err)
+			}
+
+			var names []string
+			err = client.Select(context.Background(), "SELECT name FROM system.users", func(row Row) error {
+				name, err := row.GetString("name")
+				names = append(names, name)
+				return err
+			})
+			if err != nil {
+				t.Fatalf("Select() error = %v", err)
+			}
+
+			if gotAcceptEncoding != tt.wantAcceptEncoding && tt.wantAcceptEncoding != "" {
+				t.Errorf("Accept-Encoding got = %q, want %q", gotAcceptEncoding, tt.wantAcceptEncoding)
+			}
+			if gotEnabled != tt.wantEnabled {
+				t.Errorf("enable_http_compression got = %q, want %q", gotEnabled, tt.wantEnabled)
+			}
+			if len(names) != 1 || names[0] != "john" {
+				t.Errorf("Select() rows = %q, want %q", names, []string{"john"})
+			}
+		})
+	}
+}
+
+func Test_NewHTTPClient_unsupportedCompression(t *testing.T) {
+	_, err := NewHTTPClient(HTTPClientConfig{
+		Protocol:    "http",
+		Host:        "localhost",
+		Port:        8123,
+		BasicAuth:   &BasicAuth{Username: "default"},
+		Compression: "lz4hc",
+	})
+	if err == nil {
+		t.Errorf("NewHTTPClient() expected error for unsupported compression")
+	}
+}
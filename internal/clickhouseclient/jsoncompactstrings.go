@@ -59,6 +59,22 @@ func (j jsonCompatStrings) Rows() []Row {
 				} else {
 					data.Set(colNames[i], val)
 				}
+			case "Int64":
+				val, err := strconv.ParseInt(field, 10, 64)
+				if err != nil {
+					// Failed parsing as number, return value as-is.
+					data.Set(colNames[i], field)
+				} else {
+					data.Set(colNames[i], val)
+				}
+			case "Array(String)":
+				val, err := parseStringArray(field)
+				if err != nil {
+					// Failed parsing as array, return value as-is.
+					data.Set(colNames[i], field)
+				} else {
+					data.Set(colNames[i], val)
+				}
 			default:
 				panic(fmt.Sprintf("unknown data type %q", colTypes[i]))
 			}
@@ -41,6 +41,64 @@ func Test_jsonCompatStrings_Rows(t *testing.T) {
 				}),
 			},
 		},
+		{
+			name: "Array of strings",
+			jsonCompatStrings: jsonCompatStrings{
+				Meta: []struct {
+					Name string
+					Type string
+				}{
+					{
+						Name: "default_roles_list",
+						Type: "Array(String)",
+					},
+				},
+				Data: [][]string{
+					{
+						`['team,ops','bob\'s-role']`,
+					},
+				},
+			},
+			want: []Row{
+				func() Row {
+					row := Row{}
+					row.Set("default_roles_list", []string{"team,ops", "bob's-role"})
+					return row
+				}(),
+			},
+		},
+		{
+			name: "Numbers",
+			jsonCompatStrings: jsonCompatStrings{
+				Meta: []struct {
+					Name string
+					Type string
+				}{
+					{
+						Name: "max_queries",
+						Type: "UInt64",
+					},
+					{
+						Name: "offset",
+						Type: "Int64",
+					},
+				},
+				Data: [][]string{
+					{
+						"18446744073709551615",
+						"-3600",
+					},
+				},
+			},
+			want: []Row{
+				func() Row {
+					row := Row{}
+					row.Set("max_queries", uint64(18446744073709551615))
+					row.Set("offset", int64(-3600))
+					return row
+				}(),
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -4,7 +4,11 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"net"
 	"reflect"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
@@ -16,32 +20,99 @@ import (
 
 const defaultDatabase = "default"
 
+// nativeCompressionMethods are the compression methods supported by the native protocol.
+var nativeCompressionMethods = map[string]clickhouse.CompressionMethod{
+	"none":  clickhouse.CompressionNone,
+	"lz4":   clickhouse.CompressionLZ4,
+	"lz4hc": clickhouse.CompressionLZ4HC,
+	"zstd":  clickhouse.CompressionZSTD,
+}
+
 type nativeClient struct {
 	connection driver.Conn
+	// session holds a single connection, to run preStatements and the following query on the same connection.
+	session     driver.Conn
+	sessionLock sync.Mutex
+	// preStatements are run before every Exec, on session.
+	preStatements []string
 }
 
 type NativeClientConfig struct {
-	Host             string
-	Port             uint16
+	Host string
+	Port uint16
+	// SocketPath is the path of a unix socket to connect to instead of Host and Port.
+	SocketPath string
+	// UserAgent in the form name/version is reported to the server as the client name, if set.
+	UserAgent        string
 	UserPasswordAuth *UserPasswordAuth
-	EnableTLS        bool
+	// ClientCertAuth authenticates with a TLS client certificate instead of a password. It requires EnableTLS.
+	ClientCertAuth *ClientCertAuth
+	EnableTLS      bool
+	// TLSConfig is the TLS configuration used when EnableTLS is set. Nil means the default configuration.
+	TLSConfig *tls.Config
+	// BlockBufferSize is the number of blocks buffered while reading query results. Zero means clickhouse-go default.
+	BlockBufferSize uint8
+	// Compression is the name of the compression method to use (none, lz4, lz4hc or zstd). Empty means no compression.
+	Compression string
+	// MaxRetries is the number of times a query failing with a network error is retried.
+	// Exec is only retried when the query never reached the server. Zero disables retries.
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry, doubled after each attempt.
+	RetryBackoff time.Duration
+	// QueryTimeout is the deadline of every query. Zero means no deadline.
+	QueryTimeout time.Duration
+	// MaxOpenConns is the maximum number of connections opened to the server. Zero means clickhouse-go default.
+	MaxOpenConns int
+	// MaxIdleConns is the maximum number of connections kept open while unused. Zero means clickhouse-go default.
+	MaxIdleConns int
+	// ConnMaxLifetime is how long a connection can be reused for. Zero means clickhouse-go default.
+	ConnMaxLifetime time.Duration
+	// Database is the default database of the queries, overriding the one of the authentication method. Empty means
+	// the database of the authentication method.
+	Database string
+	// Settings are set on the session of every query.
+	Settings map[string]string
+	// PreStatements are run before every Exec, such as SET statements enabling experimental features. They run on
+	// a dedicated connection shared with the query, since SET statements only apply to the connection they run on.
+	PreStatements []string
 }
 
 func NewNativeClient(config NativeClientConfig) (ClickhouseClient, error) {
-	if config.Host == "" {
-		return nil, errors.New("Host is required")
-	}
-	if config.Port == 0 {
-		return nil, errors.New("Port is required")
+	if config.SocketPath == "" {
+		if config.Host == "" {
+			return nil, errors.New("Host is required")
+		}
+		if config.Port == 0 {
+			return nil, errors.New("Port is required")
+		}
 	}
-	if config.UserPasswordAuth == nil {
+	if (config.UserPasswordAuth == nil) == (config.ClientCertAuth == nil) {
 		return nil, errors.New("Exactly one authentication method is required")
 	}
+	if config.ClientCertAuth != nil && !config.EnableTLS {
+		return nil, errors.New("Client certificate authentication requires TLS")
+	}
 
 	options := clickhouse.Options{
 		Addr: []string{fmt.Sprintf("%s:%d", config.Host, config.Port)},
 	}
 
+	if config.UserAgent != "" {
+		name, version, _ := strings.Cut(config.UserAgent, "/")
+		options.ClientInfo.Products = append(options.ClientInfo.Products, struct {
+			Name    string
+			Version string
+		}{Name: name, Version: version})
+	}
+
+	if config.SocketPath != "" {
+		options.Addr = []string{config.SocketPath}
+		options.DialContext = func(ctx context.Context, addr string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", addr)
+		}
+	}
+
 	if config.UserPasswordAuth != nil {
 		auth := clickhouse.Auth{}
 		auth.Database = config.UserPasswordAuth.Database
@@ -55,8 +126,61 @@ func NewNativeClient(config NativeClientConfig) (ClickhouseClient, error) {
 		options.Auth = auth
 	}
 
+	if config.ClientCertAuth != nil {
+		auth := clickhouse.Auth{}
+		auth.Database = config.ClientCertAuth.Database
+		auth.Username = config.ClientCertAuth.Username
+
+		if auth.Database == "" {
+			auth.Database = defaultDatabase
+		}
+
+		options.Auth = auth
+	}
+
+	if config.Database != "" {
+		options.Auth.Database = config.Database
+	}
+
+	if len(config.Settings) > 0 {
+		options.Settings = clickhouse.Settings{}
+		for name, value := range config.Settings {
+			options.Settings[name] = value
+		}
+	}
+
 	if config.EnableTLS {
 		options.TLS = &tls.Config{} //nolint:gosec
+		if config.TLSConfig != nil {
+			options.TLS = config.TLSConfig.Clone()
+		}
+		if config.ClientCertAuth != nil {
+			options.TLS.Certificates = []tls.Certificate{config.ClientCertAuth.Certificate}
+		}
+	}
+
+	if config.BlockBufferSize > 0 {
+		options.BlockBufferSize = config.BlockBufferSize
+	}
+
+	if config.MaxOpenConns > 0 {
+		options.MaxOpenConns = config.MaxOpenConns
+	}
+	if config.MaxIdleConns > 0 {
+		options.MaxIdleConns = config.MaxIdleConns
+	}
+	if config.ConnMaxLifetime > 0 {
+		options.ConnMaxLifetime = config.ConnMaxLifetime
+	}
+
+	if config.Compression != "" {
+		method, ok := nativeCompressionMethods[config.Compression]
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("unsupported compression method %q", config.Compression))
+		}
+		options.Compression = &clickhouse.Compression{
+			Method: method,
+		}
 	}
 
 	conn, err := clickhouse.Open(&options)
@@ -73,13 +197,27 @@ func NewNativeClient(config NativeClientConfig) (ClickhouseClient, error) {
 		return nil, err
 	}
 
-	return &nativeClient{
-		connection: conn,
-	}, nil
+	client := &nativeClient{
+		connection:    conn,
+		preStatements: config.PreStatements,
+	}
+
+	if len(config.PreStatements) > 0 {
+		sessionOptions := options
+		sessionOptions.MaxOpenConns = 1
+		sessionOptions.MaxIdleConns = 1
+
+		client.session, err = clickhouse.Open(&sessionOptions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return withRetry(withQueryTimeout(withRedactedErrors(client), config.QueryTimeout), config.MaxRetries, config.RetryBackoff), nil
 }
 
 func (i *nativeClient) Select(ctx context.Context, qry string, callback func(Row) error) error {
-	ctx = tflog.SetField(ctx, "Query", qry)
+	ctx = tflog.SetField(ctx, "Query", RedactSecrets(qry))
 	tflog.Debug(ctx, "Running Query")
 
 	rows, err := i.connection.Query(ctx, qry)
@@ -118,6 +256,11 @@ func (i *nativeClient) Select(ctx context.Context, qry string, callback func(Row
 				ret.Set(rows.Columns()[i], *v)
 			case *uint64:
 				ret.Set(rows.Columns()[i], *v)
+			case *int64:
+				ret.Set(rows.Columns()[i], *v)
+			case *[]string:
+				// Array(String), copied since the scan variable is reused for the next rows.
+				ret.Set(rows.Columns()[i], slices.Clone(*v))
 			default:
 				return errors.New(fmt.Sprintf("unsupported column type: %s", reflect.TypeOf(v)))
 			}
@@ -132,9 +275,13 @@ func (i *nativeClient) Select(ctx context.Context, qry string, callback func(Row
 }
 
 func (i *nativeClient) Exec(ctx context.Context, qry string) error {
-	ctx = tflog.SetField(ctx, "Query", qry)
+	ctx = tflog.SetField(ctx, "Query", RedactSecrets(qry))
 	tflog.Debug(ctx, "Running Query")
 
+	if i.session != nil {
+		return i.execInSession(ctx, qry)
+	}
+
 	err := i.connection.Exec(ctx, qry)
 	if err != nil {
 		return errors.WithMessage(err, "error executing query")
@@ -142,3 +289,24 @@ func (i *nativeClient) Exec(ctx context.Context, qry string) error {
 
 	return nil
 }
+
+// execInSession runs the pre statements and qry on the single connection of session. The lock prevents
+// concurrent queries from running between them.
+func (i *nativeClient) execInSession(ctx context.Context, qry string) error {
+	i.sessionLock.Lock()
+	defer i.sessionLock.Unlock()
+
+	for _, statement := range i.preStatements {
+		err := i.session.Exec(ctx, statement)
+		if err != nil {
+			return errors.WithMessage(err, "error running pre statement")
+		}
+	}
+
+	err := i.session.Exec(ctx, qry)
+	if err != nil {
+		return errors.WithMessage(err, "error executing query")
+	}
+
+	return nil
+}
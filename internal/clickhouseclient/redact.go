@@ -0,0 +1,61 @@
+package clickhouseclient
+
+import (
+	"context"
+	"regexp"
+)
+
+// secretPattern matches the quoted secret following BY in identification clauses, e.g. IDENTIFIED WITH sha256_hash BY '...'.
+// An unterminated quote, as found in truncated queries echoed by the server, is matched up to the end of the text.
+var secretPattern = regexp.MustCompile(`(?i)(\bBY\s+)'(?:[^'\\]|\\.)*(?:'|$)`)
+
+// RedactSecrets returns s with the passwords and password hashes of identification clauses replaced, so that it can be logged.
+func RedactSecrets(s string) string {
+	return secretPattern.ReplaceAllString(s, "${1}'<redacted>'")
+}
+
+// redactingClient wraps a ClickhouseClient and removes secrets from the errors it returns, since drivers and servers can
+// echo the query in their error messages.
+type redactingClient struct {
+	client ClickhouseClient
+}
+
+func withRedactedErrors(client ClickhouseClient) ClickhouseClient {
+	return &redactingClient{
+		client: client,
+	}
+}
+
+func (r *redactingClient) Select(ctx context.Context, qry string, callback func(Row) error) error {
+	return redactError(r.client.Select(ctx, qry, callback))
+}
+
+func (r *redactingClient) Exec(ctx context.Context, qry string) error {
+	return redactError(r.client.Exec(ctx, qry))
+}
+
+// redactedError is an error whose message has its secrets redacted. The original error is only kept to classify it,
+// e.g. with errors.Cause and errors.As, its message and stack trace are never printed.
+type redactedError struct {
+	err error
+}
+
+func redactError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &redactedError{err: err}
+}
+
+func (e *redactedError) Error() string {
+	return RedactSecrets(e.err.Error())
+}
+
+func (e *redactedError) Cause() error {
+	return e.err
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
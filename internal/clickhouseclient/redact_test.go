@@ -0,0 +1,81 @@
+package clickhouseclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pingcap/errors"
+)
+
+func Test_RedactSecrets(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{
+			name: "Password hash",
+			s:    "CREATE USER `john` IDENTIFIED WITH sha256_hash BY 'abcdef';",
+			want: "CREATE USER `john` IDENTIFIED WITH sha256_hash BY '<redacted>';",
+		},
+		{
+			name: "Several methods with escaped quotes",
+			s:    "ALTER USER `john` IDENTIFIED WITH plaintext_password BY 'it\\'s secret', bcrypt_hash BY '$2y$12$abc';",
+			want: "ALTER USER `john` IDENTIFIED WITH plaintext_password BY '<redacted>', bcrypt_hash BY '<redacted>';",
+		},
+		{
+			name: "Truncated query",
+			s:    "Syntax error: failed at position 40 (IDENTIFIED WITH plaintext_password BY 'secr",
+			want: "Syntax error: failed at position 40 (IDENTIFIED WITH plaintext_password BY '<redacted>'",
+		},
+		{
+			name: "No secret",
+			s:    "SELECT `name` FROM `system`.`users` ORDER BY `name` ASC;",
+			want: "SELECT `name` FROM `system`.`users` ORDER BY `name` ASC;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RedactSecrets(tt.s); got != tt.want {
+				t.Errorf("RedactSecrets() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// echoingClient fails every query with an error repeating it, as some servers do.
+type echoingClient struct {
+	statusCode int
+}
+
+func (e *echoingClient) Select(ctx context.Context, qry string, callback func(Row) error) error {
+	return e.Exec(ctx, qry)
+}
+
+func (e *echoingClient) Exec(ctx context.Context, qry string) error {
+	return errors.WithStack(&httpStatusError{StatusCode: e.statusCode, Body: fmt.Sprintf("Code: 62. DB::Exception: Syntax error in %s", qry)})
+}
+
+func Test_redactingClient(t *testing.T) {
+	client := withRetry(withRedactedErrors(&echoingClient{statusCode: http.StatusServiceUnavailable}), 1, time.Millisecond)
+
+	err := client.Exec(context.Background(), "CREATE USER `john` IDENTIFIED WITH plaintext_password BY 'changeme';")
+	if err == nil {
+		t.Fatalf("Exec() error = nil, want an error")
+	}
+
+	for _, format := range []string{"%v", "%+v"} {
+		if msg := fmt.Sprintf(format, errors.WithMessage(err, "error running query")); strings.Contains(msg, "changeme") {
+			t.Errorf("Exec() error formatted with %s contains the password: %s", format, msg)
+		}
+	}
+
+	// The original error is still classified, a 503 is retried.
+	if !isTransientError(err) {
+		t.Errorf("isTransientError() = false, want true")
+	}
+}
@@ -0,0 +1,135 @@
+package clickhouseclient
+
+import (
+	"context"
+	"crypto/tls"
+	stderrors "errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/pingcap/errors"
+)
+
+// retryingClient wraps a ClickhouseClient and retries queries failing with transient errors.
+type retryingClient struct {
+	client     ClickhouseClient
+	maxRetries int
+	backoff    time.Duration
+}
+
+// withRetry wraps client so that queries failing with transient errors are retried up to maxRetries times,
+// waiting backoff before the first retry and doubling the wait after each attempt.
+func withRetry(client ClickhouseClient, maxRetries int, backoff time.Duration) ClickhouseClient {
+	if maxRetries <= 0 {
+		return client
+	}
+
+	return &retryingClient{
+		client:     client,
+		maxRetries: maxRetries,
+		backoff:    backoff,
+	}
+}
+
+func (r *retryingClient) Select(ctx context.Context, qry string, callback func(Row) error) error {
+	called := false
+	return r.do(ctx, func() error {
+		return r.client.Select(ctx, qry, func(row Row) error {
+			called = true
+			return callback(row)
+		})
+	}, func(err error) bool {
+		// Rows already handed to the callback can't be taken back, so the query is not run again.
+		return !called && isTransientError(err)
+	})
+}
+
+func (r *retryingClient) Exec(ctx context.Context, qry string) error {
+	// A statement may have been run by the server even if the response was lost, so it is only
+	// sent again when the error proves it never reached the server.
+	return r.do(ctx, func() error {
+		return r.client.Exec(ctx, qry)
+	}, isUnsentError)
+}
+
+func (r *retryingClient) do(ctx context.Context, run func() error, canRetry func(error) bool) error {
+	backoff := r.backoff
+	for attempt := 0; ; attempt++ {
+		err := run()
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= r.maxRetries || ctx.Err() != nil || !canRetry(err) {
+			return err
+		}
+
+		tflog.Warn(ctx, "query failed with a transient error, retrying", map[string]any{
+			"attempt":     attempt + 1,
+			"max_retries": r.maxRetries,
+			"retry_in":    backoff.String(),
+			"error":       err.Error(),
+		})
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.WithMessage(err, "cancelled while waiting to retry")
+		case <-timer.C:
+		}
+
+		backoff *= 2
+	}
+}
+
+// isTransientError returns true if err is a network error or an HTTP 503 response, that are worth retrying.
+// Errors returned by the server for the query itself, such as syntax or permission errors, are not transient.
+func isTransientError(err error) bool {
+	err = errors.Cause(err)
+
+	var statusErr *httpStatusError
+	if stderrors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusServiceUnavailable
+	}
+
+	if stderrors.Is(err, context.Canceled) || stderrors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	// A server certificate that can't be verified won't become valid by trying again.
+	var certErr *tls.CertificateVerificationError
+	if stderrors.As(err, &certErr) {
+		return false
+	}
+
+	if stderrors.Is(err, io.EOF) || stderrors.Is(err, io.ErrUnexpectedEOF) ||
+		stderrors.Is(err, syscall.ECONNRESET) || stderrors.Is(err, syscall.ECONNREFUSED) || stderrors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var netErr net.Error
+	return stderrors.As(err, &netErr)
+}
+
+// isUnsentError returns true if err proves the query never reached the server: the connection could not be
+// established, or the load balancer answered with an HTTP 503 without forwarding the request.
+func isUnsentError(err error) bool {
+	err = errors.Cause(err)
+
+	var statusErr *httpStatusError
+	if stderrors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusServiceUnavailable
+	}
+
+	if stderrors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+
+	var opErr *net.OpError
+	return stderrors.As(err, &opErr) && opErr.Op == "dial"
+}
@@ -0,0 +1,239 @@
+package clickhouseclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/pingcap/errors"
+)
+
+type flakyClient struct {
+	errs  []error
+	calls int
+}
+
+func (f *flakyClient) next() error {
+	f.calls++
+	if f.calls <= len(f.errs) {
+		return f.errs[f.calls-1]
+	}
+	return nil
+}
+
+func (f *flakyClient) Select(ctx context.Context, qry string, callback func(Row) error) error {
+	if err := f.next(); err != nil {
+		return err
+	}
+	return callback(Row{})
+}
+
+func (f *flakyClient) Exec(ctx context.Context, qry string) error {
+	return f.next()
+}
+
+func Test_retryingClient_Exec(t *testing.T) {
+	tests := []struct {
+		name       string
+		errs       []error
+		maxRetries int
+		wantCalls  int
+		wantErr    bool
+	}{
+		{
+			name:       "Connection refused is retried",
+			errs:       []error{errors.WithMessage(syscall.ECONNREFUSED, "error executing query")},
+			maxRetries: 3,
+			wantCalls:  2,
+		},
+		{
+			name:       "Dial error is retried",
+			errs:       []error{&url.Error{Op: "Post", URL: "http://localhost:8123", Err: &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "localhost"}}}, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ETIMEDOUT}},
+			maxRetries: 3,
+			wantCalls:  3,
+		},
+		{
+			name:       "Service unavailable is retried",
+			errs:       []error{errors.WithStack(&httpStatusError{StatusCode: http.StatusServiceUnavailable})},
+			maxRetries: 3,
+			wantCalls:  2,
+		},
+		{
+			name:       "EOF mid-response is not retried",
+			errs:       []error{errors.WithMessage(io.ErrUnexpectedEOF, "error executing query")},
+			maxRetries: 3,
+			wantCalls:  1,
+			wantErr:    true,
+		},
+		{
+			name:       "Connection reset is not retried",
+			errs:       []error{&net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}},
+			maxRetries: 3,
+			wantCalls:  1,
+			wantErr:    true,
+		},
+		{
+			name:       "Gives up after max retries",
+			errs:       []error{syscall.ECONNREFUSED, syscall.ECONNREFUSED, syscall.ECONNREFUSED},
+			maxRetries: 2,
+			wantCalls:  3,
+			wantErr:    true,
+		},
+		{
+			name:       "Certificate verification error is not retried",
+			errs:       []error{&url.Error{Op: "Post", URL: "https://localhost:8443", Err: &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}}},
+			maxRetries: 3,
+			wantCalls:  1,
+			wantErr:    true,
+		},
+		{
+			name:       "Syntax error is not retried",
+			errs:       []error{errors.WithStack(&httpStatusError{StatusCode: http.StatusBadRequest, Body: "Code: 62. DB::Exception: Syntax error"})},
+			maxRetries: 3,
+			wantCalls:  1,
+			wantErr:    true,
+		},
+		{
+			name:       "Permission error is not retried",
+			errs:       []error{errors.New("code: 497, message: Not enough privileges")},
+			maxRetries: 3,
+			wantCalls:  1,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &flakyClient{errs: tt.errs}
+			client := withRetry(fake, tt.maxRetries, 0)
+
+			err := client.Exec(context.Background(), "SELECT 1")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Exec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if fake.calls != tt.wantCalls {
+				t.Errorf("Exec() ran %d times, want %d", fake.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func Test_retryingClient_Select(t *testing.T) {
+	tests := []struct {
+		name       string
+		errs       []error
+		maxRetries int
+		wantCalls  int
+		wantErr    bool
+	}{
+		{
+			name:       "Connection reset is retried",
+			errs:       []error{errors.WithMessage(syscall.ECONNRESET, "error executing query")},
+			maxRetries: 3,
+			wantCalls:  2,
+		},
+		{
+			name:       "EOF is retried",
+			errs:       []error{io.EOF, io.EOF},
+			maxRetries: 3,
+			wantCalls:  3,
+		},
+		{
+			name:       "Gives up after max retries",
+			errs:       []error{io.EOF, io.EOF, io.EOF},
+			maxRetries: 2,
+			wantCalls:  3,
+			wantErr:    true,
+		},
+		{
+			name:       "Syntax error is not retried",
+			errs:       []error{errors.WithStack(&httpStatusError{StatusCode: http.StatusBadRequest, Body: "Code: 62. DB::Exception: Syntax error"})},
+			maxRetries: 3,
+			wantCalls:  1,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &flakyClient{errs: tt.errs}
+			client := withRetry(fake, tt.maxRetries, 0)
+
+			err := client.Select(context.Background(), "SELECT 1", func(Row) error { return nil })
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Select() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if fake.calls != tt.wantCalls {
+				t.Errorf("Select() ran %d times, want %d", fake.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func Test_retryingClient_Select_callbackErrorNotRetried(t *testing.T) {
+	fake := &flakyClient{}
+	client := withRetry(fake, 3, 0)
+
+	err := client.Select(context.Background(), "SELECT 1", func(Row) error {
+		return io.EOF
+	})
+	if err == nil {
+		t.Fatalf("Select() expected error")
+	}
+	if fake.calls != 1 {
+		t.Errorf("Select() ran %d times, want 1", fake.calls)
+	}
+}
+
+func Test_httpClient_retriesServiceUnavailable(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"meta":[{"name":"x","type":"UInt8"}],"data":[["1"]]}`))
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		t.Fatalf("strconv.Atoi() error = %v", err)
+	}
+
+	client, err := NewHTTPClient(HTTPClientConfig{
+		Host:       u.Hostname(),
+		Port:       uint16(port),
+		BasicAuth:  &BasicAuth{Username: "default"},
+		MaxRetries: 2,
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPClient() error = %v", err)
+	}
+
+	rows := 0
+	err = client.Select(context.Background(), "SELECT 1 AS x", func(Row) error {
+		rows++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Select() error = %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("server received %d requests, want 2", requests)
+	}
+	if rows != 1 {
+		t.Errorf("Select() returned %d rows, want 1", rows)
+	}
+}
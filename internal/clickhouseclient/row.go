@@ -71,6 +71,32 @@ func (r *Row) GetUInt64(fieldName string) (uint64, error) {
 	return val.(uint64), nil
 }
 
+func (r *Row) GetInt64(fieldName string) (int64, error) {
+	val, ok := r.data[fieldName]
+	if !ok {
+		return 0, errors.New(fmt.Sprintf("field %s was not found in row", fieldName))
+	}
+
+	if reflect.TypeOf(val).Name() != "int64" {
+		return 0, errors.New(fmt.Sprintf("field %s is not an int64 (%s)", fieldName, reflect.TypeOf(val).Name()))
+	}
+
+	return val.(int64), nil
+}
+
+func (r *Row) GetStringSlice(fieldName string) ([]string, error) {
+	val, ok := r.data[fieldName]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("field %s was not found in row", fieldName))
+	}
+
+	if reflect.TypeOf(val).String() != "[]string" {
+		return nil, errors.New(fmt.Sprintf("field %s is not a string slice (%s)", fieldName, reflect.TypeOf(val).String()))
+	}
+
+	return val.([]string), nil
+}
+
 func (r *Row) Set(fieldName string, val interface{}) {
 	if r.data == nil {
 		r.data = make(map[string]interface{})
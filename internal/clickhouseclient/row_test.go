@@ -0,0 +1,45 @@
+package clickhouseclient
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRow_GetInt64(t *testing.T) {
+	row := Row{}
+	row.Set("offset", int64(-3600))
+	row.Set("count", uint64(1))
+
+	got, err := row.GetInt64("offset")
+	if err != nil {
+		t.Fatalf("GetInt64() error = %v", err)
+	}
+	if got != -3600 {
+		t.Errorf("GetInt64() got = %d, want %d", got, -3600)
+	}
+
+	if _, err := row.GetInt64("count"); err == nil {
+		t.Errorf("GetInt64() expected error for a uint64 field")
+	}
+	if _, err := row.GetInt64("missing"); err == nil {
+		t.Errorf("GetInt64() expected error for a missing field")
+	}
+}
+
+func TestRow_GetStringSlice(t *testing.T) {
+	row := Row{}
+	row.Set("roles", []string{"reader", "writer"})
+	row.Set("name", "john")
+
+	got, err := row.GetStringSlice("roles")
+	if err != nil {
+		t.Fatalf("GetStringSlice() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"reader", "writer"}) {
+		t.Errorf("GetStringSlice() got = %q, want %q", got, []string{"reader", "writer"})
+	}
+
+	if _, err := row.GetStringSlice("name"); err == nil {
+		t.Errorf("GetStringSlice() expected error for a string field")
+	}
+}
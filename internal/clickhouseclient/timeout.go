@@ -0,0 +1,54 @@
+package clickhouseclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pingcap/errors"
+)
+
+// timeoutClient wraps a ClickhouseClient and cancels queries not completing within timeout.
+type timeoutClient struct {
+	client  ClickhouseClient
+	timeout time.Duration
+}
+
+// withQueryTimeout wraps client so that every query is bound to a deadline of timeout. Zero disables the deadline.
+func withQueryTimeout(client ClickhouseClient, timeout time.Duration) ClickhouseClient {
+	if timeout <= 0 {
+		return client
+	}
+
+	return &timeoutClient{
+		client:  client,
+		timeout: timeout,
+	}
+}
+
+func (t *timeoutClient) Select(ctx context.Context, qry string, callback func(Row) error) error {
+	queryCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	return t.checkTimeout(ctx, queryCtx, t.client.Select(queryCtx, qry, callback))
+}
+
+func (t *timeoutClient) Exec(ctx context.Context, qry string) error {
+	queryCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	return t.checkTimeout(ctx, queryCtx, t.client.Exec(queryCtx, qry))
+}
+
+// checkTimeout replaces the error returned by the driver with a clear one when the query hit the deadline.
+func (t *timeoutClient) checkTimeout(ctx context.Context, queryCtx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if ctx.Err() == nil && queryCtx.Err() == context.DeadlineExceeded {
+		return errors.New(fmt.Sprintf("query did not complete within %s, it can be increased with the query_timeout provider attribute", t.timeout))
+	}
+
+	return err
+}
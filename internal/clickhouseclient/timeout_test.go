@@ -0,0 +1,68 @@
+package clickhouseclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_httpClient_queryTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Hang until the test is over.
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		t.Fatalf("strconv.Atoi() error = %v", err)
+	}
+
+	client, err := NewHTTPClient(HTTPClientConfig{
+		Host:         u.Hostname(),
+		Port:         uint16(port),
+		BasicAuth:    &BasicAuth{Username: "default"},
+		MaxRetries:   2,
+		QueryTimeout: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPClient() error = %v", err)
+	}
+
+	err = client.Exec(context.Background(), "ALTER TABLE t DELETE WHERE 1")
+	if err == nil {
+		t.Fatalf("Exec() expected error")
+	}
+
+	want := "query did not complete within 50ms, it can be increased with the query_timeout provider attribute"
+	if err.Error() != want {
+		t.Errorf("Exec() error = %q, want %q", err.Error(), want)
+	}
+	if strings.Contains(err.Error(), "context deadline exceeded") {
+		t.Errorf("Exec() error = %q, want no raw driver error", err.Error())
+	}
+}
+
+func Test_timeoutClient_cancelledContext(t *testing.T) {
+	fake := &flakyClient{errs: []error{context.Canceled}}
+	client := withQueryTimeout(fake, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := client.Exec(ctx, "SELECT 1")
+	if err != context.Canceled {
+		t.Errorf("Exec() error = %v, want %v", err, context.Canceled)
+	}
+}
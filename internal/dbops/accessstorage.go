@@ -0,0 +1,91 @@
+package dbops
+
+import (
+	"context"
+
+	"github.com/pingcap/errors"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/querybuilder"
+)
+
+// AccessEntity is a kind of access entity, named after the system table listing them.
+type AccessEntity string
+
+const (
+	AccessEntityUser            AccessEntity = "users"
+	AccessEntityRole            AccessEntity = "roles"
+	AccessEntitySettingsProfile AccessEntity = "settings_profiles"
+)
+
+// ReadOnlyAccessStorage returns the name of the user directory holding the given access entity when that directory
+// is read-only, e.g. users.xml, or an empty string when the entity can be altered and dropped.
+// Entities that can't be found are reported as writable.
+func (i *impl) ReadOnlyAccessStorage(ctx context.Context, entity AccessEntity, name string, clusterName *string) (string, error) {
+	clusterName = i.withDefaultCluster(clusterName)
+	sql, err := i.newSelect([]querybuilder.Field{querybuilder.NewField("storage")}, "system."+string(entity)).
+		WithCluster(clusterName).
+		Where(querybuilder.WhereEquals("name", name)).
+		Build()
+	if err != nil {
+		return "", errors.WithMessage(err, "error building query")
+	}
+
+	var storage string
+	err = i.clickhouseClient.Select(ctx, sql, func(data clickhouseclient.Row) error {
+		s, err := data.GetString("storage")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'storage' field")
+		}
+
+		storage = s
+		return nil
+	})
+	if err != nil {
+		return "", errors.WithMessage(err, "error running query")
+	}
+
+	if storage == "" {
+		return "", nil
+	}
+
+	directories, err := i.UserDirectories(ctx, clusterName)
+	if err != nil {
+		return "", errors.WithMessage(err, "error getting user directories")
+	}
+
+	for _, d := range directories {
+		if d.Name == storage && d.ReadOnly {
+			return storage, nil
+		}
+	}
+
+	return "", nil
+}
+
+// AccessEntityID returns the ID of the access entity with the given name, or an empty string when there is none.
+func (i *impl) AccessEntityID(ctx context.Context, entity AccessEntity, name string, clusterName *string) (string, error) {
+	clusterName = i.withDefaultCluster(clusterName)
+	sql, err := i.newSelect([]querybuilder.Field{querybuilder.NewField("id").ToString()}, "system."+string(entity)).
+		WithCluster(clusterName).
+		Where(querybuilder.WhereEquals("name", name)).
+		Build()
+	if err != nil {
+		return "", errors.WithMessage(err, "error building query")
+	}
+
+	var id string
+	err = i.clickhouseClient.Select(ctx, sql, func(data clickhouseclient.Row) error {
+		id, err = data.GetString("id")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'id' field")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", errors.WithMessage(err, "error running query")
+	}
+
+	return id, nil
+}
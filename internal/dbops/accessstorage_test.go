@@ -0,0 +1,122 @@
+package dbops
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+)
+
+func Test_ReadOnlyAccessStorage(t *testing.T) {
+	tests := []struct {
+		name    string
+		storage string
+		want    string
+	}{
+		{
+			name:    "Entity in users.xml",
+			storage: "users_xml",
+			want:    "users_xml",
+		},
+		{
+			name:    "Entity in a writable directory",
+			storage: "local_directory",
+			want:    "",
+		},
+		{
+			name:    "Entity not found",
+			storage: "",
+			want:    "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					row := clickhouseclient.Row{}
+					switch {
+					case strings.Contains(qry, "`system`.`roles`"):
+						if tt.storage == "" {
+							return nil
+						}
+						row.Set("storage", tt.storage)
+					case strings.Contains(qry, "`system`.`user_directories`"):
+						row.Set("name", "users_xml")
+						row.Set("type", "users_xml")
+						row.Set("precedence", uint64(0))
+						row.Set("params", "{}")
+						other := clickhouseclient.Row{}
+						other.Set("name", "local_directory")
+						other.Set("type", "local_directory")
+						other.Set("precedence", uint64(1))
+						other.Set("params", `{"readonly":false}`)
+						return []clickhouseclient.Row{row, other}
+					default:
+						return nil
+					}
+					return []clickhouseclient.Row{row}
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			got, err := client.ReadOnlyAccessStorage(context.Background(), AccessEntityRole, "reader", nil)
+			if err != nil {
+				t.Fatalf("ReadOnlyAccessStorage() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ReadOnlyAccessStorage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_AccessEntityID(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want string
+	}{
+		{
+			name: "Entity found",
+			id:   "a1b2c3d4-0000-0000-0000-000000000000",
+			want: "a1b2c3d4-0000-0000-0000-000000000000",
+		},
+		{
+			name: "Entity not found",
+			id:   "",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					if tt.id == "" || !strings.Contains(qry, "`system`.`settings_profiles`") {
+						return nil
+					}
+					row := clickhouseclient.Row{}
+					row.Set("id", tt.id)
+					return []clickhouseclient.Row{row}
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			got, err := client.AccessEntityID(context.Background(), AccessEntitySettingsProfile, "limits", nil)
+			if err != nil {
+				t.Fatalf("AccessEntityID() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("AccessEntityID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package dbops
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pingcap/errors"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/querybuilder"
+)
+
+// checkExistsOnAllReplicas returns an error naming the replicas of the cluster where the given access entity is
+// missing, so that an ALTER ... ON CLUSTER doesn't fail on some of them only.
+// Nothing is checked without a cluster, or when access entities are kept in replicated storage and thus shared by
+// all the replicas.
+func (i *impl) checkExistsOnAllReplicas(ctx context.Context, entity AccessEntity, name string, clusterName *string) error {
+	if clusterName == nil || *clusterName == "" {
+		return nil
+	}
+
+	replicated, err := i.IsReplicatedStorage(ctx, clusterName)
+	if err != nil {
+		return errors.WithMessage(err, "error checking if the cluster uses replicated storage")
+	}
+	if replicated {
+		return nil
+	}
+
+	replicas, err := i.replicaHosts(ctx, "system.one", nil, *clusterName)
+	if err != nil {
+		return errors.WithMessage(err, "error listing the replicas of the cluster")
+	}
+
+	found, err := i.replicaHosts(ctx, "system."+string(entity), querybuilder.WhereEquals("name", name), *clusterName)
+	if err != nil {
+		return errors.WithMessage(err, "error listing the replicas holding the entity")
+	}
+
+	missing := make([]string, 0)
+	for _, host := range replicas {
+		if !slices.Contains(found, host) {
+			missing = append(missing, host)
+		}
+	}
+
+	if len(missing) > 0 {
+		return errors.New(fmt.Sprintf("%s %q is missing on the replicas %s of cluster %q. Create it there, or use replicated storage for access entities", entitySingular(entity), name, strings.Join(missing, ", "), *clusterName))
+	}
+
+	return nil
+}
+
+// replicaHosts returns the host names of the replicas of the cluster where the given table has matching rows.
+func (i *impl) replicaHosts(ctx context.Context, table string, where querybuilder.Where, clusterName string) ([]string, error) {
+	q := querybuilder.NewSelect([]querybuilder.Field{querybuilder.NewExpressionField("hostName()", "host")}, table).
+		WithCluster(&clusterName).
+		WithClusterReads(querybuilder.ClusterReadsAllReplicas)
+	if where != nil {
+		q = q.Where(where)
+	}
+
+	sql, err := q.OrderBy(querybuilder.NewField("host"), querybuilder.ASC).Build()
+	if err != nil {
+		return nil, errors.WithMessage(err, "error building query")
+	}
+
+	hosts := make([]string, 0)
+	err = i.clickhouseClient.Select(ctx, sql, func(data clickhouseclient.Row) error {
+		host, err := data.GetString("host")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'host' field")
+		}
+
+		hosts = append(hosts, host)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "error running query")
+	}
+
+	return hosts, nil
+}
+
+// entitySingular returns the name of a single access entity of the given kind, for error messages.
+func entitySingular(entity AccessEntity) string {
+	switch entity {
+	case AccessEntityUser:
+		return "user"
+	case AccessEntityRole:
+		return "role"
+	case AccessEntitySettingsProfile:
+		return "settings profile"
+	}
+
+	return string(entity)
+}
@@ -0,0 +1,99 @@
+package dbops
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+)
+
+func Test_checkExistsOnAllReplicas(t *testing.T) {
+	clusterName := "cluster1"
+
+	hostRows := func(hosts ...string) []clickhouseclient.Row {
+		rows := make([]clickhouseclient.Row, 0)
+		for _, h := range hosts {
+			row := clickhouseclient.Row{}
+			row.Set("host", h)
+			rows = append(rows, row)
+		}
+		return rows
+	}
+
+	tests := []struct {
+		name        string
+		clusterName *string
+		storage     string
+		found       []string
+		wantErr     string
+	}{
+		{
+			name:        "No cluster",
+			clusterName: nil,
+			storage:     "local_directory",
+		},
+		{
+			name:        "Replicated storage",
+			clusterName: &clusterName,
+			storage:     "replicated",
+		},
+		{
+			name:        "Found on all replicas",
+			clusterName: &clusterName,
+			storage:     "local_directory",
+			found:       []string{"replica1", "replica2", "replica3"},
+		},
+		{
+			name:        "Missing on some replicas",
+			clusterName: &clusterName,
+			storage:     "local_directory",
+			found:       []string{"replica2"},
+			wantErr:     `user "john" is missing on the replicas replica1, replica3 of cluster "cluster1"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hostQueries := 0
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					switch {
+					case strings.Contains(qry, "`system`.`user_directories`"):
+						row := clickhouseclient.Row{}
+						row.Set("type", tt.storage)
+						row.Set("precedence", uint64(0))
+						return []clickhouseclient.Row{row}
+					case strings.Contains(qry, "clusterAllReplicas('cluster1', `system`.`one`)"):
+						hostQueries++
+						return hostRows("replica1", "replica2", "replica3")
+					case strings.Contains(qry, "clusterAllReplicas('cluster1', `system`.`users`)") && strings.Contains(qry, "`name` = 'john'"):
+						hostQueries++
+						return hostRows(tt.found...)
+					}
+					return nil
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			err = client.(*impl).checkExistsOnAllReplicas(context.Background(), AccessEntityUser, "john", tt.clusterName)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("checkExistsOnAllReplicas() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("checkExistsOnAllReplicas() error = %v, want %q", err, tt.wantErr)
+			}
+
+			wantHostQueries := 2
+			if tt.clusterName == nil || tt.storage == "replicated" {
+				wantHostQueries = 0
+			}
+			if hostQueries != wantHostQueries {
+				t.Errorf("checkExistsOnAllReplicas() ran %d host queries, want %d", hostQueries, wantHostQueries)
+			}
+		})
+	}
+}
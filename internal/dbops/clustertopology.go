@@ -0,0 +1,75 @@
+package dbops
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/pingcap/errors"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/querybuilder"
+)
+
+// ClusterReplica is a replica of a cluster, as listed in system.clusters.
+type ClusterReplica struct {
+	ShardNum   uint64
+	ReplicaNum uint64
+	HostName   string
+	// IsLocal is true for the replica the client is connected to.
+	IsLocal bool
+}
+
+// GetClusterTopology returns the replicas of the given cluster as configured on the server the client is connected
+// to, ordered by shard and replica. It returns no replicas when there is no such cluster.
+func (i *impl) GetClusterTopology(ctx context.Context, clusterName string) ([]ClusterReplica, error) {
+	sql, err := i.newSelect(
+		[]querybuilder.Field{
+			querybuilder.NewExpressionField("toUInt64(shard_num)", "shard_num"),
+			querybuilder.NewExpressionField("toUInt64(replica_num)", "replica_num"),
+			querybuilder.NewField("host_name"),
+			querybuilder.NewField("is_local"),
+		},
+		"system.clusters",
+	).Where(querybuilder.WhereEquals("cluster", clusterName)).Build()
+	if err != nil {
+		return nil, errors.WithMessage(err, "error building query")
+	}
+
+	replicas := make([]ClusterReplica, 0)
+	err = i.clickhouseClient.Select(ctx, sql, func(data clickhouseclient.Row) error {
+		shardNum, err := data.GetUInt64("shard_num")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'shard_num' field")
+		}
+		replicaNum, err := data.GetUInt64("replica_num")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'replica_num' field")
+		}
+		hostName, err := data.GetString("host_name")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'host_name' field")
+		}
+		isLocal, err := data.GetBool("is_local")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'is_local' field")
+		}
+
+		replicas = append(replicas, ClusterReplica{
+			ShardNum:   shardNum,
+			ReplicaNum: replicaNum,
+			HostName:   hostName,
+			IsLocal:    isLocal,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "error running query")
+	}
+
+	slices.SortFunc(replicas, func(a, b ClusterReplica) int {
+		return cmp.Or(cmp.Compare(a.ShardNum, b.ShardNum), cmp.Compare(a.ReplicaNum, b.ReplicaNum))
+	})
+
+	return replicas, nil
+}
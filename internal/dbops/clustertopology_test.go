@@ -0,0 +1,56 @@
+package dbops
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+)
+
+func Test_GetClusterTopology(t *testing.T) {
+	replicaRow := func(shardNum uint64, replicaNum uint64, hostName string, isLocal uint8) clickhouseclient.Row {
+		row := clickhouseclient.Row{}
+		row.Set("shard_num", shardNum)
+		row.Set("replica_num", replicaNum)
+		row.Set("host_name", hostName)
+		row.Set("is_local", isLocal)
+		return row
+	}
+
+	var query string
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			query = qry
+			return []clickhouseclient.Row{
+				replicaRow(2, 1, "clickhouse-03", 0),
+				replicaRow(1, 2, "clickhouse-02", 0),
+				replicaRow(1, 1, "clickhouse-01", 1),
+			}
+		},
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	replicas, err := client.GetClusterTopology(context.Background(), "cluster1")
+	if err != nil {
+		t.Fatalf("GetClusterTopology() error = %v", err)
+	}
+
+	want := []ClusterReplica{
+		{ShardNum: 1, ReplicaNum: 1, HostName: "clickhouse-01", IsLocal: true},
+		{ShardNum: 1, ReplicaNum: 2, HostName: "clickhouse-02"},
+		{ShardNum: 2, ReplicaNum: 1, HostName: "clickhouse-03"},
+	}
+	if !reflect.DeepEqual(replicas, want) {
+		t.Errorf("GetClusterTopology() = %+v, want %+v", replicas, want)
+	}
+
+	wantQuery := "SELECT toUInt64(shard_num) AS `shard_num`, toUInt64(replica_num) AS `replica_num`, `host_name`, `is_local` FROM `system`.`clusters` WHERE (`cluster` = 'cluster1');"
+	if query != wantQuery {
+		t.Errorf("GetClusterTopology() query = %q, want %q", query, wantQuery)
+	}
+}
@@ -0,0 +1,114 @@
+package dbops
+
+import (
+	"context"
+
+	"github.com/pingcap/errors"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/querybuilder"
+)
+
+// SupportsRoleComment checks whether the server supports comments on roles, that is whether 'system.roles' has a
+// 'comment' column. Older ClickHouse versions reject the COMMENT clause of CREATE ROLE and ALTER ROLE.
+func (i *impl) SupportsRoleComment(ctx context.Context, clusterName *string) (bool, error) {
+	clusterName = i.withDefaultCluster(clusterName)
+	return i.hasSystemColumn(ctx, "roles", "comment", clusterName)
+}
+
+// SupportsUserComment checks whether the server supports comments on users, that is whether 'system.users' has a
+// 'comment' column. Older ClickHouse versions reject the COMMENT clause of CREATE USER and ALTER USER.
+func (i *impl) SupportsUserComment(ctx context.Context, clusterName *string) (bool, error) {
+	clusterName = i.withDefaultCluster(clusterName)
+	return i.hasSystemColumn(ctx, "users", "comment", clusterName)
+}
+
+// hasSystemColumn returns true if the given table of the 'system' database has the given column.
+// The result is cached separately for each cluster, since it can't change without upgrading the servers.
+func (i *impl) hasSystemColumn(ctx context.Context, table string, column string, clusterName *string) (bool, error) {
+	if clusterName != nil && *clusterName == "" {
+		// Unknown cluster names are planned as empty strings.
+		clusterName = nil
+	}
+
+	key := table + "." + column
+	if clusterName != nil {
+		key = *clusterName + ":" + key
+	}
+
+	i.systemColumnsMu.Lock()
+	defer i.systemColumnsMu.Unlock()
+
+	if found, ok := i.systemColumns[key]; ok {
+		return found, nil
+	}
+
+	sql, err := i.
+		newSelect([]querybuilder.Field{querybuilder.NewField("name")}, "system.columns").
+		WithCluster(clusterName).
+		Where(querybuilder.AndWhere(
+			querybuilder.WhereEquals("database", "system"),
+			querybuilder.WhereEquals("table", table),
+			querybuilder.WhereEquals("name", column),
+		)).
+		Build()
+	if err != nil {
+		return false, errors.WithMessage(err, "error building query")
+	}
+
+	found := false
+	err = i.clickhouseClient.Select(ctx, sql, func(_ clickhouseclient.Row) error {
+		found = true
+		return nil
+	})
+	if err != nil {
+		return false, errors.WithMessage(err, "error running query")
+	}
+
+	if i.systemColumns == nil {
+		i.systemColumns = make(map[string]bool)
+	}
+	i.systemColumns[key] = found
+
+	return found, nil
+}
+
+// getRoleComment returns the comment of the given role, or nil if the server doesn't support comments on roles.
+func (i *impl) getRoleComment(ctx context.Context, name string, clusterName *string) (*string, error) {
+	supported, err := i.SupportsRoleComment(ctx, clusterName)
+	if err != nil {
+		return nil, errors.WithMessage(err, "error checking support for role comments")
+	}
+	if !supported {
+		return nil, nil
+	}
+
+	return i.getComment(ctx, "system.roles", name, clusterName)
+}
+
+// getComment returns the 'comment' column of the entity with the given name in the given system table.
+func (i *impl) getComment(ctx context.Context, table string, name string, clusterName *string) (*string, error) {
+	sql, err := i.
+		newSelect([]querybuilder.Field{querybuilder.NewField("comment")}, table).
+		WithCluster(clusterName).
+		Where(querybuilder.WhereEquals("name", name)).
+		Build()
+	if err != nil {
+		return nil, errors.WithMessage(err, "error building query")
+	}
+
+	var comment *string
+	err = i.clickhouseClient.Select(ctx, sql, func(data clickhouseclient.Row) error {
+		value, err := data.GetString("comment")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'comment' field")
+		}
+		comment = &value
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "error running query")
+	}
+
+	return comment, nil
+}
@@ -16,6 +16,7 @@ type Database struct {
 }
 
 func (i *impl) CreateDatabase(ctx context.Context, database Database, clusterName *string) (*Database, error) {
+	clusterName = i.withDefaultCluster(clusterName)
 	builder := querybuilder.NewCreateDatabase(database.Name).WithCluster(clusterName)
 	if database.Comment != "" {
 		builder.WithComment(database.Comment)
@@ -25,16 +26,19 @@ func (i *impl) CreateDatabase(ctx context.Context, database Database, clusterNam
 		return nil, errors.WithMessage(err, "error building query")
 	}
 
-	err = i.clickhouseClient.Exec(ctx, sql)
+	err = i.execWithContext(ctx, sql, clusterName)
 	if err != nil {
-		return nil, errors.WithMessage(err, "error running query")
+		return nil, err
 	}
 
-	return i.FindDatabaseByName(ctx, database.Name, clusterName)
+	return readAfterCreate(ctx, i, func() (*Database, error) {
+		return i.FindDatabaseByName(ctx, database.Name, clusterName)
+	})
 }
 
 func (i *impl) GetDatabase(ctx context.Context, uuid string, clusterName *string) (*Database, error) {
-	sql, err := querybuilder.NewSelect(
+	clusterName = i.withDefaultCluster(clusterName)
+	sql, err := i.newSelect(
 		[]querybuilder.Field{querybuilder.NewField("name"), querybuilder.NewField("comment")},
 		"system.databases",
 	).WithCluster(clusterName).Where(querybuilder.WhereEquals("uuid", uuid)).Build()
@@ -73,6 +77,7 @@ func (i *impl) GetDatabase(ctx context.Context, uuid string, clusterName *string
 }
 
 func (i *impl) DeleteDatabase(ctx context.Context, uuid string, clusterName *string) error {
+	clusterName = i.withDefaultCluster(clusterName)
 	database, err := i.GetDatabase(ctx, uuid, clusterName)
 	if err != nil {
 		return errors.WithMessage(err, "error getting database name")
@@ -88,16 +93,17 @@ func (i *impl) DeleteDatabase(ctx context.Context, uuid string, clusterName *str
 		return errors.WithMessage(err, "error building query")
 	}
 
-	err = i.clickhouseClient.Exec(ctx, sql)
+	err = i.execWithContext(ctx, sql, clusterName)
 	if err != nil {
-		return errors.WithMessage(err, "error running query")
+		return err
 	}
 
 	return nil
 }
 
 func (i *impl) FindDatabaseByName(ctx context.Context, name string, clusterName *string) (*Database, error) {
-	sql, err := querybuilder.NewSelect(
+	clusterName = i.withDefaultCluster(clusterName)
+	sql, err := i.newSelect(
 		[]querybuilder.Field{querybuilder.NewField("uuid").ToString()},
 		"system.databases",
 	).WithCluster(clusterName).Where(querybuilder.WhereEquals("name", name)).Build()
@@ -0,0 +1,179 @@
+package dbops
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/pingcap/errors"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/querybuilder"
+)
+
+// EffectiveGrants is the set of privileges a user has, either directly or through the roles granted to it.
+type EffectiveGrants struct {
+	// Roles are all the roles granted to the user, directly or through other roles.
+	Roles []string
+	// Grants are the privileges granted to the user or to any of its roles, without duplicates.
+	Grants []GrantPrivilege
+}
+
+// GetEffectiveGrants resolves the privileges of a user by walking the role grants graph starting from the user.
+// Partial revokes are not applied: only the grants are returned.
+func (i *impl) GetEffectiveGrants(ctx context.Context, userName string, clusterName *string) (*EffectiveGrants, error) {
+	clusterName = i.withDefaultCluster(clusterName)
+	// Only the existence of the user matters, not its attributes.
+	exists, err := i.UserExists(ctx, userName, clusterName)
+	if err != nil {
+		return nil, errors.WithMessage(err, "error getting user")
+	}
+
+	if !exists {
+		// User not found
+		return nil, nil
+	}
+
+	grants, err := i.GetAllGrantsForGrantee(ctx, &userName, nil, clusterName)
+	if err != nil {
+		return nil, errors.WithMessage(err, "error getting user grants")
+	}
+
+	roles := make([]string, 0)
+	{
+		queue, err := i.getGrantedRoles(ctx, &userName, nil, clusterName)
+		if err != nil {
+			return nil, errors.WithMessage(err, "error getting user roles")
+		}
+
+		for len(queue) > 0 {
+			roleName := queue[0]
+			queue = queue[1:]
+
+			if slices.Contains(roles, roleName) {
+				continue
+			}
+			roles = append(roles, roleName)
+
+			roleGrants, err := i.GetAllGrantsForGrantee(ctx, nil, &roleName, clusterName)
+			if err != nil {
+				return nil, errors.WithMessage(err, "error getting role grants")
+			}
+			grants = append(grants, roleGrants...)
+
+			inherited, err := i.getGrantedRoles(ctx, nil, &roleName, clusterName)
+			if err != nil {
+				return nil, errors.WithMessage(err, "error getting role roles")
+			}
+			queue = append(queue, inherited...)
+		}
+	}
+
+	slices.Sort(roles)
+
+	return &EffectiveGrants{
+		Roles:  roles,
+		Grants: flattenGrants(grants),
+	}, nil
+}
+
+// getGrantedRoles returns the names of the roles directly granted to the given user or role.
+func (i *impl) getGrantedRoles(ctx context.Context, granteeUserName *string, granteeRoleName *string, clusterName *string) ([]string, error) {
+	var granteeWhere querybuilder.Where
+	{
+		if granteeUserName != nil {
+			granteeWhere = querybuilder.WhereEquals("user_name", *granteeUserName)
+		} else if granteeRoleName != nil {
+			granteeWhere = querybuilder.WhereEquals("role_name", *granteeRoleName)
+		} else {
+			return nil, errors.New("either GranteeUserName or GranteeRoleName must be set")
+		}
+	}
+
+	sql, err := i.newSelect(
+		[]querybuilder.Field{querybuilder.NewField("granted_role_name")},
+		"system.role_grants",
+	).
+		WithCluster(clusterName).
+		Where(granteeWhere).
+		Build()
+	if err != nil {
+		return nil, errors.WithMessage(err, "error building query")
+	}
+
+	roles := make([]string, 0)
+	err = i.clickhouseClient.Select(ctx, sql, func(data clickhouseclient.Row) error {
+		roleName, err := data.GetString("granted_role_name")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'granted_role_name' field")
+		}
+		roles = append(roles, roleName)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "error running query")
+	}
+
+	return roles, nil
+}
+
+// flattenGrants removes partial revokes and duplicated privileges from the given grants, clearing the grantee.
+// A privilege is granted with grant option if any of the duplicates has it. The result is sorted.
+func flattenGrants(grants []GrantPrivilege) []GrantPrivilege {
+	ret := make([]GrantPrivilege, 0)
+
+	for _, g := range grants {
+		if g.IsPartialRevoke {
+			continue
+		}
+
+		idx := slices.IndexFunc(ret, func(other GrantPrivilege) bool {
+			return other.AccessType == g.AccessType &&
+				equalStringPtr(other.DatabaseName, g.DatabaseName) &&
+				equalStringPtr(other.TableName, g.TableName) &&
+				equalStringPtr(other.ColumnName, g.ColumnName)
+		})
+		if idx >= 0 {
+			ret[idx].GrantOption = ret[idx].GrantOption || g.GrantOption
+			continue
+		}
+
+		ret = append(ret, GrantPrivilege{
+			AccessType:   g.AccessType,
+			DatabaseName: g.DatabaseName,
+			TableName:    g.TableName,
+			ColumnName:   g.ColumnName,
+			GrantOption:  g.GrantOption,
+		})
+	}
+
+	slices.SortFunc(ret, func(a, b GrantPrivilege) int {
+		for _, c := range []int{
+			strings.Compare(a.AccessType, b.AccessType),
+			strings.Compare(valueOrEmpty(a.DatabaseName), valueOrEmpty(b.DatabaseName)),
+			strings.Compare(valueOrEmpty(a.TableName), valueOrEmpty(b.TableName)),
+			strings.Compare(valueOrEmpty(a.ColumnName), valueOrEmpty(b.ColumnName)),
+		} {
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+
+	return ret
+}
+
+func equalStringPtr(a *string, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func valueOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
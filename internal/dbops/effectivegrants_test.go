@@ -0,0 +1,92 @@
+package dbops
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+)
+
+func Test_GetEffectiveGrants(t *testing.T) {
+	db1 := "db1"
+	db2 := "db2"
+	table := "secret"
+
+	grantRow := func(accessType string, database *string, table *string, grantOption bool, partialRevoke bool) clickhouseclient.Row {
+		row := clickhouseclient.Row{}
+		row.Set("access_type", accessType)
+		row.Set("database", database)
+		row.Set("table", table)
+		row.Set("column", (*string)(nil))
+		row.Set("user_name", (*string)(nil))
+		row.Set("role_name", (*string)(nil))
+		row.Set("grant_option", boolToUInt8(grantOption))
+		row.Set("is_partial_revoke", boolToUInt8(partialRevoke))
+		return row
+	}
+
+	roleGrantRow := func(roleName string) clickhouseclient.Row {
+		row := clickhouseclient.Row{}
+		row.Set("granted_role_name", roleName)
+		return row
+	}
+
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			switch {
+			case strings.Contains(qry, "`system`.`users`"):
+				row := clickhouseclient.Row{}
+				row.Set("cnt", uint64(1))
+				return []clickhouseclient.Row{row}
+			case strings.Contains(qry, "`system`.`role_grants`") && strings.Contains(qry, "`user_name` = 'john'"):
+				return []clickhouseclient.Row{roleGrantRow("reader")}
+			case strings.Contains(qry, "`system`.`role_grants`") && strings.Contains(qry, "`role_name` = 'reader'"):
+				return []clickhouseclient.Row{roleGrantRow("base")}
+			case strings.Contains(qry, "`system`.`role_grants`") && strings.Contains(qry, "`role_name` = 'base'"):
+				// Cycles must not cause infinite loops.
+				return []clickhouseclient.Row{roleGrantRow("reader")}
+			case strings.Contains(qry, "`system`.`grants`") && strings.Contains(qry, "`user_name` = 'john'"):
+				return []clickhouseclient.Row{grantRow("SELECT", &db1, nil, false, false)}
+			case strings.Contains(qry, "`system`.`grants`") && strings.Contains(qry, "`role_name` = 'reader'"):
+				return []clickhouseclient.Row{
+					grantRow("SELECT", &db1, nil, true, false),
+					grantRow("SELECT", &db1, &table, false, true),
+				}
+			case strings.Contains(qry, "`system`.`grants`") && strings.Contains(qry, "`role_name` = 'base'"):
+				return []clickhouseclient.Row{grantRow("INSERT", &db2, nil, false, false)}
+			}
+			return nil
+		},
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	got, err := client.GetEffectiveGrants(context.Background(), "john", nil)
+	if err != nil {
+		t.Fatalf("GetEffectiveGrants() error = %v", err)
+	}
+
+	want := &EffectiveGrants{
+		Roles: []string{"base", "reader"},
+		Grants: []GrantPrivilege{
+			{AccessType: "INSERT", DatabaseName: &db2},
+			{AccessType: "SELECT", DatabaseName: &db1, GrantOption: true},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetEffectiveGrants() = %+v, want %+v", got, want)
+	}
+}
+
+func boolToUInt8(b bool) uint8 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -17,9 +17,12 @@ type GrantPrivilege struct {
 	GranteeUserName *string `json:"user_name"`
 	GranteeRoleName *string `json:"role_name"`
 	GrantOption     bool    `json:"grant_option"`
+	// IsPartialRevoke is true when the row describes a privilege revoked from a broader grant, rather than a grant.
+	IsPartialRevoke bool `json:"is_partial_revoke"`
 }
 
 func (i *impl) GrantPrivilege(ctx context.Context, grantPrivilege GrantPrivilege, clusterName *string) (*GrantPrivilege, error) {
+	clusterName = i.withDefaultCluster(clusterName)
 	var to string
 	{
 		if grantPrivilege.GranteeUserName != nil {
@@ -42,15 +45,18 @@ func (i *impl) GrantPrivilege(ctx context.Context, grantPrivilege GrantPrivilege
 		return nil, errors.WithMessage(err, "error building query")
 	}
 
-	err = i.clickhouseClient.Exec(ctx, sql)
+	err = i.execWithContext(ctx, sql, clusterName)
 	if err != nil {
-		return nil, errors.WithMessage(err, "error running query")
+		return nil, err
 	}
 
-	return i.GetGrantPrivilege(ctx, grantPrivilege.AccessType, grantPrivilege.DatabaseName, grantPrivilege.TableName, grantPrivilege.ColumnName, grantPrivilege.GranteeUserName, grantPrivilege.GranteeRoleName, clusterName)
+	return readAfterCreate(ctx, i, func() (*GrantPrivilege, error) {
+		return i.GetGrantPrivilege(ctx, grantPrivilege.AccessType, grantPrivilege.DatabaseName, grantPrivilege.TableName, grantPrivilege.ColumnName, grantPrivilege.GranteeUserName, grantPrivilege.GranteeRoleName, clusterName)
+	})
 }
 
 func (i *impl) GetGrantPrivilege(ctx context.Context, accessType string, database *string, table *string, column *string, granteeUserName *string, granteeRoleName *string, clusterName *string) (*GrantPrivilege, error) {
+	clusterName = i.withDefaultCluster(clusterName)
 	where := make([]querybuilder.Where, 0)
 
 	{
@@ -82,7 +88,7 @@ func (i *impl) GetGrantPrivilege(ctx context.Context, accessType string, databas
 		}
 	}
 
-	sql, err := querybuilder.NewSelect(
+	sql, err := i.newSelect(
 		[]querybuilder.Field{
 			querybuilder.NewField("access_type").ToString(),
 			querybuilder.NewField("database"),
@@ -91,6 +97,7 @@ func (i *impl) GetGrantPrivilege(ctx context.Context, accessType string, databas
 			querybuilder.NewField("user_name"),
 			querybuilder.NewField("role_name"),
 			querybuilder.NewField("grant_option"),
+			querybuilder.NewField("is_partial_revoke"),
 		},
 		"system.grants",
 	).WithCluster(clusterName).Where(where...).Build()
@@ -98,7 +105,9 @@ func (i *impl) GetGrantPrivilege(ctx context.Context, accessType string, databas
 		return nil, errors.WithMessage(err, "error building query")
 	}
 
-	var grantPrivilege *GrantPrivilege
+	// The grant and its grant option can be reported as separate rows, e.g. when the grant option was granted later.
+	// They are merged into a single grant, and partial revokes are only returned when there is no grant.
+	var grantPrivilege, partialRevoke *GrantPrivilege
 
 	err = i.clickhouseClient.Select(ctx, sql, func(data clickhouseclient.Row) error {
 		accessType, err := data.GetString("access_type")
@@ -129,7 +138,16 @@ func (i *impl) GetGrantPrivilege(ctx context.Context, accessType string, databas
 		if err != nil {
 			return errors.WithMessage(err, "error scanning query result, missing 'grant_option' field")
 		}
-		grantPrivilege = &GrantPrivilege{
+		isPartialRevoke, err := data.GetBool("is_partial_revoke")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'is_partial_revoke' field")
+		}
+		if grantPrivilege != nil && !isPartialRevoke {
+			grantPrivilege.GrantOption = grantPrivilege.GrantOption || grantOption
+			return nil
+		}
+
+		row := &GrantPrivilege{
 			AccessType:      accessType,
 			DatabaseName:    database,
 			TableName:       table,
@@ -137,6 +155,12 @@ func (i *impl) GetGrantPrivilege(ctx context.Context, accessType string, databas
 			GranteeUserName: granteeUserName,
 			GranteeRoleName: granteeRoleName,
 			GrantOption:     grantOption,
+			IsPartialRevoke: isPartialRevoke,
+		}
+		if isPartialRevoke {
+			partialRevoke = row
+		} else {
+			grantPrivilege = row
 		}
 
 		return nil
@@ -146,14 +170,15 @@ func (i *impl) GetGrantPrivilege(ctx context.Context, accessType string, databas
 	}
 
 	if grantPrivilege == nil {
-		// Grant not found
-		return nil, nil
+		// Grant not found, but the privilege might be partially revoked.
+		return partialRevoke, nil
 	}
 
 	return grantPrivilege, nil
 }
 
 func (i *impl) RevokeGrantPrivilege(ctx context.Context, accessType string, database *string, table *string, column *string, granteeUserName *string, granteeRoleName *string, clusterName *string) error {
+	clusterName = i.withDefaultCluster(clusterName)
 	var from string
 	{
 		if granteeUserName != nil {
@@ -175,15 +200,16 @@ func (i *impl) RevokeGrantPrivilege(ctx context.Context, accessType string, data
 		return errors.WithMessage(err, "error building query")
 	}
 
-	err = i.clickhouseClient.Exec(ctx, sql)
+	err = i.execWithContext(ctx, sql, clusterName)
 	if err != nil {
-		return errors.WithMessage(err, "error running query")
+		return err
 	}
 
 	return nil
 }
 
 func (i *impl) GetAllGrantsForGrantee(ctx context.Context, granteeUsername *string, granteeRoleName *string, clusterName *string) ([]GrantPrivilege, error) {
+	clusterName = i.withDefaultCluster(clusterName)
 	// Get all grants for the same grantee.
 	var to querybuilder.Where
 	{
@@ -196,7 +222,7 @@ func (i *impl) GetAllGrantsForGrantee(ctx context.Context, granteeUsername *stri
 		}
 	}
 
-	sql, err := querybuilder.NewSelect([]querybuilder.Field{
+	sql, err := i.newSelect([]querybuilder.Field{
 		querybuilder.NewField("access_type").ToString(),
 		querybuilder.NewField("database"),
 		querybuilder.NewField("table"),
@@ -204,6 +230,7 @@ func (i *impl) GetAllGrantsForGrantee(ctx context.Context, granteeUsername *stri
 		querybuilder.NewField("user_name"),
 		querybuilder.NewField("role_name"),
 		querybuilder.NewField("grant_option"),
+		querybuilder.NewField("is_partial_revoke"),
 	}, "system.grants").WithCluster(clusterName).Where(to).Build()
 	if err != nil {
 		return nil, errors.WithMessage(err, "error building query")
@@ -240,6 +267,10 @@ func (i *impl) GetAllGrantsForGrantee(ctx context.Context, granteeUsername *stri
 		if err != nil {
 			return errors.WithMessage(err, "error scanning query result, missing 'grant_option' field")
 		}
+		isPartialRevoke, err := data.GetBool("is_partial_revoke")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'is_partial_revoke' field")
+		}
 
 		ret = append(ret, GrantPrivilege{
 			AccessType:      accessType,
@@ -249,6 +280,7 @@ func (i *impl) GetAllGrantsForGrantee(ctx context.Context, granteeUsername *stri
 			GranteeUserName: granteeUserName,
 			GranteeRoleName: granteeRoleName,
 			GrantOption:     grantOption,
+			IsPartialRevoke: isPartialRevoke,
 		})
 
 		return nil
@@ -259,3 +291,36 @@ func (i *impl) GetAllGrantsForGrantee(ctx context.Context, granteeUsername *stri
 
 	return ret, nil
 }
+
+// UserGrants are the privileges and roles granted to a user directly.
+type UserGrants struct {
+	Privileges []GrantPrivilege
+	Roles      []string
+}
+
+// ListUserGrants returns the privileges and roles granted to the given user, out of system.grants and system.role_grants.
+// Partial revokes are not returned, since they go away along with the grants they restrict.
+func (i *impl) ListUserGrants(ctx context.Context, userName string, clusterName *string) (*UserGrants, error) {
+	clusterName = i.withDefaultCluster(clusterName)
+	grants, err := i.GetAllGrantsForGrantee(ctx, &userName, nil, clusterName)
+	if err != nil {
+		return nil, errors.WithMessage(err, "error getting user privileges")
+	}
+
+	privileges := make([]GrantPrivilege, 0)
+	for _, g := range grants {
+		if !g.IsPartialRevoke {
+			privileges = append(privileges, g)
+		}
+	}
+
+	roles, err := i.ListGrantedRoles(ctx, userName, clusterName)
+	if err != nil {
+		return nil, errors.WithMessage(err, "error getting user roles")
+	}
+
+	return &UserGrants{
+		Privileges: privileges,
+		Roles:      roles,
+	}, nil
+}
@@ -0,0 +1,154 @@
+package dbops
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+)
+
+func Test_GetGrantPrivilege_rows(t *testing.T) {
+	grantRow := func(grantOption bool, isPartialRevoke bool) clickhouseclient.Row {
+		database := "default"
+		userName := "john"
+		row := clickhouseclient.Row{}
+		row.Set("access_type", "SELECT")
+		row.Set("database", &database)
+		row.Set("table", (*string)(nil))
+		row.Set("column", (*string)(nil))
+		row.Set("user_name", &userName)
+		row.Set("role_name", (*string)(nil))
+		row.Set("grant_option", boolToUInt8(grantOption))
+		row.Set("is_partial_revoke", boolToUInt8(isPartialRevoke))
+		return row
+	}
+
+	tests := []struct {
+		name                string
+		rows                []clickhouseclient.Row
+		wantFound           bool
+		wantGrantOption     bool
+		wantIsPartialRevoke bool
+	}{
+		{
+			name:      "Not granted",
+			rows:      nil,
+			wantFound: false,
+		},
+		{
+			name:            "Granted without grant option",
+			rows:            []clickhouseclient.Row{grantRow(false, false)},
+			wantFound:       true,
+			wantGrantOption: false,
+		},
+		{
+			name:            "Grant option in a separate row",
+			rows:            []clickhouseclient.Row{grantRow(false, false), grantRow(true, false)},
+			wantFound:       true,
+			wantGrantOption: true,
+		},
+		{
+			name:            "Grant option in the first row",
+			rows:            []clickhouseclient.Row{grantRow(true, false), grantRow(false, false)},
+			wantFound:       true,
+			wantGrantOption: true,
+		},
+		{
+			name:                "Partially revoked only",
+			rows:                []clickhouseclient.Row{grantRow(false, true)},
+			wantFound:           true,
+			wantIsPartialRevoke: true,
+		},
+		{
+			name:            "Grant takes precedence over partial revoke",
+			rows:            []clickhouseclient.Row{grantRow(false, true), grantRow(true, false)},
+			wantFound:       true,
+			wantGrantOption: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					return tt.rows
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			database := "default"
+			userName := "john"
+			got, err := client.GetGrantPrivilege(context.Background(), "SELECT", &database, nil, nil, &userName, nil, nil)
+			if err != nil {
+				t.Fatalf("GetGrantPrivilege() error = %v", err)
+			}
+			if (got != nil) != tt.wantFound {
+				t.Fatalf("GetGrantPrivilege() = %v, wantFound %v", got, tt.wantFound)
+			}
+			if got == nil {
+				return
+			}
+			if got.GrantOption != tt.wantGrantOption {
+				t.Errorf("GetGrantPrivilege() GrantOption = %v, want %v", got.GrantOption, tt.wantGrantOption)
+			}
+			if got.IsPartialRevoke != tt.wantIsPartialRevoke {
+				t.Errorf("GetGrantPrivilege() IsPartialRevoke = %v, want %v", got.IsPartialRevoke, tt.wantIsPartialRevoke)
+			}
+		})
+	}
+}
+
+func Test_ListUserGrants(t *testing.T) {
+	privilegeRow := func(accessType string, database string, isPartialRevoke bool) clickhouseclient.Row {
+		userName := "john"
+		row := clickhouseclient.Row{}
+		row.Set("access_type", accessType)
+		row.Set("database", &database)
+		row.Set("table", (*string)(nil))
+		row.Set("column", (*string)(nil))
+		row.Set("user_name", &userName)
+		row.Set("role_name", (*string)(nil))
+		row.Set("grant_option", boolToUInt8(false))
+		row.Set("is_partial_revoke", boolToUInt8(isPartialRevoke))
+		return row
+	}
+
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			switch {
+			case strings.Contains(qry, "`system`.`grants`"):
+				return []clickhouseclient.Row{
+					privilegeRow("SELECT", "default", false),
+					privilegeRow("SELECT", "secret", true),
+				}
+			case strings.Contains(qry, "`system`.`role_grants`"):
+				row := clickhouseclient.Row{}
+				row.Set("granted_role_name", "reader")
+				return []clickhouseclient.Row{row}
+			}
+			return nil
+		},
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	got, err := client.ListUserGrants(context.Background(), "john", nil)
+	if err != nil {
+		t.Fatalf("ListUserGrants() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got.Roles, []string{"reader"}) {
+		t.Errorf("ListUserGrants() Roles = %q, want %q", got.Roles, []string{"reader"})
+	}
+	if len(got.Privileges) != 1 || got.Privileges[0].AccessType != "SELECT" || *got.Privileges[0].DatabaseName != "default" {
+		t.Errorf("ListUserGrants() Privileges = %+v, want SELECT on default only", got.Privileges)
+	}
+}
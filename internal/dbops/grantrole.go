@@ -3,8 +3,9 @@ package dbops
 import (
 	"context"
 	"fmt"
-	"strings"
+	"slices"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/pingcap/errors"
 
 	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
@@ -19,6 +20,7 @@ type GrantRole struct {
 }
 
 func (i *impl) GrantRole(ctx context.Context, grantRole GrantRole, clusterName *string) (*GrantRole, error) {
+	clusterName = i.withDefaultCluster(clusterName)
 	var to string
 	{
 		if grantRole.GranteeUserName != nil {
@@ -35,9 +37,9 @@ func (i *impl) GrantRole(ctx context.Context, grantRole GrantRole, clusterName *
 		return nil, errors.WithMessage(err, "error building query")
 	}
 
-	err = i.clickhouseClient.Exec(ctx, sql)
+	err = i.execWithContext(ctx, sql, clusterName)
 	if err != nil {
-		return nil, errors.WithMessage(err, "error running query")
+		return nil, err
 	}
 
 	// Activate role as DEFAULT ROLE if grantee is a user (not a role)
@@ -47,10 +49,13 @@ func (i *impl) GrantRole(ctx context.Context, grantRole GrantRole, clusterName *
 		_ = i.activateDefaultRole(ctx, *grantRole.GranteeUserName, grantRole.RoleName, clusterName)
 	}
 
-	return i.GetGrantRole(ctx, grantRole.RoleName, grantRole.GranteeUserName, grantRole.GranteeRoleName, clusterName)
+	return readAfterCreate(ctx, i, func() (*GrantRole, error) {
+		return i.GetGrantRole(ctx, grantRole.RoleName, grantRole.GranteeUserName, grantRole.GranteeRoleName, clusterName)
+	})
 }
 
 func (i *impl) GetGrantRole(ctx context.Context, grantedRoleName string, granteeUserName *string, granteeRoleName *string, clusterName *string) (*GrantRole, error) {
+	clusterName = i.withDefaultCluster(clusterName)
 	var granteeWhere querybuilder.Where
 	{
 		if granteeUserName != nil {
@@ -62,7 +67,7 @@ func (i *impl) GetGrantRole(ctx context.Context, grantedRoleName string, grantee
 		}
 	}
 
-	sql, err := querybuilder.NewSelect(
+	sql, err := i.newSelect(
 		[]querybuilder.Field{
 			querybuilder.NewField("granted_role_name"),
 			querybuilder.NewField("user_name"),
@@ -117,6 +122,7 @@ func (i *impl) GetGrantRole(ctx context.Context, grantedRoleName string, grantee
 }
 
 func (i *impl) RevokeGrantRole(ctx context.Context, grantedRoleName string, granteeUserName *string, granteeRoleName *string, clusterName *string) error {
+	clusterName = i.withDefaultCluster(clusterName)
 	var grantee string
 	{
 		if granteeUserName != nil {
@@ -132,9 +138,9 @@ func (i *impl) RevokeGrantRole(ctx context.Context, grantedRoleName string, gran
 		return errors.WithMessage(err, "error building query")
 	}
 
-	err = i.clickhouseClient.Exec(ctx, sql)
+	err = i.execWithContext(ctx, sql, clusterName)
 	if err != nil {
-		return errors.WithMessage(err, "error running query")
+		return err
 	}
 
 	// Deactivate role from DEFAULT ROLE if grantee is a user (not a role)
@@ -147,171 +153,317 @@ func (i *impl) RevokeGrantRole(ctx context.Context, grantedRoleName string, gran
 	return nil
 }
 
-// activateDefaultRole adds the role to user's default roles using ALTER USER DEFAULT ROLE
-func (i *impl) activateDefaultRole(ctx context.Context, userName string, roleName string, clusterName *string) error {
-	// Get current default roles
-	currentRoles, err := i.getDefaultRoles(ctx, userName, clusterName)
+// UpdateGrantRole changes the admin option of an existing role grant, without revoking the role.
+func (i *impl) UpdateGrantRole(ctx context.Context, grantRole GrantRole, clusterName *string) (*GrantRole, error) {
+	clusterName = i.withDefaultCluster(clusterName)
+	existing, err := i.GetGrantRole(ctx, grantRole.RoleName, grantRole.GranteeUserName, grantRole.GranteeRoleName, clusterName)
 	if err != nil {
-		// If we can't get default roles (e.g., user doesn't exist yet), skip activation
-		// The role is still granted, just not activated as default
-		return nil
+		return nil, errors.WithMessage(err, "error getting existing role grant")
 	}
 
-	// Check if role is already in default roles
-	for _, role := range currentRoles {
-		if role == roleName {
-			// Role is already a default role, nothing to do
-			return nil
+	if existing == nil {
+		return nil, nil
+	}
+
+	if existing.AdminOption == grantRole.AdminOption {
+		// Nothing to do.
+		return existing, nil
+	}
+
+	var grantee string
+	if grantRole.GranteeUserName != nil {
+		grantee = *grantRole.GranteeUserName
+	} else {
+		grantee = *grantRole.GranteeRoleName
+	}
+
+	var sql string
+	if grantRole.AdminOption {
+		sql, err = querybuilder.GrantRole(grantRole.RoleName, grantee).WithCluster(clusterName).WithAdminOption(true).Build()
+	} else {
+		sql, err = querybuilder.RevokeRole(grantRole.RoleName, grantee).WithCluster(clusterName).AdminOptionOnly().Build()
+	}
+	if err != nil {
+		return nil, errors.WithMessage(err, "error building query")
+	}
+
+	err = i.execWithContext(ctx, sql, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.GetGrantRole(ctx, grantRole.RoleName, grantRole.GranteeUserName, grantRole.GranteeRoleName, clusterName)
+}
+
+// GrantRoleWithSettingsProfile grants a role to a user like GrantRole and adds settingsProfile to the settings
+// profiles of the same user. If the profile can't be added, a grant that didn't exist before is revoked again,
+// so that a failure never leaves only half of the changes behind.
+func (i *impl) GrantRoleWithSettingsProfile(ctx context.Context, grantRole GrantRole, settingsProfile string, clusterName *string) (*GrantRole, error) {
+	clusterName = i.withDefaultCluster(clusterName)
+	if grantRole.GranteeUserName == nil {
+		return nil, errors.New("a settings profile can only be set when granting a role to a user")
+	}
+
+	existing, err := i.GetGrantRole(ctx, grantRole.RoleName, grantRole.GranteeUserName, nil, clusterName)
+	if err != nil {
+		return nil, errors.WithMessage(err, "error getting existing role grant")
+	}
+
+	granted, err := i.GrantRole(ctx, grantRole, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = i.UpdateUserSettingsProfile(ctx, *grantRole.GranteeUserName, nil, &settingsProfile, clusterName)
+	if err != nil {
+		if existing == nil {
+			if revokeErr := i.RevokeGrantRole(ctx, grantRole.RoleName, grantRole.GranteeUserName, nil, clusterName); revokeErr != nil {
+				return nil, errors.WithMessage(err, fmt.Sprintf("error setting settings profile, and revoking the role grant failed as well: %v", revokeErr))
+			}
 		}
+		return nil, errors.WithMessage(err, "error setting settings profile, role grant was rolled back")
 	}
 
-	// Add the new role to the list
-	currentRoles = append(currentRoles, roleName)
+	return granted, nil
+}
 
-	// Build ALTER USER DEFAULT ROLE query
-	sql := buildAlterUserDefaultRoleSQL(userName, currentRoles, clusterName)
+// RevokeGrantRoleWithSettingsProfile removes settingsProfile from the settings profiles of the user and revokes the
+// role granted to it. If the role can't be revoked, the settings profile is added back.
+func (i *impl) RevokeGrantRoleWithSettingsProfile(ctx context.Context, grantedRoleName string, granteeUserName string, settingsProfile string, clusterName *string) error {
+	clusterName = i.withDefaultCluster(clusterName)
+	user, err := i.GetUserByName(ctx, granteeUserName, clusterName)
+	if err != nil {
+		return errors.WithMessage(err, "error getting grantee user")
+	}
 
-	// Execute the query
-	if err := i.clickhouseClient.Exec(ctx, sql); err != nil {
-		// If ALTER USER fails, return error but don't fail the entire grant operation
-		// The role is still granted, just not activated as default
-		return errors.WithMessage(err, "error executing ALTER USER DEFAULT ROLE")
+	if user == nil {
+		// Dropping the user removed both the grant and the profile already.
+		return nil
+	}
+
+	_, err = i.UpdateUserSettingsProfile(ctx, granteeUserName, &settingsProfile, nil, clusterName)
+	if err != nil {
+		return errors.WithMessage(err, "error removing settings profile")
+	}
+
+	err = i.RevokeGrantRole(ctx, grantedRoleName, &granteeUserName, nil, clusterName)
+	if err != nil {
+		if _, restoreErr := i.UpdateUserSettingsProfile(ctx, granteeUserName, nil, &settingsProfile, clusterName); restoreErr != nil {
+			return errors.WithMessage(err, fmt.Sprintf("error revoking role grant, and restoring the settings profile failed as well: %v", restoreErr))
+		}
+		return errors.WithMessage(err, "error revoking role grant, settings profile was restored")
 	}
 
 	return nil
 }
 
+// activateDefaultRole adds the role to user's default roles using ALTER USER DEFAULT ROLE
+func (i *impl) activateDefaultRole(ctx context.Context, userName string, roleName string, clusterName *string) error {
+	return i.reconcileDefaultRoles(ctx, userName, []string{roleName}, nil, clusterName)
+}
+
 // deactivateDefaultRole removes the role from user's default roles using ALTER USER DEFAULT ROLE
 func (i *impl) deactivateDefaultRole(ctx context.Context, userName string, roleName string, clusterName *string) error {
+	return i.reconcileDefaultRoles(ctx, userName, nil, []string{roleName}, clusterName)
+}
+
+// defaultRolesBatch is a set of default role changes for the same user, applied together.
+type defaultRolesBatch struct {
+	add    []string
+	remove []string
+	// err is the result of applying the batch, set before the user lock is released.
+	err error
+}
+
+// merge adds the given changes to the batch. A later change to the same role overrides an earlier one.
+func (b *defaultRolesBatch) merge(add []string, remove []string) {
+	for _, role := range add {
+		b.remove = slices.DeleteFunc(b.remove, func(r string) bool { return r == role })
+		if !slices.Contains(b.add, role) {
+			b.add = append(b.add, role)
+		}
+	}
+	for _, role := range remove {
+		b.add = slices.DeleteFunc(b.add, func(r string) bool { return r == role })
+		if !slices.Contains(b.remove, role) {
+			b.remove = append(b.remove, role)
+		}
+	}
+}
+
+// reconcileDefaultRoles computes the final set of default roles for the user, adding 'add' and removing 'remove'
+// from the current ones, and applies it with a single ALTER USER DEFAULT ROLE query. No query is run if the set
+// of default roles doesn't change.
+// Changes requested for the same user while another one is being applied, e.g. by several grant_role resources
+// created in parallel, are batched: the first caller getting the user lock applies all of them with a single query,
+// and the others return its result.
+func (i *impl) reconcileDefaultRoles(ctx context.Context, userName string, add []string, remove []string, clusterName *string) error {
+	key := userKey(userName, clusterName)
+
+	i.pendingDefaultRolesMu.Lock()
+	if i.pendingDefaultRoles == nil {
+		i.pendingDefaultRoles = make(map[string]*defaultRolesBatch)
+	}
+	batch, ok := i.pendingDefaultRoles[key]
+	if !ok {
+		batch = &defaultRolesBatch{}
+		i.pendingDefaultRoles[key] = batch
+	}
+	batch.merge(add, remove)
+	i.pendingDefaultRolesMu.Unlock()
+
+	defer i.lockUser(userName, clusterName)()
+
+	i.pendingDefaultRolesMu.Lock()
+	pending := i.pendingDefaultRoles[key] == batch
+	if pending {
+		// Later changes start a new batch.
+		delete(i.pendingDefaultRoles, key)
+	}
+	i.pendingDefaultRolesMu.Unlock()
+
+	if !pending {
+		// Applied by a caller that got the lock earlier, and released it only after setting the result.
+		return batch.err
+	}
+
+	batch.err = i.applyDefaultRoles(ctx, userName, batch.add, batch.remove, clusterName)
+	return batch.err
+}
+
+// applyDefaultRoles runs the read-modify-write of the default roles of the user. The caller must hold the user lock.
+func (i *impl) applyDefaultRoles(ctx context.Context, userName string, add []string, remove []string, clusterName *string) error {
 	// Get current default roles
 	currentRoles, err := i.getDefaultRoles(ctx, userName, clusterName)
 	if err != nil {
-		// If we can't get default roles, skip deactivation
-		// The role is still revoked, just not deactivated from default
+		// If we can't get default roles (e.g., user doesn't exist yet), skip reconciliation
+		// The roles are still granted or revoked, just not (de)activated as default
 		return nil
 	}
 
-	// Check if role is in default roles
-	found := false
-	newRoles := make([]string, 0, len(currentRoles))
+	if len(add) > 0 {
+		// A role that is not granted can't be a default role, and would make the whole ALTER USER fail.
+		granted, err := i.ListGrantedRoles(ctx, userName, clusterName)
+		if err != nil {
+			return errors.WithMessage(err, "error listing granted roles")
+		}
+
+		add = slices.DeleteFunc(slices.Clone(add), func(role string) bool {
+			if slices.Contains(granted, role) {
+				return false
+			}
+
+			tflog.Warn(ctx, "role is not granted to the user, not activating it as default role", map[string]any{
+				"user": userName,
+				"role": role,
+			})
+			return true
+		})
+	}
+
+	changed := false
+
+	newRoles := make([]string, 0, len(currentRoles)+len(add))
 	for _, role := range currentRoles {
-		if role == roleName {
-			found = true
+		if slices.Contains(remove, role) {
 			// Skip this role - remove it from the list
+			changed = true
 			continue
 		}
 		newRoles = append(newRoles, role)
 	}
 
-	// If role was not in default roles, nothing to do
-	if !found {
+	for _, role := range add {
+		if slices.Contains(newRoles, role) {
+			// Role is already a default role, nothing to do
+			continue
+		}
+		newRoles = append(newRoles, role)
+		changed = true
+	}
+
+	if !changed {
 		return nil
 	}
 
 	// Build ALTER USER DEFAULT ROLE query with updated list
-	sql := buildAlterUserDefaultRoleSQL(userName, newRoles, clusterName)
+	sql, err := querybuilder.NewAlterUser(userName).
+		WithCluster(clusterName).
+		SetDefaultRoles(newRoles).
+		Build()
+	if err != nil {
+		return errors.WithMessage(err, "error building ALTER USER DEFAULT ROLE query")
+	}
 
 	// Execute the query
-	if err := i.clickhouseClient.Exec(ctx, sql); err != nil {
-		// If ALTER USER fails, return error but don't fail the entire revoke operation
-		// The role is still revoked, just not deactivated from default
+	if err := i.execWithContext(ctx, sql, clusterName); err != nil {
+		// If ALTER USER fails, return error but don't fail the entire grant or revoke operation
 		return errors.WithMessage(err, "error executing ALTER USER DEFAULT ROLE")
 	}
 
 	return nil
 }
 
-// getDefaultRoles retrieves current default roles for a user from system.users
-func (i *impl) getDefaultRoles(ctx context.Context, userName string, clusterName *string) ([]string, error) {
-	// Use toString() to convert Array(String) to string representation
-	sql, err := querybuilder.
-		NewSelect(
-			[]querybuilder.Field{querybuilder.NewField("default_roles_list").ToString()},
-			"system.users",
+// ListGrantedRoles returns the names of the roles granted to the user.
+func (i *impl) ListGrantedRoles(ctx context.Context, userName string, clusterName *string) ([]string, error) {
+	clusterName = i.withDefaultCluster(clusterName)
+	sql, err := i.
+		newSelect(
+			[]querybuilder.Field{querybuilder.NewField("granted_role_name")},
+			"system.role_grants",
 		).
 		WithCluster(clusterName).
-		Where(querybuilder.WhereEquals("name", userName)).
+		Where(querybuilder.WhereEquals("user_name", userName)).
 		Build()
 	if err != nil {
-		return nil, errors.WithMessage(err, "error building SELECT query")
+		return nil, errors.WithMessage(err, "error building query")
 	}
 
-	var roles []string
-	found := false
+	roles := make([]string, 0)
 	err = i.clickhouseClient.Select(ctx, sql, func(data clickhouseclient.Row) error {
-		found = true
-		// default_roles_list is an Array(String) in ClickHouse, converted to string via toString()
-		// toString() always returns a string, even for empty arrays (returns "[]")
-		rolesValue, err := data.GetString("default_roles_list")
+		roleName, err := data.GetString("granted_role_name")
 		if err != nil {
-			// Try nullable string as fallback
-			rolesValuePtr, err2 := data.GetNullableString("default_roles_list")
-			if err2 != nil {
-				return errors.WithMessage(err, "error scanning default_roles_list field")
-			}
-			if rolesValuePtr == nil || *rolesValuePtr == "" {
-				return nil // No default roles
-			}
-			rolesValue = *rolesValuePtr
+			return errors.WithMessage(err, "error scanning query result, missing 'granted_role_name' field")
 		}
 
-		if rolesValue == "" || rolesValue == "[]" {
-			return nil // No default roles
-		}
-
-		// Parse the array string format from ClickHouse toString()
-		// ClickHouse toString() returns arrays as ['role1','role2'] or [] for empty
-		rolesStr := strings.Trim(rolesValue, "[]")
-		if rolesStr == "" {
-			return nil
-		}
-
-		// Split by comma and clean up quotes
-		parts := strings.Split(rolesStr, ",")
-		for _, part := range parts {
-			role := strings.Trim(strings.TrimSpace(part), "'\"")
-			if role != "" {
-				roles = append(roles, role)
-			}
-		}
+		roles = append(roles, roleName)
 		return nil
 	})
 	if err != nil {
-		return nil, errors.WithMessage(err, "error running SELECT query")
-	}
-
-	if !found {
-		// User not found, return empty roles list
-		return []string{}, nil
+		return nil, errors.WithMessage(err, "error running query")
 	}
 
 	return roles, nil
 }
 
-// buildAlterUserDefaultRoleSQL builds ALTER USER ... DEFAULT ROLE SQL query
-func buildAlterUserDefaultRoleSQL(userName string, roles []string, clusterName *string) string {
-	var roleClause string
-	if len(roles) == 0 {
-		// If no roles, use NONE to remove all default roles
-		roleClause = "NONE"
-	} else {
-		// Quote role names
-		quotedRoles := make([]string, 0, len(roles))
-		for _, role := range roles {
-			quotedRoles = append(quotedRoles, fmt.Sprintf("`%s`", role))
-		}
-		roleClause = strings.Join(quotedRoles, ", ")
+// getDefaultRoles retrieves current default roles for a user from system.users
+func (i *impl) getDefaultRoles(ctx context.Context, userName string, clusterName *string) ([]string, error) {
+	sql, err := i.
+		newSelect(
+			[]querybuilder.Field{querybuilder.NewField("default_roles_list")},
+			"system.users",
+		).
+		WithCluster(clusterName).
+		Where(querybuilder.WhereEquals("name", userName)).
+		Build()
+	if err != nil {
+		return nil, errors.WithMessage(err, "error building SELECT query")
 	}
 
-	sql := fmt.Sprintf(
-		"ALTER USER `%s` DEFAULT ROLE %s",
-		userName,
-		roleClause,
-	)
+	roles := make([]string, 0)
+	err = i.clickhouseClient.Select(ctx, sql, func(data clickhouseclient.Row) error {
+		// default_roles_list is an Array(String) in ClickHouse.
+		defaultRoles, err := data.GetStringSlice("default_roles_list")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning default_roles_list field")
+		}
 
-	if clusterName != nil {
-		sql += fmt.Sprintf(" ON CLUSTER %s", *clusterName)
+		roles = defaultRoles
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "error running SELECT query")
 	}
 
-	return sql + ";"
+	return roles, nil
 }
@@ -0,0 +1,528 @@
+package dbops
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/querybuilder"
+)
+
+func Test_GrantRole_defaultRole(t *testing.T) {
+	userName := "john"
+
+	tests := []struct {
+		name      string
+		roleName  string
+		wantAlter string
+	}{
+		{
+			name:      "Role activated as default",
+			roleName:  "reader",
+			wantAlter: "ALTER USER `john` DEFAULT ROLE `existing`, `reader`;",
+		},
+		{
+			name:      "Role already default",
+			roleName:  "existing",
+			wantAlter: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					row := clickhouseclient.Row{}
+					switch {
+					case strings.Contains(qry, "`system`.`users`"):
+						row.Set("default_roles_list", []string{"existing"})
+					case strings.Contains(qry, "`system`.`role_grants`") && !strings.Contains(qry, "`granted_role_name` ="):
+						return grantedRoleRows(tt.roleName, "existing")
+					case strings.Contains(qry, "`system`.`role_grants`"):
+						row.Set("granted_role_name", tt.roleName)
+						row.Set("user_name", &userName)
+						row.Set("role_name", (*string)(nil))
+						row.Set("with_admin_option", uint8(0))
+					}
+					return []clickhouseclient.Row{row}
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			_, err = client.GrantRole(context.Background(), GrantRole{RoleName: tt.roleName, GranteeUserName: &userName}, nil)
+			if err != nil {
+				t.Fatalf("GrantRole() error = %v", err)
+			}
+
+			alters := make([]string, 0)
+			for _, qry := range fake.execs {
+				if strings.Contains(qry, "DEFAULT ROLE") {
+					alters = append(alters, qry)
+				}
+			}
+
+			if tt.wantAlter == "" {
+				if len(alters) != 0 {
+					t.Errorf("GrantRole() ran DEFAULT ROLE queries %v, want none", alters)
+				}
+				return
+			}
+			if len(alters) != 1 || alters[0] != tt.wantAlter {
+				t.Errorf("GrantRole() DEFAULT ROLE queries = %v, want %q", alters, tt.wantAlter)
+			}
+		})
+	}
+}
+
+func Test_GrantRole_singleDefaultRoleAlterForConcurrentGrants(t *testing.T) {
+	userName := "john"
+	roles := []string{"reader", "writer", "auditor"}
+
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			row := clickhouseclient.Row{}
+			switch {
+			case strings.Contains(qry, "`system`.`users`"):
+				row.Set("default_roles_list", []string{"existing"})
+			case strings.Contains(qry, "`system`.`role_grants`") && !strings.Contains(qry, "`granted_role_name` ="):
+				return grantedRoleRows(append([]string{"existing"}, roles...)...)
+			case strings.Contains(qry, "`system`.`role_grants`"):
+				row.Set("granted_role_name", "reader")
+				row.Set("user_name", &userName)
+				row.Set("role_name", (*string)(nil))
+				row.Set("with_admin_option", uint8(0))
+			}
+			return []clickhouseclient.Row{row}
+		},
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	i := client.(*impl)
+
+	// Hold the user lock until all the grants are waiting to activate their role, like grant_role resources
+	// created in parallel while another change to the user is running.
+	unlock := i.lockUser(userName, nil)
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(roles))
+	for idx, role := range roles {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[idx] = client.GrantRole(context.Background(), GrantRole{RoleName: role, GranteeUserName: &userName}, nil)
+		}()
+	}
+
+	for {
+		i.pendingDefaultRolesMu.Lock()
+		batch := i.pendingDefaultRoles[userName]
+		waiting := batch != nil && len(batch.add) == len(roles)
+		i.pendingDefaultRolesMu.Unlock()
+		if waiting {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	unlock()
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("GrantRole() error = %v", err)
+		}
+	}
+
+	alters := make([]string, 0)
+	for _, qry := range fake.execs {
+		if strings.Contains(qry, "DEFAULT ROLE") {
+			alters = append(alters, qry)
+		}
+	}
+
+	if len(alters) != 1 {
+		t.Fatalf("GrantRole() ran DEFAULT ROLE queries %v, want a single one", alters)
+	}
+	for _, role := range append([]string{"existing"}, roles...) {
+		if !strings.Contains(alters[0], "`"+role+"`") {
+			t.Errorf("GrantRole() DEFAULT ROLE query = %q, want it to include %q", alters[0], role)
+		}
+	}
+}
+
+func Test_reconcileDefaultRoles_specialCharacters(t *testing.T) {
+	tests := []struct {
+		name         string
+		defaultRoles []string
+		add          []string
+		remove       []string
+		want         string
+	}{
+		{
+			name:         "Activate role with comma",
+			defaultRoles: []string{"bob's-role"},
+			add:          []string{"team,ops"},
+			want:         "ALTER USER `john` DEFAULT ROLE `bob's-role`, `team,ops`;",
+		},
+		{
+			name:         "Deactivate role with quote",
+			defaultRoles: []string{"team,ops", "bob's-role"},
+			remove:       []string{"bob's-role"},
+			want:         "ALTER USER `john` DEFAULT ROLE `team,ops`;",
+		},
+		{
+			name:         "Role with comma is not confused with its parts",
+			defaultRoles: []string{"team,ops"},
+			remove:       []string{"team", "ops"},
+			want:         "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					if strings.Contains(qry, "`system`.`role_grants`") {
+						return grantedRoleRows(tt.add...)
+					}
+					row := clickhouseclient.Row{}
+					row.Set("default_roles_list", tt.defaultRoles)
+					return []clickhouseclient.Row{row}
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			err = client.(*impl).reconcileDefaultRoles(context.Background(), "john", tt.add, tt.remove, nil)
+			if err != nil {
+				t.Fatalf("reconcileDefaultRoles() error = %v", err)
+			}
+
+			got := ""
+			if len(fake.execs) > 0 {
+				got = fake.execs[0]
+			}
+			if got != tt.want {
+				t.Errorf("reconcileDefaultRoles() query = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_reconcileDefaultRoles_notGranted(t *testing.T) {
+	tests := []struct {
+		name    string
+		granted []string
+		add     []string
+		want    string
+	}{
+		{
+			name:    "Role not granted is skipped",
+			granted: []string{"existing", "reader"},
+			add:     []string{"reader", "writer"},
+			want:    "ALTER USER `john` DEFAULT ROLE `existing`, `reader`;",
+		},
+		{
+			name:    "No query when no requested role is granted",
+			granted: []string{"existing"},
+			add:     []string{"writer"},
+			want:    "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					if strings.Contains(qry, "`system`.`role_grants`") {
+						return grantedRoleRows(tt.granted...)
+					}
+					row := clickhouseclient.Row{}
+					row.Set("default_roles_list", []string{"existing"})
+					return []clickhouseclient.Row{row}
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			err = client.(*impl).reconcileDefaultRoles(context.Background(), "john", tt.add, nil, nil)
+			if err != nil {
+				t.Fatalf("reconcileDefaultRoles() error = %v", err)
+			}
+
+			got := ""
+			if len(fake.execs) > 0 {
+				got = fake.execs[0]
+			}
+			if got != tt.want {
+				t.Errorf("reconcileDefaultRoles() query = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_reconcileDefaultRoles_onCluster(t *testing.T) {
+	clusterName := "my-cluster"
+
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			if strings.Contains(qry, "`system`.`role_grants`") {
+				return grantedRoleRows("existing", "reader")
+			}
+			row := clickhouseclient.Row{}
+			row.Set("default_roles_list", []string{"existing"})
+			return []clickhouseclient.Row{row}
+		},
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	err = client.(*impl).reconcileDefaultRoles(context.Background(), "john", []string{"reader"}, nil, &clusterName)
+	if err != nil {
+		t.Fatalf("reconcileDefaultRoles() error = %v", err)
+	}
+
+	want, err := querybuilder.NewAlterUser("john").
+		WithCluster(&clusterName).
+		SetDefaultRoles([]string{"existing", "reader"}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	if len(fake.execs) != 1 || fake.execs[0] != want {
+		t.Errorf("reconcileDefaultRoles() queries = %q, want %q", fake.execs, want)
+	}
+	if !strings.Contains(want, "ON CLUSTER 'my-cluster'") {
+		t.Errorf("expected quoted cluster name in %q", want)
+	}
+}
+
+func Test_UpdateGrantRole(t *testing.T) {
+	userName := "john"
+
+	tests := []struct {
+		name            string
+		existingAdmin   uint8
+		wantAdminOption bool
+		want            string
+	}{
+		{
+			name:            "Add admin option",
+			existingAdmin:   0,
+			wantAdminOption: true,
+			want:            "GRANT `reader` TO `john` WITH ADMIN OPTION;",
+		},
+		{
+			name:            "Remove admin option",
+			existingAdmin:   1,
+			wantAdminOption: false,
+			want:            "REVOKE ADMIN OPTION FOR `reader` FROM `john`;",
+		},
+		{
+			name:            "Admin option unchanged",
+			existingAdmin:   1,
+			wantAdminOption: true,
+			want:            "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					row := clickhouseclient.Row{}
+					row.Set("granted_role_name", "reader")
+					row.Set("user_name", &userName)
+					row.Set("role_name", (*string)(nil))
+					row.Set("with_admin_option", tt.existingAdmin)
+					return []clickhouseclient.Row{row}
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			_, err = client.UpdateGrantRole(context.Background(), GrantRole{
+				RoleName:        "reader",
+				GranteeUserName: &userName,
+				AdminOption:     tt.wantAdminOption,
+			}, nil)
+			if err != nil {
+				t.Fatalf("UpdateGrantRole() error = %v", err)
+			}
+
+			got := ""
+			if len(fake.execs) > 0 {
+				got = fake.execs[0]
+			}
+			if got != tt.want {
+				t.Errorf("UpdateGrantRole() query = %q, want %q", got, tt.want)
+			}
+			if len(fake.execs) > 1 {
+				t.Errorf("UpdateGrantRole() ran %d queries, want at most 1: %v", len(fake.execs), fake.execs)
+			}
+		})
+	}
+}
+
+func Test_GrantRoleWithSettingsProfile(t *testing.T) {
+	userName := "john"
+
+	tests := []struct {
+		name        string
+		profileErr  error
+		wantErr     bool
+		wantRevoked bool
+	}{
+		{
+			name: "Grant and profile",
+		},
+		{
+			name:        "Grant is rolled back when the profile can't be set",
+			profileErr:  errors.New("code: 180, message: There is no profile `missing`"),
+			wantErr:     true,
+			wantRevoked: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{}
+			fake.rows = func(qry string) []clickhouseclient.Row {
+				row := clickhouseclient.Row{}
+				switch {
+				case strings.Contains(qry, "`system`.`role_grants`"):
+					if len(fake.execs) == 0 {
+						// Not granted yet.
+						return nil
+					}
+					row.Set("granted_role_name", "reader")
+					row.Set("user_name", &userName)
+					row.Set("role_name", (*string)(nil))
+					row.Set("with_admin_option", uint8(0))
+				case strings.Contains(qry, "`auth_type`"):
+					id := "00000000-0000-0000-0000-000000000000"
+					row.Set("name", userName)
+					row.Set("id", &id)
+					row.Set("auth_type", "sha256_password")
+				default:
+					return nil
+				}
+				return []clickhouseclient.Row{row}
+			}
+			fake.execErr = func(qry string) error {
+				if strings.Contains(qry, "ADD PROFILES") {
+					return tt.profileErr
+				}
+				return nil
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			_, err = client.GrantRoleWithSettingsProfile(context.Background(), GrantRole{
+				RoleName:        "reader",
+				GranteeUserName: &userName,
+			}, "limited", nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GrantRoleWithSettingsProfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !slices.Contains(fake.execs, "GRANT `reader` TO `john`;") {
+				t.Errorf("GrantRoleWithSettingsProfile() queries = %q, want the role to be granted", fake.execs)
+			}
+			if !slices.Contains(fake.execs, "ALTER USER `john` ADD PROFILES 'limited';") {
+				t.Errorf("GrantRoleWithSettingsProfile() queries = %q, want the profile to be added", fake.execs)
+			}
+			revoked := slices.Contains(fake.execs, "REVOKE `reader` FROM `john`;")
+			if revoked != tt.wantRevoked {
+				t.Errorf("GrantRoleWithSettingsProfile() revoked = %v, want %v: %q", revoked, tt.wantRevoked, fake.execs)
+			}
+		})
+	}
+}
+
+func Test_RevokeGrantRoleWithSettingsProfile_restoresProfile(t *testing.T) {
+	userName := "john"
+	limited := "limited"
+
+	fake := &fakeClickhouseClient{}
+	fake.rows = func(qry string) []clickhouseclient.Row {
+		row := clickhouseclient.Row{}
+		switch {
+		case strings.Contains(qry, "`auth_type`"):
+			id := "00000000-0000-0000-0000-000000000000"
+			row.Set("name", userName)
+			row.Set("id", &id)
+			row.Set("auth_type", "sha256_password")
+		case strings.Contains(qry, "`system`.`settings_profile_elements`"):
+			if len(fake.execs) > 0 {
+				// The profile was dropped by the first query.
+				return nil
+			}
+			row.Set("profile_name", (*string)(nil))
+			row.Set("inherit_profile", &limited)
+			row.Set("setting_name", (*string)(nil))
+		default:
+			return nil
+		}
+		return []clickhouseclient.Row{row}
+	}
+	fake.execErr = func(qry string) error {
+		if strings.HasPrefix(qry, "REVOKE") {
+			return errors.New("code: 497, message: Not enough privileges")
+		}
+		return nil
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	err = client.RevokeGrantRoleWithSettingsProfile(context.Background(), "reader", userName, limited, nil)
+	if err == nil {
+		t.Fatalf("RevokeGrantRoleWithSettingsProfile() expected error")
+	}
+
+	want := []string{
+		"ALTER USER `john` DROP PROFILES 'limited';",
+		"REVOKE `reader` FROM `john`;",
+		"ALTER USER `john` ADD PROFILES 'limited';",
+	}
+	if !slices.Equal(fake.execs, want) {
+		t.Errorf("RevokeGrantRoleWithSettingsProfile() queries = %q, want %q", fake.execs, want)
+	}
+}
+
+// grantedRoleRows returns the system.role_grants rows listing the given roles as granted.
+func grantedRoleRows(roles ...string) []clickhouseclient.Row {
+	rows := make([]clickhouseclient.Row, 0, len(roles))
+	for _, role := range roles {
+		row := clickhouseclient.Row{}
+		row.Set("granted_role_name", role)
+		rows = append(rows, row)
+	}
+	return rows
+}
@@ -1,15 +1,226 @@
 package dbops
 
 import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pingcap/errors"
+
 	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/querybuilder"
 )
 
 type impl struct {
 	clickhouseClient clickhouseclient.ClickhouseClient
+
+	readAfterCreateRetries int
+	readAfterCreateBackoff time.Duration
+
+	disallowRename bool
+
+	// reservedSettingsProfiles are the names of the settings profiles that can't be created, renamed or dropped.
+	reservedSettingsProfiles []string
+
+	now func() time.Time
+
+	validateSQL bool
+
+	clusterReads querybuilder.ClusterReads
+
+	namePrefix string
+
+	// defaultCluster is used by the operations called without a cluster name, when set.
+	defaultCluster *string
+
+	// replicatedStorage caches the result of IsReplicatedStorage for each cluster name, "" being the server connected to.
+	replicatedStorageMu sync.Mutex
+	replicatedStorage   map[string]bool
+
+	// systemColumns caches the result of hasSystemColumn for each cluster name, table and column.
+	systemColumnsMu sync.Mutex
+	systemColumns   map[string]bool
+
+	// userLocks serializes concurrent changes to the settings profiles and default roles of the same user.
+	userLocks keyedMutex
+
+	// pendingDefaultRoles holds, for each user, the default role changes waiting for the user lock. They are all
+	// applied by the first caller getting the lock, see reconcileDefaultRoles.
+	pendingDefaultRolesMu sync.Mutex
+	pendingDefaultRoles   map[string]*defaultRolesBatch
+}
+
+// Option customizes the behaviour of the Client returned by NewClient.
+type Option func(*impl)
+
+// WithReadAfterCreateRetries makes create operations retry reading back the created object up to 'retries' times,
+// waiting 'backoff' between attempts. This is useful when writes and reads can hit different replicas, for example
+// behind an HTTP load balancer.
+func WithReadAfterCreateRetries(retries int, backoff time.Duration) Option {
+	return func(i *impl) {
+		i.readAfterCreateRetries = retries
+		i.readAfterCreateBackoff = backoff
+	}
+}
+
+// WithAllowRename controls whether users, roles and settings profiles can be renamed. When false, updates changing
+// the name of any of these entities fail instead of issuing a RENAME TO.
+func WithAllowRename(allow bool) Option {
+	return func(i *impl) {
+		i.disallowRename = !allow
+	}
+}
+
+// DefaultReservedSettingsProfiles are the built-in settings profiles of ClickHouse, reserved unless
+// WithReservedSettingsProfiles says otherwise.
+var DefaultReservedSettingsProfiles = []string{"default", "readonly"}
+
+// WithReservedSettingsProfiles overrides the names of the settings profiles that can't be created, renamed or
+// dropped. An empty list allows managing any settings profile.
+func WithReservedSettingsProfiles(names []string) Option {
+	return func(i *impl) {
+		i.reservedSettingsProfiles = names
+	}
+}
+
+// WithClock overrides the function used to get the current time, for example when checking whether
+// a user's credentials have expired. Defaults to time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(i *impl) {
+		i.now = now
+	}
+}
+
+// WithSQLValidation makes the Validate* methods send the generated queries to the server with EXPLAIN AST,
+// which parses them without running them. Without it, queries are only validated structurally.
+func WithSQLValidation() Option {
+	return func(i *impl) {
+		i.validateSQL = true
+	}
+}
+
+// Cluster read modes supported by WithClusterReads.
+const (
+	ClusterReadsAnyReplica   = string(querybuilder.ClusterReadsAnyReplica)
+	ClusterReadsAllReplicas  = string(querybuilder.ClusterReadsAllReplicas)
+	ClusterReadsFirstReplica = string(querybuilder.ClusterReadsFirstReplica)
+)
+
+// WithClusterReads sets which replicas the reads of system tables go to when a cluster name is given, one of the
+// ClusterReads* constants. Defaults to ClusterReadsAnyReplica.
+func WithClusterReads(mode string) Option {
+	return func(i *impl) {
+		i.clusterReads = querybuilder.ClusterReads(mode)
+	}
 }
 
-func NewClient(clickhouseClient clickhouseclient.ClickhouseClient) (Client, error) {
-	return &impl{
-		clickhouseClient: clickhouseClient,
-	}, nil
+// WithNamePrefix prepends the given prefix to the names of the users, roles, settings profiles and row policies,
+// and to the references to them, e.g. grantees. The prefix is removed from the names read back, so callers only
+// deal with short names. Databases and the reserved settings profiles are not prefixed.
+func WithNamePrefix(prefix string) Option {
+	return func(i *impl) {
+		i.namePrefix = prefix
+	}
+}
+
+// WithDefaultCluster makes the operations called without a cluster name run on the given cluster instead of the
+// replica hit by the query. An explicit cluster name still takes precedence.
+func WithDefaultCluster(clusterName string) Option {
+	return func(i *impl) {
+		i.defaultCluster = &clusterName
+	}
+}
+
+func NewClient(clickhouseClient clickhouseclient.ClickhouseClient, opts ...Option) (Client, error) {
+	i := &impl{
+		clickhouseClient:         clickhouseClient,
+		now:                      time.Now,
+		reservedSettingsProfiles: DefaultReservedSettingsProfiles,
+	}
+
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	if i.namePrefix != "" {
+		return &namePrefixClient{Client: i, prefix: i.namePrefix, reservedSettingsProfiles: i.reservedSettingsProfiles}, nil
+	}
+
+	return i, nil
+}
+
+// validateQuery asks the server to parse the given query without running it, if SQL validation is enabled.
+func (i *impl) validateQuery(ctx context.Context, sql string) error {
+	if !i.validateSQL {
+		return nil
+	}
+
+	err := i.clickhouseClient.Select(ctx, "EXPLAIN AST "+strings.TrimSuffix(sql, ";"), func(clickhouseclient.Row) error {
+		return nil
+	})
+	if err != nil {
+		return errors.WithMessage(err, "error validating query")
+	}
+
+	return nil
+}
+
+// checkRename returns an error if renaming is not allowed and the name of the given entity changes.
+func (i *impl) checkRename(entityType string, oldName string, newName string) error {
+	if i.disallowRename && oldName != newName {
+		return errors.Errorf("renaming %s %q to %q is not allowed by the provider configuration (allow_rename = false). Please create a new resource instead", entityType, oldName, newName)
+	}
+
+	return nil
+}
+
+// checkReservedSettingsProfile returns an error if the settings profile with the given name is reserved.
+func (i *impl) checkReservedSettingsProfile(name string) error {
+	if slices.Contains(i.reservedSettingsProfiles, name) {
+		return errors.Errorf("settings profile %q is reserved and can't be created, renamed or dropped by the provider (reserved_settings_profiles). Remove it from the Terraform state, or from the reserved_settings_profiles provider attribute to manage it anyway", name)
+	}
+
+	return nil
+}
+
+// newSelect returns a SELECT query builder reading from the replicas configured with WithClusterReads.
+func (i *impl) newSelect(fields []querybuilder.Field, from string) querybuilder.SelectQueryBuilder {
+	return querybuilder.NewSelect(fields, from).WithClusterReads(i.clusterReads)
+}
+
+// execWithContext runs the statement, adding it and the cluster it ran on to the error, if any.
+// The secrets of the statement, e.g. password hashes, are redacted.
+func (i *impl) execWithContext(ctx context.Context, sql string, clusterName *string) error {
+	err := i.clickhouseClient.Exec(ctx, sql)
+	if err == nil {
+		return nil
+	}
+
+	msg := fmt.Sprintf("error running query %q", clickhouseclient.RedactSecrets(sql))
+	if clusterName != nil {
+		msg = fmt.Sprintf("%s on cluster %q", msg, *clusterName)
+	}
+
+	return errors.WithMessage(err, msg)
+}
+
+func (i *impl) newCount(from string) querybuilder.SelectQueryBuilder {
+	return querybuilder.NewCount(from).WithClusterReads(i.clusterReads)
+}
+
+// DefaultClusterName returns the cluster used by the operations called without a cluster name, or nil.
+func (i *impl) DefaultClusterName() *string {
+	return i.defaultCluster
+}
+
+// withDefaultCluster returns the given cluster name, or the default cluster when it is nil.
+func (i *impl) withDefaultCluster(clusterName *string) *string {
+	if clusterName == nil {
+		return i.defaultCluster
+	}
+
+	return clusterName
 }
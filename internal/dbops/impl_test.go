@@ -0,0 +1,87 @@
+package dbops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+)
+
+// fakeClickhouseClient records the queries run through Exec and answers Select queries with the rows returned by 'rows'.
+// When set, 'selectErr' and 'execErr' make Select and Exec fail for the queries they return an error for.
+type fakeClickhouseClient struct {
+	mu        sync.Mutex
+	execs     []string
+	rows      func(qry string) []clickhouseclient.Row
+	selectErr func(qry string) error
+	execErr   func(qry string) error
+}
+
+func (f *fakeClickhouseClient) Select(_ context.Context, qry string, callback func(clickhouseclient.Row) error) error {
+	if f.selectErr != nil {
+		if err := f.selectErr(qry); err != nil {
+			return err
+		}
+	}
+	for _, row := range f.rows(qry) {
+		if err := callback(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeClickhouseClient) Exec(_ context.Context, qry string) error {
+	f.mu.Lock()
+	f.execs = append(f.execs, qry)
+	f.mu.Unlock()
+	if f.execErr != nil {
+		return f.execErr(qry)
+	}
+	return nil
+}
+
+// systemColumnRows answers the queries checking whether a column of a system table exists, finding only the given ones.
+func systemColumnRows(qry string, columns ...string) []clickhouseclient.Row {
+	for _, column := range columns {
+		if strings.Contains(qry, fmt.Sprintf("'%s'", column)) {
+			row := clickhouseclient.Row{}
+			row.Set("name", column)
+			return []clickhouseclient.Row{row}
+		}
+	}
+	return nil
+}
+
+func Test_execWithContext(t *testing.T) {
+	clusterName := "cluster1"
+	fake := &fakeClickhouseClient{
+		execErr: func(qry string) error {
+			return errors.New("code: 62, message: Syntax error")
+		},
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	err = client.(*impl).execWithContext(context.Background(), "ALTER USER `john` ON CLUSTER 'cluster1' IDENTIFIED WITH plaintext_password BY 'changeme';", &clusterName)
+	if err == nil {
+		t.Fatalf("execWithContext() error = nil, want an error")
+	}
+
+	msg := fmt.Sprintf("%+v", err)
+	for _, want := range []string{"ALTER USER `john`", `on cluster "cluster1"`, "Syntax error"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("execWithContext() error = %q, want it to contain %q", msg, want)
+		}
+	}
+	if strings.Contains(msg, "changeme") {
+		t.Errorf("execWithContext() error = %q, contains the password", msg)
+	}
+}
@@ -15,23 +15,42 @@ type Client interface {
 	DeleteRole(ctx context.Context, id string, clusterName *string) error
 	FindRoleByName(ctx context.Context, name string, clusterName *string) (*Role, error)
 	UpdateRole(ctx context.Context, role Role, clusterName *string) (*Role, error)
+	ListRoles(ctx context.Context, namePrefix string, clusterName *string) ([]Role, error)
 
 	CreateUser(ctx context.Context, user User, clusterName *string) (*User, error)
 	GetUserByName(ctx context.Context, name string, clusterName *string) (*User, error)
 	resolveUserName(ctx context.Context, name string, clusterName *string) (string, error)
 	GetUserByUUID(ctx context.Context, uuid string, clusterName *string) (*User, error)
+	UserExists(ctx context.Context, name string, clusterName *string) (bool, error)
 	DeleteUser(ctx context.Context, id string, clusterName *string) error
 	FindUserByName(ctx context.Context, name string, clusterName *string) (*User, error)
 	UpdateUser(ctx context.Context, user User, clusterName *string) (*User, error)
+	UpdateUserSettingsProfile(ctx context.Context, name string, oldProfile *string, newProfile *string, clusterName *string) (*User, error)
+	GetUserGrantees(ctx context.Context, userID string, clusterName *string) (*UserGrantees, error)
+	SetUserGrantees(ctx context.Context, userID string, grantees UserGrantees, clusterName *string) error
 
 	GrantRole(ctx context.Context, grantRole GrantRole, clusterName *string) (*GrantRole, error)
+	ListGrantedRoles(ctx context.Context, userName string, clusterName *string) ([]string, error)
 	GetGrantRole(ctx context.Context, grantedRoleName string, granteeUserName *string, granteeRoleName *string, clusterName *string) (*GrantRole, error)
+	UpdateGrantRole(ctx context.Context, grantRole GrantRole, clusterName *string) (*GrantRole, error)
 	RevokeGrantRole(ctx context.Context, grantedRoleName string, granteeUserName *string, granteeRoleName *string, clusterName *string) error
+	GrantRoleWithSettingsProfile(ctx context.Context, grantRole GrantRole, settingsProfile string, clusterName *string) (*GrantRole, error)
+	RevokeGrantRoleWithSettingsProfile(ctx context.Context, grantedRoleName string, granteeUserName string, settingsProfile string, clusterName *string) error
 
 	GrantPrivilege(ctx context.Context, grantPrivilege GrantPrivilege, clusterName *string) (*GrantPrivilege, error)
 	GetGrantPrivilege(ctx context.Context, accessType string, database *string, table *string, column *string, granteeUserName *string, granteeRoleName *string, clusterName *string) (*GrantPrivilege, error)
 	RevokeGrantPrivilege(ctx context.Context, accessType string, database *string, table *string, column *string, granteeUserName *string, granteeRoleName *string, clusterName *string) error
 	GetAllGrantsForGrantee(ctx context.Context, granteeUsername *string, granteeRoleName *string, clusterName *string) ([]GrantPrivilege, error)
+	ListUserGrants(ctx context.Context, userName string, clusterName *string) (*UserGrants, error)
+	GetEffectiveGrants(ctx context.Context, userName string, clusterName *string) (*EffectiveGrants, error)
+
+	CreateRowPolicy(ctx context.Context, rowPolicy RowPolicy, clusterName *string) (*RowPolicy, error)
+	GetRowPolicy(ctx context.Context, id string, clusterName *string) (*RowPolicy, error)
+	FindRowPolicyByName(ctx context.Context, name string, databaseName string, tableName string, clusterName *string) (*RowPolicy, error)
+	UpdateRowPolicy(ctx context.Context, rowPolicy RowPolicy, clusterName *string) (*RowPolicy, error)
+	DeleteRowPolicy(ctx context.Context, id string, clusterName *string) error
+	SameRowPolicyExpression(ctx context.Context, expression string, other string) (bool, error)
+	ValidateRowPolicy(ctx context.Context, rowPolicy RowPolicy, clusterName *string) error
 
 	CreateSettingsProfile(ctx context.Context, profile SettingsProfile, clusterName *string) (*SettingsProfile, error)
 	GetSettingsProfile(ctx context.Context, id string, clusterName *string) (*SettingsProfile, error)
@@ -40,15 +59,44 @@ type Client interface {
 	FindSettingsProfileByName(ctx context.Context, name string, clusterName *string) (*SettingsProfile, error)
 	AssociateSettingsProfile(ctx context.Context, id string, roleId *string, userId *string, clusterName *string) error
 	DisassociateSettingsProfile(ctx context.Context, id string, roleId *string, userId *string, clusterName *string) error
-	// GetSettingsProfileByName returns the settings profile by name.
+	// AssociateSettingsProfiles adds the settings profiles to the role or user, skipping the ones it already has.
+	AssociateSettingsProfiles(ctx context.Context, ids []string, roleId *string, userId *string, clusterName *string) error
+	// DisassociateSettingsProfiles drops the settings profiles from the role or user, skipping the ones it doesn't have.
+	DisassociateSettingsProfiles(ctx context.Context, ids []string, roleId *string, userId *string, clusterName *string) error
+	// ReplaceRoleSettingsProfiles associates the settings profile to the role, replacing all its other profiles and settings.
+	ReplaceRoleSettingsProfiles(ctx context.Context, id string, roleId string, clusterName *string) error
+	// GetSettingsProfileByName returns the settings profile by name, looking it up on all the replicas of the cluster.
 	GetSettingsProfileByName(ctx context.Context, name string, clusterName *string) (*SettingsProfile, error)
+	// ListSettingsProfileAssociations returns the users and roles associated to the settings profile with the given name.
+	ListSettingsProfileAssociations(ctx context.Context, profileName string, clusterName *string) (*SettingsProfileAssociations, error)
 
 	// AssociateSettingsProfileByName attaches a settings profile (by name) to a role or user.
 	AssociateSettingsProfileByName(ctx context.Context, profileName string, roleID *string, userID *string, clusterName *string) error
 
+	GetProfileForAll(ctx context.Context, clusterName *string) (*SettingsProfile, error)
+	SetProfileForAll(ctx context.Context, id string, clusterName *string) error
+	ClearProfileForAll(ctx context.Context, id string, clusterName *string) error
+
 	CreateSetting(ctx context.Context, settingsProfileID string, setting Setting, clusterName *string) (*Setting, error)
 	GetSetting(ctx context.Context, settingsProfileID string, name string, clusterName *string) (*Setting, error)
 	DeleteSetting(ctx context.Context, settingsProfileID string, name string, clusterName *string) error
+	ValidateSetting(ctx context.Context, setting Setting, clusterName *string) error
 
-	IsReplicatedStorage(ctx context.Context) (bool, error)
+	IsReplicatedStorage(ctx context.Context, clusterName *string) (bool, error)
+	// ServerVersion returns the version and uptime of the server the client is connected to.
+	ServerVersion(ctx context.Context) (*ServerVersion, error)
+	// UserDirectories returns the storages for users, roles and the other access entities, ordered by precedence.
+	UserDirectories(ctx context.Context, clusterName *string) ([]UserDirectory, error)
+	// GetClusterTopology returns the replicas of the given cluster, ordered by shard and replica.
+	GetClusterTopology(ctx context.Context, clusterName string) ([]ClusterReplica, error)
+	// ReadOnlyAccessStorage returns the name of the read-only user directory holding the given entity, if any.
+	ReadOnlyAccessStorage(ctx context.Context, entity AccessEntity, name string, clusterName *string) (string, error)
+	// AccessEntityID returns the ID of the entity with the given name, or an empty string when there is none.
+	AccessEntityID(ctx context.Context, entity AccessEntity, name string, clusterName *string) (string, error)
+	// SupportsRoleComment returns true if the server supports the COMMENT clause of CREATE ROLE and ALTER ROLE.
+	SupportsRoleComment(ctx context.Context, clusterName *string) (bool, error)
+	// SupportsUserComment returns true if the server supports the COMMENT clause of CREATE USER and ALTER USER.
+	SupportsUserComment(ctx context.Context, clusterName *string) (bool, error)
+	// DefaultClusterName returns the cluster used when no cluster name is given, or nil when there is none.
+	DefaultClusterName() *string
 }
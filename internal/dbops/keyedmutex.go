@@ -0,0 +1,43 @@
+package dbops
+
+import (
+	"sync"
+)
+
+// keyedMutex is a set of mutexes identified by a key, created on first use. The zero value is ready to use.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// lock locks the mutex of the given key, and returns the function unlocking it.
+func (k *keyedMutex) lock(key string) func() {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*sync.Mutex)
+	}
+	l, ok := k.locks[key]
+	if !ok {
+		l = &sync.Mutex{}
+		k.locks[key] = l
+	}
+	k.mu.Unlock()
+
+	l.Lock()
+	return l.Unlock
+}
+
+// lockUser serializes the changes to the settings profiles and default roles of a user made through this client, as
+// they depend on the current state of the user. It returns the function releasing the lock.
+func (i *impl) lockUser(userName string, clusterName *string) func() {
+	return i.userLocks.lock(userKey(userName, clusterName))
+}
+
+// userKey identifies a user on a cluster, or on the server connected to when clusterName is nil.
+func userKey(userName string, clusterName *string) string {
+	if clusterName != nil {
+		return *clusterName + "/" + userName
+	}
+
+	return userName
+}
@@ -0,0 +1,157 @@
+package dbops
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+)
+
+func Test_UpdateUserSettingsProfile_concurrent(t *testing.T) {
+	addProfile := regexp.MustCompile(`ADD PROFILES '([^']+)'`)
+
+	var mu sync.Mutex
+	profiles := make([]string, 0)
+
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			switch {
+			case strings.Contains(qry, "`auth_type`"):
+				id := "00000000-0000-0000-0000-000000000000"
+				row := clickhouseclient.Row{}
+				row.Set("name", "john")
+				row.Set("id", &id)
+				row.Set("auth_type", "sha256_password")
+				return []clickhouseclient.Row{row}
+			case strings.Contains(qry, "`system`.`settings_profile_elements`"):
+				mu.Lock()
+				ret := make([]clickhouseclient.Row, 0)
+				for _, p := range profiles {
+					row := clickhouseclient.Row{}
+					row.Set("inherit_profile", &p)
+					ret = append(ret, row)
+				}
+				mu.Unlock()
+				// Give the other goroutine a chance to read the same profiles.
+				time.Sleep(10 * time.Millisecond)
+				return ret
+			}
+			return nil
+		},
+		execErr: func(qry string) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if m := addProfile.FindStringSubmatch(qry); m != nil {
+				// Adding a profile is only done after checking the user doesn't have it already.
+				if slices.Contains(profiles, m[1]) {
+					return fmt.Errorf("profile %q is already associated to the user", m[1])
+				}
+				profiles = append(profiles, m[1])
+			}
+			return nil
+		},
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	// Separate runs associating the same profile to the user.
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for idx := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			profile := "readonly"
+			_, errs[idx] = client.UpdateUserSettingsProfile(context.Background(), "john", nil, &profile, nil)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("UpdateUserSettingsProfile() error = %v", err)
+		}
+	}
+
+	if !slices.Equal(profiles, []string{"readonly"}) {
+		t.Errorf("UpdateUserSettingsProfile() attached profiles = %v, want [readonly]", profiles)
+	}
+}
+
+func Test_reconcileDefaultRoles_concurrent(t *testing.T) {
+	setDefaultRoles := regexp.MustCompile("DEFAULT ROLE (.+);")
+
+	var mu sync.Mutex
+	defaultRoles := make([]string, 0)
+
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			row := clickhouseclient.Row{}
+			switch {
+			case strings.Contains(qry, "`default_roles_list`"):
+				mu.Lock()
+				row.Set("default_roles_list", slices.Clone(defaultRoles))
+				mu.Unlock()
+				// Give the other goroutine a chance to read the same default roles.
+				time.Sleep(10 * time.Millisecond)
+				return []clickhouseclient.Row{row}
+			case strings.Contains(qry, "`system`.`role_grants`"):
+				ret := make([]clickhouseclient.Row, 0)
+				for _, role := range []string{"reader", "writer"} {
+					row := clickhouseclient.Row{}
+					row.Set("granted_role_name", role)
+					ret = append(ret, row)
+				}
+				return ret
+			}
+			return nil
+		},
+		execErr: func(qry string) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if m := setDefaultRoles.FindStringSubmatch(qry); m != nil {
+				defaultRoles = strings.Split(strings.ReplaceAll(m[1], "`", ""), ", ")
+			}
+			return nil
+		},
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	i := client.(*impl)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for idx, role := range []string{"reader", "writer"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[idx] = i.reconcileDefaultRoles(context.Background(), "john", []string{role}, nil, nil)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("reconcileDefaultRoles() error = %v", err)
+		}
+	}
+
+	slices.Sort(defaultRoles)
+	if !slices.Equal(defaultRoles, []string{"reader", "writer"}) {
+		t.Errorf("reconcileDefaultRoles() default roles = %v, want both", defaultRoles)
+	}
+}
@@ -0,0 +1,499 @@
+package dbops
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// namePrefixClient adds a prefix to the names of the users, roles, settings profiles and row policies passed to the
+// wrapped client, and removes it from the names it returns, so that configurations can use short names while the
+// server uses prefixed ones. References to those entities, e.g. grantees or default roles, are prefixed as well.
+// Databases, tables and the reserved settings profiles are never prefixed.
+type namePrefixClient struct {
+	Client
+
+	prefix string
+	// reservedSettingsProfiles are kept as is, since they are built into the server.
+	reservedSettingsProfiles []string
+}
+
+func (c *namePrefixClient) add(name string) string {
+	return c.prefix + name
+}
+
+func (c *namePrefixClient) addPtr(name *string) *string {
+	if name == nil {
+		return nil
+	}
+	prefixed := c.add(*name)
+	return &prefixed
+}
+
+func (c *namePrefixClient) addAll(names []string) []string {
+	if names == nil {
+		return nil
+	}
+	ret := make([]string, 0, len(names))
+	for _, n := range names {
+		ret = append(ret, c.add(n))
+	}
+	return ret
+}
+
+// addRef prefixes a reference to a user, that is either its name or its UUID.
+func (c *namePrefixClient) addRef(ref string) string {
+	if _, err := uuid.Parse(ref); err == nil {
+		return ref
+	}
+	return c.add(ref)
+}
+
+func (c *namePrefixClient) addRefPtr(ref *string) *string {
+	if ref == nil {
+		return nil
+	}
+	prefixed := c.addRef(*ref)
+	return &prefixed
+}
+
+func (c *namePrefixClient) strip(name string) string {
+	return strings.TrimPrefix(name, c.prefix)
+}
+
+func (c *namePrefixClient) stripPtr(name *string) *string {
+	if name == nil {
+		return nil
+	}
+	stripped := c.strip(*name)
+	return &stripped
+}
+
+func (c *namePrefixClient) stripAll(names []string) []string {
+	if names == nil {
+		return nil
+	}
+	ret := make([]string, 0, len(names))
+	for _, n := range names {
+		ret = append(ret, c.strip(n))
+	}
+	return ret
+}
+
+func (c *namePrefixClient) addProfile(name string) string {
+	if name == "" || slices.Contains(c.reservedSettingsProfiles, name) {
+		return name
+	}
+	return c.add(name)
+}
+
+func (c *namePrefixClient) addProfilePtr(name *string) *string {
+	if name == nil {
+		return nil
+	}
+	prefixed := c.addProfile(*name)
+	return &prefixed
+}
+
+func (c *namePrefixClient) addProfiles(names []string) []string {
+	if names == nil {
+		return nil
+	}
+	ret := make([]string, 0, len(names))
+	for _, n := range names {
+		ret = append(ret, c.addProfile(n))
+	}
+	return ret
+}
+
+func (c *namePrefixClient) stripProfile(name string) string {
+	if slices.Contains(c.reservedSettingsProfiles, name) {
+		return name
+	}
+	return c.strip(name)
+}
+
+func (c *namePrefixClient) stripProfiles(names []string) []string {
+	if names == nil {
+		return nil
+	}
+	ret := make([]string, 0, len(names))
+	for _, n := range names {
+		ret = append(ret, c.stripProfile(n))
+	}
+	return ret
+}
+
+func (c *namePrefixClient) addEntity(entity AccessEntity, name string) string {
+	if entity == AccessEntitySettingsProfile {
+		return c.addProfile(name)
+	}
+	return c.add(name)
+}
+
+func (c *namePrefixClient) addRole(role Role) Role {
+	role.Name = c.add(role.Name)
+	role.SettingsProfiles = c.addProfiles(role.SettingsProfiles)
+	return role
+}
+
+func (c *namePrefixClient) stripRole(role *Role) *Role {
+	if role == nil {
+		return nil
+	}
+	role.Name = c.strip(role.Name)
+	role.SettingsProfiles = c.stripProfiles(role.SettingsProfiles)
+	return role
+}
+
+func (c *namePrefixClient) addGrantees(grantees *UserGrantees) *UserGrantees {
+	if grantees == nil {
+		return nil
+	}
+	return &UserGrantees{Any: grantees.Any, Names: c.addAll(grantees.Names), Except: c.addAll(grantees.Except)}
+}
+
+func (c *namePrefixClient) stripGrantees(grantees *UserGrantees) *UserGrantees {
+	if grantees == nil {
+		return nil
+	}
+	return &UserGrantees{Any: grantees.Any, Names: c.stripAll(grantees.Names), Except: c.stripAll(grantees.Except)}
+}
+
+func (c *namePrefixClient) addUser(user User) User {
+	user.ID = c.addRef(user.ID)
+	user.Name = c.add(user.Name)
+	if user.DefaultRole != "" {
+		user.DefaultRole = c.add(user.DefaultRole)
+	}
+	user.DefaultRoles = c.addAll(user.DefaultRoles)
+	user.SettingsProfile = c.addProfile(user.SettingsProfile)
+	user.SettingsProfiles = c.addProfiles(user.SettingsProfiles)
+	user.Grantees = c.addGrantees(user.Grantees)
+	return user
+}
+
+func (c *namePrefixClient) stripUser(user *User) *User {
+	if user == nil {
+		return nil
+	}
+	user.Name = c.strip(user.Name)
+	if user.DefaultRole != "" {
+		user.DefaultRole = c.strip(user.DefaultRole)
+	}
+	user.DefaultRoles = c.stripAll(user.DefaultRoles)
+	user.SettingsProfiles = c.stripProfiles(user.SettingsProfiles)
+	user.Grantees = c.stripGrantees(user.Grantees)
+	return user
+}
+
+func (c *namePrefixClient) addGrantRole(grantRole GrantRole) GrantRole {
+	grantRole.RoleName = c.add(grantRole.RoleName)
+	grantRole.GranteeUserName = c.addPtr(grantRole.GranteeUserName)
+	grantRole.GranteeRoleName = c.addPtr(grantRole.GranteeRoleName)
+	return grantRole
+}
+
+func (c *namePrefixClient) stripGrantRole(grantRole *GrantRole) *GrantRole {
+	if grantRole == nil {
+		return nil
+	}
+	grantRole.RoleName = c.strip(grantRole.RoleName)
+	grantRole.GranteeUserName = c.stripPtr(grantRole.GranteeUserName)
+	grantRole.GranteeRoleName = c.stripPtr(grantRole.GranteeRoleName)
+	return grantRole
+}
+
+func (c *namePrefixClient) stripGrantPrivilege(grantPrivilege *GrantPrivilege) *GrantPrivilege {
+	if grantPrivilege == nil {
+		return nil
+	}
+	grantPrivilege.GranteeUserName = c.stripPtr(grantPrivilege.GranteeUserName)
+	grantPrivilege.GranteeRoleName = c.stripPtr(grantPrivilege.GranteeRoleName)
+	return grantPrivilege
+}
+
+func (c *namePrefixClient) stripGrantPrivileges(grantPrivileges []GrantPrivilege) []GrantPrivilege {
+	for idx := range grantPrivileges {
+		c.stripGrantPrivilege(&grantPrivileges[idx])
+	}
+	return grantPrivileges
+}
+
+func (c *namePrefixClient) addRowPolicy(rowPolicy RowPolicy) RowPolicy {
+	rowPolicy.Name = c.add(rowPolicy.Name)
+	rowPolicy.ApplyTo = c.addAll(rowPolicy.ApplyTo)
+	return rowPolicy
+}
+
+func (c *namePrefixClient) stripRowPolicy(rowPolicy *RowPolicy) *RowPolicy {
+	if rowPolicy == nil {
+		return nil
+	}
+	rowPolicy.Name = c.strip(rowPolicy.Name)
+	rowPolicy.ApplyTo = c.stripAll(rowPolicy.ApplyTo)
+	return rowPolicy
+}
+
+func (c *namePrefixClient) addSettingsProfile(profile SettingsProfile) SettingsProfile {
+	profile.Name = c.addProfile(profile.Name)
+	profile.InheritFrom = c.addProfiles(profile.InheritFrom)
+	profile.ApplyTo = c.addAll(profile.ApplyTo)
+	profile.ApplyToExcept = c.addAll(profile.ApplyToExcept)
+	return profile
+}
+
+func (c *namePrefixClient) stripSettingsProfile(profile *SettingsProfile) *SettingsProfile {
+	if profile == nil {
+		return nil
+	}
+	profile.Name = c.stripProfile(profile.Name)
+	profile.InheritFrom = c.stripProfiles(profile.InheritFrom)
+	profile.ApplyTo = c.stripAll(profile.ApplyTo)
+	profile.ApplyToExcept = c.stripAll(profile.ApplyToExcept)
+	return profile
+}
+
+func (c *namePrefixClient) CreateRole(ctx context.Context, role Role, clusterName *string) (*Role, error) {
+	created, err := c.Client.CreateRole(ctx, c.addRole(role), clusterName)
+	return c.stripRole(created), err
+}
+
+func (c *namePrefixClient) GetRole(ctx context.Context, id string, clusterName *string) (*Role, error) {
+	role, err := c.Client.GetRole(ctx, id, clusterName)
+	return c.stripRole(role), err
+}
+
+func (c *namePrefixClient) FindRoleByName(ctx context.Context, name string, clusterName *string) (*Role, error) {
+	role, err := c.Client.FindRoleByName(ctx, c.add(name), clusterName)
+	return c.stripRole(role), err
+}
+
+func (c *namePrefixClient) UpdateRole(ctx context.Context, role Role, clusterName *string) (*Role, error) {
+	updated, err := c.Client.UpdateRole(ctx, c.addRole(role), clusterName)
+	return c.stripRole(updated), err
+}
+
+// ListRoles only returns the roles carrying the prefix.
+func (c *namePrefixClient) ListRoles(ctx context.Context, namePrefix string, clusterName *string) ([]Role, error) {
+	roles, err := c.Client.ListRoles(ctx, c.add(namePrefix), clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]Role, 0, len(roles))
+	for _, role := range roles {
+		ret = append(ret, *c.stripRole(&role))
+	}
+
+	return ret, nil
+}
+
+func (c *namePrefixClient) CreateUser(ctx context.Context, user User, clusterName *string) (*User, error) {
+	created, err := c.Client.CreateUser(ctx, c.addUser(user), clusterName)
+	return c.stripUser(created), err
+}
+
+func (c *namePrefixClient) GetUserByName(ctx context.Context, name string, clusterName *string) (*User, error) {
+	user, err := c.Client.GetUserByName(ctx, c.add(name), clusterName)
+	return c.stripUser(user), err
+}
+
+func (c *namePrefixClient) GetUserByUUID(ctx context.Context, id string, clusterName *string) (*User, error) {
+	user, err := c.Client.GetUserByUUID(ctx, id, clusterName)
+	return c.stripUser(user), err
+}
+
+func (c *namePrefixClient) UserExists(ctx context.Context, name string, clusterName *string) (bool, error) {
+	return c.Client.UserExists(ctx, c.add(name), clusterName)
+}
+
+func (c *namePrefixClient) DeleteUser(ctx context.Context, id string, clusterName *string) error {
+	return c.Client.DeleteUser(ctx, c.addRef(id), clusterName)
+}
+
+func (c *namePrefixClient) FindUserByName(ctx context.Context, name string, clusterName *string) (*User, error) {
+	user, err := c.Client.FindUserByName(ctx, c.add(name), clusterName)
+	return c.stripUser(user), err
+}
+
+func (c *namePrefixClient) UpdateUser(ctx context.Context, user User, clusterName *string) (*User, error) {
+	updated, err := c.Client.UpdateUser(ctx, c.addUser(user), clusterName)
+	return c.stripUser(updated), err
+}
+
+func (c *namePrefixClient) UpdateUserSettingsProfile(ctx context.Context, name string, oldProfile *string, newProfile *string, clusterName *string) (*User, error) {
+	updated, err := c.Client.UpdateUserSettingsProfile(ctx, c.add(name), c.addProfilePtr(oldProfile), c.addProfilePtr(newProfile), clusterName)
+	return c.stripUser(updated), err
+}
+
+func (c *namePrefixClient) GetUserGrantees(ctx context.Context, userID string, clusterName *string) (*UserGrantees, error) {
+	grantees, err := c.Client.GetUserGrantees(ctx, c.addRef(userID), clusterName)
+	return c.stripGrantees(grantees), err
+}
+
+func (c *namePrefixClient) SetUserGrantees(ctx context.Context, userID string, grantees UserGrantees, clusterName *string) error {
+	return c.Client.SetUserGrantees(ctx, c.addRef(userID), *c.addGrantees(&grantees), clusterName)
+}
+
+func (c *namePrefixClient) GrantRole(ctx context.Context, grantRole GrantRole, clusterName *string) (*GrantRole, error) {
+	granted, err := c.Client.GrantRole(ctx, c.addGrantRole(grantRole), clusterName)
+	return c.stripGrantRole(granted), err
+}
+
+func (c *namePrefixClient) ListGrantedRoles(ctx context.Context, userName string, clusterName *string) ([]string, error) {
+	roles, err := c.Client.ListGrantedRoles(ctx, c.add(userName), clusterName)
+	return c.stripAll(roles), err
+}
+
+func (c *namePrefixClient) GetGrantRole(ctx context.Context, grantedRoleName string, granteeUserName *string, granteeRoleName *string, clusterName *string) (*GrantRole, error) {
+	grantRole, err := c.Client.GetGrantRole(ctx, c.add(grantedRoleName), c.addPtr(granteeUserName), c.addPtr(granteeRoleName), clusterName)
+	return c.stripGrantRole(grantRole), err
+}
+
+func (c *namePrefixClient) UpdateGrantRole(ctx context.Context, grantRole GrantRole, clusterName *string) (*GrantRole, error) {
+	updated, err := c.Client.UpdateGrantRole(ctx, c.addGrantRole(grantRole), clusterName)
+	return c.stripGrantRole(updated), err
+}
+
+func (c *namePrefixClient) RevokeGrantRole(ctx context.Context, grantedRoleName string, granteeUserName *string, granteeRoleName *string, clusterName *string) error {
+	return c.Client.RevokeGrantRole(ctx, c.add(grantedRoleName), c.addPtr(granteeUserName), c.addPtr(granteeRoleName), clusterName)
+}
+
+func (c *namePrefixClient) GrantRoleWithSettingsProfile(ctx context.Context, grantRole GrantRole, settingsProfile string, clusterName *string) (*GrantRole, error) {
+	granted, err := c.Client.GrantRoleWithSettingsProfile(ctx, c.addGrantRole(grantRole), c.addProfile(settingsProfile), clusterName)
+	return c.stripGrantRole(granted), err
+}
+
+func (c *namePrefixClient) RevokeGrantRoleWithSettingsProfile(ctx context.Context, grantedRoleName string, granteeUserName string, settingsProfile string, clusterName *string) error {
+	return c.Client.RevokeGrantRoleWithSettingsProfile(ctx, c.add(grantedRoleName), c.add(granteeUserName), c.addProfile(settingsProfile), clusterName)
+}
+
+func (c *namePrefixClient) GrantPrivilege(ctx context.Context, grantPrivilege GrantPrivilege, clusterName *string) (*GrantPrivilege, error) {
+	grantPrivilege.GranteeUserName = c.addPtr(grantPrivilege.GranteeUserName)
+	grantPrivilege.GranteeRoleName = c.addPtr(grantPrivilege.GranteeRoleName)
+
+	granted, err := c.Client.GrantPrivilege(ctx, grantPrivilege, clusterName)
+	return c.stripGrantPrivilege(granted), err
+}
+
+func (c *namePrefixClient) GetGrantPrivilege(ctx context.Context, accessType string, database *string, table *string, column *string, granteeUserName *string, granteeRoleName *string, clusterName *string) (*GrantPrivilege, error) {
+	grantPrivilege, err := c.Client.GetGrantPrivilege(ctx, accessType, database, table, column, c.addPtr(granteeUserName), c.addPtr(granteeRoleName), clusterName)
+	return c.stripGrantPrivilege(grantPrivilege), err
+}
+
+func (c *namePrefixClient) RevokeGrantPrivilege(ctx context.Context, accessType string, database *string, table *string, column *string, granteeUserName *string, granteeRoleName *string, clusterName *string) error {
+	return c.Client.RevokeGrantPrivilege(ctx, accessType, database, table, column, c.addPtr(granteeUserName), c.addPtr(granteeRoleName), clusterName)
+}
+
+func (c *namePrefixClient) GetAllGrantsForGrantee(ctx context.Context, granteeUsername *string, granteeRoleName *string, clusterName *string) ([]GrantPrivilege, error) {
+	grants, err := c.Client.GetAllGrantsForGrantee(ctx, c.addPtr(granteeUsername), c.addPtr(granteeRoleName), clusterName)
+	return c.stripGrantPrivileges(grants), err
+}
+
+func (c *namePrefixClient) ListUserGrants(ctx context.Context, userName string, clusterName *string) (*UserGrants, error) {
+	grants, err := c.Client.ListUserGrants(ctx, c.add(userName), clusterName)
+	if grants != nil {
+		grants.Privileges = c.stripGrantPrivileges(grants.Privileges)
+		grants.Roles = c.stripAll(grants.Roles)
+	}
+	return grants, err
+}
+
+func (c *namePrefixClient) GetEffectiveGrants(ctx context.Context, userName string, clusterName *string) (*EffectiveGrants, error) {
+	effective, err := c.Client.GetEffectiveGrants(ctx, c.add(userName), clusterName)
+	if effective != nil {
+		effective.Roles = c.stripAll(effective.Roles)
+		effective.Grants = c.stripGrantPrivileges(effective.Grants)
+	}
+	return effective, err
+}
+
+func (c *namePrefixClient) CreateRowPolicy(ctx context.Context, rowPolicy RowPolicy, clusterName *string) (*RowPolicy, error) {
+	created, err := c.Client.CreateRowPolicy(ctx, c.addRowPolicy(rowPolicy), clusterName)
+	return c.stripRowPolicy(created), err
+}
+
+func (c *namePrefixClient) GetRowPolicy(ctx context.Context, id string, clusterName *string) (*RowPolicy, error) {
+	rowPolicy, err := c.Client.GetRowPolicy(ctx, id, clusterName)
+	return c.stripRowPolicy(rowPolicy), err
+}
+
+func (c *namePrefixClient) FindRowPolicyByName(ctx context.Context, name string, databaseName string, tableName string, clusterName *string) (*RowPolicy, error) {
+	rowPolicy, err := c.Client.FindRowPolicyByName(ctx, c.add(name), databaseName, tableName, clusterName)
+	return c.stripRowPolicy(rowPolicy), err
+}
+
+func (c *namePrefixClient) UpdateRowPolicy(ctx context.Context, rowPolicy RowPolicy, clusterName *string) (*RowPolicy, error) {
+	updated, err := c.Client.UpdateRowPolicy(ctx, c.addRowPolicy(rowPolicy), clusterName)
+	return c.stripRowPolicy(updated), err
+}
+
+func (c *namePrefixClient) CreateSettingsProfile(ctx context.Context, profile SettingsProfile, clusterName *string) (*SettingsProfile, error) {
+	created, err := c.Client.CreateSettingsProfile(ctx, c.addSettingsProfile(profile), clusterName)
+	return c.stripSettingsProfile(created), err
+}
+
+func (c *namePrefixClient) GetSettingsProfile(ctx context.Context, id string, clusterName *string) (*SettingsProfile, error) {
+	profile, err := c.Client.GetSettingsProfile(ctx, id, clusterName)
+	return c.stripSettingsProfile(profile), err
+}
+
+func (c *namePrefixClient) UpdateSettingsProfile(ctx context.Context, settingsProfile SettingsProfile, clusterName *string) (*SettingsProfile, error) {
+	updated, err := c.Client.UpdateSettingsProfile(ctx, c.addSettingsProfile(settingsProfile), clusterName)
+	return c.stripSettingsProfile(updated), err
+}
+
+func (c *namePrefixClient) FindSettingsProfileByName(ctx context.Context, name string, clusterName *string) (*SettingsProfile, error) {
+	profile, err := c.Client.FindSettingsProfileByName(ctx, c.addProfile(name), clusterName)
+	return c.stripSettingsProfile(profile), err
+}
+
+func (c *namePrefixClient) GetSettingsProfileByName(ctx context.Context, name string, clusterName *string) (*SettingsProfile, error) {
+	profile, err := c.Client.GetSettingsProfileByName(ctx, c.addProfile(name), clusterName)
+	return c.stripSettingsProfile(profile), err
+}
+
+func (c *namePrefixClient) ListSettingsProfileAssociations(ctx context.Context, profileName string, clusterName *string) (*SettingsProfileAssociations, error) {
+	associations, err := c.Client.ListSettingsProfileAssociations(ctx, c.addProfile(profileName), clusterName)
+	if associations != nil {
+		associations.Users = c.stripAll(associations.Users)
+		associations.Roles = c.stripAll(associations.Roles)
+	}
+	return associations, err
+}
+
+func (c *namePrefixClient) AssociateSettingsProfile(ctx context.Context, id string, roleId *string, userId *string, clusterName *string) error {
+	return c.Client.AssociateSettingsProfile(ctx, id, roleId, c.addRefPtr(userId), clusterName)
+}
+
+func (c *namePrefixClient) DisassociateSettingsProfile(ctx context.Context, id string, roleId *string, userId *string, clusterName *string) error {
+	return c.Client.DisassociateSettingsProfile(ctx, id, roleId, c.addRefPtr(userId), clusterName)
+}
+
+func (c *namePrefixClient) AssociateSettingsProfiles(ctx context.Context, ids []string, roleId *string, userId *string, clusterName *string) error {
+	return c.Client.AssociateSettingsProfiles(ctx, ids, roleId, c.addRefPtr(userId), clusterName)
+}
+
+func (c *namePrefixClient) DisassociateSettingsProfiles(ctx context.Context, ids []string, roleId *string, userId *string, clusterName *string) error {
+	return c.Client.DisassociateSettingsProfiles(ctx, ids, roleId, c.addRefPtr(userId), clusterName)
+}
+
+func (c *namePrefixClient) AssociateSettingsProfileByName(ctx context.Context, profileName string, roleID *string, userID *string, clusterName *string) error {
+	return c.Client.AssociateSettingsProfileByName(ctx, c.addProfile(profileName), roleID, c.addRefPtr(userID), clusterName)
+}
+
+func (c *namePrefixClient) GetProfileForAll(ctx context.Context, clusterName *string) (*SettingsProfile, error) {
+	profile, err := c.Client.GetProfileForAll(ctx, clusterName)
+	return c.stripSettingsProfile(profile), err
+}
+
+func (c *namePrefixClient) ReadOnlyAccessStorage(ctx context.Context, entity AccessEntity, name string, clusterName *string) (string, error) {
+	return c.Client.ReadOnlyAccessStorage(ctx, entity, c.addEntity(entity, name), clusterName)
+}
+
+func (c *namePrefixClient) AccessEntityID(ctx context.Context, entity AccessEntity, name string, clusterName *string) (string, error) {
+	return c.Client.AccessEntityID(ctx, entity, c.addEntity(entity, name), clusterName)
+}
@@ -0,0 +1,167 @@
+package dbops
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+)
+
+func Test_namePrefix_user(t *testing.T) {
+	var selects []string
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			selects = append(selects, qry)
+			if !strings.Contains(qry, "`auth_type`") {
+				return nil
+			}
+			id := "00000000-0000-0000-0000-000000000000"
+			row := clickhouseclient.Row{}
+			row.Set("name", "tenant_john")
+			row.Set("id", &id)
+			row.Set("auth_type", "no_password")
+			return []clickhouseclient.Row{row}
+		},
+	}
+
+	client, err := NewClient(fake, WithNamePrefix("tenant_"))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	created, err := client.CreateUser(context.Background(), User{Name: "john", NoPassword: true, DefaultRole: "reader", SettingsProfile: "default"}, nil)
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+
+	// The reserved 'default' settings profile is not prefixed.
+	want := []string{"CREATE USER IF NOT EXISTS `tenant_john` IDENTIFIED WITH no_password SETTINGS PROFILE 'default' DEFAULT ROLE 'tenant_reader';"}
+	if !reflect.DeepEqual(fake.execs, want) {
+		t.Errorf("CreateUser() queries = %q, want %q", fake.execs, want)
+	}
+	if created == nil || created.Name != "john" {
+		t.Fatalf("CreateUser() = %+v, want user named %q", created, "john")
+	}
+
+	// Importing uses the short name as well.
+	selects = nil
+	imported, err := client.GetUserByName(context.Background(), "john", nil)
+	if err != nil {
+		t.Fatalf("GetUserByName() error = %v", err)
+	}
+	if imported == nil || imported.Name != "john" {
+		t.Fatalf("GetUserByName() = %+v, want user named %q", imported, "john")
+	}
+	if len(selects) == 0 || !strings.Contains(selects[0], "'tenant_john'") {
+		t.Errorf("GetUserByName() queries = %q, want a lookup of 'tenant_john'", selects)
+	}
+}
+
+func Test_namePrefix_role(t *testing.T) {
+	var selects []string
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			selects = append(selects, qry)
+			if !strings.Contains(qry, "`system`.`roles`") {
+				return nil
+			}
+			row := clickhouseclient.Row{}
+			row.Set("id", "00000000-0000-0000-0000-000000000001")
+			row.Set("name", "tenant_reader")
+			return []clickhouseclient.Row{row}
+		},
+	}
+
+	client, err := NewClient(fake, WithNamePrefix("tenant_"))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	created, err := client.CreateRole(context.Background(), Role{Name: "reader"}, nil)
+	if err != nil {
+		t.Fatalf("CreateRole() error = %v", err)
+	}
+
+	want := []string{"CREATE ROLE `tenant_reader`;"}
+	if !reflect.DeepEqual(fake.execs, want) {
+		t.Errorf("CreateRole() queries = %q, want %q", fake.execs, want)
+	}
+	if created == nil || created.Name != "reader" {
+		t.Fatalf("CreateRole() = %+v, want role named %q", created, "reader")
+	}
+
+	// Roles are read back by UUID, with the prefix removed.
+	read, err := client.GetRole(context.Background(), created.ID, nil)
+	if err != nil {
+		t.Fatalf("GetRole() error = %v", err)
+	}
+	if read == nil || read.Name != "reader" {
+		t.Fatalf("GetRole() = %+v, want role named %q", read, "reader")
+	}
+
+	selects = nil
+	found, err := client.FindRoleByName(context.Background(), "reader", nil)
+	if err != nil {
+		t.Fatalf("FindRoleByName() error = %v", err)
+	}
+	if found == nil || found.Name != "reader" {
+		t.Fatalf("FindRoleByName() = %+v, want role named %q", found, "reader")
+	}
+	if len(selects) == 0 || !strings.Contains(selects[0], "'tenant_reader'") {
+		t.Errorf("FindRoleByName() queries = %q, want a lookup of 'tenant_reader'", selects)
+	}
+}
+
+func Test_namePrefix_ListRoles(t *testing.T) {
+	var query string
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			query = qry
+			row := clickhouseclient.Row{}
+			row.Set("id", "00000000-0000-0000-0000-000000000001")
+			row.Set("name", "tenant_reader")
+			return []clickhouseclient.Row{row}
+		},
+	}
+
+	client, err := NewClient(fake, WithNamePrefix("tenant_"))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	roles, err := client.ListRoles(context.Background(), "", nil)
+	if err != nil {
+		t.Fatalf("ListRoles() error = %v", err)
+	}
+	if len(roles) != 1 || roles[0].Name != "reader" {
+		t.Errorf("ListRoles() = %+v, want only the role named %q", roles, "reader")
+	}
+	if !strings.Contains(query, "WHERE (`name` LIKE 'tenant\\\\_%')") {
+		t.Errorf("ListRoles() query = %q, want the roles filtered by the prefix on the server", query)
+	}
+}
+
+func Test_namePrefix_grantRole(t *testing.T) {
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			return nil
+		},
+	}
+
+	client, err := NewClient(fake, WithNamePrefix("tenant_"))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	user := "john"
+	err = client.RevokeGrantRole(context.Background(), "reader", &user, nil, nil)
+	if err != nil {
+		t.Fatalf("RevokeGrantRole() error = %v", err)
+	}
+
+	if len(fake.execs) != 1 || !strings.Contains(fake.execs[0], "`tenant_reader`") || !strings.Contains(fake.execs[0], "`tenant_john`") {
+		t.Errorf("RevokeGrantRole() queries = %q, want the prefixed role and user", fake.execs)
+	}
+}
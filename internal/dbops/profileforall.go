@@ -0,0 +1,122 @@
+package dbops
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pingcap/errors"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/querybuilder"
+)
+
+// GetProfileForAll returns the settings profile applied to all users and roles, or nil if there is none.
+func (i *impl) GetProfileForAll(ctx context.Context, clusterName *string) (*SettingsProfile, error) {
+	clusterName = i.withDefaultCluster(clusterName)
+	sql, err := i.
+		newSelect([]querybuilder.Field{querybuilder.NewField("id").ToString()}, "system.settings_profiles").
+		WithCluster(clusterName).
+		Where(querybuilder.WhereEquals("apply_to_all", 1)).
+		Build()
+	if err != nil {
+		return nil, errors.WithMessage(err, "error building query")
+	}
+
+	ids := make([]string, 0)
+	err = i.clickhouseClient.Select(ctx, sql, func(data clickhouseclient.Row) error {
+		id, err := data.GetString("id")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'id' field")
+		}
+
+		ids = append(ids, id)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "error running query")
+	}
+
+	switch len(ids) {
+	case 0:
+		return nil, nil
+	case 1:
+		return i.GetSettingsProfile(ctx, ids[0], clusterName)
+	default:
+		return nil, errors.New(fmt.Sprintf("found %d settings profiles applied to all users and roles, expected at most one", len(ids)))
+	}
+}
+
+// SetProfileForAll applies the settings profile with the given ID to all users and roles.
+// It fails if another settings profile is already applied to all of them.
+func (i *impl) SetProfileForAll(ctx context.Context, id string, clusterName *string) error {
+	clusterName = i.withDefaultCluster(clusterName)
+	profile, err := i.GetSettingsProfile(ctx, id, clusterName)
+	if err != nil {
+		return errors.WithMessage(err, "error getting settings profile")
+	}
+
+	if profile == nil {
+		return errors.New(fmt.Sprintf("settings profile with id %q was not found", id))
+	}
+
+	existing, err := i.GetProfileForAll(ctx, clusterName)
+	if err != nil {
+		return errors.WithMessage(err, "error getting settings profile applied to all")
+	}
+
+	if existing != nil {
+		if existing.ID == id {
+			// Already applied.
+			return nil
+		}
+
+		return errors.New(fmt.Sprintf("settings profile %q is already applied to all users and roles", existing.Name))
+	}
+
+	sql, err := querybuilder.
+		NewAlterSettingsProfile(profile.Name).
+		WithCluster(clusterName).
+		To(querybuilder.ApplyTo{All: true}).
+		Build()
+	if err != nil {
+		return errors.WithMessage(err, "error building query")
+	}
+
+	err = i.execWithContext(ctx, sql, clusterName)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ClearProfileForAll stops applying the settings profile with the given ID to all users and roles.
+// Nothing is done if the profile is not the one applied to all of them.
+func (i *impl) ClearProfileForAll(ctx context.Context, id string, clusterName *string) error {
+	clusterName = i.withDefaultCluster(clusterName)
+	existing, err := i.GetProfileForAll(ctx, clusterName)
+	if err != nil {
+		return errors.WithMessage(err, "error getting settings profile applied to all")
+	}
+
+	if existing == nil || existing.ID != id {
+		// That's what we want.
+		return nil
+	}
+
+	sql, err := querybuilder.
+		NewAlterSettingsProfile(existing.Name).
+		WithCluster(clusterName).
+		To(querybuilder.ApplyTo{}).
+		Build()
+	if err != nil {
+		return errors.WithMessage(err, "error building query")
+	}
+
+	err = i.execWithContext(ctx, sql, clusterName)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package dbops
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+)
+
+// fakeProfileForAll returns a fake client with profiles whose ID is their name, where the profile with the
+// given name, if any, is applied to all.
+func fakeProfileForAll(profileForAll string) *fakeClickhouseClient {
+	return &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			if !strings.Contains(qry, "`system`.`settings_profiles`") {
+				return nil
+			}
+
+			row := clickhouseclient.Row{}
+			if strings.Contains(qry, "`apply_to_all` = 1") {
+				if profileForAll == "" {
+					return nil
+				}
+				row.Set("id", profileForAll)
+				return []clickhouseclient.Row{row}
+			}
+
+			for _, name := range []string{"baseline", "other"} {
+				if strings.Contains(qry, "`id` = '"+name+"'") {
+					row.Set("name", name)
+					row.Set("apply_to_all", boolToUInt8(name == profileForAll))
+					row.Set("apply_to_list", []string{})
+					row.Set("apply_to_except", []string{})
+					return []clickhouseclient.Row{row}
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func Test_SetProfileForAll(t *testing.T) {
+	tests := []struct {
+		name          string
+		profileForAll string
+		want          string
+		wantErr       bool
+	}{
+		{
+			name:          "No profile applied to all",
+			profileForAll: "",
+			want:          "ALTER SETTINGS PROFILE `baseline` TO ALL;",
+		},
+		{
+			name:          "Profile already applied to all",
+			profileForAll: "baseline",
+			want:          "",
+		},
+		{
+			name:          "Another profile applied to all",
+			profileForAll: "other",
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := fakeProfileForAll(tt.profileForAll)
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			err = client.SetProfileForAll(context.Background(), "baseline", nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetProfileForAll() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got := ""
+			if len(fake.execs) > 0 {
+				got = fake.execs[0]
+			}
+			if got != tt.want {
+				t.Errorf("SetProfileForAll() query = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ClearProfileForAll(t *testing.T) {
+	tests := []struct {
+		name          string
+		profileForAll string
+		want          string
+	}{
+		{
+			name:          "Profile applied to all",
+			profileForAll: "baseline",
+			want:          "ALTER SETTINGS PROFILE `baseline` TO NONE;",
+		},
+		{
+			name:          "No profile applied to all",
+			profileForAll: "",
+			want:          "",
+		},
+		{
+			name:          "Another profile applied to all",
+			profileForAll: "other",
+			want:          "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := fakeProfileForAll(tt.profileForAll)
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			err = client.ClearProfileForAll(context.Background(), "baseline", nil)
+			if err != nil {
+				t.Fatalf("ClearProfileForAll() error = %v", err)
+			}
+
+			got := ""
+			if len(fake.execs) > 0 {
+				got = fake.execs[0]
+			}
+			if got != tt.want {
+				t.Errorf("ClearProfileForAll() query = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -10,9 +10,44 @@ import (
 )
 
 // IsReplicatedStorage queries system tables and checks if the highest priority storage system for users and roles is 'replicated'.
-func (i *impl) IsReplicatedStorage(ctx context.Context) (bool, error) {
-	sql, err := querybuilder.
-		NewSelect([]querybuilder.Field{querybuilder.NewField("type"), querybuilder.NewField("precedence")}, "system.user_directories").
+// When clusterName is set, the replicas of that cluster are checked instead of the server the client is connected to.
+// The result is cached separately for each cluster, since it can't change without restarting the servers.
+func (i *impl) IsReplicatedStorage(ctx context.Context, clusterName *string) (bool, error) {
+	clusterName = i.withDefaultCluster(clusterName)
+	if clusterName != nil && *clusterName == "" {
+		// Unknown cluster names are planned as empty strings.
+		clusterName = nil
+	}
+
+	key := ""
+	if clusterName != nil {
+		key = *clusterName
+	}
+
+	i.replicatedStorageMu.Lock()
+	defer i.replicatedStorageMu.Unlock()
+
+	if replicated, ok := i.replicatedStorage[key]; ok {
+		return replicated, nil
+	}
+
+	replicated, err := i.isReplicatedStorage(ctx, clusterName)
+	if err != nil {
+		return false, err
+	}
+
+	if i.replicatedStorage == nil {
+		i.replicatedStorage = make(map[string]bool)
+	}
+	i.replicatedStorage[key] = replicated
+
+	return replicated, nil
+}
+
+func (i *impl) isReplicatedStorage(ctx context.Context, clusterName *string) (bool, error) {
+	sql, err := i.
+		newSelect([]querybuilder.Field{querybuilder.NewField("type"), querybuilder.NewField("precedence")}, "system.user_directories").
+		WithCluster(clusterName).
 		Where(querybuilder.WhereDiffers("type", "users_xml")).
 		Build()
 	if err != nil {
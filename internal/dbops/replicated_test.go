@@ -0,0 +1,114 @@
+package dbops
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+)
+
+func Test_IsReplicatedStorage_cachedPerCluster(t *testing.T) {
+	queries := make(map[string]int)
+
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			queries[qry]++
+
+			row := clickhouseclient.Row{}
+			row.Set("precedence", uint64(1))
+			// Only cluster1 uses replicated storage.
+			if strings.Contains(qry, "cluster('cluster1'") {
+				row.Set("type", "replicated")
+			} else {
+				row.Set("type", "local_directory")
+			}
+			return []clickhouseclient.Row{row}
+		},
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	cluster1 := "cluster1"
+	cluster2 := "cluster2"
+	empty := ""
+
+	tests := []struct {
+		name        string
+		clusterName *string
+		want        bool
+	}{
+		{name: "Connected server", clusterName: nil, want: false},
+		{name: "Replicated cluster", clusterName: &cluster1, want: true},
+		{name: "Other cluster", clusterName: &cluster2, want: false},
+		{name: "Connected server again", clusterName: nil, want: false},
+		{name: "Replicated cluster again", clusterName: &cluster1, want: true},
+		{name: "Unknown cluster name", clusterName: &empty, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.IsReplicatedStorage(context.Background(), tt.clusterName)
+			if err != nil {
+				t.Fatalf("IsReplicatedStorage() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsReplicatedStorage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if len(queries) != 3 {
+		t.Errorf("IsReplicatedStorage() ran %d distinct queries, want 3: %v", len(queries), queries)
+	}
+	for qry, count := range queries {
+		if count != 1 {
+			t.Errorf("IsReplicatedStorage() ran %q %d times, want it cached after the first", qry, count)
+		}
+	}
+}
+
+func Test_IsReplicatedStorage_concurrentCalls(t *testing.T) {
+	var queries atomic.Int32
+
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			queries.Add(1)
+
+			row := clickhouseclient.Row{}
+			row.Set("precedence", uint64(1))
+			row.Set("type", "replicated")
+			return []clickhouseclient.Row{row}
+		},
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	// Terraform plans the resources concurrently, so the first calls race for the cache.
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := client.IsReplicatedStorage(context.Background(), nil)
+			if err != nil {
+				t.Errorf("IsReplicatedStorage() error = %v", err)
+			}
+			if !got {
+				t.Errorf("IsReplicatedStorage() = %v, want true", got)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := queries.Load(); got != 1 {
+		t.Errorf("IsReplicatedStorage() ran %d queries, want 1", got)
+	}
+}
@@ -0,0 +1,41 @@
+package dbops
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/pingcap/errors"
+)
+
+// readAfterCreate runs read and, if it finds nothing, retries according to the client's read after create settings.
+// Errors returned by read are not retried. An error is returned if the created object is still not visible after the
+// last retry.
+func readAfterCreate[T any](ctx context.Context, i *impl, read func() (*T, error)) (*T, error) {
+	for attempt := 0; attempt <= i.readAfterCreateRetries; attempt++ {
+		if attempt > 0 {
+			tflog.Debug(ctx, "created object not visible yet, retrying read", map[string]any{
+				"attempt":     attempt,
+				"max_retries": i.readAfterCreateRetries,
+			})
+
+			timer := time.NewTimer(i.readAfterCreateBackoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, errors.WithMessage(ctx.Err(), "error waiting for the created object to be visible")
+			case <-timer.C:
+			}
+		}
+
+		ret, err := read()
+		if err != nil {
+			return nil, err
+		}
+		if ret != nil {
+			return ret, nil
+		}
+	}
+
+	return nil, errors.Errorf("created object not visible after %d retries", i.readAfterCreateRetries)
+}
@@ -0,0 +1,69 @@
+package dbops
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func Test_readAfterCreate(t *testing.T) {
+	found := "found"
+	readErr := errors.New("code: 497, message: Not enough privileges")
+
+	tests := []struct {
+		name      string
+		results   []*string
+		err       error
+		want      *string
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name:      "Found at first attempt",
+			results:   []*string{&found},
+			want:      &found,
+			wantCalls: 1,
+		},
+		{
+			name:      "Found after a retry",
+			results:   []*string{nil, &found},
+			want:      &found,
+			wantCalls: 2,
+		},
+		{
+			name:      "Not found after all retries",
+			results:   []*string{nil, nil, nil, nil},
+			wantCalls: 3,
+			wantErr:   true,
+		},
+		{
+			name:      "Errors are not retried",
+			err:       readErr,
+			wantCalls: 1,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := &impl{readAfterCreateRetries: 2}
+
+			calls := 0
+			got, err := readAfterCreate(context.Background(), i, func() (*string, error) {
+				calls++
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return tt.results[calls-1], nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readAfterCreate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("readAfterCreate() = %v, want %v", got, tt.want)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("readAfterCreate() read %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package dbops
 
 import (
 	"context"
+	"slices"
 
 	"github.com/pingcap/errors"
 
@@ -13,6 +14,12 @@ type Role struct {
 	ID               string   `json:"id" ch:"id"`
 	Name             string   `json:"name" ch:"name"`
 	SettingsProfiles []string `json:"-"`
+	// Settings are the settings of the role, in the order they are applied.
+	// When nil, settings are not changed by UpdateRole.
+	Settings []Setting `json:"-"`
+	// Comment is the comment of the role. It is nil when the server doesn't support comments on roles.
+	// When nil, the comment is not changed by UpdateRole.
+	Comment *string `json:"-"`
 }
 
 func (r *Role) HasSettingProfile(profileName string) bool {
@@ -26,21 +33,47 @@ func (r *Role) HasSettingProfile(profileName string) bool {
 }
 
 func (i *impl) CreateRole(ctx context.Context, role Role, clusterName *string) (*Role, error) {
-	sql, err := querybuilder.NewCreateRole(role.Name).WithCluster(clusterName).Build()
+	clusterName = i.withDefaultCluster(clusterName)
+	settings, err := i.typedSettings(ctx, role.Settings)
+	if err != nil {
+		return nil, err
+	}
+
+	q := querybuilder.NewCreateRole(role.Name).WithCluster(clusterName)
+
+	for _, setting := range settings {
+		q = addSetting(q, setting)
+	}
+
+	if role.Comment != nil {
+		supported, err := i.SupportsRoleComment(ctx, clusterName)
+		if err != nil {
+			return nil, errors.WithMessage(err, "error checking support for role comments")
+		}
+		// The comment is left out on servers not supporting it.
+		if supported {
+			q = q.WithComment(role.Comment)
+		}
+	}
+
+	sql, err := q.Build()
 	if err != nil {
 		return nil, errors.WithMessage(err, "error building query")
 	}
 
-	err = i.clickhouseClient.Exec(ctx, sql)
+	err = i.execWithContext(ctx, sql, clusterName)
 	if err != nil {
-		return nil, errors.WithMessage(err, "error running query")
+		return nil, err
 	}
 
-	return i.FindRoleByName(ctx, role.Name, clusterName)
+	return readAfterCreate(ctx, i, func() (*Role, error) {
+		return i.FindRoleByName(ctx, role.Name, clusterName)
+	})
 }
 
 func (i *impl) GetRole(ctx context.Context, id string, clusterName *string) (*Role, error) { // nolint:dupl
-	sql, err := querybuilder.NewSelect(
+	clusterName = i.withDefaultCluster(clusterName)
+	sql, err := i.newSelect(
 		[]querybuilder.Field{querybuilder.NewField("name")},
 		"system.roles",
 	).WithCluster(clusterName).Where(querybuilder.WhereEquals("id", id)).Build()
@@ -70,18 +103,27 @@ func (i *impl) GetRole(ctx context.Context, id string, clusterName *string) (*Ro
 		return nil, nil
 	}
 
-	// Check if role has settings profile associated.
+	// Check if role has settings profile associated and its settings.
 	{
-		sql, err = querybuilder.
-			NewSelect([]querybuilder.Field{querybuilder.NewField("inherit_profile")}, "system.settings_profile_elements").
+		sql, err = i.
+			newSelect([]querybuilder.Field{
+				querybuilder.NewField("inherit_profile"),
+				querybuilder.NewField("setting_name"),
+				querybuilder.NewField("value"),
+				querybuilder.NewField("min"),
+				querybuilder.NewField("max"),
+				querybuilder.NewField("writability").ToString(),
+			}, "system.settings_profile_elements").
 			WithCluster(clusterName).
 			Where(querybuilder.WhereEquals("role_name", role.Name)).
+			OrderBy(querybuilder.NewField("index"), querybuilder.ASC).
 			Build()
 		if err != nil {
 			return nil, errors.WithMessage(err, "error building query")
 		}
 
 		profiles := make([]string, 0)
+		role.Settings = make([]Setting, 0)
 		err = i.clickhouseClient.Select(ctx, sql, func(data clickhouseclient.Row) error {
 			profile, err := data.GetNullableString("inherit_profile")
 			if err != nil {
@@ -91,6 +133,20 @@ func (i *impl) GetRole(ctx context.Context, id string, clusterName *string) (*Ro
 			if profile != nil {
 				profiles = append(profiles, *profile)
 			}
+
+			settingName, err := data.GetNullableString("setting_name")
+			if err != nil {
+				return errors.WithMessage(err, "error scanning query result, missing 'setting_name' field")
+			}
+
+			if settingName != nil {
+				setting, err := settingFromRow(*settingName, data)
+				if err != nil {
+					return err
+				}
+				role.Settings = append(role.Settings, *setting)
+			}
+
 			return nil
 		})
 		if err != nil {
@@ -100,10 +156,16 @@ func (i *impl) GetRole(ctx context.Context, id string, clusterName *string) (*Ro
 		role.SettingsProfiles = profiles
 	}
 
+	role.Comment, err = i.getRoleComment(ctx, role.Name, clusterName)
+	if err != nil {
+		return nil, errors.WithMessage(err, "error getting role comment")
+	}
+
 	return role, nil
 }
 
 func (i *impl) DeleteRole(ctx context.Context, id string, clusterName *string) error {
+	clusterName = i.withDefaultCluster(clusterName)
 	role, err := i.GetRole(ctx, id, clusterName)
 	if err != nil {
 		return errors.WithMessage(err, "error getting role")
@@ -119,16 +181,17 @@ func (i *impl) DeleteRole(ctx context.Context, id string, clusterName *string) e
 		return errors.WithMessage(err, "error building query")
 	}
 
-	err = i.clickhouseClient.Exec(ctx, sql)
+	err = i.execWithContext(ctx, sql, clusterName)
 	if err != nil {
-		return errors.WithMessage(err, "error running query")
+		return err
 	}
 
 	return nil
 }
 
 func (i *impl) FindRoleByName(ctx context.Context, name string, clusterName *string) (*Role, error) {
-	sql, err := querybuilder.NewSelect(
+	clusterName = i.withDefaultCluster(clusterName)
+	sql, err := i.newSelect(
 		[]querybuilder.Field{querybuilder.NewField("id").ToString()},
 		"system.roles",
 	).Where(querybuilder.WhereEquals("name", name)).WithCluster(clusterName).Build()
@@ -158,25 +221,108 @@ func (i *impl) FindRoleByName(ctx context.Context, name string, clusterName *str
 	return i.GetRole(ctx, uuid, clusterName)
 }
 
+// ListRoles returns the roles whose name starts with namePrefix, ordered by name. An empty namePrefix returns all the
+// roles. Only the ID and name of the roles are set.
+func (i *impl) ListRoles(ctx context.Context, namePrefix string, clusterName *string) ([]Role, error) {
+	clusterName = i.withDefaultCluster(clusterName)
+	q := i.newSelect(
+		[]querybuilder.Field{
+			querybuilder.NewField("name"),
+			querybuilder.NewField("id").ToString(),
+		},
+		"system.roles",
+	).WithCluster(clusterName).OrderBy(querybuilder.NewField("name"), querybuilder.ASC)
+
+	if namePrefix != "" {
+		q = q.Where(querybuilder.WhereLike("name", querybuilder.LikePrefix(namePrefix)))
+	}
+
+	sql, err := q.Build()
+	if err != nil {
+		return nil, errors.WithMessage(err, "error building query")
+	}
+
+	roles := make([]Role, 0)
+
+	err = i.clickhouseClient.Select(ctx, sql, func(data clickhouseclient.Row) error {
+		name, err := data.GetString("name")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'name' field")
+		}
+		id, err := data.GetString("id")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'id' field")
+		}
+
+		roles = append(roles, Role{
+			ID:   id,
+			Name: name,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "error running query")
+	}
+
+	return roles, nil
+}
+
 func (i *impl) UpdateRole(ctx context.Context, role Role, clusterName *string) (*Role, error) {
+	clusterName = i.withDefaultCluster(clusterName)
 	// Retrieve current role
 	existing, err := i.GetRole(ctx, role.ID, clusterName)
 	if err != nil {
 		return nil, errors.WithMessage(err, "Unable to get existing role")
 	}
 
-	sql, err := querybuilder.
+	if existing == nil {
+		return nil, nil
+	}
+
+	if err := i.checkRename("role", existing.Name, role.Name); err != nil {
+		return nil, err
+	}
+
+	settingsChanged := role.Settings != nil && !slices.EqualFunc(existing.Settings, role.Settings, Setting.equal)
+	// The existing comment is nil when the server doesn't support comments on roles.
+	commentChanged := role.Comment != nil && existing.Comment != nil && *existing.Comment != *role.Comment
+	if existing.Name == role.Name && !settingsChanged && !commentChanged {
+		// Nothing to do.
+		return existing, nil
+	}
+
+	q := querybuilder.
 		NewAlterRole(existing.Name).
 		WithCluster(clusterName).
-		RenameTo(&role.Name).
-		Build()
+		RenameTo(&role.Name)
+
+	if settingsChanged {
+		// Settings are dropped and added back in the desired order, because ClickHouse appends the added settings
+		// to the elements of the role.
+		for _, setting := range existing.Settings {
+			q = q.RemoveSetting(setting.Name)
+		}
+		settings, err := i.typedSettings(ctx, role.Settings)
+		if err != nil {
+			return nil, err
+		}
+		for _, setting := range settings {
+			q = addSetting(q, setting)
+		}
+	}
+
+	if commentChanged {
+		q = q.WithComment(role.Comment)
+	}
+
+	sql, err := q.Build()
 	if err != nil {
 		return nil, errors.WithMessage(err, "error building query")
 	}
 
-	err = i.clickhouseClient.Exec(ctx, sql)
+	err = i.execWithContext(ctx, sql, clusterName)
 	if err != nil {
-		return nil, errors.WithMessage(err, "error running query")
+		return nil, err
 	}
 
 	return i.GetRole(ctx, role.ID, clusterName)
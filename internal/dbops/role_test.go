@@ -0,0 +1,448 @@
+package dbops
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+)
+
+func Test_UpdateRole_settings(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+
+	elementRow := func(inheritProfile *string, settingName *string, value *string) clickhouseclient.Row {
+		row := clickhouseclient.Row{}
+		row.Set("inherit_profile", inheritProfile)
+		row.Set("setting_name", settingName)
+		row.Set("value", value)
+		row.Set("min", (*string)(nil))
+		row.Set("max", (*string)(nil))
+		row.Set("writability", (*string)(nil))
+		return row
+	}
+
+	tests := []struct {
+		name      string
+		roleName  string
+		settings  []Setting
+		wantAlter string
+	}{
+		{
+			name:      "Settings not managed",
+			roleName:  "reader",
+			settings:  nil,
+			wantAlter: "",
+		},
+		{
+			name:     "Settings unchanged",
+			roleName: "reader",
+			settings: []Setting{
+				{Name: "max_threads", Value: strPtr("4")},
+				{Name: "readonly", Value: strPtr("1")},
+			},
+			wantAlter: "",
+		},
+		{
+			name:      "Rename only",
+			roleName:  "writer",
+			settings:  nil,
+			wantAlter: "ALTER ROLE `reader` RENAME TO `writer`;",
+		},
+		{
+			name:     "Settings reordered",
+			roleName: "reader",
+			settings: []Setting{
+				{Name: "readonly", Value: strPtr("1")},
+				{Name: "max_threads", Value: strPtr("4")},
+			},
+			wantAlter: "ALTER ROLE `reader` DROP SETTINGS `max_threads`, `readonly` ADD SETTINGS `readonly` = '1', `max_threads` = '4';",
+		},
+		{
+			name:      "Settings removed",
+			roleName:  "reader",
+			settings:  []Setting{},
+			wantAlter: "ALTER ROLE `reader` DROP SETTINGS `max_threads`, `readonly`;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					switch {
+					case strings.Contains(qry, "`system`.`roles`"):
+						row := clickhouseclient.Row{}
+						row.Set("name", "reader")
+						return []clickhouseclient.Row{row}
+					case strings.Contains(qry, "`system`.`settings_profile_elements`"):
+						return []clickhouseclient.Row{
+							elementRow(strPtr("default"), nil, nil),
+							elementRow(nil, strPtr("max_threads"), strPtr("4")),
+							elementRow(nil, strPtr("readonly"), strPtr("1")),
+						}
+					}
+					return nil
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			role, err := client.UpdateRole(context.Background(), Role{ID: "id", Name: tt.roleName, Settings: tt.settings}, nil)
+			if err != nil {
+				t.Fatalf("UpdateRole() error = %v", err)
+			}
+			if role == nil {
+				t.Fatalf("UpdateRole() returned nil role")
+			}
+
+			got := ""
+			if len(fake.execs) > 0 {
+				got = fake.execs[0]
+			}
+			if got != tt.wantAlter {
+				t.Errorf("UpdateRole() query = %q, want %q", got, tt.wantAlter)
+			}
+		})
+	}
+}
+
+func Test_GetRole_settings(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			row := clickhouseclient.Row{}
+			switch {
+			case strings.Contains(qry, "`system`.`roles`"):
+				row.Set("name", "reader")
+			case strings.Contains(qry, "`system`.`settings_profile_elements`"):
+				row.Set("inherit_profile", (*string)(nil))
+				row.Set("setting_name", strPtr("max_memory_usage"))
+				row.Set("value", (*string)(nil))
+				row.Set("min", strPtr("0"))
+				row.Set("max", strPtr("1000"))
+				row.Set("writability", strPtr("CONST"))
+			default:
+				return nil
+			}
+			return []clickhouseclient.Row{row}
+		},
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	role, err := client.GetRole(context.Background(), "id", nil)
+	if err != nil {
+		t.Fatalf("GetRole() error = %v", err)
+	}
+	if role == nil {
+		t.Fatalf("GetRole() returned nil role")
+	}
+
+	want := Setting{Name: "max_memory_usage", Min: strPtr("0"), Max: strPtr("1000"), Writability: strPtr("CONST")}
+	if len(role.Settings) != 1 || !role.Settings[0].equal(want) {
+		t.Errorf("GetRole() Settings = %+v, want %+v", role.Settings, want)
+	}
+	if len(role.SettingsProfiles) != 0 {
+		t.Errorf("GetRole() SettingsProfiles = %v, want none", role.SettingsProfiles)
+	}
+}
+
+func Test_ListRoles(t *testing.T) {
+	roleRow := func(name string, id string) clickhouseclient.Row {
+		row := clickhouseclient.Row{}
+		row.Set("name", name)
+		row.Set("id", id)
+		return row
+	}
+
+	var query string
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			query = qry
+			return []clickhouseclient.Row{
+				roleRow("reader", "00000000-0000-0000-0000-000000000001"),
+				roleRow("writer", "00000000-0000-0000-0000-000000000002"),
+			}
+		},
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	roles, err := client.ListRoles(context.Background(), "", nil)
+	if err != nil {
+		t.Fatalf("ListRoles() error = %v", err)
+	}
+
+	want := []Role{
+		{ID: "00000000-0000-0000-0000-000000000001", Name: "reader"},
+		{ID: "00000000-0000-0000-0000-000000000002", Name: "writer"},
+	}
+	if !reflect.DeepEqual(roles, want) {
+		t.Errorf("ListRoles() = %+v, want %+v", roles, want)
+	}
+
+	wantQuery := "SELECT `name`, toString(`id`) AS `id` FROM `system`.`roles` ORDER BY `name` ASC;"
+	if query != wantQuery {
+		t.Errorf("ListRoles() query = %q, want %q", query, wantQuery)
+	}
+}
+
+func Test_ListRoles_namePrefix(t *testing.T) {
+	var query string
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			query = qry
+			return nil
+		},
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	if _, err := client.ListRoles(context.Background(), "team_a", nil); err != nil {
+		t.Fatalf("ListRoles() error = %v", err)
+	}
+
+	wantQuery := "SELECT `name`, toString(`id`) AS `id` FROM `system`.`roles` WHERE (`name` LIKE 'team\\\\_a%') ORDER BY `name` ASC;"
+	if query != wantQuery {
+		t.Errorf("ListRoles() query = %q, want %q", query, wantQuery)
+	}
+}
+
+func Test_ListRoles_clusterReads(t *testing.T) {
+	clusterName := "cluster1"
+
+	tests := []struct {
+		name      string
+		opts      []Option
+		wantQuery string
+	}{
+		{
+			name:      "Default",
+			wantQuery: "SELECT `name`, toString(`id`) AS `id` FROM cluster('cluster1', `system`.`roles`) ORDER BY `name` ASC;",
+		},
+		{
+			name:      "All replicas",
+			opts:      []Option{WithClusterReads(ClusterReadsAllReplicas)},
+			wantQuery: "SELECT DISTINCT `name`, toString(`id`) AS `id` FROM clusterAllReplicas('cluster1', `system`.`roles`) ORDER BY `name` ASC;",
+		},
+		{
+			name:      "First replica",
+			opts:      []Option{WithClusterReads(ClusterReadsFirstReplica)},
+			wantQuery: "SELECT `name`, toString(`id`) AS `id` FROM cluster('cluster1', `system`.`roles`) ORDER BY `name` ASC SETTINGS load_balancing = 'in_order', prefer_localhost_replica = 0;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query string
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					query = qry
+					return nil
+				},
+			}
+
+			client, err := NewClient(fake, tt.opts...)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			if _, err := client.ListRoles(context.Background(), "", &clusterName); err != nil {
+				t.Fatalf("ListRoles() error = %v", err)
+			}
+
+			if query != tt.wantQuery {
+				t.Errorf("ListRoles() query = %q, want %q", query, tt.wantQuery)
+			}
+		})
+	}
+}
+
+func Test_CreateRole_comment(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			switch {
+			case strings.Contains(qry, "`system`.`columns`"):
+				row := clickhouseclient.Row{}
+				row.Set("name", "comment")
+				return []clickhouseclient.Row{row}
+			case strings.Contains(qry, "`system`.`roles`"):
+				row := clickhouseclient.Row{}
+				row.Set("id", "00000000-0000-0000-0000-000000000001")
+				row.Set("name", "reader")
+				row.Set("comment", "owner: team-data")
+				return []clickhouseclient.Row{row}
+			}
+			return nil
+		},
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	role, err := client.CreateRole(context.Background(), Role{Name: "reader", Comment: strPtr("owner: team-data")}, nil)
+	if err != nil {
+		t.Fatalf("CreateRole() error = %v", err)
+	}
+
+	wantCreate := "CREATE ROLE `reader` COMMENT 'owner: team-data';"
+	if len(fake.execs) != 1 || fake.execs[0] != wantCreate {
+		t.Errorf("CreateRole() queries = %q, want %q", fake.execs, wantCreate)
+	}
+	if role.Comment == nil || *role.Comment != "owner: team-data" {
+		t.Errorf("CreateRole() Comment = %v, want %q", role.Comment, "owner: team-data")
+	}
+}
+
+func Test_UpdateRole_comment(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name      string
+		supported bool
+		comment   *string
+		wantAlter string
+	}{
+		{
+			name:      "Comment not managed",
+			supported: true,
+			comment:   nil,
+			wantAlter: "",
+		},
+		{
+			name:      "Comment unchanged",
+			supported: true,
+			comment:   strPtr("owner: team-data"),
+			wantAlter: "",
+		},
+		{
+			name:      "Comment changed",
+			supported: true,
+			comment:   strPtr("owner: team-ops"),
+			wantAlter: "ALTER ROLE `reader` COMMENT 'owner: team-ops';",
+		},
+		{
+			name:      "Comment removed",
+			supported: true,
+			comment:   strPtr(""),
+			wantAlter: "ALTER ROLE `reader` COMMENT '';",
+		},
+		{
+			name:      "Comments not supported",
+			supported: false,
+			comment:   strPtr("owner: team-ops"),
+			wantAlter: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					switch {
+					case strings.Contains(qry, "`system`.`columns`"):
+						if !tt.supported {
+							return nil
+						}
+						row := clickhouseclient.Row{}
+						row.Set("name", "comment")
+						return []clickhouseclient.Row{row}
+					case strings.Contains(qry, "`system`.`roles`"):
+						row := clickhouseclient.Row{}
+						row.Set("name", "reader")
+						row.Set("comment", "owner: team-data")
+						return []clickhouseclient.Row{row}
+					}
+					return nil
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			role, err := client.UpdateRole(context.Background(), Role{ID: "id", Name: "reader", Comment: tt.comment}, nil)
+			if err != nil {
+				t.Fatalf("UpdateRole() error = %v", err)
+			}
+			if role == nil {
+				t.Fatalf("UpdateRole() returned nil role")
+			}
+
+			got := ""
+			if len(fake.execs) > 0 {
+				got = fake.execs[0]
+			}
+			if got != tt.wantAlter {
+				t.Errorf("UpdateRole() query = %q, want %q", got, tt.wantAlter)
+			}
+		})
+	}
+}
+
+func Test_CreateRole_defaultCluster(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name        string
+		clusterName *string
+		want        string
+	}{
+		{
+			name:        "Default cluster",
+			clusterName: nil,
+			want:        "CREATE ROLE `reader` ON CLUSTER 'cluster1';",
+		},
+		{
+			name:        "Explicit cluster takes precedence",
+			clusterName: strPtr("cluster2"),
+			want:        "CREATE ROLE `reader` ON CLUSTER 'cluster2';",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					if !strings.Contains(qry, "`system`.`roles`") {
+						return nil
+					}
+					row := clickhouseclient.Row{}
+					row.Set("id", "00000000-0000-0000-0000-000000000001")
+					row.Set("name", "reader")
+					return []clickhouseclient.Row{row}
+				},
+			}
+
+			client, err := NewClient(fake, WithDefaultCluster("cluster1"))
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			_, err = client.CreateRole(context.Background(), Role{Name: "reader"}, tt.clusterName)
+			if err != nil {
+				t.Fatalf("CreateRole() error = %v", err)
+			}
+
+			if len(fake.execs) != 1 || fake.execs[0] != tt.want {
+				t.Errorf("CreateRole() queries = %q, want %q", fake.execs, tt.want)
+			}
+		})
+	}
+}
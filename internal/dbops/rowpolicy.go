@@ -0,0 +1,278 @@
+package dbops
+
+import (
+	"context"
+	"slices"
+
+	"github.com/pingcap/errors"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/querybuilder"
+)
+
+type RowPolicy struct {
+	ID              string   `json:"id"`
+	Name            string   `json:"short_name"`
+	DatabaseName    string   `json:"database"`
+	TableName       string   `json:"table"`
+	Restrictive     bool     `json:"is_restrictive"`
+	UsingExpression string   `json:"select_filter"`
+	ApplyTo         []string `json:"apply_to_list"`
+}
+
+func (p *RowPolicy) kind() querybuilder.RowPolicyKind {
+	if p.Restrictive {
+		return querybuilder.RowPolicyKindRestrictive
+	}
+
+	return querybuilder.RowPolicyKindPermissive
+}
+
+func (i *impl) CreateRowPolicy(ctx context.Context, rowPolicy RowPolicy, clusterName *string) (*RowPolicy, error) {
+	clusterName = i.withDefaultCluster(clusterName)
+	sql, err := querybuilder.
+		NewCreateRowPolicy(rowPolicy.Name, rowPolicy.DatabaseName, rowPolicy.TableName).
+		WithCluster(clusterName).
+		WithKind(rowPolicy.kind()).
+		Using(rowPolicy.UsingExpression).
+		To(rowPolicy.ApplyTo).
+		Build()
+	if err != nil {
+		return nil, errors.WithMessage(err, "error building query")
+	}
+
+	err = i.execWithContext(ctx, sql, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	return readAfterCreate(ctx, i, func() (*RowPolicy, error) {
+		return i.FindRowPolicyByName(ctx, rowPolicy.Name, rowPolicy.DatabaseName, rowPolicy.TableName, clusterName)
+	})
+}
+
+// ValidateRowPolicy checks the query creating the given row policy can be built and, when SQL validation is enabled,
+// that the server is able to parse it, e.g. to report syntax errors in the using expression during plan.
+// The query is never run, so neither the row policy nor its table need to exist.
+func (i *impl) ValidateRowPolicy(ctx context.Context, rowPolicy RowPolicy, clusterName *string) error {
+	clusterName = i.withDefaultCluster(clusterName)
+	sql, err := querybuilder.
+		NewCreateRowPolicy(rowPolicy.Name, rowPolicy.DatabaseName, rowPolicy.TableName).
+		WithCluster(clusterName).
+		WithKind(rowPolicy.kind()).
+		Using(rowPolicy.UsingExpression).
+		To(rowPolicy.ApplyTo).
+		Build()
+	if err != nil {
+		return errors.WithMessage(err, "error building query")
+	}
+
+	return i.validateQuery(ctx, sql)
+}
+
+func (i *impl) GetRowPolicy(ctx context.Context, id string, clusterName *string) (*RowPolicy, error) {
+	clusterName = i.withDefaultCluster(clusterName)
+	return i.selectRowPolicy(ctx, querybuilder.WhereEquals("id", id), clusterName)
+}
+
+func (i *impl) FindRowPolicyByName(ctx context.Context, name string, databaseName string, tableName string, clusterName *string) (*RowPolicy, error) {
+	clusterName = i.withDefaultCluster(clusterName)
+	return i.selectRowPolicy(ctx, querybuilder.AndWhere(
+		querybuilder.WhereEquals("short_name", name),
+		querybuilder.WhereEquals("database", databaseName),
+		querybuilder.WhereEquals("table", tableName),
+	), clusterName)
+}
+
+func (i *impl) UpdateRowPolicy(ctx context.Context, rowPolicy RowPolicy, clusterName *string) (*RowPolicy, error) {
+	clusterName = i.withDefaultCluster(clusterName)
+	existing, err := i.GetRowPolicy(ctx, rowPolicy.ID, clusterName)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Unable to get existing row policy")
+	}
+
+	if existing == nil {
+		return nil, errors.Errorf("row policy with id %q not found", rowPolicy.ID)
+	}
+
+	q := querybuilder.
+		NewAlterRowPolicy(existing.Name, existing.DatabaseName, existing.TableName).
+		WithCluster(clusterName)
+
+	changes := false
+
+	if existing.Restrictive != rowPolicy.Restrictive {
+		q = q.WithKind(rowPolicy.kind())
+		changes = true
+	}
+
+	if existing.UsingExpression != rowPolicy.UsingExpression {
+		// The server stores a normalized version of the expression, so a different text is not necessarily a change.
+		same, err := i.SameRowPolicyExpression(ctx, existing.UsingExpression, rowPolicy.UsingExpression)
+		if err != nil {
+			return nil, errors.WithMessage(err, "error comparing USING expressions")
+		}
+		if !same {
+			q = q.Using(&rowPolicy.UsingExpression)
+			changes = true
+		}
+	}
+
+	if !sameElements(existing.ApplyTo, rowPolicy.ApplyTo) {
+		q = q.To(rowPolicy.ApplyTo)
+		changes = true
+	}
+
+	if !changes {
+		return existing, nil
+	}
+
+	sql, err := q.Build()
+	if err != nil {
+		return nil, errors.WithMessage(err, "error building query")
+	}
+
+	err = i.execWithContext(ctx, sql, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.GetRowPolicy(ctx, rowPolicy.ID, clusterName)
+}
+
+func (i *impl) DeleteRowPolicy(ctx context.Context, id string, clusterName *string) error {
+	clusterName = i.withDefaultCluster(clusterName)
+	rowPolicy, err := i.GetRowPolicy(ctx, id, clusterName)
+	if err != nil {
+		return errors.WithMessage(err, "error looking up row policy")
+	}
+
+	if rowPolicy == nil {
+		// Desired status
+		return nil
+	}
+
+	sql, err := querybuilder.
+		NewDropRowPolicy(rowPolicy.Name, rowPolicy.DatabaseName, rowPolicy.TableName).
+		WithCluster(clusterName).
+		Build()
+	if err != nil {
+		return errors.WithMessage(err, "error building query")
+	}
+
+	err = i.execWithContext(ctx, sql, clusterName)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SameRowPolicyExpression tells whether the server parses the given expressions to the same syntax tree, e.g. when
+// ClickHouse stored "a = 1 and b = 2" as "(a = 1) AND (b = 2)".
+func (i *impl) SameRowPolicyExpression(ctx context.Context, expression string, other string) (bool, error) {
+	ast, err := i.explainExpression(ctx, expression)
+	if err != nil {
+		return false, err
+	}
+
+	otherAST, err := i.explainExpression(ctx, other)
+	if err != nil {
+		return false, err
+	}
+
+	return slices.Equal(ast, otherAST), nil
+}
+
+// explainExpression returns the lines of the syntax tree of the given expression, as parsed by the server.
+func (i *impl) explainExpression(ctx context.Context, expression string) ([]string, error) {
+	var lines []string
+	err := i.clickhouseClient.Select(ctx, "EXPLAIN AST SELECT "+expression, func(data clickhouseclient.Row) error {
+		line, err := data.GetString("explain")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'explain' field")
+		}
+		lines = append(lines, line)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "error parsing expression")
+	}
+
+	return lines, nil
+}
+
+func (i *impl) selectRowPolicy(ctx context.Context, where querybuilder.Where, clusterName *string) (*RowPolicy, error) {
+	sql, err := i.newSelect(
+		[]querybuilder.Field{
+			querybuilder.NewField("id").ToString(),
+			querybuilder.NewField("short_name"),
+			querybuilder.NewField("database"),
+			querybuilder.NewField("table"),
+			querybuilder.NewField("select_filter"),
+			querybuilder.NewField("is_restrictive"),
+			querybuilder.NewField("apply_to_list"),
+		},
+		"system.row_policies",
+	).WithCluster(clusterName).Where(where).Build()
+	if err != nil {
+		return nil, errors.WithMessage(err, "error building query")
+	}
+
+	var rowPolicy *RowPolicy
+
+	err = i.clickhouseClient.Select(ctx, sql, func(data clickhouseclient.Row) error {
+		id, err := data.GetString("id")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'id' field")
+		}
+		name, err := data.GetString("short_name")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'short_name' field")
+		}
+		database, err := data.GetString("database")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'database' field")
+		}
+		table, err := data.GetString("table")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'table' field")
+		}
+		selectFilter, err := data.GetNullableString("select_filter")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'select_filter' field")
+		}
+		restrictive, err := data.GetBool("is_restrictive")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'is_restrictive' field")
+		}
+		applyTo, err := data.GetStringSlice("apply_to_list")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'apply_to_list' field")
+		}
+
+		rowPolicy = &RowPolicy{
+			ID:           id,
+			Name:         name,
+			DatabaseName: database,
+			TableName:    table,
+			Restrictive:  restrictive,
+			ApplyTo:      applyTo,
+		}
+		if selectFilter != nil {
+			rowPolicy.UsingExpression = *selectFilter
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "error running query")
+	}
+
+	if rowPolicy == nil {
+		// Row policy not found
+		return nil, nil
+	}
+
+	return rowPolicy, nil
+}
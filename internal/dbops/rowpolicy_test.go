@@ -0,0 +1,210 @@
+package dbops
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+)
+
+// fakeExplainAST answers EXPLAIN AST queries with a syntax tree built from the words of the expression, ignoring
+// parentheses and the case of the keywords, like the server does.
+func fakeExplainAST() *fakeClickhouseClient {
+	return &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			expression, ok := strings.CutPrefix(qry, "EXPLAIN AST SELECT ")
+			if !ok {
+				return nil
+			}
+
+			var rows []clickhouseclient.Row
+			for _, word := range strings.Fields(strings.NewReplacer("(", " ", ")", " ").Replace(expression)) {
+				row := clickhouseclient.Row{}
+				row.Set("explain", strings.ToLower(word))
+				rows = append(rows, row)
+			}
+			return rows
+		},
+	}
+}
+
+func Test_SameRowPolicyExpression(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		other      string
+		want       bool
+	}{
+		{
+			name:       "Reformatted by the server",
+			expression: "(a = 1) AND (b = 2)",
+			other:      "a = 1 and b = 2",
+			want:       true,
+		},
+		{
+			name:       "Changed",
+			expression: "(a = 1) AND (b = 2)",
+			other:      "a = 1 and b = 3",
+			want:       false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(fakeExplainAST())
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			got, err := client.SameRowPolicyExpression(context.Background(), tt.expression, tt.other)
+			if err != nil {
+				t.Fatalf("SameRowPolicyExpression() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("SameRowPolicyExpression() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ValidateRowPolicy(t *testing.T) {
+	rowPolicy := RowPolicy{
+		Name:            "tenant",
+		DatabaseName:    "default",
+		TableName:       "events",
+		UsingExpression: "tenant_id = 1",
+		ApplyTo:         []string{"reader"},
+	}
+
+	tests := []struct {
+		name      string
+		opts      []Option
+		rowPolicy RowPolicy
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name:      "Structural validation only",
+			rowPolicy: rowPolicy,
+			want:      nil,
+		},
+		{
+			name:      "Server side validation",
+			opts:      []Option{WithSQLValidation()},
+			rowPolicy: rowPolicy,
+			want:      []string{"EXPLAIN AST CREATE ROW POLICY `tenant` ON `default`.`events` AS PERMISSIVE FOR SELECT USING tenant_id = 1 TO `reader`"},
+		},
+		{
+			name:      "Invalid row policy is not sent to the server",
+			opts:      []Option{WithSQLValidation()},
+			rowPolicy: RowPolicy{Name: "tenant", UsingExpression: "tenant_id = 1"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var selects []string
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					selects = append(selects, qry)
+					return nil
+				},
+			}
+
+			client, err := NewClient(fake, tt.opts...)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			err = client.ValidateRowPolicy(context.Background(), tt.rowPolicy, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateRowPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if len(selects) != len(tt.want) {
+				t.Fatalf("ValidateRowPolicy() ran %v, want %v", selects, tt.want)
+			}
+			for i := range tt.want {
+				if selects[i] != tt.want[i] {
+					t.Errorf("ValidateRowPolicy() ran %q, want %q", selects[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func Test_UpdateRowPolicy(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name      string
+		using     string
+		applyTo   []string
+		wantAlter string
+	}{
+		{
+			name:    "Expression reformatted by the server and roles reordered",
+			using:   "tenant_id = 1 and active = 1",
+			applyTo: []string{"writer", "reader"},
+		},
+		{
+			name:      "Expression changed",
+			using:     "tenant_id = 2 and active = 1",
+			applyTo:   []string{"reader", "writer"},
+			wantAlter: "ALTER ROW POLICY `tenant` ON `db`.`tbl` FOR SELECT USING tenant_id = 2 and active = 1;",
+		},
+		{
+			name:      "Role added",
+			using:     "tenant_id = 1 and active = 1",
+			applyTo:   []string{"reader", "writer", "auditor"},
+			wantAlter: "ALTER ROW POLICY `tenant` ON `db`.`tbl` TO `reader`, `writer`, `auditor`;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := fakeExplainAST()
+			explain := fake.rows
+			fake.rows = func(qry string) []clickhouseclient.Row {
+				if !strings.Contains(qry, "`system`.`row_policies`") {
+					return explain(qry)
+				}
+				row := clickhouseclient.Row{}
+				row.Set("id", "00000000-0000-0000-0000-000000000001")
+				row.Set("short_name", "tenant")
+				row.Set("database", "db")
+				row.Set("table", "tbl")
+				row.Set("select_filter", strPtr("(tenant_id = 1) AND (active = 1)"))
+				row.Set("is_restrictive", uint8(0))
+				row.Set("apply_to_list", []string{"reader", "writer"})
+				return []clickhouseclient.Row{row}
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			_, err = client.UpdateRowPolicy(context.Background(), RowPolicy{
+				ID:              "00000000-0000-0000-0000-000000000001",
+				Name:            "tenant",
+				DatabaseName:    "db",
+				TableName:       "tbl",
+				UsingExpression: tt.using,
+				ApplyTo:         tt.applyTo,
+			}, nil)
+			if err != nil {
+				t.Fatalf("UpdateRowPolicy() error = %v", err)
+			}
+
+			if tt.wantAlter == "" {
+				if len(fake.execs) != 0 {
+					t.Errorf("UpdateRowPolicy() ran %v, want no query", fake.execs)
+				}
+				return
+			}
+			if len(fake.execs) != 1 || fake.execs[0] != tt.wantAlter {
+				t.Errorf("UpdateRowPolicy() ran %v, want %q", fake.execs, tt.wantAlter)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package dbops
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pingcap/errors"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/querybuilder"
+)
+
+// ServerVersion describes the build of the server the client is connected to.
+type ServerVersion struct {
+	// Version is the full version of the server, such as 25.3.2.39.
+	Version string
+	// Uptime is how long the server has been running, with a precision of one second.
+	Uptime time.Duration
+}
+
+// UserDirectory is a storage for users, roles and the other access entities, as listed in system.user_directories.
+type UserDirectory struct {
+	Name string
+	// Type is the type of storage, such as users_xml, local_directory or replicated.
+	Type string
+	// Precedence is the order the directories are searched in, the lowest first. New entities are created in the
+	// first writable directory.
+	Precedence uint64
+	// ReadOnly is true when the entities in the directory can't be created, altered or dropped with SQL queries.
+	ReadOnly bool
+}
+
+// readOnlyUserDirectoryTypes are the types of user directories that never accept writes.
+var readOnlyUserDirectoryTypes = []string{"users_xml", "ldap"}
+
+// isReadOnlyUserDirectory tells if a user directory is read-only out of its type and its 'params' column, a JSON
+// object including a 'readonly' flag for the storages that can be configured as read-only, e.g. local_directory.
+func isReadOnlyUserDirectory(udType string, params string) bool {
+	for _, t := range readOnlyUserDirectoryTypes {
+		if udType == t {
+			return true
+		}
+	}
+
+	var parsed struct {
+		ReadOnly bool `json:"readonly"`
+	}
+	if err := json.Unmarshal([]byte(params), &parsed); err != nil {
+		return false
+	}
+
+	return parsed.ReadOnly
+}
+
+// ServerVersion returns the version and uptime of the server the client is connected to.
+func (i *impl) ServerVersion(ctx context.Context) (*ServerVersion, error) {
+	sql, err := i.newSelect(
+		[]querybuilder.Field{
+			querybuilder.NewExpressionField("version()", "version"),
+			querybuilder.NewExpressionField("toUInt64(uptime())", "uptime"),
+		},
+		"system.one",
+	).Build()
+	if err != nil {
+		return nil, errors.WithMessage(err, "error building query")
+	}
+
+	var version *ServerVersion
+	err = i.clickhouseClient.Select(ctx, sql, func(data clickhouseclient.Row) error {
+		v, err := data.GetString("version")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'version' field")
+		}
+		uptime, err := data.GetUInt64("uptime")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'uptime' field")
+		}
+
+		version = &ServerVersion{
+			Version: v,
+			Uptime:  time.Duration(uptime) * time.Second,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "error running query")
+	}
+
+	if version == nil {
+		return nil, errors.New("server version query returned no rows")
+	}
+
+	return version, nil
+}
+
+// UserDirectories returns the storages for access entities configured on the server, ordered by precedence.
+func (i *impl) UserDirectories(ctx context.Context, clusterName *string) ([]UserDirectory, error) {
+	clusterName = i.withDefaultCluster(clusterName)
+	sql, err := i.newSelect(
+		[]querybuilder.Field{
+			querybuilder.NewField("name"),
+			querybuilder.NewField("type"),
+			querybuilder.NewField("precedence"),
+			querybuilder.NewField("params"),
+		},
+		"system.user_directories",
+	).WithCluster(clusterName).OrderBy(querybuilder.NewField("precedence"), querybuilder.ASC).Build()
+	if err != nil {
+		return nil, errors.WithMessage(err, "error building query")
+	}
+
+	directories := make([]UserDirectory, 0)
+	err = i.clickhouseClient.Select(ctx, sql, func(data clickhouseclient.Row) error {
+		name, err := data.GetString("name")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'name' field")
+		}
+		udType, err := data.GetString("type")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'type' field")
+		}
+		precedence, err := data.GetUInt64("precedence")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'precedence' field")
+		}
+		params, err := data.GetString("params")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'params' field")
+		}
+
+		for _, d := range directories {
+			if d.Name == name && d.Type == udType && d.Precedence == precedence {
+				// Same directory reported by another replica of the cluster.
+				return nil
+			}
+		}
+
+		directories = append(directories, UserDirectory{
+			Name:       name,
+			Type:       udType,
+			Precedence: precedence,
+			ReadOnly:   isReadOnlyUserDirectory(udType, params),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "error running query")
+	}
+
+	return directories, nil
+}
@@ -0,0 +1,91 @@
+package dbops
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+)
+
+func Test_ServerVersion(t *testing.T) {
+	var query string
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			query = qry
+			row := clickhouseclient.Row{}
+			row.Set("version", "25.3.2.39")
+			row.Set("uptime", uint64(3725))
+			return []clickhouseclient.Row{row}
+		},
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	version, err := client.ServerVersion(context.Background())
+	if err != nil {
+		t.Fatalf("ServerVersion() error = %v", err)
+	}
+
+	want := &ServerVersion{Version: "25.3.2.39", Uptime: time.Hour + 2*time.Minute + 5*time.Second}
+	if !reflect.DeepEqual(version, want) {
+		t.Errorf("ServerVersion() = %+v, want %+v", version, want)
+	}
+
+	wantQuery := "SELECT version() AS `version`, toUInt64(uptime()) AS `uptime` FROM `system`.`one`;"
+	if query != wantQuery {
+		t.Errorf("ServerVersion() query = %q, want %q", query, wantQuery)
+	}
+}
+
+func Test_UserDirectories(t *testing.T) {
+	directoryRow := func(name string, udType string, precedence uint64, params string) clickhouseclient.Row {
+		row := clickhouseclient.Row{}
+		row.Set("name", name)
+		row.Set("type", udType)
+		row.Set("precedence", precedence)
+		row.Set("params", params)
+		return row
+	}
+
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			if !strings.Contains(qry, "`system`.`user_directories`") {
+				return nil
+			}
+			return []clickhouseclient.Row{
+				directoryRow("users_xml", "users_xml", 0, `{"path":"\/etc\/clickhouse-server\/users.xml"}`),
+				directoryRow("local_directory", "local_directory", 1, `{"path":"\/var\/lib\/clickhouse\/access\/","readonly":true}`),
+				directoryRow("replicated", "replicated", 2, `{"zookeeper_path":"\/clickhouse\/access"}`),
+				// Same directories reported by another replica.
+				directoryRow("users_xml", "users_xml", 0, `{"path":"\/etc\/clickhouse-server\/users.xml"}`),
+				directoryRow("replicated", "replicated", 2, `{"zookeeper_path":"\/clickhouse\/access"}`),
+			}
+		},
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	clusterName := "cluster1"
+	directories, err := client.UserDirectories(context.Background(), &clusterName)
+	if err != nil {
+		t.Fatalf("UserDirectories() error = %v", err)
+	}
+
+	want := []UserDirectory{
+		{Name: "users_xml", Type: "users_xml", Precedence: 0, ReadOnly: true},
+		{Name: "local_directory", Type: "local_directory", Precedence: 1, ReadOnly: true},
+		{Name: "replicated", Type: "replicated", Precedence: 2},
+	}
+	if !reflect.DeepEqual(directories, want) {
+		t.Errorf("UserDirectories() = %+v, want %+v", directories, want)
+	}
+}
@@ -3,6 +3,8 @@ package dbops
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/pingcap/errors"
 
@@ -16,9 +18,118 @@ type Setting struct {
 	Min         *string
 	Max         *string
 	Writability *string
+	// Raw makes Value, Min and Max be sent verbatim as SQL expressions, e.g. getSetting('max_threads').
+	// Otherwise they are sent unquoted when the setting has a numeric or boolean type in system.settings and they
+	// are numbers or booleans, and quoted as strings in any other case, e.g. for custom settings.
+	// Raw is never reported by ClickHouse, so it is false in the settings returned by reads.
+	Raw bool
+}
+
+// settingAdder is implemented by the query builders accepting settings.
+type settingAdder[Q any] interface {
+	AddSetting(name string, value *string, min *string, max *string, writability *string) Q
+	AddRawSetting(name string, value *string, min *string, max *string, writability *string) Q
+}
+
+// addSetting adds the setting to the query, verbatim when it is raw.
+func addSetting[Q settingAdder[Q]](q Q, setting Setting) Q {
+	if setting.Raw {
+		return q.AddRawSetting(setting.Name, setting.Value, setting.Min, setting.Max, setting.Writability)
+	}
+
+	return q.AddSetting(setting.Name, setting.Value, setting.Min, setting.Max, setting.Writability)
+}
+
+var integerSettingType = regexp.MustCompile(`^(NonZero)?U?Int[0-9]+(Auto)?$`)
+
+var numericSettingValue = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+
+// isNumericSettingType returns true if values of settings with the given type in system.settings are numbers.
+func isNumericSettingType(settingType string) bool {
+	switch settingType {
+	case "Bool", "Float", "Double", "Seconds", "Milliseconds", "MaxThreads":
+		return true
+	}
+
+	return integerSettingType.MatchString(settingType)
+}
+
+// isNumericSettingValue returns true if the value can be sent unquoted for a setting of the given numeric type.
+func isNumericSettingValue(settingType string, value *string) bool {
+	if value == nil {
+		return true
+	}
+
+	if settingType == "Bool" {
+		if _, ok := boolSettingValues[strings.ToLower(*value)]; ok {
+			return true
+		}
+	}
+
+	return numericSettingValue.MatchString(*value)
+}
+
+// typedSettings returns the settings with Raw set for the ones whose values are numbers or booleans according to
+// the type of the setting in system.settings, so that they are not sent as strings, e.g. log_queries = 1.
+// Settings missing from system.settings, like custom settings, and settings of any other type are left quoted.
+func (i *impl) typedSettings(ctx context.Context, settings []Setting) ([]Setting, error) {
+	names := make([]querybuilder.Where, 0)
+	for _, setting := range settings {
+		if !setting.Raw {
+			names = append(names, querybuilder.WhereEquals("name", setting.Name))
+		}
+	}
+
+	if len(names) == 0 {
+		return settings, nil
+	}
+
+	sql, err := querybuilder.NewSelect([]querybuilder.Field{
+		querybuilder.NewField("name"),
+		querybuilder.NewField("type"),
+	}, "system.settings").
+		Where(querybuilder.OrWhere(names...)).
+		Build()
+	if err != nil {
+		return nil, errors.WithMessage(err, "error building query")
+	}
+
+	settingTypes := make(map[string]string)
+	err = i.clickhouseClient.Select(ctx, sql, func(data clickhouseclient.Row) error {
+		name, err := data.GetString("name")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'name' field")
+		}
+
+		settingType, err := data.GetString("type")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'type' field")
+		}
+
+		settingTypes[name] = settingType
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "error getting the types of the settings")
+	}
+
+	ret := make([]Setting, 0, len(settings))
+	for _, setting := range settings {
+		settingType, ok := settingTypes[setting.Name]
+		if !setting.Raw && ok && isNumericSettingType(settingType) {
+			setting.Raw = isNumericSettingValue(settingType, setting.Value) &&
+				isNumericSettingValue(settingType, setting.Min) &&
+				isNumericSettingValue(settingType, setting.Max)
+		}
+		ret = append(ret, setting)
+	}
+
+	return ret, nil
 }
 
 func (i *impl) CreateSetting(ctx context.Context, settingsProfileID string, setting Setting, clusterName *string) (*Setting, error) {
+	clusterName = i.withDefaultCluster(clusterName)
 	settingsProfile, err := i.GetSettingsProfile(ctx, settingsProfileID, clusterName)
 	if err != nil {
 		return nil, errors.WithMessage(err, "error getting settings profile")
@@ -28,23 +139,48 @@ func (i *impl) CreateSetting(ctx context.Context, settingsProfileID string, sett
 		return nil, errors.New(fmt.Sprintf("settings profile with id %q was not found", settingsProfileID))
 	}
 
-	sql, err := querybuilder.NewAlterSettingsProfile(settingsProfile.Name).
-		WithCluster(clusterName).
-		AddSetting(setting.Name, setting.Value, setting.Min, setting.Max, setting.Writability).
-		Build()
+	settings, err := i.typedSettings(ctx, []Setting{setting})
+	if err != nil {
+		return nil, err
+	}
+
+	q := querybuilder.NewAlterSettingsProfile(settingsProfile.Name).
+		WithCluster(clusterName)
+
+	sql, err := addSetting(q, settings[0]).Build()
 	if err != nil {
 		return nil, errors.WithMessage(err, "error building query")
 	}
 
-	err = i.clickhouseClient.Exec(ctx, sql)
+	err = i.execWithContext(ctx, sql, clusterName)
 	if err != nil {
-		return nil, errors.WithMessage(err, "error running query")
+		return nil, err
+	}
+
+	return readAfterCreate(ctx, i, func() (*Setting, error) {
+		return i.GetSetting(ctx, settingsProfileID, setting.Name, clusterName)
+	})
+}
+
+// ValidateSetting checks the query adding the given setting to a settings profile can be built and, when SQL
+// validation is enabled, that the server is able to parse it.
+// The settings profile doesn't need to exist, as the query is never run: this allows validating settings
+// belonging to profiles that are not created yet.
+func (i *impl) ValidateSetting(ctx context.Context, setting Setting, clusterName *string) error {
+	clusterName = i.withDefaultCluster(clusterName)
+	q := querybuilder.NewAlterSettingsProfile("validation").
+		WithCluster(clusterName)
+
+	sql, err := addSetting(q, setting).Build()
+	if err != nil {
+		return errors.WithMessage(err, "error building query")
 	}
 
-	return i.GetSetting(ctx, settingsProfileID, setting.Name, clusterName)
+	return i.validateQuery(ctx, sql)
 }
 
 func (i *impl) GetSetting(ctx context.Context, settingsProfileID string, name string, clusterName *string) (*Setting, error) {
+	clusterName = i.withDefaultCluster(clusterName)
 	settingsProfile, err := i.GetSettingsProfile(ctx, settingsProfileID, clusterName)
 	if err != nil {
 		return nil, errors.WithMessage(err, "error getting settings profile")
@@ -55,7 +191,7 @@ func (i *impl) GetSetting(ctx context.Context, settingsProfileID string, name st
 		return nil, nil
 	}
 
-	sql, err := querybuilder.NewSelect([]querybuilder.Field{
+	sql, err := i.newSelect([]querybuilder.Field{
 		querybuilder.NewField("value"),
 		querybuilder.NewField("min"),
 		querybuilder.NewField("max"),
@@ -74,33 +210,10 @@ func (i *impl) GetSetting(ctx context.Context, settingsProfileID string, name st
 	var setting *Setting
 
 	err = i.clickhouseClient.Select(ctx, sql, func(data clickhouseclient.Row) error {
-		value, err := data.GetNullableString("value")
-		if err != nil {
-			return errors.WithMessage(err, "error scanning query result, missing 'value' field")
-		}
-
-		minV, err := data.GetNullableString("min")
-		if err != nil {
-			return errors.WithMessage(err, "error scanning query result, missing 'min' field")
-		}
-
-		maxV, err := data.GetNullableString("max")
-		if err != nil {
-			return errors.WithMessage(err, "error scanning query result, missing 'max' field")
-		}
-
-		writability, err := data.GetNullableString("writability")
-		if err != nil {
-			return errors.WithMessage(err, "error scanning query result, missing 'writability' field")
-		}
-
 		if setting == nil {
-			setting = &Setting{
-				Name:        name,
-				Value:       value,
-				Min:         minV,
-				Max:         maxV,
-				Writability: writability,
+			setting, err = settingFromRow(name, data)
+			if err != nil {
+				return err
 			}
 		}
 
@@ -114,6 +227,7 @@ func (i *impl) GetSetting(ctx context.Context, settingsProfileID string, name st
 }
 
 func (i *impl) DeleteSetting(ctx context.Context, settingsProfileID string, name string, clusterName *string) error {
+	clusterName = i.withDefaultCluster(clusterName)
 	settingsProfile, err := i.GetSettingsProfile(ctx, settingsProfileID, clusterName)
 	if err != nil {
 		return errors.WithMessage(err, "error getting settings profile")
@@ -131,10 +245,83 @@ func (i *impl) DeleteSetting(ctx context.Context, settingsProfileID string, name
 		return errors.WithMessage(err, "error building query")
 	}
 
-	err = i.clickhouseClient.Exec(ctx, sql)
+	err = i.execWithContext(ctx, sql, clusterName)
 	if err != nil {
-		return errors.WithMessage(err, "error running query")
+		return err
 	}
 
 	return nil
 }
+
+// settingFromRow reads the value, min, max and writability of a setting out of a system.settings_profile_elements row.
+func settingFromRow(name string, data clickhouseclient.Row) (*Setting, error) {
+	value, err := data.GetNullableString("value")
+	if err != nil {
+		return nil, errors.WithMessage(err, "error scanning query result, missing 'value' field")
+	}
+
+	minV, err := data.GetNullableString("min")
+	if err != nil {
+		return nil, errors.WithMessage(err, "error scanning query result, missing 'min' field")
+	}
+
+	maxV, err := data.GetNullableString("max")
+	if err != nil {
+		return nil, errors.WithMessage(err, "error scanning query result, missing 'max' field")
+	}
+
+	writability, err := data.GetNullableString("writability")
+	if err != nil {
+		return nil, errors.WithMessage(err, "error scanning query result, missing 'writability' field")
+	}
+
+	return &Setting{
+		Name:        name,
+		Value:       value,
+		Min:         minV,
+		Max:         maxV,
+		Writability: writability,
+	}, nil
+}
+
+func (s Setting) equal(other Setting) bool {
+	return s.Name == other.Name &&
+		SameSettingValue(s.Value, other.Value) &&
+		SameSettingValue(s.Min, other.Min) &&
+		SameSettingValue(s.Max, other.Max) &&
+		equalStringPtr(s.Writability, other.Writability)
+}
+
+// boolSettingValues are the values ClickHouse reports for boolean settings set to true or false.
+var boolSettingValues = map[string]string{
+	"true":  "1",
+	"false": "0",
+}
+
+// SameSettingValue returns true if both values of a setting are the same once stored by ClickHouse.
+// Boolean settings set to true or false are reported as 1 or 0, in any case.
+func SameSettingValue(a *string, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	return normalizeSettingValue(*a) == normalizeSettingValue(*b)
+}
+
+// SettingValue returns the configured value of a setting if ClickHouse reports it as the given value, so that the
+// configured spelling is kept, e.g. true instead of 1. Otherwise the reported value is returned.
+func SettingValue(configured *string, reported *string) *string {
+	if SameSettingValue(configured, reported) {
+		return configured
+	}
+
+	return reported
+}
+
+func normalizeSettingValue(value string) string {
+	if normalized, ok := boolSettingValues[strings.ToLower(value)]; ok {
+		return normalized
+	}
+
+	return value
+}
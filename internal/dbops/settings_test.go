@@ -0,0 +1,270 @@
+package dbops
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/querybuilder"
+)
+
+func Test_ValidateSetting(t *testing.T) {
+	value := "1000"
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		setting Setting
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "Structural validation only",
+			setting: Setting{Name: "max_threads", Value: &value},
+			want:    nil,
+		},
+		{
+			name:    "Server side validation",
+			opts:    []Option{WithSQLValidation()},
+			setting: Setting{Name: "max_threads", Value: &value},
+			want:    []string{"EXPLAIN AST ALTER SETTINGS PROFILE `validation` ADD SETTINGS `max_threads` = '1000'"},
+		},
+		{
+			name:    "Invalid setting is not sent to the server",
+			opts:    []Option{WithSQLValidation()},
+			setting: Setting{Name: "max_threads"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var selects []string
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					selects = append(selects, qry)
+					return nil
+				},
+			}
+
+			client, err := NewClient(fake, tt.opts...)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			err = client.ValidateSetting(context.Background(), tt.setting, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateSetting() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if len(selects) != len(tt.want) {
+				t.Fatalf("ValidateSetting() ran %v, want %v", selects, tt.want)
+			}
+			for i := range tt.want {
+				if selects[i] != tt.want[i] {
+					t.Errorf("ValidateSetting() ran %q, want %q", selects[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func Test_typedSettings(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+
+	// Types of the settings in system.settings. Custom settings, like SQL_tenant, are not listed.
+	settingTypes := map[string]string{
+		"log_queries":      "Bool",
+		"max_memory_usage": "UInt64",
+		"max_threads":      "MaxThreads",
+		"log_comment":      "String",
+	}
+
+	tests := []struct {
+		name    string
+		setting Setting
+		want    string
+	}{
+		{
+			name:    "Boolean setting",
+			setting: Setting{Name: "log_queries", Value: strPtr("1")},
+			want:    "ALTER ROLE `reader` ADD SETTINGS `log_queries` = 1;",
+		},
+		{
+			name:    "Boolean setting set to true",
+			setting: Setting{Name: "log_queries", Value: strPtr("true")},
+			want:    "ALTER ROLE `reader` ADD SETTINGS `log_queries` = true;",
+		},
+		{
+			name:    "Numeric setting with min and max",
+			setting: Setting{Name: "max_memory_usage", Value: strPtr("1000"), Min: strPtr("0"), Max: strPtr("2000"), Writability: strPtr("CONST")},
+			want:    "ALTER ROLE `reader` ADD SETTINGS `max_memory_usage` = 1000 MIN 0 MAX 2000 CONST;",
+		},
+		{
+			name:    "Numeric setting with a non numeric value",
+			setting: Setting{Name: "max_threads", Value: strPtr("auto")},
+			want:    "ALTER ROLE `reader` ADD SETTINGS `max_threads` = 'auto';",
+		},
+		{
+			name:    "String setting with a numeric looking value stays a string",
+			setting: Setting{Name: "log_comment", Value: strPtr("42")},
+			want:    "ALTER ROLE `reader` ADD SETTINGS `log_comment` = '42';",
+		},
+		{
+			name:    "Custom setting with a numeric looking value stays a string",
+			setting: Setting{Name: "SQL_tenant", Value: strPtr("42")},
+			want:    "ALTER ROLE `reader` ADD SETTINGS `SQL_tenant` = '42';",
+		},
+		{
+			name:    "Raw setting",
+			setting: Setting{Name: "SQL_tenant", Value: strPtr("toString(42)"), Raw: true},
+			want:    "ALTER ROLE `reader` ADD SETTINGS `SQL_tenant` = toString(42);",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					if !strings.Contains(qry, "`system`.`settings`") {
+						return nil
+					}
+
+					rows := make([]clickhouseclient.Row, 0)
+					for name, settingType := range settingTypes {
+						if strings.Contains(qry, "'"+name+"'") {
+							row := clickhouseclient.Row{}
+							row.Set("name", name)
+							row.Set("type", settingType)
+							rows = append(rows, row)
+						}
+					}
+					return rows
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			settings, err := client.(*impl).typedSettings(context.Background(), []Setting{tt.setting})
+			if err != nil {
+				t.Fatalf("typedSettings() error = %v", err)
+			}
+
+			got, err := addSetting(querybuilder.NewAlterRole("reader"), settings[0]).Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("typedSettings() query = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_SameSettingValue(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name string
+		a    *string
+		b    *string
+		want bool
+	}{
+		{
+			name: "Both nil",
+			want: true,
+		},
+		{
+			name: "One nil",
+			a:    strPtr("1"),
+			want: false,
+		},
+		{
+			name: "Same numeric value",
+			a:    strPtr("4"),
+			b:    strPtr("4"),
+			want: true,
+		},
+		{
+			name: "Different numeric value",
+			a:    strPtr("4"),
+			b:    strPtr("8"),
+			want: false,
+		},
+		{
+			name: "True is reported as 1",
+			a:    strPtr("true"),
+			b:    strPtr("1"),
+			want: true,
+		},
+		{
+			name: "False is reported as 0",
+			a:    strPtr("FALSE"),
+			b:    strPtr("0"),
+			want: true,
+		},
+		{
+			name: "True is not 0",
+			a:    strPtr("true"),
+			b:    strPtr("0"),
+			want: false,
+		},
+		{
+			name: "Other strings are compared as they are",
+			a:    strPtr("Enabled"),
+			b:    strPtr("enabled"),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SameSettingValue(tt.a, tt.b); got != tt.want {
+				t.Errorf("SameSettingValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_SettingValue(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name       string
+		configured *string
+		reported   *string
+		want       *string
+	}{
+		{
+			name:       "Configured boolean is kept",
+			configured: strPtr("true"),
+			reported:   strPtr("1"),
+			want:       strPtr("true"),
+		},
+		{
+			name:       "Changed value is reported",
+			configured: strPtr("true"),
+			reported:   strPtr("0"),
+			want:       strPtr("0"),
+		},
+		{
+			name:     "Nothing configured",
+			reported: strPtr("1"),
+			want:     strPtr("1"),
+		},
+		{
+			name:       "Value removed on the server",
+			configured: strPtr("1"),
+			want:       nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SettingValue(tt.configured, tt.reported)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("SettingValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package dbops
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"github.com/pingcap/errors"
 
@@ -14,36 +15,94 @@ type SettingsProfile struct {
 	ID          string   `json:"id"`
 	Name        string   `json:"name"`
 	InheritFrom []string `json:"-"`
+	// ApplyToAll makes the profile apply to all users and roles but the ones in ApplyToExcept.
+	// When ApplyToAll is false and both ApplyTo and ApplyToExcept are nil, the users and roles the profile applies
+	// to are not changed by UpdateSettingsProfile.
+	ApplyToAll    bool     `json:"apply_to_all"`
+	ApplyTo       []string `json:"apply_to_list"`
+	ApplyToExcept []string `json:"apply_to_except"`
+	// Settings are the settings of the profile, in the order they are applied.
+	// When nil, settings are not changed by UpdateSettingsProfile.
+	Settings []Setting `json:"-"`
+}
+
+// SettingsProfileAssociations are the users and roles inheriting a settings profile, i.e. associated to it with
+// ALTER USER/ROLE ... SETTINGS PROFILE.
+type SettingsProfileAssociations struct {
+	Users []string
+	Roles []string
+}
+
+// managesApplyTo returns false when the users and roles the profile applies to are left as they are.
+func (p *SettingsProfile) managesApplyTo() bool {
+	return p.ApplyToAll || p.ApplyTo != nil || p.ApplyToExcept != nil
+}
+
+func (p *SettingsProfile) applyTo() querybuilder.ApplyTo {
+	return querybuilder.ApplyTo{
+		All:    p.ApplyToAll,
+		Names:  p.ApplyTo,
+		Except: p.ApplyToExcept,
+	}
 }
 
 func (i *impl) CreateSettingsProfile(ctx context.Context, profile SettingsProfile, clusterName *string) (*SettingsProfile, error) {
-	sql, err := querybuilder.
+	clusterName = i.withDefaultCluster(clusterName)
+	if err := i.checkReservedSettingsProfile(profile.Name); err != nil {
+		return nil, err
+	}
+
+	settings, err := i.typedSettings(ctx, profile.Settings)
+	if err != nil {
+		return nil, err
+	}
+
+	q := querybuilder.
 		NewCreateSettingsProfile(profile.Name).
 		WithCluster(clusterName).
 		InheritFrom(profile.InheritFrom).
-		Build()
+		To(profile.applyTo())
+
+	for _, setting := range settings {
+		q = addSetting(q, setting)
+	}
+
+	sql, err := q.Build()
 	if err != nil {
 		return nil, errors.WithMessage(err, "error building query")
 	}
 
-	err = i.clickhouseClient.Exec(ctx, sql)
+	err = i.execWithContext(ctx, sql, clusterName)
 	if err != nil {
-		return nil, errors.WithMessage(err, "error running query")
+		return nil, err
 	}
 
-	return i.FindSettingsProfileByName(ctx, profile.Name, clusterName)
+	return readAfterCreate(ctx, i, func() (*SettingsProfile, error) {
+		return i.FindSettingsProfileByName(ctx, profile.Name, clusterName)
+	})
 }
 
 func (i *impl) GetSettingsProfile(ctx context.Context, id string, clusterName *string) (*SettingsProfile, error) {
+	clusterName = i.withDefaultCluster(clusterName)
+	return i.getSettingsProfile(ctx, id, clusterName, i.clusterReads)
+}
+
+// getSettingsProfile returns the settings profile with the given id, reading the replicas of the cluster as set by
+// clusterReads.
+func (i *impl) getSettingsProfile(ctx context.Context, id string, clusterName *string, clusterReads querybuilder.ClusterReads) (*SettingsProfile, error) {
 	var profile *SettingsProfile
 
 	sql, err := querybuilder.
 		NewSelect(
 			[]querybuilder.Field{
 				querybuilder.NewField("name"),
+				querybuilder.NewField("apply_to_all"),
+				querybuilder.NewField("apply_to_list"),
+				querybuilder.NewField("apply_to_except"),
 			},
 			"system.settings_profiles",
 		).
+		WithClusterReads(clusterReads).
 		WithCluster(clusterName).
 		Where(querybuilder.WhereEquals("id", id)).
 		Build()
@@ -57,10 +116,26 @@ func (i *impl) GetSettingsProfile(ctx context.Context, id string, clusterName *s
 			return errors.WithMessage(err, "error scanning query result, missing 'name' field")
 		}
 
+		applyToAll, err := data.GetBool("apply_to_all")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'apply_to_all' field")
+		}
+		applyTo, err := data.GetStringSlice("apply_to_list")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'apply_to_list' field")
+		}
+		applyToExcept, err := data.GetStringSlice("apply_to_except")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'apply_to_except' field")
+		}
+
 		if profile == nil {
 			profile = &SettingsProfile{
-				ID:   id,
-				Name: name,
+				ID:            id,
+				Name:          name,
+				ApplyToAll:    applyToAll,
+				ApplyTo:       applyTo,
+				ApplyToExcept: applyToExcept,
 			}
 		}
 
@@ -75,16 +150,27 @@ func (i *impl) GetSettingsProfile(ctx context.Context, id string, clusterName *s
 		return nil, nil
 	}
 
-	// Check roles this profile is inheriting from.
+	// Check roles this profile is inheriting from and its settings.
+	// 'index' is only used to return the inherited profiles and the settings in the order they were declared, it is
+	// not exposed because ClickHouse doesn't allow choosing the position of the elements of a settings profile.
 	{
 		sql, err := querybuilder.
-			NewSelect([]querybuilder.Field{querybuilder.NewField("inherit_profile")}, "system.settings_profile_elements").
+			NewSelect([]querybuilder.Field{
+				querybuilder.NewField("inherit_profile"),
+				querybuilder.NewField("setting_name"),
+				querybuilder.NewField("value"),
+				querybuilder.NewField("min"),
+				querybuilder.NewField("max"),
+				querybuilder.NewField("writability").ToString(),
+			}, "system.settings_profile_elements").
+			WithClusterReads(clusterReads).
 			Where(querybuilder.WhereEquals("profile_name", profile.Name)).
 			OrderBy(querybuilder.NewField("index"), querybuilder.ASC).
 			Build()
 		if err != nil {
 			return nil, errors.WithMessage(err, "error building query")
 		}
+		profile.Settings = make([]Setting, 0)
 		err = i.clickhouseClient.Select(ctx, sql, func(data clickhouseclient.Row) error {
 			inheritedProfileName, err := data.GetNullableString("inherit_profile")
 			if err != nil {
@@ -95,6 +181,19 @@ func (i *impl) GetSettingsProfile(ctx context.Context, id string, clusterName *s
 				profile.InheritFrom = append(profile.InheritFrom, *inheritedProfileName)
 			}
 
+			settingName, err := data.GetNullableString("setting_name")
+			if err != nil {
+				return errors.WithMessage(err, "error scanning query result, missing 'setting_name' field")
+			}
+
+			if settingName != nil {
+				setting, err := settingFromRow(*settingName, data)
+				if err != nil {
+					return err
+				}
+				profile.Settings = append(profile.Settings, *setting)
+			}
+
 			return nil
 		})
 		if err != nil {
@@ -105,7 +204,54 @@ func (i *impl) GetSettingsProfile(ctx context.Context, id string, clusterName *s
 	return profile, nil
 }
 
+// ListSettingsProfileAssociations returns the users and roles associated to the settings profile with the given name.
+func (i *impl) ListSettingsProfileAssociations(ctx context.Context, profileName string, clusterName *string) (*SettingsProfileAssociations, error) {
+	clusterName = i.withDefaultCluster(clusterName)
+	sql, err := i.
+		newSelect([]querybuilder.Field{
+			querybuilder.NewField("user_name"),
+			querybuilder.NewField("role_name"),
+		}, "system.settings_profile_elements").
+		WithCluster(clusterName).
+		Where(querybuilder.WhereEquals("inherit_profile", profileName)).
+		Build()
+	if err != nil {
+		return nil, errors.WithMessage(err, "error building query")
+	}
+
+	associations := &SettingsProfileAssociations{
+		Users: make([]string, 0),
+		Roles: make([]string, 0),
+	}
+	err = i.clickhouseClient.Select(ctx, sql, func(data clickhouseclient.Row) error {
+		userName, err := data.GetNullableString("user_name")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'user_name' field")
+		}
+		roleName, err := data.GetNullableString("role_name")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'role_name' field")
+		}
+
+		// Profiles inheriting this one have neither.
+		if userName != nil && !slices.Contains(associations.Users, *userName) {
+			associations.Users = append(associations.Users, *userName)
+		}
+		if roleName != nil && !slices.Contains(associations.Roles, *roleName) {
+			associations.Roles = append(associations.Roles, *roleName)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "error running query")
+	}
+
+	return associations, nil
+}
+
 func (i *impl) DeleteSettingsProfile(ctx context.Context, id string, clusterName *string) error {
+	clusterName = i.withDefaultCluster(clusterName)
 	profile, err := i.GetSettingsProfile(ctx, id, clusterName)
 	if err != nil {
 		return errors.WithMessage(err, "error looking up settings profile name")
@@ -116,20 +262,26 @@ func (i *impl) DeleteSettingsProfile(ctx context.Context, id string, clusterName
 		return nil
 	}
 
-	sql, err := querybuilder.NewDropSettingsProfile(profile.Name).WithCluster(clusterName).Build()
+	if err := i.checkReservedSettingsProfile(profile.Name); err != nil {
+		return err
+	}
+
+	// The settings profile may be dropped by someone else in the meantime.
+	sql, err := querybuilder.NewDropSettingsProfile(profile.Name).WithCluster(clusterName).IfExists().Build()
 	if err != nil {
 		return errors.WithMessage(err, "error building query")
 	}
 
-	err = i.clickhouseClient.Exec(ctx, sql)
+	err = i.execWithContext(ctx, sql, clusterName)
 	if err != nil {
-		return errors.WithMessage(err, "error running query")
+		return err
 	}
 
 	return nil
 }
 
 func (i *impl) UpdateSettingsProfile(ctx context.Context, settingsProfile SettingsProfile, clusterName *string) (*SettingsProfile, error) {
+	clusterName = i.withDefaultCluster(clusterName)
 	// Retrieve current setting profile
 	existing, err := i.GetSettingsProfile(ctx, settingsProfile.ID, clusterName)
 	if err != nil {
@@ -140,25 +292,78 @@ func (i *impl) UpdateSettingsProfile(ctx context.Context, settingsProfile Settin
 		return nil, nil
 	}
 
-	sql, err := querybuilder.
+	if err := i.checkRename("settings profile", existing.Name, settingsProfile.Name); err != nil {
+		return nil, err
+	}
+
+	if existing.Name != settingsProfile.Name {
+		for _, name := range []string{existing.Name, settingsProfile.Name} {
+			if err := i.checkReservedSettingsProfile(name); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := i.checkExistsOnAllReplicas(ctx, AccessEntitySettingsProfile, existing.Name, clusterName); err != nil {
+		return nil, err
+	}
+
+	q := querybuilder.
 		NewAlterSettingsProfile(existing.Name).
 		WithCluster(clusterName).
 		InheritFrom(settingsProfile.InheritFrom).
-		RenameTo(&settingsProfile.Name).
-		Build()
+		RenameTo(&settingsProfile.Name)
+
+	if settingsProfile.Settings != nil && !slices.EqualFunc(existing.Settings, settingsProfile.Settings, Setting.equal) {
+		// Settings are dropped and added back in the desired order, because ClickHouse appends the added settings
+		// to the elements of the profile.
+		for _, setting := range existing.Settings {
+			q = q.RemoveSetting(setting.Name)
+		}
+		settings, err := i.typedSettings(ctx, settingsProfile.Settings)
+		if err != nil {
+			return nil, err
+		}
+		for _, setting := range settings {
+			q = addSetting(q, setting)
+		}
+	}
+
+	if settingsProfile.managesApplyTo() && (existing.ApplyToAll != settingsProfile.ApplyToAll ||
+		!sameElements(existing.ApplyTo, settingsProfile.ApplyTo) ||
+		!sameElements(existing.ApplyToExcept, settingsProfile.ApplyToExcept)) {
+		q = q.To(settingsProfile.applyTo())
+	}
+
+	sql, err := q.Build()
 	if err != nil {
 		return nil, errors.WithMessage(err, "error building query")
 	}
 
-	err = i.clickhouseClient.Exec(ctx, sql)
+	err = i.execWithContext(ctx, sql, clusterName)
 	if err != nil {
-		return nil, errors.WithMessage(err, "error running query")
+		return nil, err
 	}
 
 	return i.GetSettingsProfile(ctx, settingsProfile.ID, clusterName)
 }
 
 func (i *impl) AssociateSettingsProfile(ctx context.Context, id string, roleId *string, userId *string, clusterName *string) error {
+	clusterName = i.withDefaultCluster(clusterName)
+	return i.AssociateSettingsProfiles(ctx, []string{id}, roleId, userId, clusterName)
+}
+
+// AssociateSettingsProfiles adds the settings profiles with the given IDs to the role or user, in a single
+// ADD PROFILES clause. The profiles already associated to it are skipped.
+func (i *impl) AssociateSettingsProfiles(ctx context.Context, ids []string, roleId *string, userId *string, clusterName *string) error {
+	clusterName = i.withDefaultCluster(clusterName)
+	return i.changeSettingsProfiles(ctx, ids, roleId, userId, true, clusterName)
+}
+
+// ReplaceRoleSettingsProfiles runs ALTER ROLE ... SETTINGS PROFILE, which replaces all the settings profiles and
+// settings of the role, unlike AssociateSettingsProfile which adds the profile to the existing ones.
+func (i *impl) ReplaceRoleSettingsProfiles(ctx context.Context, id string, roleId string, clusterName *string) error {
+	clusterName = i.withDefaultCluster(clusterName)
 	profile, err := i.GetSettingsProfile(ctx, id, clusterName)
 	if err != nil {
 		return errors.WithMessage(err, "error looking up settings profile name")
@@ -168,67 +373,59 @@ func (i *impl) AssociateSettingsProfile(ctx context.Context, id string, roleId *
 		return errors.New("No Settings Profile with such ID found")
 	}
 
-	if roleId != nil {
-		role, err := i.GetRole(ctx, *roleId, clusterName)
-		if err != nil {
-			return errors.WithMessage(err, "Cannot find role")
-		}
-
-		if role == nil {
-			return errors.New("role not found")
-		}
-		sql, err := querybuilder.
-			NewAlterRole(role.Name).
-			WithCluster(clusterName).
-			AddSettingsProfile(&profile.Name).
-			Build()
-		if err != nil {
-			return errors.WithMessage(err, "Error building query")
-		}
-
-		err = i.clickhouseClient.Exec(ctx, sql)
-		if err != nil {
-			return errors.WithMessage(err, "error running query")
-		}
-
-		return nil
-	} else if userId != nil {
-		user, err := i.resolveUserName(ctx, *userId, clusterName)
-		if err != nil {
-			return errors.WithMessage(err, "error resolving user")
-		}
-		if user == "" {
-			return errors.New("Cannot find user")
-		}
+	role, err := i.GetRole(ctx, roleId, clusterName)
+	if err != nil {
+		return errors.WithMessage(err, "Cannot find role")
+	}
 
-		sql, err := querybuilder.
-			NewAlterUser(user).
-			WithCluster(clusterName).
-			AddSettingsProfile(&profile.Name).
-			Build()
-		if err != nil {
-			return errors.WithMessage(err, "Error building query")
-		}
+	if role == nil {
+		return errors.New("role not found")
+	}
 
-		err = i.clickhouseClient.Exec(ctx, sql)
-		if err != nil {
-			return errors.WithMessage(err, "error running query")
-		}
+	sql, err := querybuilder.
+		NewAlterRole(role.Name).
+		WithCluster(clusterName).
+		SetSettingsProfile(&profile.Name).
+		Build()
+	if err != nil {
+		return errors.WithMessage(err, "Error building query")
+	}
 
-		return nil
+	err = i.execWithContext(ctx, sql, clusterName)
+	if err != nil {
+		return err
 	}
 
-	return errors.New("Neither roleId nor userId were specified")
+	return nil
 }
 
 func (i *impl) DisassociateSettingsProfile(ctx context.Context, id string, roleId *string, userId *string, clusterName *string) error {
-	profile, err := i.GetSettingsProfile(ctx, id, clusterName)
-	if err != nil {
-		return errors.WithMessage(err, "error looking up settings profile name")
-	}
+	clusterName = i.withDefaultCluster(clusterName)
+	return i.DisassociateSettingsProfiles(ctx, []string{id}, roleId, userId, clusterName)
+}
 
-	if profile == nil {
-		return errors.New("No Settings Profile with such ID found")
+// DisassociateSettingsProfiles drops the settings profiles with the given IDs from the role or user, in a single
+// DROP PROFILES clause. The profiles not associated to it anymore are skipped.
+func (i *impl) DisassociateSettingsProfiles(ctx context.Context, ids []string, roleId *string, userId *string, clusterName *string) error {
+	clusterName = i.withDefaultCluster(clusterName)
+	return i.changeSettingsProfiles(ctx, ids, roleId, userId, false, clusterName)
+}
+
+// changeSettingsProfiles adds (associate) or drops (!associate) the settings profiles with the given IDs to or from
+// the role or user, diffing them against the profiles it currently has.
+func (i *impl) changeSettingsProfiles(ctx context.Context, ids []string, roleId *string, userId *string, associate bool, clusterName *string) error {
+	names := make([]string, 0)
+	for _, id := range ids {
+		profile, err := i.GetSettingsProfile(ctx, id, clusterName)
+		if err != nil {
+			return errors.WithMessage(err, "error looking up settings profile name")
+		}
+
+		if profile == nil {
+			return errors.New("No Settings Profile with such ID found")
+		}
+
+		names = append(names, profile.Name)
 	}
 
 	if roleId != nil {
@@ -241,42 +438,68 @@ func (i *impl) DisassociateSettingsProfile(ctx context.Context, id string, roleI
 			return errors.New("role not found")
 		}
 
-		sql, err := querybuilder.
-			NewAlterRole(role.Name).
-			WithCluster(clusterName).
-			DropSettingsProfile(&profile.Name).
-			Build()
+		changes := settingsProfilesToChange(names, role.SettingsProfiles, associate)
+		if len(changes) == 0 {
+			return nil
+		}
+
+		q := querybuilder.NewAlterRole(role.Name).WithCluster(clusterName)
+		if associate {
+			q = q.AddSettingsProfiles(changes)
+		} else {
+			q = q.DropSettingsProfiles(changes)
+		}
+
+		sql, err := q.Build()
 		if err != nil {
 			return errors.WithMessage(err, "Error building query")
 		}
 
-		err = i.clickhouseClient.Exec(ctx, sql)
+		err = i.execWithContext(ctx, sql, clusterName)
 		if err != nil {
-			return errors.WithMessage(err, "error running query")
+			return err
 		}
 
 		return nil
 	} else if userId != nil {
-		user, err := i.resolveUserName(ctx, *userId, clusterName)
+		userName, err := i.resolveUserName(ctx, *userId, clusterName)
 		if err != nil {
 			return errors.WithMessage(err, "error resolving user")
 		}
-		if user == "" {
+		if userName == "" {
 			return errors.New("Cannot find user")
 		}
 
-		sql, err := querybuilder.
-			NewAlterUser(user).
-			WithCluster(clusterName).
-			DropSettingsProfile(&profile.Name).
-			Build()
+		defer i.lockUser(userName, clusterName)()
+
+		user, err := i.GetUserByName(ctx, userName, clusterName)
+		if err != nil {
+			return errors.WithMessage(err, "error getting user")
+		}
+		if user == nil {
+			return errors.New("Cannot find user")
+		}
+
+		changes := settingsProfilesToChange(names, user.SettingsProfiles, associate)
+		if len(changes) == 0 {
+			return nil
+		}
+
+		q := querybuilder.NewAlterUser(user.Name).WithCluster(clusterName)
+		if associate {
+			q = q.AddSettingsProfiles(changes)
+		} else {
+			q = q.DropSettingsProfiles(changes)
+		}
+
+		sql, err := q.Build()
 		if err != nil {
 			return errors.WithMessage(err, "Error building query")
 		}
 
-		err = i.clickhouseClient.Exec(ctx, sql)
+		err = i.execWithContext(ctx, sql, clusterName)
 		if err != nil {
-			return errors.WithMessage(err, "error running query")
+			return err
 		}
 
 		return nil
@@ -285,7 +508,30 @@ func (i *impl) DisassociateSettingsProfile(ctx context.Context, id string, roleI
 	return errors.New("Neither roleId nor userId were specified")
 }
 
+// settingsProfilesToChange returns the profiles that need to be added (associate) or dropped (!associate), given the
+// ones currently associated. When the current profiles couldn't be read (nil), all of them are returned.
+func settingsProfilesToChange(names []string, current []string, associate bool) []string {
+	changes := make([]string, 0)
+	for _, name := range names {
+		if slices.Contains(changes, name) {
+			continue
+		}
+		if current == nil || slices.Contains(current, name) != associate {
+			changes = append(changes, name)
+		}
+	}
+
+	return changes
+}
+
 func (i *impl) FindSettingsProfileByName(ctx context.Context, name string, clusterName *string) (*SettingsProfile, error) {
+	clusterName = i.withDefaultCluster(clusterName)
+	return i.findSettingsProfileByName(ctx, name, clusterName, i.clusterReads)
+}
+
+// findSettingsProfileByName returns the settings profile with the given name, reading the replicas of the cluster as
+// set by clusterReads.
+func (i *impl) findSettingsProfileByName(ctx context.Context, name string, clusterName *string, clusterReads querybuilder.ClusterReads) (*SettingsProfile, error) {
 	sql, err := querybuilder.
 		NewSelect(
 			[]querybuilder.Field{
@@ -293,6 +539,7 @@ func (i *impl) FindSettingsProfileByName(ctx context.Context, name string, clust
 			},
 			"system.settings_profiles",
 		).
+		WithClusterReads(clusterReads).
 		WithCluster(clusterName).
 		Where(querybuilder.WhereEquals("name", name)).
 		Build()
@@ -320,11 +567,15 @@ func (i *impl) FindSettingsProfileByName(ctx context.Context, name string, clust
 		return nil, errors.New(fmt.Sprintf("settings profile with name %s not found", name))
 	}
 
-	return i.GetSettingsProfile(ctx, settingsProfileID, clusterName)
+	return i.getSettingsProfile(ctx, settingsProfileID, clusterName, clusterReads)
 }
 
+// GetSettingsProfileByName looks the settings profile up on all the replicas of the cluster, whatever the cluster
+// reads mode, so that it is found even if the replica answering the query doesn't have it, e.g. without replicated
+// access storage.
 func (i *impl) GetSettingsProfileByName(ctx context.Context, name string, clusterName *string) (*SettingsProfile, error) {
-	return i.FindSettingsProfileByName(ctx, name, clusterName)
+	clusterName = i.withDefaultCluster(clusterName)
+	return i.findSettingsProfileByName(ctx, name, clusterName, querybuilder.ClusterReadsAllReplicas)
 }
 
 // ALTER USER/ROLE IF EXISTS ... SETTINGS PROFILE <name>
@@ -335,6 +586,7 @@ func (i *impl) AssociateSettingsProfileByName(
 	userId *string,
 	clusterName *string,
 ) error {
+	clusterName = i.withDefaultCluster(clusterName)
 	if profileName == "" {
 		return errors.New("profile name must be provided")
 	}
@@ -352,6 +604,8 @@ func (i *impl) AssociateSettingsProfileByName(
 			return errors.New("Cannot find user")
 		}
 
+		defer i.lockUser(u, clusterName)()
+
 		sqlStr, err := querybuilder.NewAlterUser(u).
 			IfExists().
 			WithCluster(clusterName).
@@ -360,7 +614,7 @@ func (i *impl) AssociateSettingsProfileByName(
 		if err != nil {
 			return errors.WithMessage(err, "Error building legacy ALTER USER ... SETTINGS PROFILE query")
 		}
-		return errors.WithMessage(i.clickhouseClient.Exec(ctx, sqlStr), "error running legacy ALTER USER ... SETTINGS PROFILE query")
+		return errors.WithMessage(i.execWithContext(ctx, sqlStr, clusterName), "error running legacy ALTER USER ... SETTINGS PROFILE query")
 	}
 
 	// ROLE path (legacy, 23.4)
@@ -381,8 +635,17 @@ func (i *impl) AssociateSettingsProfileByName(
 		if err != nil {
 			return errors.WithMessage(err, "Error building legacy ALTER ROLE ... SETTINGS PROFILE query")
 		}
-		return errors.WithMessage(i.clickhouseClient.Exec(ctx, sqlStr), "error running legacy ALTER ROLE ... SETTINGS PROFILE query")
+		return errors.WithMessage(i.execWithContext(ctx, sqlStr, clusterName), "error running legacy ALTER ROLE ... SETTINGS PROFILE query")
 	}
 
 	return errors.New("Neither roleId nor userId were specified")
 }
+
+// sameElements returns true if a and b contain the same strings, regardless of their order.
+func sameElements(a []string, b []string) bool {
+	a = slices.Clone(a)
+	b = slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
@@ -0,0 +1,559 @@
+package dbops
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+)
+
+func Test_UpdateSettingsProfile_settings(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+
+	elementRow := func(inheritProfile *string, settingName *string, value *string) clickhouseclient.Row {
+		row := clickhouseclient.Row{}
+		row.Set("inherit_profile", inheritProfile)
+		row.Set("setting_name", settingName)
+		row.Set("value", value)
+		row.Set("min", (*string)(nil))
+		row.Set("max", (*string)(nil))
+		row.Set("writability", (*string)(nil))
+		return row
+	}
+
+	tests := []struct {
+		name      string
+		settings  []Setting
+		wantAlter string
+	}{
+		{
+			name:      "Settings not managed",
+			settings:  nil,
+			wantAlter: "ALTER SETTINGS PROFILE `prf1` DROP ALL PROFILES INHERIT `default`;",
+		},
+		{
+			name: "Settings unchanged",
+			settings: []Setting{
+				{Name: "max_threads", Value: strPtr("4")},
+				{Name: "readonly", Value: strPtr("1")},
+			},
+			wantAlter: "ALTER SETTINGS PROFILE `prf1` DROP ALL PROFILES INHERIT `default`;",
+		},
+		{
+			name: "Settings reordered",
+			settings: []Setting{
+				{Name: "readonly", Value: strPtr("1")},
+				{Name: "max_threads", Value: strPtr("4")},
+			},
+			wantAlter: "ALTER SETTINGS PROFILE `prf1` DROP ALL PROFILES DROP SETTINGS `max_threads`, `readonly` ADD SETTINGS `readonly` = '1', `max_threads` = '4' INHERIT `default`;",
+		},
+		{
+			name: "Setting changed",
+			settings: []Setting{
+				{Name: "max_threads", Value: strPtr("8")},
+			},
+			wantAlter: "ALTER SETTINGS PROFILE `prf1` DROP ALL PROFILES DROP SETTINGS `max_threads`, `readonly` ADD SETTINGS `max_threads` = '8' INHERIT `default`;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					switch {
+					case strings.Contains(qry, "`system`.`settings_profiles`"):
+						row := clickhouseclient.Row{}
+						row.Set("name", "prf1")
+						row.Set("apply_to_all", uint8(0))
+						row.Set("apply_to_list", []string{})
+						row.Set("apply_to_except", []string{})
+						return []clickhouseclient.Row{row}
+					case strings.Contains(qry, "`inherit_profile` = 'prf1'"):
+						return nil
+					case strings.Contains(qry, "`system`.`settings_profile_elements`"):
+						return []clickhouseclient.Row{
+							elementRow(strPtr("default"), nil, nil),
+							elementRow(nil, strPtr("max_threads"), strPtr("4")),
+							elementRow(nil, strPtr("readonly"), strPtr("1")),
+						}
+					}
+					return nil
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			_, err = client.UpdateSettingsProfile(context.Background(), SettingsProfile{
+				ID:          "00000000-0000-0000-0000-000000000000",
+				Name:        "prf1",
+				InheritFrom: []string{"default"},
+				Settings:    tt.settings,
+			}, nil)
+			if err != nil {
+				t.Fatalf("UpdateSettingsProfile() error = %v", err)
+			}
+
+			if len(fake.execs) != 1 {
+				t.Fatalf("UpdateSettingsProfile() ran %d queries, want 1", len(fake.execs))
+			}
+			if fake.execs[0] != tt.wantAlter {
+				t.Errorf("UpdateSettingsProfile() query = %q, want %q", fake.execs[0], tt.wantAlter)
+			}
+		})
+	}
+}
+
+func Test_UpdateSettingsProfile_applyTo(t *testing.T) {
+	tests := []struct {
+		name      string
+		profile   SettingsProfile
+		wantAlter string
+	}{
+		{
+			name:      "Apply to not managed",
+			profile:   SettingsProfile{},
+			wantAlter: "ALTER SETTINGS PROFILE `prf1` DROP ALL PROFILES;",
+		},
+		{
+			name:      "Apply to unchanged",
+			profile:   SettingsProfile{ApplyToAll: true, ApplyTo: []string{}, ApplyToExcept: []string{}},
+			wantAlter: "ALTER SETTINGS PROFILE `prf1` DROP ALL PROFILES;",
+		},
+		{
+			name:      "Apply to changed",
+			profile:   SettingsProfile{ApplyTo: []string{"john"}, ApplyToExcept: []string{}},
+			wantAlter: "ALTER SETTINGS PROFILE `prf1` DROP ALL PROFILES TO `john`;",
+		},
+		{
+			name:      "Apply to removed",
+			profile:   SettingsProfile{ApplyTo: []string{}, ApplyToExcept: []string{}},
+			wantAlter: "ALTER SETTINGS PROFILE `prf1` DROP ALL PROFILES TO NONE;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					if strings.Contains(qry, "`system`.`settings_profiles`") {
+						// The profile is applied to all, e.g. by the clickhousedbops_default_settings_profile resource.
+						row := clickhouseclient.Row{}
+						row.Set("name", "prf1")
+						row.Set("apply_to_all", uint8(1))
+						row.Set("apply_to_list", []string{})
+						row.Set("apply_to_except", []string{})
+						return []clickhouseclient.Row{row}
+					}
+					return nil
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			profile := tt.profile
+			profile.ID = "00000000-0000-0000-0000-000000000000"
+			profile.Name = "prf1"
+			_, err = client.UpdateSettingsProfile(context.Background(), profile, nil)
+			if err != nil {
+				t.Fatalf("UpdateSettingsProfile() error = %v", err)
+			}
+
+			if len(fake.execs) != 1 {
+				t.Fatalf("UpdateSettingsProfile() ran %d queries, want 1", len(fake.execs))
+			}
+			if fake.execs[0] != tt.wantAlter {
+				t.Errorf("UpdateSettingsProfile() query = %q, want %q", fake.execs[0], tt.wantAlter)
+			}
+		})
+	}
+}
+
+func Test_ListSettingsProfileAssociations(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+	associationRow := func(userName *string, roleName *string) clickhouseclient.Row {
+		row := clickhouseclient.Row{}
+		row.Set("user_name", userName)
+		row.Set("role_name", roleName)
+		return row
+	}
+
+	var query string
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			query = qry
+			return []clickhouseclient.Row{
+				associationRow(strPtr("john"), nil),
+				associationRow(nil, strPtr("reader")),
+				// Another profile inheriting prf1.
+				associationRow(nil, nil),
+			}
+		},
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	associations, err := client.ListSettingsProfileAssociations(context.Background(), "prf1", nil)
+	if err != nil {
+		t.Fatalf("ListSettingsProfileAssociations() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(associations.Users, []string{"john"}) {
+		t.Errorf("ListSettingsProfileAssociations() Users = %v, want %v", associations.Users, []string{"john"})
+	}
+	if !reflect.DeepEqual(associations.Roles, []string{"reader"}) {
+		t.Errorf("ListSettingsProfileAssociations() Roles = %v, want %v", associations.Roles, []string{"reader"})
+	}
+
+	wantQuery := "SELECT `user_name`, `role_name` FROM `system`.`settings_profile_elements` WHERE (`inherit_profile` = 'prf1');"
+	if query != wantQuery {
+		t.Errorf("ListSettingsProfileAssociations() query = %q, want %q", query, wantQuery)
+	}
+}
+
+func Test_AssociateSettingsProfile_role(t *testing.T) {
+	tests := []struct {
+		name    string
+		replace bool
+		want    string
+	}{
+		{
+			name:    "Profile added to the existing ones",
+			replace: false,
+			want:    "ALTER ROLE `reader` ADD PROFILE 'prf1';",
+		},
+		{
+			name:    "Existing profiles replaced",
+			replace: true,
+			want:    "ALTER ROLE `reader` SETTINGS PROFILE 'prf1';",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					row := clickhouseclient.Row{}
+					switch {
+					case strings.Contains(qry, "`system`.`settings_profiles`"):
+						row.Set("name", "prf1")
+						row.Set("apply_to_all", uint8(0))
+						row.Set("apply_to_list", []string{})
+						row.Set("apply_to_except", []string{})
+					case strings.Contains(qry, "`system`.`roles`"):
+						row.Set("name", "reader")
+					default:
+						return nil
+					}
+					return []clickhouseclient.Row{row}
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			roleID := "11111111-1111-1111-1111-111111111111"
+			if tt.replace {
+				err = client.ReplaceRoleSettingsProfiles(context.Background(), "00000000-0000-0000-0000-000000000000", roleID, nil)
+			} else {
+				err = client.AssociateSettingsProfile(context.Background(), "00000000-0000-0000-0000-000000000000", &roleID, nil, nil)
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+
+			if !reflect.DeepEqual(fake.execs, []string{tt.want}) {
+				t.Errorf("queries = %q, want %q", fake.execs, []string{tt.want})
+			}
+		})
+	}
+}
+
+func Test_AssociateSettingsProfiles_role(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+
+	profileIDs := map[string]string{
+		"00000000-0000-0000-0000-00000000000a": "a",
+		"00000000-0000-0000-0000-00000000000b": "b",
+		"00000000-0000-0000-0000-00000000000c": "c",
+	}
+
+	elementRow := func(inheritProfile string) clickhouseclient.Row {
+		row := clickhouseclient.Row{}
+		row.Set("inherit_profile", strPtr(inheritProfile))
+		row.Set("setting_name", (*string)(nil))
+		row.Set("value", (*string)(nil))
+		row.Set("min", (*string)(nil))
+		row.Set("max", (*string)(nil))
+		row.Set("writability", (*string)(nil))
+		return row
+	}
+
+	tests := []struct {
+		name      string
+		ids       []string
+		associate bool
+		want      []string
+	}{
+		{
+			name:      "Only missing profiles are added",
+			ids:       []string{"00000000-0000-0000-0000-00000000000a", "00000000-0000-0000-0000-00000000000c"},
+			associate: true,
+			want:      []string{"ALTER ROLE `reader` ADD PROFILE 'c';"},
+		},
+		{
+			name:      "All profiles already associated",
+			ids:       []string{"00000000-0000-0000-0000-00000000000a", "00000000-0000-0000-0000-00000000000b"},
+			associate: true,
+			want:      nil,
+		},
+		{
+			name:      "Only associated profiles are dropped",
+			ids:       []string{"00000000-0000-0000-0000-00000000000a", "00000000-0000-0000-0000-00000000000b", "00000000-0000-0000-0000-00000000000c"},
+			associate: false,
+			want:      []string{"ALTER ROLE `reader` DROP PROFILES 'a', 'b';"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					switch {
+					case strings.Contains(qry, "`system`.`settings_profiles`"):
+						for id, name := range profileIDs {
+							if strings.Contains(qry, id) {
+								row := clickhouseclient.Row{}
+								row.Set("name", name)
+								row.Set("apply_to_all", uint8(0))
+								row.Set("apply_to_list", []string{})
+								row.Set("apply_to_except", []string{})
+								return []clickhouseclient.Row{row}
+							}
+						}
+					case strings.Contains(qry, "`system`.`roles`"):
+						row := clickhouseclient.Row{}
+						row.Set("name", "reader")
+						return []clickhouseclient.Row{row}
+					case strings.Contains(qry, "`role_name` = 'reader'"):
+						return []clickhouseclient.Row{elementRow("a"), elementRow("b")}
+					}
+					return nil
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			roleID := "11111111-1111-1111-1111-111111111111"
+			if tt.associate {
+				err = client.AssociateSettingsProfiles(context.Background(), tt.ids, &roleID, nil, nil)
+			} else {
+				err = client.DisassociateSettingsProfiles(context.Background(), tt.ids, &roleID, nil, nil)
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+
+			if !reflect.DeepEqual(fake.execs, tt.want) {
+				t.Errorf("queries = %q, want %q", fake.execs, tt.want)
+			}
+		})
+	}
+}
+
+func Test_CreateSettingsProfile_inheritFromOrder(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+	inheritFrom := []string{"web", "analytics", "readonly"}
+
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			switch {
+			case strings.Contains(qry, "`system`.`settings_profiles`"):
+				row := clickhouseclient.Row{}
+				row.Set("id", "00000000-0000-0000-0000-000000000000")
+				row.Set("name", "prf1")
+				row.Set("apply_to_all", uint8(0))
+				row.Set("apply_to_list", []string{})
+				row.Set("apply_to_except", []string{})
+				return []clickhouseclient.Row{row}
+			case strings.Contains(qry, "`inherit_profile` = 'prf1'"):
+				return nil
+			case strings.Contains(qry, "`system`.`settings_profile_elements`"):
+				if !strings.Contains(qry, "ORDER BY `index` ASC") {
+					t.Errorf("elements are not read in order: %s", qry)
+				}
+				rows := make([]clickhouseclient.Row, 0)
+				for _, name := range inheritFrom {
+					row := clickhouseclient.Row{}
+					row.Set("inherit_profile", strPtr(name))
+					row.Set("setting_name", (*string)(nil))
+					rows = append(rows, row)
+				}
+				return rows
+			}
+			return nil
+		},
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	profile, err := client.CreateSettingsProfile(context.Background(), SettingsProfile{
+		Name:        "prf1",
+		InheritFrom: inheritFrom,
+	}, nil)
+	if err != nil {
+		t.Fatalf("CreateSettingsProfile() error = %v", err)
+	}
+
+	wantCreate := "CREATE SETTINGS PROFILE `prf1` INHERIT `web`, INHERIT `analytics`, INHERIT `readonly`;"
+	if len(fake.execs) != 1 || fake.execs[0] != wantCreate {
+		t.Errorf("CreateSettingsProfile() queries = %q, want %q", fake.execs, wantCreate)
+	}
+	if !reflect.DeepEqual(profile.InheritFrom, inheritFrom) {
+		t.Errorf("CreateSettingsProfile() InheritFrom = %v, want %v", profile.InheritFrom, inheritFrom)
+	}
+}
+
+func Test_DeleteSettingsProfile_reserved(t *testing.T) {
+	tests := []struct {
+		name      string
+		profile   string
+		opts      []Option
+		wantErr   bool
+		wantExecs int
+	}{
+		{
+			name:    "Built-in profile",
+			profile: "readonly",
+			wantErr: true,
+		},
+		{
+			name:      "Other profile",
+			profile:   "prf1",
+			wantExecs: 1,
+		},
+		{
+			name:      "Built-in profile no longer reserved",
+			profile:   "readonly",
+			opts:      []Option{WithReservedSettingsProfiles(nil)},
+			wantExecs: 1,
+		},
+		{
+			name:    "Custom reserved profile",
+			profile: "prf1",
+			opts:    []Option{WithReservedSettingsProfiles([]string{"prf1"})},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					if !strings.Contains(qry, "`system`.`settings_profiles`") {
+						return nil
+					}
+					row := clickhouseclient.Row{}
+					row.Set("name", tt.profile)
+					row.Set("apply_to_all", uint8(0))
+					row.Set("apply_to_list", []string{})
+					row.Set("apply_to_except", []string{})
+					return []clickhouseclient.Row{row}
+				},
+			}
+
+			client, err := NewClient(fake, tt.opts...)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			err = client.DeleteSettingsProfile(context.Background(), "00000000-0000-0000-0000-000000000000", nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DeleteSettingsProfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(fake.execs) != tt.wantExecs {
+				t.Errorf("DeleteSettingsProfile() queries = %q, want %d queries", fake.execs, tt.wantExecs)
+			}
+		})
+	}
+}
+
+func Test_CreateSettingsProfile_reserved(t *testing.T) {
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			return nil
+		},
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	_, err = client.CreateSettingsProfile(context.Background(), SettingsProfile{Name: "default"}, nil)
+	if err == nil {
+		t.Fatalf("CreateSettingsProfile() expected error for a reserved profile")
+	}
+	if len(fake.execs) != 0 {
+		t.Errorf("CreateSettingsProfile() queries = %q, want none", fake.execs)
+	}
+}
+
+func Test_GetSettingsProfileByName_allReplicas(t *testing.T) {
+	clusterName := "cluster1"
+
+	var queries []string
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			queries = append(queries, qry)
+			row := clickhouseclient.Row{}
+			switch {
+			case !strings.Contains(qry, "clusterAllReplicas('cluster1',"):
+				// Replicas read with cluster() don't have the profile.
+				return nil
+			case strings.Contains(qry, "`system`.`settings_profiles`") && strings.Contains(qry, "`name` = 'prf1'"):
+				row.Set("id", "00000000-0000-0000-0000-000000000000")
+			case strings.Contains(qry, "`system`.`settings_profiles`"):
+				row.Set("name", "prf1")
+				row.Set("apply_to_all", uint8(0))
+				row.Set("apply_to_list", []string{})
+				row.Set("apply_to_except", []string{})
+			default:
+				return nil
+			}
+			return []clickhouseclient.Row{row}
+		},
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	profile, err := client.GetSettingsProfileByName(context.Background(), "prf1", &clusterName)
+	if err != nil {
+		t.Fatalf("GetSettingsProfileByName() error = %v", err)
+	}
+	if profile == nil || profile.Name != "prf1" {
+		t.Fatalf("GetSettingsProfileByName() = %+v, want profile prf1", profile)
+	}
+
+	for _, qry := range queries {
+		if strings.Contains(qry, "cluster('cluster1',") {
+			t.Errorf("GetSettingsProfileByName() query %q doesn't read all the replicas", qry)
+		}
+	}
+}
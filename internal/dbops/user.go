@@ -2,8 +2,12 @@ package dbops
 
 import (
 	"context"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/pingcap/errors"
 
 	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
@@ -11,13 +15,48 @@ import (
 )
 
 type User struct {
-	ID                 string   `json:"id"`
-	Name               string   `json:"name"`
-	PasswordSha256Hash string   `json:"-"`
-	DefaultRole        string   `json:"-"`
-	SSLCertificateCN   string   `json:"-"`
-	SettingsProfile    string   `json:"-"`
-	SettingsProfiles   []string `json:"-"`
+	ID                 string `json:"id"`
+	Name               string `json:"name"`
+	PasswordSha256Hash string `json:"-"`
+	PasswordBcryptHash string `json:"-"`
+	// PasswordPlaintext is stored as is by ClickHouse, it's only meant for legacy setups.
+	PasswordPlaintext string `json:"-"`
+	// NoPassword creates a user that can log in without any credentials. It's only used when creating a user.
+	NoPassword       bool   `json:"-"`
+	DefaultRole      string `json:"-"`
+	SSLCertificateCN string `json:"-"`
+	// SettingsProfile is the profile to associate to the user when creating it. It is never set when reading a user,
+	// since a user can have several profiles: see SettingsProfiles.
+	SettingsProfile string `json:"-"`
+	// SettingsProfiles are all the profiles associated to the user. When creating a user, they are associated along
+	// with SettingsProfile.
+	SettingsProfiles []string `json:"-"`
+	// AuthType is the authentication method of the user as reported by system.users, e.g. 'sha256_password'.
+	// When the user has several methods, it is the first one.
+	AuthType string `json:"-"`
+	// Authentications are all the identification methods of the user. When set on create or update, they replace
+	// the single method fields, e.g. PasswordSha256Hash or SSLCertificateCN. When reading a user, password hashes are never set.
+	Authentications []UserAuthentication `json:"-"`
+	// Hosts the user can connect from. Nil when they are not managed, or couldn't be read.
+	Hosts []UserHost `json:"-"`
+	// Grantees the user can grant its privileges and roles to. Nil when they are not managed, or couldn't be read.
+	Grantees *UserGrantees `json:"-"`
+	// DefaultRoles of the user. Nil when they are not managed, or couldn't be read.
+	// When updating a user, an empty list removes all the default roles.
+	DefaultRoles []string `json:"-"`
+
+	// ValidUntil is the expiration time of the user's credentials, nil if they never expire.
+	// When updating a user, nil removes the expiration.
+	ValidUntil *time.Time `json:"-"`
+	// Expired is true when ValidUntil is in the past.
+	Expired bool `json:"-"`
+
+	// Comment of the user. Nil when it is not managed, or the server doesn't support comments on users.
+	Comment *string `json:"-"`
+
+	// Partial is true when only the name of the user could be read, because the provider is not allowed to read all
+	// the columns of system.users. The other attributes are then unknown rather than empty.
+	Partial bool `json:"-"`
 }
 
 func (i *impl) resolveUserName(ctx context.Context, ref string, clusterName *string) (string, error) {
@@ -46,23 +85,60 @@ func (u *User) HasSettingProfile(profileName string) bool {
 }
 
 func (i *impl) CreateUser(ctx context.Context, user User, clusterName *string) (*User, error) {
+	clusterName = i.withDefaultCluster(clusterName)
 	q := querybuilder.
 		NewCreateUser(user.Name).
 		WithCluster(clusterName)
 
 	// Choose identification method
-	if user.SSLCertificateCN != "" {
+	if len(user.Authentications) > 0 {
+		q = q.IdentifiedWithMethods(toQueryBuilderAuthentications(user.Authentications))
+	} else if user.SSLCertificateCN != "" {
 		q = q.IdentifiedWithSSLCertCN(user.SSLCertificateCN)
 	} else if user.PasswordSha256Hash != "" {
 		q = q.Identified(querybuilder.IdentificationSHA256Hash, user.PasswordSha256Hash)
+	} else if user.PasswordBcryptHash != "" {
+		q = q.Identified(querybuilder.IdentificationBcryptHash, user.PasswordBcryptHash)
+	} else if user.PasswordPlaintext != "" {
+		q = q.Identified(querybuilder.IdentificationPlaintext, user.PasswordPlaintext)
+	} else if user.NoPassword {
+		q = q.IdentifiedWithNoPassword()
 	}
 
 	if user.DefaultRole != "" {
 		q = q.WithDefaultRole(&user.DefaultRole)
 	}
 
-	if user.SettingsProfile != "" {
-		q = q.WithSettingsProfile(&user.SettingsProfile)
+	profiles := slices.Clone(user.SettingsProfiles)
+	if user.SettingsProfile != "" && !slices.Contains(profiles, user.SettingsProfile) {
+		profiles = append([]string{user.SettingsProfile}, profiles...)
+	}
+	if len(profiles) > 0 {
+		q = q.WithSettingsProfiles(profiles)
+	}
+
+	if user.Hosts != nil {
+		q = q.WithHosts(toQueryBuilderHosts(user.Hosts))
+	}
+
+	if user.ValidUntil != nil {
+		q = q.WithValidUntil(user.ValidUntil)
+	}
+
+	if user.Grantees != nil {
+		grantees := user.Grantees.toQueryBuilder()
+		q = q.WithGrantees(&grantees)
+	}
+
+	if user.Comment != nil {
+		supported, err := i.SupportsUserComment(ctx, clusterName)
+		if err != nil {
+			return nil, errors.WithMessage(err, "error checking support for user comments")
+		}
+		// The comment is left out on servers not supporting it.
+		if supported {
+			q = q.WithComment(user.Comment)
+		}
 	}
 
 	sql, err := q.Build()
@@ -70,20 +146,53 @@ func (i *impl) CreateUser(ctx context.Context, user User, clusterName *string) (
 		return nil, errors.WithMessage(err, "error building query")
 	}
 
-	err = i.clickhouseClient.Exec(ctx, sql)
+	err = i.execWithContext(ctx, sql, clusterName)
 	if err != nil {
-		return nil, errors.WithMessage(err, "error running query")
+		return nil, err
 	}
 
-	return i.GetUserByName(ctx, user.Name, clusterName)
+	return readAfterCreate(ctx, i, func() (*User, error) {
+		return i.GetUserByName(ctx, user.Name, clusterName)
+	})
 }
 
 func (i *impl) GetUserByName(ctx context.Context, name string, clusterName *string) (*User, error) {
-	sql, err := querybuilder.
-		NewSelect([]querybuilder.Field{
-			querybuilder.NewField("name"),
-			querybuilder.NewField("id").ToString(), // optional; for introspection only
-		}, "system.users").
+	clusterName = i.withDefaultCluster(clusterName)
+	columns, err := i.getUserColumns(ctx, clusterName)
+	if err != nil {
+		return nil, errors.WithMessage(err, "error checking the columns of system.users")
+	}
+
+	fields := []querybuilder.Field{
+		querybuilder.NewField("name"),
+		querybuilder.NewField("id").ToString(), // optional; for introspection only
+		querybuilder.NewField("auth_type").ToString(),
+	}
+	if columns.hosts {
+		for _, field := range userHostFields {
+			fields = append(fields, querybuilder.NewField(field))
+		}
+	}
+	if columns.defaultRoles {
+		fields = append(fields, querybuilder.NewField("default_roles_list"))
+	}
+	if columns.grantees {
+		for _, field := range userGranteesFields {
+			fields = append(fields, querybuilder.NewField(field))
+		}
+	}
+	if columns.validUntil {
+		fields = append(fields, querybuilder.NewField("valid_until").ToUTCString())
+	}
+	if columns.authParams {
+		fields = append(fields, querybuilder.NewField("auth_params"))
+	}
+	if columns.comment {
+		fields = append(fields, querybuilder.NewField("comment"))
+	}
+
+	sql, err := i.
+		newSelect(fields, "system.users").
 		WithCluster(clusterName).
 		Where(querybuilder.WhereEquals("name", name)).
 		Build()
@@ -92,19 +201,72 @@ func (i *impl) GetUserByName(ctx context.Context, name string, clusterName *stri
 	}
 
 	var user *User
+	var authTypes, authParams []string
 	err = i.clickhouseClient.Select(ctx, sql, func(data clickhouseclient.Row) error {
 		n, err := data.GetString("name")
 		if err != nil {
 			return errors.WithMessage(err, "error scanning query result, missing 'name' field")
 		}
 		chID, _ := data.GetNullableString("id") // may vary across nodes; do not use for identity
-		u := &User{Name: n}
+		authType, err := data.GetString("auth_type")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'auth_type' field")
+		}
+		authTypes = parseAuthTypes(authType)
+		u := &User{Name: n, AuthType: parseAuthType(authType)}
 		if chID != nil {
 			u.ID = *chID
 		}
+
+		if columns.hosts {
+			u.Hosts, err = userHostsFromRow(data)
+			if err != nil {
+				return err
+			}
+		}
+		if columns.defaultRoles {
+			u.DefaultRoles, err = data.GetStringSlice("default_roles_list")
+			if err != nil {
+				return errors.WithMessage(err, "error scanning query result, missing 'default_roles_list' field")
+			}
+		}
+		if columns.grantees {
+			u.Grantees, err = userGranteesFromRow(data)
+			if err != nil {
+				return err
+			}
+		}
+		if columns.validUntil {
+			u.ValidUntil, err = validUntilFromRow(data)
+			if err != nil {
+				return err
+			}
+		}
+		if columns.authParams {
+			authParams, err = authParamsFromRow(data)
+			if err != nil {
+				return err
+			}
+		}
+		if columns.comment {
+			comment, err := data.GetString("comment")
+			if err != nil {
+				return errors.WithMessage(err, "error scanning query result, missing 'comment' field")
+			}
+			u.Comment = &comment
+		}
+
 		user = u
 		return nil
 	})
+	if err != nil && isAccessDenied(err) {
+		// Least privileged accounts might not be allowed to read all the columns of system.users.
+		tflog.Warn(ctx, "not allowed to read all the attributes of the user, only checking it exists", map[string]any{
+			"user_name": name,
+			"error":     err.Error(),
+		})
+		return i.getUserNameOnly(ctx, name, clusterName)
+	}
 	if err != nil {
 		return nil, errors.WithMessage(err, "error running query")
 	}
@@ -112,12 +274,18 @@ func (i *impl) GetUserByName(ctx context.Context, name string, clusterName *stri
 		return nil, nil // not found
 	}
 
-	// Also fetch settings profiles (unchanged)
+	// Also fetch settings profiles.
+	// Only elements attached to the user directly are considered: elements belonging to a settings profile
+	// (profile_name is set) are inherited through that profile and must not be reconciled as the user's own.
+	// Elements setting a value directly on the user have no inherit_profile and are skipped as well.
 	{
-		sql, err = querybuilder.
-			NewSelect([]querybuilder.Field{querybuilder.NewField("inherit_profile")}, "system.settings_profile_elements").
+		sql, err = i.
+			newSelect([]querybuilder.Field{querybuilder.NewField("inherit_profile")}, "system.settings_profile_elements").
 			WithCluster(clusterName).
-			Where(querybuilder.WhereEquals("user_name", user.Name)).
+			Where(querybuilder.AndWhere(
+				querybuilder.WhereEquals("user_name", user.Name),
+				querybuilder.IsNull("profile_name"),
+			)).
 			Build()
 		if err != nil {
 			return nil, errors.WithMessage(err, "error building query")
@@ -138,21 +306,172 @@ func (i *impl) GetUserByName(ctx context.Context, name string, clusterName *stri
 			return nil, errors.WithMessage(err, "error running query")
 		}
 		user.SettingsProfiles = profiles
-		if len(profiles) > 0 {
-			user.SettingsProfile = profiles[0]
+	}
+
+	user.Authentications = authenticationsFromColumns(authTypes, authParams)
+	for _, a := range user.Authentications {
+		if a.Type == AuthTypeSSLCertificate && a.Value != "" {
+			user.SSLCertificateCN = a.Value
+			break
+		}
+	}
+
+	user.Expired = user.ValidUntil != nil && !i.now().Before(*user.ValidUntil)
+
+	return user, nil
+}
+
+// userColumns tells which of the optional columns of system.users exist on the server.
+// Older ClickHouse versions lack some of them, e.g. 'valid_until' or 'comment'.
+type userColumns struct {
+	hosts        bool
+	defaultRoles bool
+	grantees     bool
+	validUntil   bool
+	authParams   bool
+	comment      bool
+}
+
+// getUserColumns returns the optional columns of system.users that exist on the server.
+func (i *impl) getUserColumns(ctx context.Context, clusterName *string) (userColumns, error) {
+	var columns userColumns
+	for _, c := range []struct {
+		name  string
+		found *bool
+	}{
+		{name: "host_ip", found: &columns.hosts},
+		{name: "default_roles_list", found: &columns.defaultRoles},
+		{name: "grantees_any", found: &columns.grantees},
+		{name: "valid_until", found: &columns.validUntil},
+		{name: "auth_params", found: &columns.authParams},
+		{name: "comment", found: &columns.comment},
+	} {
+		var err error
+		*c.found, err = i.hasSystemColumn(ctx, "users", c.name, clusterName)
+		if err != nil {
+			return userColumns{}, err
+		}
+	}
+
+	return columns, nil
+}
+
+// UserExists returns true if a user with the given name exists.
+// It's cheaper than GetUserByName, and only needs to be allowed to count the rows of system.users.
+func (i *impl) UserExists(ctx context.Context, name string, clusterName *string) (bool, error) {
+	clusterName = i.withDefaultCluster(clusterName)
+	sql, err := i.
+		newCount("system.users").
+		WithCluster(clusterName).
+		Where(querybuilder.WhereEquals("name", name)).
+		Build()
+	if err != nil {
+		return false, errors.WithMessage(err, "error building query")
+	}
+
+	var count uint64
+	err = i.clickhouseClient.Select(ctx, sql, func(data clickhouseclient.Row) error {
+		count, err = data.GetUInt64("cnt")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'cnt' field")
+		}
+		return nil
+	})
+	if err != nil && isAccessDenied(err) {
+		user, err := i.getUserNameOnly(ctx, name, clusterName)
+		return user != nil, err
+	}
+	if err != nil {
+		return false, errors.WithMessage(err, "error running query")
+	}
+
+	return count > 0, nil
+}
+
+// getUserNameOnly returns the user with the given name, reading nothing but its name from system.users.
+// The user is marked as partial.
+func (i *impl) getUserNameOnly(ctx context.Context, name string, clusterName *string) (*User, error) {
+	sql, err := i.
+		newSelect([]querybuilder.Field{querybuilder.NewField("name")}, "system.users").
+		WithCluster(clusterName).
+		Where(querybuilder.WhereEquals("name", name)).
+		Build()
+	if err != nil {
+		return nil, errors.WithMessage(err, "error building query")
+	}
+
+	var user *User
+	err = i.clickhouseClient.Select(ctx, sql, func(data clickhouseclient.Row) error {
+		n, err := data.GetString("name")
+		if err != nil {
+			return errors.WithMessage(err, "error scanning query result, missing 'name' field")
 		}
+		user = &User{Name: n, Partial: true}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "error running query")
 	}
 
 	return user, nil
 }
 
+// isAccessDenied returns true if the given error is ClickHouse refusing the query because of missing privileges.
+// Both the native ('code: 497, message: ...') and the HTTP ('Code: 497. DB::Exception: ...') error formats are matched.
+func isAccessDenied(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "code: 497") ||
+		strings.Contains(msg, "access_denied") ||
+		strings.Contains(msg, "not enough privileges")
+}
+
+// parseAuthType returns the authentication method out of the 'auth_type' column converted to string.
+// When the user has multiple authentication methods, the first one is returned.
+func parseAuthType(value string) string {
+	authTypes := parseAuthTypes(value)
+	if len(authTypes) == 0 {
+		return ""
+	}
+
+	return authTypes[0]
+}
+
+// validUntilFromRow returns the expiration time of the user's credentials out of the 'valid_until' column,
+// or nil if they never expire.
+func validUntilFromRow(data clickhouseclient.Row) (*time.Time, error) {
+	value, err := data.GetNullableString("valid_until")
+	if err != nil {
+		return nil, errors.WithMessage(err, "error scanning query result, missing 'valid_until' field")
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	t, err := time.ParseInLocation(time.DateTime, *value, time.UTC)
+	if err != nil {
+		return nil, errors.WithMessage(err, "error parsing 'valid_until' field")
+	}
+
+	return &t, nil
+}
+
+// sameValidUntil returns true if both expiration times are the same, up to the second precision of ClickHouse.
+func sameValidUntil(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	return a.Truncate(time.Second).Equal(b.Truncate(time.Second))
+}
+
 func (i *impl) GetUserByUUID(ctx context.Context, uuidStr string, clusterName *string) (*User, error) {
+	clusterName = i.withDefaultCluster(clusterName)
 	if _, parseErr := uuid.Parse(uuidStr); parseErr != nil {
 		return i.GetUserByName(ctx, uuidStr, clusterName)
 	}
 
-	sql, err := querybuilder.
-		NewSelect([]querybuilder.Field{querybuilder.NewField("name")}, "system.users").
+	sql, err := i.
+		newSelect([]querybuilder.Field{querybuilder.NewField("name")}, "system.users").
 		WithCluster(clusterName).
 		Where(querybuilder.WhereEquals("id", uuidStr)).
 		Build()
@@ -179,30 +498,31 @@ func (i *impl) GetUserByUUID(ctx context.Context, uuidStr string, clusterName *s
 
 // Delete by name
 func (i *impl) DeleteUser(ctx context.Context, name string, clusterName *string) error {
-	user, err := i.GetUserByName(ctx, name, clusterName)
-	if err != nil {
-		return errors.WithMessage(err, "error getting user")
-	}
-	if user == nil {
-		return nil // desired state
-	}
-
-	sql, err := querybuilder.NewDropUser(user.Name).WithCluster(clusterName).Build()
+	clusterName = i.withDefaultCluster(clusterName)
+	// IF EXISTS makes dropping a user that is already gone a no-op, without looking it up first.
+	sql, err := querybuilder.NewDropUser(name).WithCluster(clusterName).IfExists().Build()
 	if err != nil {
 		return errors.WithMessage(err, "error building query")
 	}
-	if err = i.clickhouseClient.Exec(ctx, sql); err != nil {
-		return errors.WithMessage(err, "error running query")
+	if err = i.execWithContext(ctx, sql, clusterName); err != nil {
+		return err
 	}
 	return nil
 }
 
 func (i *impl) FindUserByName(ctx context.Context, name string, clusterName *string) (*User, error) {
+	clusterName = i.withDefaultCluster(clusterName)
 	return i.GetUserByName(ctx, name, clusterName)
 }
 
 func (i *impl) UpdateUser(ctx context.Context, user User, clusterName *string) (*User, error) {
+	clusterName = i.withDefaultCluster(clusterName)
 	currentName := user.ID
+	if user.DefaultRoles != nil {
+		// The default roles of the user may be changed by reconcileDefaultRoles at the same time.
+		defer i.lockUser(currentName, clusterName)()
+	}
+
 	existing, err := i.GetUserByName(ctx, currentName, clusterName)
 	if err != nil {
 		return nil, errors.WithMessage(err, "Unable to get existing user")
@@ -211,36 +531,123 @@ func (i *impl) UpdateUser(ctx context.Context, user User, clusterName *string) (
 		return nil, errors.Errorf("user %q not found", currentName)
 	}
 
-	// Only rename if the target name actually differs
-	wantsRename := user.Name != existing.Name
+	changeCN := user.SSLCertificateCN != "" && user.SSLCertificateCN != existing.SSLCertificateCN
+	changeHosts := user.Hosts != nil && !SameHosts(user.Hosts, existing.Hosts)
+	changeValidUntil := !sameValidUntil(user.ValidUntil, existing.ValidUntil)
+	changeAuthentications := len(user.Authentications) > 0
+	changeDefaultRoles := user.DefaultRoles != nil && (existing.DefaultRoles == nil || !sameElements(user.DefaultRoles, existing.DefaultRoles))
+	changeGrantees := user.Grantees != nil && (existing.Grantees == nil || !sameGrantees(*user.Grantees, *existing.Grantees))
+	// The existing comment is nil when the server doesn't support comments on users.
+	changeComment := user.Comment != nil && existing.Comment != nil && *existing.Comment != *user.Comment
 
-	var wantsSettingsProfile bool
-	var desiredProfile *string
-	if user.SettingsProfile != "" && !existing.HasSettingProfile(user.SettingsProfile) {
-		wantsSettingsProfile = true
-		p := user.SettingsProfile
-		desiredProfile = &p
+	// Only alter the user if the target name actually differs, a new password or new identification methods are set,
+	// the certificate CN, the hosts, the expiration, the default roles, the grantees or the comment changed.
+	// Settings profile changes are handled by UpdateUserSettingsProfile, since they depend on the previously managed profile.
+	if user.Name == existing.Name && user.PasswordSha256Hash == "" && user.PasswordBcryptHash == "" && user.PasswordPlaintext == "" && !changeCN && !changeHosts && !changeValidUntil && !changeAuthentications && !changeDefaultRoles && !changeGrantees && !changeComment {
+		return existing, nil
 	}
 
-	if !wantsRename && !wantsSettingsProfile {
-		// No changes (since we don't alter other props via ALTER yet)
-		return existing, nil
+	if err := i.checkRename("user", existing.Name, user.Name); err != nil {
+		return nil, err
+	}
+
+	if err := i.checkExistsOnAllReplicas(ctx, AccessEntityUser, existing.Name, clusterName); err != nil {
+		return nil, err
+	}
+
+	q := querybuilder.NewAlterUser(existing.Name).
+		WithCluster(clusterName).
+		RenameTo(&user.Name)
+
+	// Changing the password in place preserves the grants and settings profiles of the user.
+	if changeAuthentications {
+		q = q.IdentifiedWithMethods(toQueryBuilderAuthentications(user.Authentications))
+	} else if user.PasswordSha256Hash != "" {
+		q = q.Identified(querybuilder.IdentificationSHA256Hash, user.PasswordSha256Hash)
+	} else if user.PasswordBcryptHash != "" {
+		q = q.Identified(querybuilder.IdentificationBcryptHash, user.PasswordBcryptHash)
+	} else if user.PasswordPlaintext != "" {
+		q = q.Identified(querybuilder.IdentificationPlaintext, user.PasswordPlaintext)
+	} else if changeCN {
+		q = q.IdentifiedWithSSLCertCN(user.SSLCertificateCN)
 	}
 
-	q := querybuilder.NewAlterUser(existing.Name).WithCluster(clusterName)
-	if wantsRename {
-		q = q.RenameTo(&user.Name)
+	// Hosts are added and dropped one by one when possible, so that the hosts kept are allowed all along.
+	if changeHosts {
+		if add, drop, ok := hostsDelta(existing.Hosts, user.Hosts); ok {
+			q = q.AddHosts(add).DropHosts(drop)
+		} else {
+			q = q.SetHosts(toQueryBuilderHosts(user.Hosts))
+		}
+	}
+
+	if changeValidUntil {
+		q = q.SetValidUntil(user.ValidUntil)
 	}
-	if wantsSettingsProfile {
-		q = q.SetSettingsProfile(desiredProfile)
+
+	if changeDefaultRoles {
+		q = q.SetDefaultRoles(user.DefaultRoles)
+	}
+
+	if changeGrantees {
+		q = q.SetGrantees(user.Grantees.toQueryBuilder())
+	}
+
+	if changeComment {
+		q = q.SetComment(user.Comment)
 	}
 
 	sql, err := q.Build()
 	if err != nil {
 		return nil, errors.WithMessage(err, "error building query")
 	}
-	if err = i.clickhouseClient.Exec(ctx, sql); err != nil {
-		return nil, errors.WithMessage(err, "error running query")
+	if err = i.execWithContext(ctx, sql, clusterName); err != nil {
+		return nil, err
 	}
 	return i.GetUserByName(ctx, user.Name, clusterName)
 }
+
+// UpdateUserSettingsProfile replaces oldProfile with newProfile in the settings profiles of the user,
+// leaving any other profile associated with the user untouched. Either profile can be nil.
+func (i *impl) UpdateUserSettingsProfile(ctx context.Context, name string, oldProfile *string, newProfile *string, clusterName *string) (*User, error) {
+	clusterName = i.withDefaultCluster(clusterName)
+	// The profiles of the user are checked before being changed.
+	defer i.lockUser(name, clusterName)()
+
+	existing, err := i.GetUserByName(ctx, name, clusterName)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Unable to get existing user")
+	}
+	if existing == nil {
+		return nil, errors.Errorf("user %q not found", name)
+	}
+
+	if oldProfile != nil && newProfile != nil && *oldProfile == *newProfile {
+		return existing, nil
+	}
+
+	// Don't try dropping a profile that was already removed out of band, or adding one that is already there.
+	if oldProfile != nil && !existing.HasSettingProfile(*oldProfile) {
+		oldProfile = nil
+	}
+	if newProfile != nil && existing.HasSettingProfile(*newProfile) {
+		newProfile = nil
+	}
+
+	if oldProfile == nil && newProfile == nil {
+		return existing, nil
+	}
+
+	sql, err := querybuilder.NewAlterUser(existing.Name).
+		WithCluster(clusterName).
+		DropSettingsProfile(oldProfile).
+		AddSettingsProfile(newProfile).
+		Build()
+	if err != nil {
+		return nil, errors.WithMessage(err, "error building query")
+	}
+	if err = i.execWithContext(ctx, sql, clusterName); err != nil {
+		return nil, err
+	}
+	return i.GetUserByName(ctx, existing.Name, clusterName)
+}
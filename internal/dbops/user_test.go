@@ -0,0 +1,1382 @@
+package dbops
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+)
+
+func Test_GetUserByName_authType(t *testing.T) {
+	tests := []struct {
+		name     string
+		authType string
+		want     string
+	}{
+		{
+			name:     "sha256 password",
+			authType: "sha256_password",
+			want:     "sha256_password",
+		},
+		{
+			name:     "ssl certificate",
+			authType: "ssl_certificate",
+			want:     "ssl_certificate",
+		},
+		{
+			name:     "bcrypt password as array",
+			authType: "['bcrypt_password']",
+			want:     "bcrypt_password",
+		},
+		{
+			name:     "multiple auth methods",
+			authType: "['ssl_certificate','sha256_password']",
+			want:     "ssl_certificate",
+		},
+		{
+			name:     "no password",
+			authType: "['no_password']",
+			want:     "no_password",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					if !strings.Contains(qry, "`auth_type`") {
+						return nil
+					}
+					id := "00000000-0000-0000-0000-000000000000"
+					row := clickhouseclient.Row{}
+					row.Set("name", "john")
+					row.Set("id", &id)
+					row.Set("auth_type", tt.authType)
+					return []clickhouseclient.Row{row}
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			user, err := client.GetUserByName(context.Background(), "john", nil)
+			if err != nil {
+				t.Fatalf("GetUserByName() error = %v", err)
+			}
+			if user == nil {
+				t.Fatalf("GetUserByName() returned nil user")
+			}
+			if user.AuthType != tt.want {
+				t.Errorf("GetUserByName() AuthType = %q, want %q", user.AuthType, tt.want)
+			}
+		})
+	}
+}
+
+func Test_GetUserByName_ignoresProfileSettings(t *testing.T) {
+	profileElementRow := func(profileName *string, inheritProfile *string, settingName *string) clickhouseclient.Row {
+		row := clickhouseclient.Row{}
+		row.Set("profile_name", profileName)
+		row.Set("inherit_profile", inheritProfile)
+		row.Set("setting_name", settingName)
+		return row
+	}
+
+	readonly := "readonly"
+	maxMemoryUsage := "max_memory_usage"
+
+	// Both the user and its profile set max_memory_usage.
+	elements := []clickhouseclient.Row{
+		profileElementRow(nil, &readonly, nil),
+		profileElementRow(nil, nil, &maxMemoryUsage),
+		profileElementRow(&readonly, nil, &maxMemoryUsage),
+	}
+
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			switch {
+			case strings.Contains(qry, "`auth_type`"):
+				id := "00000000-0000-0000-0000-000000000000"
+				row := clickhouseclient.Row{}
+				row.Set("name", "john")
+				row.Set("id", &id)
+				row.Set("auth_type", "sha256_password")
+				return []clickhouseclient.Row{row}
+			case strings.Contains(qry, "`system`.`settings_profile_elements`"):
+				ret := make([]clickhouseclient.Row, 0)
+				for _, row := range elements {
+					profileName, _ := row.GetNullableString("profile_name")
+					if profileName != nil && strings.Contains(qry, "`profile_name` IS NULL") {
+						continue
+					}
+					ret = append(ret, row)
+				}
+				return ret
+			}
+			return nil
+		},
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	user, err := client.GetUserByName(context.Background(), "john", nil)
+	if err != nil {
+		t.Fatalf("GetUserByName() error = %v", err)
+	}
+	if user == nil {
+		t.Fatalf("GetUserByName() returned nil user")
+	}
+
+	if !reflect.DeepEqual(user.SettingsProfiles, []string{readonly}) {
+		t.Errorf("GetUserByName() SettingsProfiles = %v, want %v", user.SettingsProfiles, []string{readonly})
+	}
+}
+
+func Test_GetUserByName_sslCertificateCN(t *testing.T) {
+	tests := []struct {
+		name       string
+		authType   string
+		authParams any
+		want       string
+	}{
+		{
+			name:       "ssl certificate",
+			authType:   "ssl_certificate",
+			authParams: `{"common_names":["john.example.com"]}`,
+			want:       "john.example.com",
+		},
+		{
+			name:       "ssl certificate with multiple auth methods",
+			authType:   "['ssl_certificate']",
+			authParams: []string{`{"common_names":["john.example.com","other"]}`},
+			want:       "john.example.com",
+		},
+		{
+			name:       "ssl certificate after a password",
+			authType:   "['sha256_password','ssl_certificate']",
+			authParams: []string{`{}`, `{"common_names":["john.example.com"]}`},
+			want:       "john.example.com",
+		},
+		{
+			name:       "password",
+			authType:   "sha256_password",
+			authParams: `{}`,
+			want:       "",
+		},
+		{
+			name:       "invalid auth params",
+			authType:   "ssl_certificate",
+			authParams: `not json`,
+			want:       "",
+		},
+		{
+			name:       "auth params not returned",
+			authType:   "ssl_certificate",
+			authParams: nil,
+			want:       "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					row := clickhouseclient.Row{}
+					switch {
+					case strings.Contains(qry, "`system`.`columns`"):
+						if tt.authParams == nil {
+							return nil
+						}
+						return systemColumnRows(qry, "auth_params")
+					case strings.Contains(qry, "`auth_type`"):
+						id := "00000000-0000-0000-0000-000000000000"
+						row.Set("name", "john")
+						row.Set("id", &id)
+						row.Set("auth_type", tt.authType)
+						row.Set("auth_params", tt.authParams)
+					default:
+						return nil
+					}
+					return []clickhouseclient.Row{row}
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			user, err := client.GetUserByName(context.Background(), "john", nil)
+			if err != nil {
+				t.Fatalf("GetUserByName() error = %v", err)
+			}
+			if user == nil {
+				t.Fatalf("GetUserByName() returned nil user")
+			}
+			if user.SSLCertificateCN != tt.want {
+				t.Errorf("GetUserByName() SSLCertificateCN = %q, want %q", user.SSLCertificateCN, tt.want)
+			}
+		})
+	}
+}
+
+func Test_CreateUser_mixedCase(t *testing.T) {
+	fake := &fakeClickhouseClient{}
+	fake.rows = func(qry string) []clickhouseclient.Row {
+		if !strings.Contains(qry, "`auth_type`") {
+			return nil
+		}
+		// User names are case sensitive: only an exact match finds the created user.
+		if len(fake.execs) == 0 || !strings.Contains(qry, "`name` = 'MyUser'") {
+			return nil
+		}
+		id := "00000000-0000-0000-0000-000000000000"
+		row := clickhouseclient.Row{}
+		row.Set("name", "MyUser")
+		row.Set("id", &id)
+		row.Set("auth_type", "sha256_password")
+		return []clickhouseclient.Row{row}
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	user, err := client.CreateUser(context.Background(), User{Name: "MyUser", PasswordSha256Hash: "hash"}, nil)
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if user == nil || user.Name != "MyUser" {
+		t.Fatalf("CreateUser() = %+v, want user named MyUser", user)
+	}
+
+	want := "CREATE USER IF NOT EXISTS `MyUser` IDENTIFIED WITH sha256_hash BY 'hash';"
+	if len(fake.execs) != 1 || fake.execs[0] != want {
+		t.Errorf("CreateUser() queries = %q, want %q", fake.execs, want)
+	}
+
+	other, err := client.GetUserByName(context.Background(), "myuser", nil)
+	if err != nil {
+		t.Fatalf("GetUserByName() error = %v", err)
+	}
+	if other != nil {
+		t.Errorf("GetUserByName() = %+v, want nil for differently cased name", other)
+	}
+}
+
+func Test_CreateUser_identification(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     User
+		authType string
+		want     string
+	}{
+		{
+			name:     "Plaintext password",
+			user:     User{Name: "john", PasswordPlaintext: "changeme"},
+			authType: AuthTypePlaintextPassword,
+			want:     "CREATE USER IF NOT EXISTS `john` IDENTIFIED WITH plaintext_password BY 'changeme';",
+		},
+		{
+			name:     "No password",
+			user:     User{Name: "john", NoPassword: true},
+			authType: AuthTypeNoPassword,
+			want:     "CREATE USER IF NOT EXISTS `john` IDENTIFIED WITH no_password;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					if !strings.Contains(qry, "`auth_type`") {
+						return nil
+					}
+					id := "00000000-0000-0000-0000-000000000000"
+					row := clickhouseclient.Row{}
+					row.Set("name", "john")
+					row.Set("id", &id)
+					row.Set("auth_type", tt.authType)
+					return []clickhouseclient.Row{row}
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			user, err := client.CreateUser(context.Background(), tt.user, nil)
+			if err != nil {
+				t.Fatalf("CreateUser() error = %v", err)
+			}
+			if user.AuthType != tt.authType {
+				t.Errorf("CreateUser() auth type = %q, want %q", user.AuthType, tt.authType)
+			}
+
+			if len(fake.execs) == 0 || fake.execs[0] != tt.want {
+				t.Errorf("CreateUser() queries = %q, want %q", fake.execs, tt.want)
+			}
+		})
+	}
+}
+
+func Test_CreateUser_settingsProfiles(t *testing.T) {
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			if !strings.Contains(qry, "`auth_type`") {
+				return nil
+			}
+			id := "00000000-0000-0000-0000-000000000000"
+			row := clickhouseclient.Row{}
+			row.Set("name", "john")
+			row.Set("id", &id)
+			row.Set("auth_type", "sha256_password")
+			return []clickhouseclient.Row{row}
+		},
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	user := User{Name: "john", PasswordSha256Hash: "hash", SettingsProfile: "readonly", SettingsProfiles: []string{"analyst", "readonly"}}
+	if _, err := client.CreateUser(context.Background(), user, nil); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+
+	want := "CREATE USER IF NOT EXISTS `john` IDENTIFIED WITH sha256_hash BY 'hash' SETTINGS PROFILE 'analyst', PROFILE 'readonly';"
+	if len(fake.execs) == 0 || fake.execs[0] != want {
+		t.Errorf("CreateUser() queries = %q, want %q", fake.execs, want)
+	}
+}
+
+func Test_GetUserByName_restrictedSystemUsers(t *testing.T) {
+	tests := []struct {
+		name      string
+		selectErr error
+		wantUser  bool
+		wantErr   bool
+	}{
+		{
+			name:      "Access denied falls back to the name",
+			selectErr: errors.New("code: 497, message: john: Not enough privileges. To execute this query, it's necessary to have the grant SHOW USERS ON *.*"),
+			wantUser:  true,
+		},
+		{
+			name:      "Other errors are returned",
+			selectErr: errors.New("code: 210, message: Connection refused"),
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					row := clickhouseclient.Row{}
+					row.Set("name", "john")
+					return []clickhouseclient.Row{row}
+				},
+				selectErr: func(qry string) error {
+					// Only the name column can be read.
+					if strings.Contains(qry, "`auth_type`") {
+						return tt.selectErr
+					}
+					return nil
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			user, err := client.GetUserByName(context.Background(), "john", nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetUserByName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (user != nil) != tt.wantUser {
+				t.Fatalf("GetUserByName() = %+v, want user %v", user, tt.wantUser)
+			}
+			if user != nil && user.Name != "john" {
+				t.Errorf("GetUserByName() Name = %q, want %q", user.Name, "john")
+			}
+			if user != nil && !user.Partial {
+				t.Errorf("GetUserByName() Partial = false, want true")
+			}
+		})
+	}
+}
+
+func Test_UpdateUser_validUntil(t *testing.T) {
+	current := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	later := time.Date(2031, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		current    *time.Time
+		validUntil *time.Time
+		want       []string
+	}{
+		{
+			name:       "Expiration changed",
+			current:    &current,
+			validUntil: &later,
+			want:       []string{"ALTER USER `john` VALID UNTIL '2031-01-02 03:04:05 UTC';"},
+		},
+		{
+			name:       "Expiration removed",
+			current:    &current,
+			validUntil: nil,
+			want:       []string{"ALTER USER `john` VALID UNTIL 'infinity';"},
+		},
+		{
+			name:       "Same expiration in another timezone",
+			current:    &current,
+			validUntil: timePtr(current.In(time.FixedZone("CET", 3600))),
+			want:       nil,
+		},
+		{
+			name:       "No expiration",
+			current:    nil,
+			validUntil: nil,
+			want:       nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					row := clickhouseclient.Row{}
+					switch {
+					case strings.Contains(qry, "`system`.`columns`"):
+						return systemColumnRows(qry, "valid_until")
+					case strings.Contains(qry, "`auth_type`"):
+						id := "00000000-0000-0000-0000-000000000000"
+						row.Set("name", "john")
+						row.Set("id", &id)
+						row.Set("auth_type", "sha256_password")
+						var value *string
+						if tt.current != nil {
+							formatted := tt.current.Format(time.DateTime)
+							value = &formatted
+						}
+						row.Set("valid_until", value)
+					default:
+						return nil
+					}
+					return []clickhouseclient.Row{row}
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			_, err = client.UpdateUser(context.Background(), User{ID: "john", Name: "john", ValidUntil: tt.validUntil}, nil)
+			if err != nil {
+				t.Fatalf("UpdateUser() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(fake.execs, tt.want) {
+				t.Errorf("UpdateUser() queries = %q, want %q", fake.execs, tt.want)
+			}
+		})
+	}
+}
+
+func Test_GetUserByName_singleQuery(t *testing.T) {
+	var mu sync.Mutex
+	queries := 0
+
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			row := clickhouseclient.Row{}
+			switch {
+			case strings.Contains(qry, "`system`.`columns`"):
+				return systemColumnRows(qry, "host_ip", "default_roles_list", "grantees_any", "valid_until", "auth_params", "comment")
+			case strings.Contains(qry, "`system`.`users`"):
+				mu.Lock()
+				queries++
+				mu.Unlock()
+				id := "00000000-0000-0000-0000-000000000000"
+				validUntil := "2030-01-02 03:04:05"
+				row.Set("name", "john")
+				row.Set("id", &id)
+				row.Set("auth_type", "ssl_certificate")
+				row.Set("host_ip", []string{})
+				row.Set("host_names", []string{"localhost"})
+				row.Set("host_names_regexp", []string{})
+				row.Set("host_names_like", []string{})
+				row.Set("default_roles_list", []string{"reader"})
+				row.Set("grantees_any", uint8(1))
+				row.Set("grantees_list", []string{})
+				row.Set("grantees_except", []string{})
+				row.Set("valid_until", &validUntil)
+				row.Set("auth_params", `{"common_names":["john.example.com"]}`)
+				row.Set("comment", "owner: team-data")
+			default:
+				return nil
+			}
+			return []clickhouseclient.Row{row}
+		},
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	user, err := client.GetUserByName(context.Background(), "john", nil)
+	if err != nil {
+		t.Fatalf("GetUserByName() error = %v", err)
+	}
+
+	if queries != 1 {
+		t.Errorf("GetUserByName() queried system.users %d times, want once", queries)
+	}
+	if !reflect.DeepEqual(user.Hosts, []UserHost{{Type: HostTypeLocal}}) {
+		t.Errorf("GetUserByName() Hosts = %+v, want LOCAL", user.Hosts)
+	}
+	if !reflect.DeepEqual(user.DefaultRoles, []string{"reader"}) {
+		t.Errorf("GetUserByName() DefaultRoles = %q, want [reader]", user.DefaultRoles)
+	}
+	if user.Grantees == nil || !user.Grantees.Any {
+		t.Errorf("GetUserByName() Grantees = %+v, want ANY", user.Grantees)
+	}
+	if user.ValidUntil == nil || !user.ValidUntil.Equal(time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("GetUserByName() ValidUntil = %v, want 2030-01-02 03:04:05", user.ValidUntil)
+	}
+	if user.SSLCertificateCN != "john.example.com" {
+		t.Errorf("GetUserByName() SSLCertificateCN = %q, want john.example.com", user.SSLCertificateCN)
+	}
+	if user.Comment == nil || *user.Comment != "owner: team-data" {
+		t.Errorf("GetUserByName() Comment = %v, want owner: team-data", user.Comment)
+	}
+}
+
+func Test_GetUserByName_validUntil(t *testing.T) {
+	current := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		supported bool
+		selectErr error
+		want      *time.Time
+		wantErr   bool
+	}{
+		{
+			name:      "Expiration read",
+			supported: true,
+			want:      &current,
+		},
+		{
+			name:      "Column not supported",
+			supported: false,
+			want:      nil,
+		},
+		{
+			name:      "Error checking the column",
+			supported: true,
+			selectErr: errors.New("code: 210, message: Connection refused"),
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					row := clickhouseclient.Row{}
+					switch {
+					case strings.Contains(qry, "`system`.`columns`"):
+						if !tt.supported {
+							return nil
+						}
+						return systemColumnRows(qry, "valid_until")
+					case strings.Contains(qry, "`auth_type`"):
+						if strings.Contains(qry, "`valid_until`") != tt.supported {
+							t.Errorf("GetUserByName() query = %q, want valid_until selected %v", qry, tt.supported)
+						}
+						id := "00000000-0000-0000-0000-000000000000"
+						row.Set("name", "john")
+						row.Set("id", &id)
+						row.Set("auth_type", "sha256_password")
+						formatted := current.Format(time.DateTime)
+						row.Set("valid_until", &formatted)
+					default:
+						return nil
+					}
+					return []clickhouseclient.Row{row}
+				},
+				selectErr: func(qry string) error {
+					if strings.Contains(qry, "`system`.`columns`") {
+						return tt.selectErr
+					}
+					return nil
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			user, err := client.GetUserByName(context.Background(), "john", nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetUserByName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !sameValidUntil(user.ValidUntil, tt.want) {
+				t.Errorf("GetUserByName() ValidUntil = %v, want %v", user.ValidUntil, tt.want)
+			}
+		})
+	}
+}
+
+func Test_GetUserByName_expired(t *testing.T) {
+	now := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name       string
+		validUntil *time.Time
+		want       bool
+	}{
+		{
+			name:       "Valid until in the past",
+			validUntil: &past,
+			want:       true,
+		},
+		{
+			name:       "Valid until now",
+			validUntil: &now,
+			want:       true,
+		},
+		{
+			name:       "Valid until in the future",
+			validUntil: &future,
+			want:       false,
+		},
+		{
+			name:       "No expiration",
+			validUntil: nil,
+			want:       false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					row := clickhouseclient.Row{}
+					switch {
+					case strings.Contains(qry, "`system`.`columns`"):
+						return systemColumnRows(qry, "valid_until")
+					case strings.Contains(qry, "`auth_type`"):
+						id := "00000000-0000-0000-0000-000000000000"
+						row.Set("name", "john")
+						row.Set("id", &id)
+						row.Set("auth_type", "sha256_password")
+						var validUntil *string
+						if tt.validUntil != nil {
+							formatted := tt.validUntil.Format(time.DateTime)
+							validUntil = &formatted
+						}
+						row.Set("valid_until", validUntil)
+					default:
+						return nil
+					}
+					return []clickhouseclient.Row{row}
+				},
+			}
+
+			client, err := NewClient(fake, WithClock(func() time.Time { return now }))
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			user, err := client.GetUserByName(context.Background(), "john", nil)
+			if err != nil {
+				t.Fatalf("GetUserByName() error = %v", err)
+			}
+			if user.Expired != tt.want {
+				t.Errorf("GetUserByName() Expired = %v, want %v", user.Expired, tt.want)
+			}
+		})
+	}
+}
+
+func Test_UpdateUserSettingsProfile(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name       string
+		profiles   []string
+		oldProfile *string
+		newProfile *string
+		wantAlter  string
+	}{
+		{
+			name:       "Profile replaced",
+			profiles:   []string{"old"},
+			oldProfile: strPtr("old"),
+			newProfile: strPtr("new"),
+			wantAlter:  "ALTER USER `john` DROP PROFILES 'old' ADD PROFILES 'new';",
+		},
+		{
+			name:       "Profile dropped",
+			profiles:   []string{"old"},
+			oldProfile: strPtr("old"),
+			wantAlter:  "ALTER USER `john` DROP PROFILES 'old';",
+		},
+		{
+			name:       "Profile added",
+			profiles:   []string{},
+			newProfile: strPtr("new"),
+			wantAlter:  "ALTER USER `john` ADD PROFILES 'new';",
+		},
+		{
+			name:       "Old profile removed out of band",
+			profiles:   []string{},
+			oldProfile: strPtr("old"),
+			newProfile: strPtr("new"),
+			wantAlter:  "ALTER USER `john` ADD PROFILES 'new';",
+		},
+		{
+			name:       "Dropped profile removed out of band",
+			profiles:   []string{},
+			oldProfile: strPtr("old"),
+			wantAlter:  "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					switch {
+					case strings.Contains(qry, "`auth_type`"):
+						id := "00000000-0000-0000-0000-000000000000"
+						row := clickhouseclient.Row{}
+						row.Set("name", "john")
+						row.Set("id", &id)
+						row.Set("auth_type", "sha256_password")
+						return []clickhouseclient.Row{row}
+					case strings.Contains(qry, "`system`.`settings_profile_elements`"):
+						ret := make([]clickhouseclient.Row, 0)
+						for _, p := range tt.profiles {
+							row := clickhouseclient.Row{}
+							row.Set("inherit_profile", &p)
+							ret = append(ret, row)
+						}
+						return ret
+					}
+					return nil
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			_, err = client.UpdateUserSettingsProfile(context.Background(), "john", tt.oldProfile, tt.newProfile, nil)
+			if err != nil {
+				t.Fatalf("UpdateUserSettingsProfile() error = %v", err)
+			}
+
+			if tt.wantAlter == "" {
+				if len(fake.execs) != 0 {
+					t.Errorf("UpdateUserSettingsProfile() ran %v, want no query", fake.execs)
+				}
+				return
+			}
+			if len(fake.execs) != 1 || fake.execs[0] != tt.wantAlter {
+				t.Errorf("UpdateUserSettingsProfile() ran %v, want %q", fake.execs, tt.wantAlter)
+			}
+		})
+	}
+}
+
+func Test_UpdateUser_defaultRoles(t *testing.T) {
+	tests := []struct {
+		name         string
+		current      []string
+		defaultRoles []string
+		want         []string
+	}{
+		{
+			name:         "Default role changed",
+			current:      []string{"reader"},
+			defaultRoles: []string{"writer"},
+			want:         []string{"ALTER USER `john` DEFAULT ROLE `writer`;"},
+		},
+		{
+			name:         "Default role removed",
+			current:      []string{"reader"},
+			defaultRoles: []string{},
+			want:         []string{"ALTER USER `john` DEFAULT ROLE NONE;"},
+		},
+		{
+			name:         "Same default roles in another order",
+			current:      []string{"reader", "writer"},
+			defaultRoles: []string{"writer", "reader"},
+			want:         nil,
+		},
+		{
+			name:         "Default roles not managed",
+			current:      []string{"reader"},
+			defaultRoles: nil,
+			want:         nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					row := clickhouseclient.Row{}
+					switch {
+					case strings.Contains(qry, "`system`.`columns`"):
+						return systemColumnRows(qry, "default_roles_list")
+					case strings.Contains(qry, "`auth_type`"):
+						id := "00000000-0000-0000-0000-000000000000"
+						row.Set("name", "john")
+						row.Set("id", &id)
+						row.Set("auth_type", "sha256_password")
+						row.Set("default_roles_list", tt.current)
+					default:
+						return nil
+					}
+					return []clickhouseclient.Row{row}
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			_, err = client.UpdateUser(context.Background(), User{ID: "john", Name: "john", DefaultRoles: tt.defaultRoles}, nil)
+			if err != nil {
+				t.Fatalf("UpdateUser() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(fake.execs, tt.want) {
+				t.Errorf("UpdateUser() queries = %q, want %q", fake.execs, tt.want)
+			}
+		})
+	}
+}
+
+func Test_GetUserByName_defaultRoles(t *testing.T) {
+	tests := []struct {
+		name         string
+		defaultRoles []string
+		want         []string
+	}{
+		{
+			name:         "Default role changed externally",
+			defaultRoles: []string{"writer"},
+			want:         []string{"writer"},
+		},
+		{
+			name:         "Several default roles",
+			defaultRoles: []string{"reader", "writer"},
+			want:         []string{"reader", "writer"},
+		},
+		{
+			name:         "No default role",
+			defaultRoles: []string{},
+			want:         []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					row := clickhouseclient.Row{}
+					switch {
+					case strings.Contains(qry, "`system`.`columns`"):
+						return systemColumnRows(qry, "default_roles_list")
+					case strings.Contains(qry, "`auth_type`"):
+						id := "00000000-0000-0000-0000-000000000000"
+						row.Set("name", "john")
+						row.Set("id", &id)
+						row.Set("auth_type", "sha256_password")
+						row.Set("default_roles_list", tt.defaultRoles)
+					default:
+						return nil
+					}
+					return []clickhouseclient.Row{row}
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			user, err := client.GetUserByName(context.Background(), "john", nil)
+			if err != nil {
+				t.Fatalf("GetUserByName() error = %v", err)
+			}
+			if user == nil {
+				t.Fatalf("GetUserByName() returned nil user")
+			}
+			if !reflect.DeepEqual(user.DefaultRoles, tt.want) {
+				t.Errorf("GetUserByName() DefaultRoles = %q, want %q", user.DefaultRoles, tt.want)
+			}
+		})
+	}
+}
+
+func Test_UpdateUser_renameOnCluster(t *testing.T) {
+	clusterName := "cluster1"
+
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			row := clickhouseclient.Row{}
+			switch {
+			case strings.Contains(qry, "`system`.`user_directories`"):
+				row.Set("type", "replicated")
+				row.Set("precedence", uint64(0))
+			case strings.Contains(qry, "`auth_type`"):
+				id := "00000000-0000-0000-0000-000000000000"
+				row.Set("name", "john")
+				row.Set("id", &id)
+				row.Set("auth_type", "sha256_password")
+			default:
+				return nil
+			}
+			return []clickhouseclient.Row{row}
+		},
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	_, err = client.UpdateUser(context.Background(), User{ID: "john", Name: "jack"}, &clusterName)
+	if err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+
+	want := []string{"ALTER USER `john` RENAME TO `jack` ON CLUSTER 'cluster1';"}
+	if !reflect.DeepEqual(fake.execs, want) {
+		t.Errorf("UpdateUser() queries = %q, want %q", fake.execs, want)
+	}
+}
+
+func Test_CreateUser_comment(t *testing.T) {
+	tests := []struct {
+		name      string
+		supported bool
+		want      string
+	}{
+		{
+			name:      "Comments supported",
+			supported: true,
+			want:      "CREATE USER IF NOT EXISTS `john` IDENTIFIED WITH no_password COMMENT 'owner: team-data';",
+		},
+		{
+			name:      "Comments not supported",
+			supported: false,
+			want:      "CREATE USER IF NOT EXISTS `john` IDENTIFIED WITH no_password;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					row := clickhouseclient.Row{}
+					switch {
+					case strings.Contains(qry, "`system`.`columns`"):
+						if !tt.supported {
+							return nil
+						}
+						return systemColumnRows(qry, "comment")
+					case strings.Contains(qry, "`auth_type`"):
+						id := "00000000-0000-0000-0000-000000000000"
+						row.Set("name", "john")
+						row.Set("id", &id)
+						row.Set("auth_type", "no_password")
+						row.Set("comment", "owner: team-data")
+					default:
+						return nil
+					}
+					return []clickhouseclient.Row{row}
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			comment := "owner: team-data"
+			user, err := client.CreateUser(context.Background(), User{Name: "john", NoPassword: true, Comment: &comment}, nil)
+			if err != nil {
+				t.Fatalf("CreateUser() error = %v", err)
+			}
+
+			if len(fake.execs) != 1 || fake.execs[0] != tt.want {
+				t.Errorf("CreateUser() queries = %q, want %q", fake.execs, tt.want)
+			}
+			if tt.supported && (user.Comment == nil || *user.Comment != comment) {
+				t.Errorf("CreateUser() Comment = %v, want %q", user.Comment, comment)
+			}
+			if !tt.supported && user.Comment != nil {
+				t.Errorf("CreateUser() Comment = %q, want nil", *user.Comment)
+			}
+		})
+	}
+}
+
+func Test_UpdateUser_comment(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name      string
+		supported bool
+		comment   *string
+		want      []string
+	}{
+		{
+			name:      "Comment not managed",
+			supported: true,
+			comment:   nil,
+			want:      nil,
+		},
+		{
+			name:      "Comment unchanged",
+			supported: true,
+			comment:   strPtr("owner: team-data"),
+			want:      nil,
+		},
+		{
+			name:      "Comment changed",
+			supported: true,
+			comment:   strPtr("owner: team-ops"),
+			want:      []string{"ALTER USER `john` COMMENT 'owner: team-ops';"},
+		},
+		{
+			name:      "Comment removed",
+			supported: true,
+			comment:   strPtr(""),
+			want:      []string{"ALTER USER `john` COMMENT '';"},
+		},
+		{
+			name:      "Comments not supported",
+			supported: false,
+			comment:   strPtr("owner: team-ops"),
+			want:      nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					row := clickhouseclient.Row{}
+					switch {
+					case strings.Contains(qry, "`system`.`columns`"):
+						if !tt.supported {
+							return nil
+						}
+						return systemColumnRows(qry, "comment")
+					case strings.Contains(qry, "`auth_type`"):
+						id := "00000000-0000-0000-0000-000000000000"
+						row.Set("name", "john")
+						row.Set("id", &id)
+						row.Set("auth_type", "sha256_password")
+						row.Set("comment", "owner: team-data")
+					default:
+						return nil
+					}
+					return []clickhouseclient.Row{row}
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			_, err = client.UpdateUser(context.Background(), User{ID: "john", Name: "john", Comment: tt.comment}, nil)
+			if err != nil {
+				t.Fatalf("UpdateUser() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(fake.execs, tt.want) {
+				t.Errorf("UpdateUser() queries = %q, want %q", fake.execs, tt.want)
+			}
+		})
+	}
+}
+
+func Test_UpdateUser_sslCertificateCN(t *testing.T) {
+	tests := []struct {
+		name string
+		cn   string
+		want []string
+	}{
+		{
+			name: "CN not managed",
+			cn:   "",
+			want: nil,
+		},
+		{
+			name: "CN unchanged",
+			cn:   "john.example.com",
+			want: nil,
+		},
+		{
+			name: "CN changed",
+			cn:   "jane.example.com",
+			want: []string{"ALTER USER `john` IDENTIFIED WITH ssl_certificate CN 'jane.example.com';"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					row := clickhouseclient.Row{}
+					switch {
+					case strings.Contains(qry, "`system`.`columns`"):
+						return systemColumnRows(qry, "auth_params")
+					case strings.Contains(qry, "`auth_type`"):
+						id := "00000000-0000-0000-0000-000000000000"
+						row.Set("name", "john")
+						row.Set("id", &id)
+						row.Set("auth_type", "ssl_certificate")
+						row.Set("auth_params", `{"common_names":["john.example.com"]}`)
+					default:
+						return nil
+					}
+					return []clickhouseclient.Row{row}
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			_, err = client.UpdateUser(context.Background(), User{ID: "john", Name: "john", SSLCertificateCN: tt.cn}, nil)
+			if err != nil {
+				t.Fatalf("UpdateUser() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(fake.execs, tt.want) {
+				t.Errorf("UpdateUser() queries = %q, want %q", fake.execs, tt.want)
+			}
+		})
+	}
+}
+
+func Test_UpdateUser_grantees(t *testing.T) {
+	tests := []struct {
+		name     string
+		grantees *UserGrantees
+		want     []string
+	}{
+		{
+			name:     "Grantees changed",
+			grantees: &UserGrantees{Names: []string{"jane"}},
+			want:     []string{"ALTER USER `john` GRANTEES `jane`;"},
+		},
+		{
+			name:     "Same grantees in another order",
+			grantees: &UserGrantees{Names: []string{"reader", "jane"}},
+			want:     nil,
+		},
+		{
+			name:     "Grantees not managed",
+			grantees: nil,
+			want:     nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					row := clickhouseclient.Row{}
+					switch {
+					case strings.Contains(qry, "`system`.`columns`"):
+						return systemColumnRows(qry, "grantees_any")
+					case strings.Contains(qry, "`auth_type`"):
+						id := "00000000-0000-0000-0000-000000000000"
+						row.Set("name", "john")
+						row.Set("id", &id)
+						row.Set("auth_type", "sha256_password")
+						row.Set("grantees_any", uint8(0))
+						row.Set("grantees_list", []string{"jane", "reader"})
+						row.Set("grantees_except", []string{})
+					default:
+						return nil
+					}
+					return []clickhouseclient.Row{row}
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			_, err = client.UpdateUser(context.Background(), User{ID: "john", Name: "john", Grantees: tt.grantees}, nil)
+			if err != nil {
+				t.Fatalf("UpdateUser() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(fake.execs, tt.want) {
+				t.Errorf("UpdateUser() queries = %q, want %q", fake.execs, tt.want)
+			}
+		})
+	}
+}
+
+func timePtr(val time.Time) *time.Time {
+	return &val
+}
+
+func Test_UpdateUser_bcryptPassword(t *testing.T) {
+	hash := "$2a$12$R9h/cIPz0gi.URNNX3kh2OPST9/PgBkqquzi.Ss7KIUgO2t0jWMUW"
+
+	tests := []struct {
+		name string
+		user User
+		want []string
+	}{
+		{
+			name: "Password changed",
+			user: User{ID: "john", Name: "john", PasswordBcryptHash: hash},
+			want: []string{"ALTER USER `john` IDENTIFIED WITH bcrypt_hash BY '" + hash + "';"},
+		},
+		{
+			name: "Bcrypt password in the identification methods",
+			user: User{ID: "john", Name: "john", Authentications: []UserAuthentication{
+				{Type: AuthTypeBcryptPassword, Value: hash},
+				{Type: AuthTypeSSLCertificate, Value: "john.example.com"},
+			}},
+			want: []string{"ALTER USER `john` IDENTIFIED WITH bcrypt_hash BY '" + hash + "', ssl_certificate CN 'john.example.com';"},
+		},
+		{
+			name: "Password not changed",
+			user: User{ID: "john", Name: "john"},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					if !strings.Contains(qry, "`auth_type`") {
+						return nil
+					}
+					id := "00000000-0000-0000-0000-000000000000"
+					row := clickhouseclient.Row{}
+					row.Set("name", "john")
+					row.Set("id", &id)
+					row.Set("auth_type", "bcrypt_password")
+					return []clickhouseclient.Row{row}
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			_, err = client.UpdateUser(context.Background(), tt.user, nil)
+			if err != nil {
+				t.Fatalf("UpdateUser() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(fake.execs, tt.want) {
+				t.Errorf("UpdateUser() queries = %q, want %q", fake.execs, tt.want)
+			}
+		})
+	}
+}
+
+func Test_UserExists(t *testing.T) {
+	tests := []struct {
+		name      string
+		count     uint64
+		denied    bool
+		want      bool
+		wantQuery string
+	}{
+		{
+			name:      "User exists",
+			count:     1,
+			want:      true,
+			wantQuery: "SELECT count() AS `cnt` FROM `system`.`users` WHERE (`name` = 'john');",
+		},
+		{
+			name:      "User not found",
+			count:     0,
+			want:      false,
+			wantQuery: "SELECT count() AS `cnt` FROM `system`.`users` WHERE (`name` = 'john');",
+		},
+		{
+			name:      "Not allowed to count users",
+			denied:    true,
+			want:      true,
+			wantQuery: "SELECT `name` FROM `system`.`users` WHERE (`name` = 'john');",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var queries []string
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					queries = append(queries, qry)
+					row := clickhouseclient.Row{}
+					if strings.Contains(qry, "count()") {
+						row.Set("cnt", tt.count)
+					} else {
+						row.Set("name", "john")
+					}
+					return []clickhouseclient.Row{row}
+				},
+			}
+			if tt.denied {
+				fake.selectErr = func(qry string) error {
+					if strings.Contains(qry, "count()") {
+						return errors.New("code: 497, message: john: Not enough privileges")
+					}
+					return nil
+				}
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			got, err := client.UserExists(context.Background(), "john", nil)
+			if err != nil {
+				t.Fatalf("UserExists() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("UserExists() = %v, want %v", got, tt.want)
+			}
+			if len(queries) == 0 || queries[len(queries)-1] != tt.wantQuery {
+				t.Errorf("UserExists() queries = %q, want last query %q", queries, tt.wantQuery)
+			}
+		})
+	}
+}
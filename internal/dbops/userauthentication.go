@@ -0,0 +1,104 @@
+package dbops
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pingcap/errors"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/querybuilder"
+)
+
+const (
+	// AuthTypeSHA256Password is the 'auth_type' of users authenticating with a SHA256 password hash.
+	AuthTypeSHA256Password = "sha256_password"
+	// AuthTypeBcryptPassword is the 'auth_type' of users authenticating with a bcrypt password hash.
+	AuthTypeBcryptPassword = "bcrypt_password"
+	// AuthTypePlaintextPassword is the 'auth_type' of users authenticating with a password stored as is.
+	AuthTypePlaintextPassword = "plaintext_password"
+	// AuthTypeNoPassword is the 'auth_type' of users that don't need any credentials to log in.
+	AuthTypeNoPassword = "no_password"
+	// AuthTypeSSLCertificate is the 'auth_type' of users authenticating with an SSL certificate.
+	AuthTypeSSLCertificate = "ssl_certificate"
+)
+
+// UserAuthentication is one of the identification methods of a user.
+// Value is the hash of the password for sha256_password and bcrypt_password, the password for plaintext_password,
+// neither of which is read back, and the certificate common name for ssl_certificate. It's empty for no_password.
+type UserAuthentication struct {
+	Type  string
+	Value string
+}
+
+// toQueryBuilderAuthentications returns the identification methods of the IDENTIFIED clause matching the given ones.
+func toQueryBuilderAuthentications(authentications []UserAuthentication) []querybuilder.Authentication {
+	ret := make([]querybuilder.Authentication, 0)
+	for _, a := range authentications {
+		with := querybuilder.IdentificationSHA256Hash
+		switch a.Type {
+		case AuthTypeBcryptPassword:
+			with = querybuilder.IdentificationBcryptHash
+		case AuthTypePlaintextPassword:
+			with = querybuilder.IdentificationPlaintext
+		case AuthTypeNoPassword:
+			with = querybuilder.IdentificationNoPassword
+		case AuthTypeSSLCertificate:
+			with = querybuilder.IdentificationSSLCertificate
+		}
+
+		ret = append(ret, querybuilder.Authentication{With: with, Value: a.Value})
+	}
+
+	return ret
+}
+
+// parseAuthTypes returns the authentication methods out of the 'auth_type' column converted to string.
+// Recent ClickHouse versions allow multiple authentication methods and return an array like
+// ['ssl_certificate','sha256_password'], while older ones return a single method.
+func parseAuthTypes(value string) []string {
+	ret := make([]string, 0)
+	for _, t := range strings.Split(strings.Trim(value, "[]"), ",") {
+		t = strings.Trim(strings.TrimSpace(t), "'\"")
+		if t != "" {
+			ret = append(ret, t)
+		}
+	}
+
+	return ret
+}
+
+// authenticationsFromColumns pairs the authentication methods of a user with their 'auth_params', which are
+// reported in the same order. The common name of SSL certificates is the first one in the parameters.
+func authenticationsFromColumns(authTypes []string, authParams []string) []UserAuthentication {
+	ret := make([]UserAuthentication, 0)
+	for idx, t := range authTypes {
+		a := UserAuthentication{Type: t}
+		if t == AuthTypeSSLCertificate && idx < len(authParams) {
+			var parsed struct {
+				CommonNames []string `json:"common_names"`
+			}
+			if err := json.Unmarshal([]byte(authParams[idx]), &parsed); err == nil && len(parsed.CommonNames) > 0 {
+				a.Value = parsed.CommonNames[0]
+			}
+		}
+		ret = append(ret, a)
+	}
+
+	return ret
+}
+
+// authParamsFromRow returns the 'auth_params' of the user, one JSON object per authentication method.
+// 'auth_params' is a single JSON object on ClickHouse versions not supporting multiple authentication methods.
+func authParamsFromRow(data clickhouseclient.Row) ([]string, error) {
+	values, err := data.GetStringSlice("auth_params")
+	if err != nil {
+		single, err := data.GetString("auth_params")
+		if err != nil {
+			return nil, errors.WithMessage(err, "error scanning query result, missing 'auth_params' field")
+		}
+		values = []string{single}
+	}
+
+	return values, nil
+}
@@ -0,0 +1,92 @@
+package dbops
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+)
+
+func Test_authenticationsFromColumns(t *testing.T) {
+	tests := []struct {
+		name       string
+		authType   string
+		authParams []string
+		want       []UserAuthentication
+	}{
+		{
+			name:       "Single method",
+			authType:   "sha256_password",
+			authParams: []string{`{}`},
+			want:       []UserAuthentication{{Type: AuthTypeSHA256Password}},
+		},
+		{
+			name:       "Password and SSL certificate",
+			authType:   "['sha256_password','ssl_certificate']",
+			authParams: []string{`{}`, `{"common_names":["john.example.com"]}`},
+			want: []UserAuthentication{
+				{Type: AuthTypeSHA256Password},
+				{Type: AuthTypeSSLCertificate, Value: "john.example.com"},
+			},
+		},
+		{
+			name:       "Missing parameters",
+			authType:   "['ssl_certificate']",
+			authParams: nil,
+			want:       []UserAuthentication{{Type: AuthTypeSSLCertificate}},
+		},
+		{
+			name:     "No method",
+			authType: "[]",
+			want:     []UserAuthentication{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := authenticationsFromColumns(parseAuthTypes(tt.authType), tt.authParams)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("authenticationsFromColumns() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_UpdateUser_authentications(t *testing.T) {
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			if !strings.Contains(qry, "`auth_type`") {
+				return nil
+			}
+			id := "00000000-0000-0000-0000-000000000000"
+			row := clickhouseclient.Row{}
+			row.Set("name", "john")
+			row.Set("id", &id)
+			row.Set("auth_type", "sha256_password")
+			return []clickhouseclient.Row{row}
+		},
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	_, err = client.UpdateUser(context.Background(), User{
+		ID:   "john",
+		Name: "john",
+		Authentications: []UserAuthentication{
+			{Type: AuthTypeSHA256Password, Value: "blah"},
+			{Type: AuthTypeSSLCertificate, Value: "john.example.com"},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+
+	want := []string{"ALTER USER `john` IDENTIFIED WITH sha256_hash BY 'blah', ssl_certificate CN 'john.example.com';"}
+	if !reflect.DeepEqual(fake.execs, want) {
+		t.Errorf("UpdateUser() queries = %q, want %q", fake.execs, want)
+	}
+}
@@ -0,0 +1,131 @@
+package dbops
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pingcap/errors"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/querybuilder"
+)
+
+// UserGrantees is the set of users and roles a user can grant its privileges and roles to, i.e. its GRANTEES clause.
+type UserGrantees struct {
+	// Any allows granting to every user and role but the ones in Except.
+	Any    bool
+	Names  []string
+	Except []string
+}
+
+// GetUserGrantees returns the grantees of the user with the given ID or name, or nil if the user doesn't exist.
+func (i *impl) GetUserGrantees(ctx context.Context, userID string, clusterName *string) (*UserGrantees, error) {
+	clusterName = i.withDefaultCluster(clusterName)
+	name, err := i.resolveUserName(ctx, userID, clusterName)
+	if err != nil {
+		return nil, errors.WithMessage(err, "error getting user")
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	fields := make([]querybuilder.Field, 0)
+	for _, field := range userGranteesFields {
+		fields = append(fields, querybuilder.NewField(field))
+	}
+
+	sql, err := i.
+		newSelect(fields, "system.users").
+		WithCluster(clusterName).
+		Where(querybuilder.WhereEquals("name", name)).
+		Build()
+	if err != nil {
+		return nil, errors.WithMessage(err, "error building query")
+	}
+
+	var grantees *UserGrantees
+	err = i.clickhouseClient.Select(ctx, sql, func(data clickhouseclient.Row) error {
+		grantees, err = userGranteesFromRow(data)
+		return err
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "error running query")
+	}
+
+	return grantees, nil
+}
+
+// userGranteesFields are the grantees columns of system.users.
+var userGranteesFields = []string{"grantees_any", "grantees_list", "grantees_except"}
+
+// userGranteesFromRow returns the grantees of the user out of the grantees columns of system.users.
+func userGranteesFromRow(data clickhouseclient.Row) (*UserGrantees, error) {
+	anyGrantee, err := data.GetBool("grantees_any")
+	if err != nil {
+		return nil, errors.WithMessage(err, "error scanning query result, missing 'grantees_any' field")
+	}
+	names, err := data.GetStringSlice("grantees_list")
+	if err != nil {
+		return nil, errors.WithMessage(err, "error scanning query result, missing 'grantees_list' field")
+	}
+	except, err := data.GetStringSlice("grantees_except")
+	if err != nil {
+		return nil, errors.WithMessage(err, "error scanning query result, missing 'grantees_except' field")
+	}
+
+	return &UserGrantees{
+		Any:    anyGrantee,
+		Names:  names,
+		Except: except,
+	}, nil
+}
+
+// SetUserGrantees replaces the grantees of the user with the given ID or name.
+// Nothing else about the user is changed, so that it doesn't conflict with the user being managed separately.
+func (i *impl) SetUserGrantees(ctx context.Context, userID string, grantees UserGrantees, clusterName *string) error {
+	clusterName = i.withDefaultCluster(clusterName)
+	name, err := i.resolveUserName(ctx, userID, clusterName)
+	if err != nil {
+		return errors.WithMessage(err, "error getting user")
+	}
+	if name == "" {
+		return errors.New(fmt.Sprintf("user with id %q was not found", userID))
+	}
+
+	sql, err := querybuilder.
+		NewAlterUser(name).
+		WithCluster(clusterName).
+		SetGrantees(grantees.toQueryBuilder()).
+		Build()
+	if err != nil {
+		return errors.WithMessage(err, "error building query")
+	}
+
+	err = i.execWithContext(ctx, sql, clusterName)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (g UserGrantees) toQueryBuilder() querybuilder.Grantees {
+	return querybuilder.Grantees{
+		Any:    g.Any,
+		Names:  g.Names,
+		Except: g.Except,
+	}
+}
+
+// sameGrantees returns true if both grantees allow the same users and roles, regardless of the order of names.
+func sameGrantees(a UserGrantees, b UserGrantees) bool {
+	if a.Any != b.Any {
+		return false
+	}
+
+	if a.Any {
+		return sameElements(a.Except, b.Except)
+	}
+
+	return sameElements(a.Names, b.Names)
+}
@@ -0,0 +1,99 @@
+package dbops
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+)
+
+func Test_GetUserGrantees(t *testing.T) {
+	fake := &fakeClickhouseClient{
+		rows: func(qry string) []clickhouseclient.Row {
+			if !strings.Contains(qry, "`grantees_any`") || !strings.Contains(qry, "`name` = 'john'") {
+				return nil
+			}
+
+			row := clickhouseclient.Row{}
+			row.Set("grantees_any", uint8(1))
+			row.Set("grantees_list", []string{})
+			row.Set("grantees_except", []string{"admin"})
+			return []clickhouseclient.Row{row}
+		},
+	}
+
+	client, err := NewClient(fake)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	got, err := client.GetUserGrantees(context.Background(), "john", nil)
+	if err != nil {
+		t.Fatalf("GetUserGrantees() error = %v", err)
+	}
+	want := &UserGrantees{Any: true, Names: []string{}, Except: []string{"admin"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetUserGrantees() = %+v, want %+v", got, want)
+	}
+
+	got, err = client.GetUserGrantees(context.Background(), "jane", nil)
+	if err != nil {
+		t.Fatalf("GetUserGrantees() error = %v", err)
+	}
+	if got != nil {
+		t.Errorf("GetUserGrantees() = %+v, want nil for a missing user", got)
+	}
+}
+
+func Test_SetUserGrantees(t *testing.T) {
+	tests := []struct {
+		name     string
+		userID   string
+		grantees UserGrantees
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "Names",
+			userID:   "john",
+			grantees: UserGrantees{Names: []string{"reader"}},
+			want:     []string{"ALTER USER `john` GRANTEES `reader`;"},
+		},
+		{
+			name:     "Any except",
+			userID:   "john",
+			grantees: UserGrantees{Any: true, Except: []string{"admin"}},
+			want:     []string{"ALTER USER `john` GRANTEES ANY EXCEPT `admin`;"},
+		},
+		{
+			name:     "Missing user",
+			userID:   "00000000-0000-0000-0000-000000000000",
+			grantees: UserGrantees{Any: true},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					return nil
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			err = client.SetUserGrantees(context.Background(), tt.userID, tt.grantees, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetUserGrantees() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(fake.execs, tt.want) {
+				t.Errorf("SetUserGrantees() queries = %q, want %q", fake.execs, tt.want)
+			}
+		})
+	}
+}
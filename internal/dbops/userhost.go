@@ -0,0 +1,186 @@
+package dbops
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/pingcap/errors"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/querybuilder"
+)
+
+const (
+	HostTypeLocal  = string(querybuilder.HostTypeLocal)
+	HostTypeIP     = string(querybuilder.HostTypeIP)
+	HostTypeName   = string(querybuilder.HostTypeName)
+	HostTypeRegexp = string(querybuilder.HostTypeRegexp)
+	HostTypeLike   = string(querybuilder.HostTypeLike)
+	HostTypeAny    = string(querybuilder.HostTypeAny)
+	HostTypeNone   = string(querybuilder.HostTypeNone)
+)
+
+const (
+	// anyHostIP is how system.users reports HOST ANY in the 'host_ip' column.
+	anyHostIP = "::/0"
+	// localHostName is how system.users reports HOST LOCAL in the 'host_names' column.
+	localHostName = "localhost"
+)
+
+// UserHost restricts the hosts a user can connect from, e.g. type IP with the subnets allowed.
+// Values are ignored for LOCAL, ANY and NONE.
+type UserHost struct {
+	Type   string
+	Values []string
+}
+
+// toQueryBuilderHosts returns the entries of the HOST clause matching the given hosts.
+func toQueryBuilderHosts(hosts []UserHost) []querybuilder.Host {
+	ret := make([]querybuilder.Host, 0)
+	for _, h := range hosts {
+		hostType := querybuilder.HostType(h.Type)
+		switch hostType {
+		case querybuilder.HostTypeLocal, querybuilder.HostTypeAny, querybuilder.HostTypeNone:
+			ret = append(ret, querybuilder.Host{Type: hostType})
+		default:
+			for _, v := range h.Values {
+				ret = append(ret, querybuilder.Host{Type: hostType, Value: v})
+			}
+		}
+	}
+
+	return ret
+}
+
+// SameHosts returns true if both lists allow connections from the same hosts, regardless of their order.
+func SameHosts(a []UserHost, b []UserHost) bool {
+	expandedA := expandHosts(a)
+	expandedB := expandHosts(b)
+
+	return slices.Equal(expandedA, expandedB)
+}
+
+// expandHosts returns a sorted, deduplicated entry for every single host allowed.
+// NAME 'localhost' is the same as LOCAL, and NONE allows no host at all.
+func expandHosts(hosts []UserHost) []string {
+	ret := make([]string, 0)
+	for _, h := range hosts {
+		switch h.Type {
+		case HostTypeNone:
+		case HostTypeLocal, HostTypeAny:
+			ret = append(ret, h.Type)
+		default:
+			for _, v := range h.Values {
+				if h.Type == HostTypeName && v == localHostName {
+					ret = append(ret, HostTypeLocal)
+					continue
+				}
+				ret = append(ret, h.Type+" "+v)
+			}
+		}
+	}
+
+	slices.Sort(ret)
+	return slices.Compact(ret)
+}
+
+// hostsDelta returns the hosts to add and to drop to go from the current hosts to the planned ones.
+// It returns false when the whole HOST clause must be rewritten instead: when the current hosts are unknown,
+// or when ANY or NONE is involved, since they can't be added or dropped.
+func hostsDelta(current []UserHost, planned []UserHost) ([]querybuilder.Host, []querybuilder.Host, bool) {
+	if current == nil {
+		return nil, nil, false
+	}
+
+	for _, h := range slices.Concat(current, planned) {
+		if h.Type == HostTypeAny || h.Type == HostTypeNone {
+			return nil, nil, false
+		}
+	}
+
+	currentHosts := normalizeHosts(toQueryBuilderHosts(current))
+	plannedHosts := normalizeHosts(toQueryBuilderHosts(planned))
+	if len(plannedHosts) == 0 {
+		return nil, nil, false
+	}
+
+	add := make([]querybuilder.Host, 0)
+	for _, h := range plannedHosts {
+		if !slices.Contains(currentHosts, h) {
+			add = append(add, h)
+		}
+	}
+
+	drop := make([]querybuilder.Host, 0)
+	for _, h := range currentHosts {
+		if !slices.Contains(plannedHosts, h) {
+			drop = append(drop, h)
+		}
+	}
+
+	return add, drop, true
+}
+
+// normalizeHosts returns the given hosts without duplicates, with NAME 'localhost' replaced by LOCAL.
+func normalizeHosts(hosts []querybuilder.Host) []querybuilder.Host {
+	ret := make([]querybuilder.Host, 0)
+	for _, h := range hosts {
+		if h.Type == querybuilder.HostTypeName && h.Value == localHostName {
+			h = querybuilder.Host{Type: querybuilder.HostTypeLocal}
+		}
+		if !slices.Contains(ret, h) {
+			ret = append(ret, h)
+		}
+	}
+
+	return ret
+}
+
+// hostsFromColumns converts the host columns of system.users to the hosts a user can connect from.
+func hostsFromColumns(ips []string, names []string, regexps []string, likes []string) []UserHost {
+	if slices.Contains(ips, anyHostIP) {
+		return []UserHost{{Type: HostTypeAny}}
+	}
+
+	hosts := make([]UserHost, 0)
+	if slices.Contains(names, localHostName) {
+		hosts = append(hosts, UserHost{Type: HostTypeLocal})
+		names = slices.DeleteFunc(slices.Clone(names), func(name string) bool {
+			return name == localHostName
+		})
+	}
+
+	for _, h := range []UserHost{
+		{Type: HostTypeIP, Values: ips},
+		{Type: HostTypeName, Values: names},
+		{Type: HostTypeRegexp, Values: regexps},
+		{Type: HostTypeLike, Values: likes},
+	} {
+		if len(h.Values) > 0 {
+			hosts = append(hosts, h)
+		}
+	}
+
+	if len(hosts) == 0 {
+		return []UserHost{{Type: HostTypeNone}}
+	}
+
+	return hosts
+}
+
+// userHostFields are the host columns of system.users.
+var userHostFields = []string{"host_ip", "host_names", "host_names_regexp", "host_names_like"}
+
+// userHostsFromRow returns the hosts the user can connect from, out of the host columns of system.users.
+func userHostsFromRow(data clickhouseclient.Row) ([]UserHost, error) {
+	columns := make([][]string, 0)
+	for _, field := range userHostFields {
+		values, err := data.GetStringSlice(field)
+		if err != nil {
+			return nil, errors.WithMessage(err, fmt.Sprintf("error scanning query result, missing '%s' field", field))
+		}
+		columns = append(columns, values)
+	}
+
+	return hostsFromColumns(columns[0], columns[1], columns[2], columns[3]), nil
+}
@@ -0,0 +1,193 @@
+package dbops
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+)
+
+func Test_hostsFromColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		ips     []string
+		names   []string
+		regexps []string
+		likes   []string
+		want    []UserHost
+	}{
+		{
+			name: "Any host",
+			ips:  []string{"::/0"},
+			want: []UserHost{{Type: HostTypeAny}},
+		},
+		{
+			name: "No host",
+			want: []UserHost{{Type: HostTypeNone}},
+		},
+		{
+			name:    "Local host along with others",
+			ips:     []string{"10.0.0.0/8"},
+			names:   []string{"localhost", "bastion.example.com"},
+			regexps: []string{".*\\.example\\.com"},
+			likes:   []string{"%.example.com"},
+			want: []UserHost{
+				{Type: HostTypeLocal},
+				{Type: HostTypeIP, Values: []string{"10.0.0.0/8"}},
+				{Type: HostTypeName, Values: []string{"bastion.example.com"}},
+				{Type: HostTypeRegexp, Values: []string{".*\\.example\\.com"}},
+				{Type: HostTypeLike, Values: []string{"%.example.com"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hostsFromColumns(tt.ips, tt.names, tt.regexps, tt.likes)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("hostsFromColumns() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_SameHosts(t *testing.T) {
+	tests := []struct {
+		name string
+		a    []UserHost
+		b    []UserHost
+		want bool
+	}{
+		{
+			name: "Different order",
+			a: []UserHost{
+				{Type: HostTypeIP, Values: []string{"10.0.0.0/8", "192.168.0.1"}},
+				{Type: HostTypeLocal},
+			},
+			b: []UserHost{
+				{Type: HostTypeLocal},
+				{Type: HostTypeIP, Values: []string{"192.168.0.1", "10.0.0.0/8"}},
+			},
+			want: true,
+		},
+		{
+			name: "Local is the same as localhost name",
+			a:    []UserHost{{Type: HostTypeLocal}},
+			b:    []UserHost{{Type: HostTypeName, Values: []string{"localhost"}}},
+			want: true,
+		},
+		{
+			name: "Values split across entries",
+			a:    []UserHost{{Type: HostTypeIP, Values: []string{"10.0.0.0/8", "192.168.0.1"}}},
+			b: []UserHost{
+				{Type: HostTypeIP, Values: []string{"10.0.0.0/8"}},
+				{Type: HostTypeIP, Values: []string{"192.168.0.1"}},
+			},
+			want: true,
+		},
+		{
+			name: "Additional host",
+			a:    []UserHost{{Type: HostTypeIP, Values: []string{"10.0.0.0/8"}}},
+			b:    []UserHost{{Type: HostTypeIP, Values: []string{"10.0.0.0/8", "192.168.0.1"}}},
+			want: false,
+		},
+		{
+			name: "Same value with a different type",
+			a:    []UserHost{{Type: HostTypeName, Values: []string{"example.com"}}},
+			b:    []UserHost{{Type: HostTypeLike, Values: []string{"example.com"}}},
+			want: false,
+		},
+		{
+			name: "Any host is not no host",
+			a:    []UserHost{{Type: HostTypeAny}},
+			b:    []UserHost{{Type: HostTypeNone}},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SameHosts(tt.a, tt.b); got != tt.want {
+				t.Errorf("SameHosts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_UpdateUser_hosts(t *testing.T) {
+	tests := []struct {
+		name  string
+		hosts []UserHost
+		want  []string
+	}{
+		{
+			name:  "Hosts added",
+			hosts: []UserHost{{Type: HostTypeIP, Values: []string{"10.0.0.0/8"}}, {Type: HostTypeLocal}, {Type: HostTypeName, Values: []string{"bastion.example.com"}}},
+			want:  []string{"ALTER USER `john` ADD HOST NAME 'bastion.example.com';"},
+		},
+		{
+			name:  "Hosts dropped",
+			hosts: []UserHost{{Type: HostTypeLocal}},
+			want:  []string{"ALTER USER `john` DROP HOST IP '10.0.0.0/8';"},
+		},
+		{
+			name:  "Hosts added and dropped",
+			hosts: []UserHost{{Type: HostTypeIP, Values: []string{"192.168.0.0/16"}}, {Type: HostTypeName, Values: []string{"localhost"}}},
+			want:  []string{"ALTER USER `john` DROP HOST IP '10.0.0.0/8' ADD HOST IP '192.168.0.0/16';"},
+		},
+		{
+			name:  "Any host",
+			hosts: []UserHost{{Type: HostTypeAny}},
+			want:  []string{"ALTER USER `john` HOST ANY;"},
+		},
+		{
+			name:  "Hosts unchanged",
+			hosts: []UserHost{{Type: HostTypeLocal}, {Type: HostTypeIP, Values: []string{"10.0.0.0/8"}}},
+			want:  nil,
+		},
+		{
+			name:  "Hosts not managed",
+			hosts: nil,
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClickhouseClient{
+				rows: func(qry string) []clickhouseclient.Row {
+					row := clickhouseclient.Row{}
+					switch {
+					case strings.Contains(qry, "`system`.`columns`"):
+						return systemColumnRows(qry, "host_ip")
+					case strings.Contains(qry, "`auth_type`"):
+						id := "00000000-0000-0000-0000-000000000000"
+						row.Set("name", "john")
+						row.Set("id", &id)
+						row.Set("auth_type", "sha256_password")
+						row.Set("host_ip", []string{"10.0.0.0/8"})
+						row.Set("host_names", []string{"localhost"})
+						row.Set("host_names_regexp", []string{})
+						row.Set("host_names_like", []string{})
+					default:
+						return nil
+					}
+					return []clickhouseclient.Row{row}
+				},
+			}
+
+			client, err := NewClient(fake)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			_, err = client.UpdateUser(context.Background(), User{ID: "john", Name: "john", Hosts: tt.hosts}, nil)
+			if err != nil {
+				t.Fatalf("UpdateUser() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(fake.execs, tt.want) {
+				t.Errorf("UpdateUser() queries = %q, want %q", fake.execs, tt.want)
+			}
+		})
+	}
+}
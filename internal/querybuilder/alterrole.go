@@ -12,24 +12,34 @@ type AlterRoleQueryBuilder interface {
 	RenameTo(newName *string) AlterRoleQueryBuilder
 	DropSettingsProfile(profileName *string) AlterRoleQueryBuilder
 	AddSettingsProfile(profileName *string) AlterRoleQueryBuilder
+	DropSettingsProfiles(profileNames []string) AlterRoleQueryBuilder
+	AddSettingsProfiles(profileNames []string) AlterRoleQueryBuilder
 	WithCluster(clusterName *string) AlterRoleQueryBuilder
 	IfExists() AlterRoleQueryBuilder
 	SetSettingsProfile(profileName *string) AlterRoleQueryBuilder
+	AddSetting(name string, value *string, min *string, max *string, writability *string) AlterRoleQueryBuilder
+	AddRawSetting(name string, value *string, min *string, max *string, writability *string) AlterRoleQueryBuilder
+	RemoveSetting(name string) AlterRoleQueryBuilder
+	WithComment(comment *string) AlterRoleQueryBuilder
 }
 
 type alterRoleQueryBuilder struct {
-	resourceName       string
-	oldSettingsProfile *string
-	newSettingsProfile *string
-	newName            *string
-	clusterName        *string
-	setSettingsProfile *string
-	ifExists           bool
+	resourceName        string
+	oldSettingsProfiles []string
+	newSettingsProfiles []string
+	newName             *string
+	clusterName         *string
+	setSettingsProfile  *string
+	ifExists            bool
+	settings            []settingData
+	removeSettings      []string
+	comment             *string
 }
 
 func NewAlterRole(resourceName string) AlterRoleQueryBuilder {
 	return &alterRoleQueryBuilder{
 		resourceName: resourceName,
+		settings:     make([]settingData, 0),
 	}
 }
 
@@ -50,12 +60,64 @@ func (q *alterRoleQueryBuilder) RenameTo(newName *string) AlterRoleQueryBuilder
 }
 
 func (q *alterRoleQueryBuilder) DropSettingsProfile(profileName *string) AlterRoleQueryBuilder {
-	q.oldSettingsProfile = profileName
+	if profileName != nil {
+		q.oldSettingsProfiles = append(q.oldSettingsProfiles, *profileName)
+	}
 	return q
 }
 
 func (q *alterRoleQueryBuilder) AddSettingsProfile(profileName *string) AlterRoleQueryBuilder {
-	q.newSettingsProfile = profileName
+	if profileName != nil {
+		q.newSettingsProfiles = append(q.newSettingsProfiles, *profileName)
+	}
+	return q
+}
+
+// DropSettingsProfiles drops all the given profiles in a single DROP PROFILES clause. Profiles also being added are
+// left untouched.
+func (q *alterRoleQueryBuilder) DropSettingsProfiles(profileNames []string) AlterRoleQueryBuilder {
+	q.oldSettingsProfiles = append(q.oldSettingsProfiles, profileNames...)
+	return q
+}
+
+// AddSettingsProfiles adds all the given profiles in a single ADD PROFILE clause. Profiles also being dropped are
+// left untouched.
+func (q *alterRoleQueryBuilder) AddSettingsProfiles(profileNames []string) AlterRoleQueryBuilder {
+	q.newSettingsProfiles = append(q.newSettingsProfiles, profileNames...)
+	return q
+}
+
+func (q *alterRoleQueryBuilder) AddSetting(name string, value *string, min *string, max *string, writability *string) AlterRoleQueryBuilder {
+	q.settings = append(q.settings, settingData{
+		Name:        name,
+		Value:       value,
+		Min:         min,
+		Max:         max,
+		Writability: writability,
+	})
+	return q
+}
+
+// AddRawSetting adds a setting whose value, min and max are SQL expressions emitted verbatim, e.g. getSetting('max_threads').
+func (q *alterRoleQueryBuilder) AddRawSetting(name string, value *string, min *string, max *string, writability *string) AlterRoleQueryBuilder {
+	q.settings = append(q.settings, settingData{
+		Name:        name,
+		Value:       value,
+		Min:         min,
+		Max:         max,
+		Writability: writability,
+		Raw:         true,
+	})
+	return q
+}
+
+func (q *alterRoleQueryBuilder) RemoveSetting(name string) AlterRoleQueryBuilder {
+	q.removeSettings = append(q.removeSettings, backtick(name))
+	return q
+}
+
+func (q *alterRoleQueryBuilder) WithComment(comment *string) AlterRoleQueryBuilder {
+	q.comment = comment
 	return q
 }
 
@@ -93,16 +155,42 @@ func (q *alterRoleQueryBuilder) Build() (string, error) {
 		anyChanges = true
 		tokens = append(tokens, "SETTINGS", "PROFILE", quote(*q.setSettingsProfile))
 	} else {
-		if q.oldSettingsProfile != nil && (q.newSettingsProfile == nil || *q.oldSettingsProfile != *q.newSettingsProfile) {
+		if drop := profilesNotIn(q.oldSettingsProfiles, q.newSettingsProfiles); len(drop) > 0 {
 			anyChanges = true
-			tokens = append(tokens, "DROP", "PROFILES", quote(*q.oldSettingsProfile))
+			tokens = append(tokens, "DROP", "PROFILES", strings.Join(quoteAll(drop), ", "))
 		}
-		if q.newSettingsProfile != nil && (q.oldSettingsProfile == nil || *q.newSettingsProfile != *q.oldSettingsProfile) {
+		if add := profilesNotIn(q.newSettingsProfiles, q.oldSettingsProfiles); len(add) > 0 {
 			anyChanges = true
-			tokens = append(tokens, "ADD", "PROFILE", quote(*q.newSettingsProfile))
+			tokens = append(tokens, "ADD", "PROFILE", strings.Join(quoteAll(add), ", "))
 		}
 	}
 
+	// Settings
+	if len(q.removeSettings) > 0 {
+		anyChanges = true
+		tokens = append(tokens, "DROP", "SETTINGS", strings.Join(q.removeSettings, ", "))
+	}
+	if len(q.settings) > 0 {
+		anyChanges = true
+
+		each := make([]string, 0)
+		for _, s := range q.settings {
+			sql, err := s.SQLDef()
+			if err != nil {
+				return "", errors.WithMessage(err, "invalid setting")
+			}
+			each = append(each, sql)
+		}
+
+		tokens = append(tokens, "ADD", "SETTINGS", strings.Join(each, ", "))
+	}
+
+	// Comment
+	if q.comment != nil {
+		anyChanges = true
+		tokens = append(tokens, "COMMENT", quote(*q.comment))
+	}
+
 	if !anyChanges {
 		return "", errors.New("no change to be made")
 	}
@@ -56,6 +56,15 @@ func Test_alterRoleQueryBuilder_Build(t *testing.T) {
 			want:               "ALTER ROLE `foo` ON CLUSTER 'cluster1' DROP PROFILES 'old' ADD PROFILE 'profile1';",
 			wantErr:            false,
 		},
+		{
+			name:               "Rename and replace profile on cluster",
+			newSettingsProfile: strPtr("profile1"),
+			oldSettingsProfile: strPtr("old"),
+			newName:            strPtr("test"),
+			clusterName:        strPtr("cluster1"),
+			want:               "ALTER ROLE `foo` RENAME TO `test` ON CLUSTER 'cluster1' DROP PROFILES 'old' ADD PROFILE 'profile1';",
+			wantErr:            false,
+		},
 		{
 			name:    "No profile set",
 			want:    "",
@@ -92,12 +101,12 @@ func Test_alterRoleQueryBuilder_Build(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			q := &alterRoleQueryBuilder{
 				resourceName:       "foo",
-				oldSettingsProfile: tt.oldSettingsProfile,
-				newSettingsProfile: tt.newSettingsProfile,
 				setSettingsProfile: tt.setSettingsProfile,
 				newName:            tt.newName,
 				clusterName:        tt.clusterName,
 			}
+			q.DropSettingsProfile(tt.oldSettingsProfile)
+			q.AddSettingsProfile(tt.newSettingsProfile)
 			got, err := q.Build()
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Build() error = %v, wantErr %v", err, tt.wantErr)
@@ -109,3 +118,136 @@ func Test_alterRoleQueryBuilder_Build(t *testing.T) {
 		})
 	}
 }
+
+func Test_alterRoleQueryBuilder_settings(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder AlterRoleQueryBuilder
+		want    string
+	}{
+		{
+			name:    "Add setting",
+			builder: NewAlterRole("foo").AddSetting("readonly", strPtr("1"), nil, nil, nil),
+			want:    "ALTER ROLE `foo` ADD SETTINGS `readonly` = '1';",
+		},
+		{
+			name:    "Remove setting",
+			builder: NewAlterRole("foo").RemoveSetting("readonly"),
+			want:    "ALTER ROLE `foo` DROP SETTINGS `readonly`;",
+		},
+		{
+			name: "Replace settings and rename on cluster",
+			builder: NewAlterRole("foo").
+				RenameTo(strPtr("bar")).
+				WithCluster(strPtr("cluster1")).
+				RemoveSetting("readonly").
+				AddSetting("max_memory_usage", nil, strPtr("0"), strPtr("1000"), strPtr("WRITABLE")),
+			want: "ALTER ROLE `foo` RENAME TO `bar` ON CLUSTER 'cluster1' DROP SETTINGS `readonly` ADD SETTINGS `max_memory_usage` MIN '0' MAX '1000' WRITABLE;",
+		},
+		{
+			name: "All changes at once on cluster",
+			builder: NewAlterRole("foo").
+				RenameTo(strPtr("bar")).
+				WithCluster(strPtr("cluster1")).
+				DropSettingsProfile(strPtr("old")).
+				AddSettingsProfile(strPtr("profile1")).
+				RemoveSetting("readonly").
+				AddSetting("max_memory_usage", nil, strPtr("0"), strPtr("1000"), strPtr("WRITABLE")).
+				WithComment(strPtr("refactored")),
+			want: "ALTER ROLE `foo` RENAME TO `bar` ON CLUSTER 'cluster1' DROP PROFILES 'old' ADD PROFILE 'profile1' DROP SETTINGS `readonly` ADD SETTINGS `max_memory_usage` MIN '0' MAX '1000' WRITABLE COMMENT 'refactored';",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.builder.Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Build() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_alterRoleQueryBuilder_comment(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder AlterRoleQueryBuilder
+		want    string
+	}{
+		{
+			name:    "Set comment",
+			builder: NewAlterRole("foo").WithComment(strPtr("owner: team-data")),
+			want:    "ALTER ROLE `foo` COMMENT 'owner: team-data';",
+		},
+		{
+			name:    "Clear comment",
+			builder: NewAlterRole("foo").WithComment(strPtr("")),
+			want:    "ALTER ROLE `foo` COMMENT '';",
+		},
+		{
+			name: "Comment with quotes and settings on cluster",
+			builder: NewAlterRole("foo").
+				WithCluster(strPtr("cluster1")).
+				AddSetting("readonly", strPtr("1"), nil, nil, nil).
+				WithComment(strPtr("team's role")),
+			want: "ALTER ROLE `foo` ON CLUSTER 'cluster1' ADD SETTINGS `readonly` = '1' COMMENT 'team\\'s role';",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.builder.Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Build() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_alterRoleQueryBuilder_settingsProfiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		drop    []string
+		add     []string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Add several profiles",
+			add:  []string{"a", "b"},
+			want: "ALTER ROLE `foo` ADD PROFILE 'a', 'b';",
+		},
+		{
+			name: "Drop several profiles",
+			drop: []string{"a", "b"},
+			want: "ALTER ROLE `foo` DROP PROFILES 'a', 'b';",
+		},
+		{
+			name: "Profiles both dropped and added are left untouched",
+			drop: []string{"a", "b"},
+			add:  []string{"b", "c"},
+			want: "ALTER ROLE `foo` DROP PROFILES 'a' ADD PROFILE 'c';",
+		},
+		{
+			name:    "Same profiles",
+			drop:    []string{"a"},
+			add:     []string{"a"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewAlterRole("foo").DropSettingsProfiles(tt.drop).AddSettingsProfiles(tt.add).Build()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Build() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
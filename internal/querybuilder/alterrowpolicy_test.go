@@ -0,0 +1,81 @@
+package querybuilder
+
+import (
+	"testing"
+)
+
+func Test_alterRowPolicyQueryBuilder_Build(t *testing.T) {
+	tests := []struct {
+		name        string
+		clusterName *string
+		kind        RowPolicyKind
+		using       *string
+		to          []string
+		setTo       bool
+		want        string
+		wantErr     bool
+	}{
+		{
+			name:  "Change USING expression",
+			using: strPtr("tenant_id = 2"),
+			want:  "ALTER ROW POLICY `policy1` ON `db`.`tbl` FOR SELECT USING tenant_id = 2;",
+		},
+		{
+			name:        "Change kind on cluster",
+			clusterName: strPtr("cluster1"),
+			kind:        RowPolicyKindRestrictive,
+			want:        "ALTER ROW POLICY `policy1` ON CLUSTER 'cluster1' ON `db`.`tbl` AS RESTRICTIVE;",
+		},
+		{
+			name:  "Change grantees",
+			to:    []string{"reader"},
+			setTo: true,
+			want:  "ALTER ROW POLICY `policy1` ON `db`.`tbl` TO `reader`;",
+		},
+		{
+			name:  "Remove all grantees",
+			setTo: true,
+			want:  "ALTER ROW POLICY `policy1` ON `db`.`tbl` TO NONE;",
+		},
+		{
+			name:  "Change everything",
+			kind:  RowPolicyKindPermissive,
+			using: strPtr("1"),
+			to:    []string{"reader", "writer"},
+			setTo: true,
+			want:  "ALTER ROW POLICY `policy1` ON `db`.`tbl` AS PERMISSIVE FOR SELECT USING 1 TO `reader`, `writer`;",
+		},
+		{
+			name:    "Empty USING expression",
+			using:   strPtr(""),
+			wantErr: true,
+		},
+		{
+			name:    "No changes",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &alterRowPolicyQueryBuilder{
+				resourceName: "policy1",
+				databaseName: "db",
+				tableName:    "tbl",
+				clusterName:  tt.clusterName,
+				kind:         tt.kind,
+				using:        tt.using,
+				to:           tt.to,
+				setTo:        tt.setTo,
+			}
+
+			got, err := q.Build()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Build() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Build() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package querybuilder
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/pingcap/errors"
@@ -11,9 +12,11 @@ type AlterSettingsProfileQueryBuilder interface {
 	QueryBuilder
 	RenameTo(newName *string) AlterSettingsProfileQueryBuilder
 	AddSetting(name string, value *string, min *string, max *string, writability *string) AlterSettingsProfileQueryBuilder
+	AddRawSetting(name string, value *string, min *string, max *string, writability *string) AlterSettingsProfileQueryBuilder
 	RemoveSetting(name string) AlterSettingsProfileQueryBuilder
 	InheritFrom(profileNames []string) AlterSettingsProfileQueryBuilder
 	WithCluster(clusterName *string) AlterSettingsProfileQueryBuilder
+	To(applyTo ApplyTo) AlterSettingsProfileQueryBuilder
 }
 
 type alterSettingsProfileQueryBuilder struct {
@@ -24,6 +27,7 @@ type alterSettingsProfileQueryBuilder struct {
 	clusterName    *string
 	dropProfiles   bool
 	inheritFrom    []string
+	applyTo        *ApplyTo
 }
 
 func NewAlterSettingsProfile(resourceName string) AlterSettingsProfileQueryBuilder {
@@ -51,6 +55,20 @@ func (q *alterSettingsProfileQueryBuilder) AddSetting(name string, value *string
 	return q
 }
 
+// AddRawSetting adds a setting whose value, min and max are SQL expressions emitted verbatim, e.g. getSetting('max_threads').
+func (q *alterSettingsProfileQueryBuilder) AddRawSetting(name string, value *string, min *string, max *string, writability *string) AlterSettingsProfileQueryBuilder {
+	q.settings = append(q.settings, settingData{
+		Name:        name,
+		Value:       value,
+		Min:         min,
+		Max:         max,
+		Writability: writability,
+		Raw:         true,
+	})
+
+	return q
+}
+
 func (q *alterSettingsProfileQueryBuilder) RemoveSetting(name string) AlterSettingsProfileQueryBuilder {
 	q.removeSettings = append(q.removeSettings, backtick(name))
 
@@ -62,9 +80,16 @@ func (q *alterSettingsProfileQueryBuilder) WithCluster(clusterName *string) Alte
 	return q
 }
 
+// InheritFrom replaces the profiles to inherit from. Their order matters, since later profiles override earlier ones.
 func (q *alterSettingsProfileQueryBuilder) InheritFrom(profileNames []string) AlterSettingsProfileQueryBuilder {
 	q.dropProfiles = true
-	q.inheritFrom = profileNames
+	q.inheritFrom = slices.Clone(profileNames)
+	return q
+}
+
+// To replaces the users and roles the profile applies to. An empty ApplyTo removes all of them.
+func (q *alterSettingsProfileQueryBuilder) To(applyTo ApplyTo) AlterSettingsProfileQueryBuilder {
+	q.applyTo = &applyTo
 	return q
 }
 
@@ -112,7 +137,11 @@ func (q *alterSettingsProfileQueryBuilder) Build() (string, error) {
 	}
 
 	if len(q.inheritFrom) > 0 {
-		tokens = append(tokens, "INHERIT", strings.Join(backtickAll(q.inheritFrom), ", "))
+		tokens = append(tokens, inheritAll(q.inheritFrom))
+	}
+
+	if q.applyTo != nil {
+		tokens = append(tokens, "TO", q.applyTo.SQLDef())
 	}
 
 	return strings.Join(tokens, " ") + ";", nil
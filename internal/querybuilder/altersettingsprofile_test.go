@@ -0,0 +1,98 @@
+package querybuilder
+
+import (
+	"testing"
+)
+
+func Test_alterSettingsProfileQueryBuilder_To(t *testing.T) {
+	tests := []struct {
+		name    string
+		applyTo *ApplyTo
+		want    string
+	}{
+		{
+			name:    "TO not changed",
+			applyTo: nil,
+			want:    "ALTER SETTINGS PROFILE `prf1`;",
+		},
+		{
+			name:    "TO users and roles",
+			applyTo: &ApplyTo{Names: []string{"john", "reader"}},
+			want:    "ALTER SETTINGS PROFILE `prf1` TO `john`, `reader`;",
+		},
+		{
+			name:    "TO nobody",
+			applyTo: &ApplyTo{},
+			want:    "ALTER SETTINGS PROFILE `prf1` TO NONE;",
+		},
+		{
+			name:    "TO all",
+			applyTo: &ApplyTo{All: true},
+			want:    "ALTER SETTINGS PROFILE `prf1` TO ALL;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewAlterSettingsProfile("prf1")
+			if tt.applyTo != nil {
+				q = q.To(*tt.applyTo)
+			}
+			got, err := q.Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Build() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_alterSettingsProfileQueryBuilder_rawSettings(t *testing.T) {
+	got, err := NewAlterSettingsProfile("prf1").
+		AddSetting("custom_setting", strPtr("abc"), nil, nil, nil).
+		AddRawSetting("max_threads", strPtr("getSetting('max_threads')"), nil, nil, nil).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	want := "ALTER SETTINGS PROFILE `prf1` ADD SETTINGS `custom_setting` = 'abc', `max_threads` = getSetting('max_threads');"
+	if got != want {
+		t.Errorf("Build() got = %v, want %v", got, want)
+	}
+}
+
+func Test_alterSettingsProfileQueryBuilder_settings(t *testing.T) {
+	got, err := NewAlterSettingsProfile("prf1").
+		RemoveSetting("max_memory_usage").
+		RemoveSetting("readonly").
+		AddSetting("max_memory_usage", strPtr("1000000"), strPtr("0"), strPtr("2000000"), strPtr("CONST")).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	want := "ALTER SETTINGS PROFILE `prf1` DROP SETTINGS `max_memory_usage`, `readonly` ADD SETTINGS `max_memory_usage` = '1000000' MIN '0' MAX '2000000' CONST;"
+	if got != want {
+		t.Errorf("Build() got = %v, want %v", got, want)
+	}
+}
+
+func Test_alterSettingsProfileQueryBuilder_InheritFrom(t *testing.T) {
+	profiles := []string{"web", "analytics", "readonly"}
+	q := NewAlterSettingsProfile("prf1").InheritFrom(profiles)
+
+	// The builder keeps its own copy of the profiles.
+	profiles[0] = "changed"
+
+	got, err := q.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	want := "ALTER SETTINGS PROFILE `prf1` DROP ALL PROFILES INHERIT `web`, INHERIT `analytics`, INHERIT `readonly`;"
+	if got != want {
+		t.Errorf("Build() got = %v, want %v", got, want)
+	}
+}
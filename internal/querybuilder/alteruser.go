@@ -1,7 +1,9 @@
 package querybuilder
 
 import (
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/pingcap/errors"
 )
@@ -10,21 +12,46 @@ import (
 type AlterUserQueryBuilder interface {
 	QueryBuilder
 	RenameTo(newName *string) AlterUserQueryBuilder
+	Identified(with Identification, by string) AlterUserQueryBuilder
+	IdentifiedWithSSLCertCN(cn string) AlterUserQueryBuilder
+	IdentifiedWithNoPassword() AlterUserQueryBuilder
+	IdentifiedWithMethods(authentications []Authentication) AlterUserQueryBuilder
+	SetDefaultRoles(roleNames []string) AlterUserQueryBuilder
+	SetHosts(hosts []Host) AlterUserQueryBuilder
+	AddHosts(hosts []Host) AlterUserQueryBuilder
+	DropHosts(hosts []Host) AlterUserQueryBuilder
+	SetValidUntil(validUntil *time.Time) AlterUserQueryBuilder
+	SetGrantees(grantees Grantees) AlterUserQueryBuilder
+	SetComment(comment *string) AlterUserQueryBuilder
 	DropSettingsProfile(profileName *string) AlterUserQueryBuilder
 	AddSettingsProfile(profileName *string) AlterUserQueryBuilder
+	DropSettingsProfiles(profileNames []string) AlterUserQueryBuilder
+	AddSettingsProfiles(profileNames []string) AlterUserQueryBuilder
 	WithCluster(clusterName *string) AlterUserQueryBuilder
 	IfExists() AlterUserQueryBuilder
 	SetSettingsProfile(profileName *string) AlterUserQueryBuilder
 }
 
 type alterUserQueryBuilder struct {
-	resourceName       string
-	oldSettingsProfile *string
-	newSettingsProfile *string
-	newName            *string
-	clusterName        *string
-	setSettingsProfile *string
-	ifExists           bool
+	resourceName        string
+	oldSettingsProfiles []string
+	newSettingsProfiles []string
+	newName             *string
+	identified          string
+	authentications     []Authentication
+	defaultRoles        []string
+	setDefaultRoles     bool
+	hosts               []Host
+	setHosts            bool
+	addHosts            []Host
+	dropHosts           []Host
+	validUntil          *time.Time
+	setValidUntil       bool
+	grantees            *Grantees
+	comment             *string
+	clusterName         *string
+	setSettingsProfile  *string
+	ifExists            bool
 }
 
 func NewAlterUser(resourceName string) AlterUserQueryBuilder {
@@ -49,13 +76,100 @@ func (q *alterUserQueryBuilder) RenameTo(newName *string) AlterUserQueryBuilder
 	return q
 }
 
+func (q *alterUserQueryBuilder) Identified(with Identification, by string) AlterUserQueryBuilder {
+	q.identified = fmt.Sprintf("IDENTIFIED WITH %s BY %s", with, quote(by))
+	return q
+}
+
+func (q *alterUserQueryBuilder) IdentifiedWithSSLCertCN(cn string) AlterUserQueryBuilder {
+	q.identified = fmt.Sprintf("IDENTIFIED WITH ssl_certificate CN %s", quote(cn))
+	return q
+}
+
+func (q *alterUserQueryBuilder) IdentifiedWithNoPassword() AlterUserQueryBuilder {
+	q.identified = fmt.Sprintf("IDENTIFIED WITH %s", IdentificationNoPassword)
+	return q
+}
+
+// IdentifiedWithMethods sets all the identification methods of the user at once. It takes precedence over Identified,
+// IdentifiedWithSSLCertCN and IdentifiedWithNoPassword.
+func (q *alterUserQueryBuilder) IdentifiedWithMethods(authentications []Authentication) AlterUserQueryBuilder {
+	q.authentications = authentications
+	return q
+}
+
+// SetDefaultRoles replaces the default roles of the user. An empty list removes all of them.
+func (q *alterUserQueryBuilder) SetDefaultRoles(roleNames []string) AlterUserQueryBuilder {
+	q.defaultRoles = roleNames
+	q.setDefaultRoles = true
+	return q
+}
+
+// SetHosts replaces the hosts the user can connect from. An empty list allows any host.
+func (q *alterUserQueryBuilder) SetHosts(hosts []Host) AlterUserQueryBuilder {
+	q.hosts = hosts
+	q.setHosts = true
+	return q
+}
+
+// AddHosts allows the user to connect from the given hosts, on top of the ones already allowed.
+// ANY and NONE can't be added, use SetHosts instead.
+func (q *alterUserQueryBuilder) AddHosts(hosts []Host) AlterUserQueryBuilder {
+	q.addHosts = hosts
+	return q
+}
+
+// DropHosts stops allowing the user to connect from the given hosts, leaving the other ones allowed.
+// ANY and NONE can't be dropped, use SetHosts instead.
+func (q *alterUserQueryBuilder) DropHosts(hosts []Host) AlterUserQueryBuilder {
+	q.dropHosts = hosts
+	return q
+}
+
+// SetValidUntil replaces the expiration time of the user's credentials. Nil removes the expiration.
+func (q *alterUserQueryBuilder) SetValidUntil(validUntil *time.Time) AlterUserQueryBuilder {
+	q.validUntil = validUntil
+	q.setValidUntil = true
+	return q
+}
+
+// SetGrantees replaces the users and roles the user can grant its privileges and roles to.
+func (q *alterUserQueryBuilder) SetGrantees(grantees Grantees) AlterUserQueryBuilder {
+	q.grantees = &grantees
+	return q
+}
+
+// SetComment replaces the comment of the user. Nil leaves it unchanged, an empty string clears it.
+func (q *alterUserQueryBuilder) SetComment(comment *string) AlterUserQueryBuilder {
+	q.comment = comment
+	return q
+}
+
 func (q *alterUserQueryBuilder) DropSettingsProfile(profileName *string) AlterUserQueryBuilder {
-	q.oldSettingsProfile = profileName
+	if profileName != nil {
+		q.oldSettingsProfiles = append(q.oldSettingsProfiles, *profileName)
+	}
 	return q
 }
 
 func (q *alterUserQueryBuilder) AddSettingsProfile(profileName *string) AlterUserQueryBuilder {
-	q.newSettingsProfile = profileName
+	if profileName != nil {
+		q.newSettingsProfiles = append(q.newSettingsProfiles, *profileName)
+	}
+	return q
+}
+
+// DropSettingsProfiles drops all the given profiles in a single DROP PROFILES clause. Profiles also being added are
+// left untouched.
+func (q *alterUserQueryBuilder) DropSettingsProfiles(profileNames []string) AlterUserQueryBuilder {
+	q.oldSettingsProfiles = append(q.oldSettingsProfiles, profileNames...)
+	return q
+}
+
+// AddSettingsProfiles adds all the given profiles in a single ADD PROFILES clause. Profiles also being dropped are
+// left untouched.
+func (q *alterUserQueryBuilder) AddSettingsProfiles(profileNames []string) AlterUserQueryBuilder {
+	q.newSettingsProfiles = append(q.newSettingsProfiles, profileNames...)
 	return q
 }
 
@@ -88,20 +202,87 @@ func (q *alterUserQueryBuilder) Build() (string, error) {
 		tokens = append(tokens, "ON", "CLUSTER", quote(*q.clusterName))
 	}
 
+	if len(q.authentications) > 0 {
+		anyChanges = true
+		identified, err := identifiedClause(q.authentications)
+		if err != nil {
+			return "", errors.WithMessage(err, "invalid identification")
+		}
+		tokens = append(tokens, identified)
+	} else if q.identified != "" {
+		anyChanges = true
+		tokens = append(tokens, q.identified)
+	}
+
+	if q.setHosts {
+		anyChanges = true
+		if len(q.hosts) == 0 {
+			tokens = append(tokens, "HOST", "ANY")
+		} else {
+			hosts, err := hostsClause(q.hosts)
+			if err != nil {
+				return "", errors.WithMessage(err, "invalid host")
+			}
+			tokens = append(tokens, hosts...)
+		}
+	}
+
+	if len(q.dropHosts) > 0 {
+		anyChanges = true
+		hosts, err := alterHostsClause("DROP", q.dropHosts)
+		if err != nil {
+			return "", errors.WithMessage(err, "invalid host to drop")
+		}
+		tokens = append(tokens, hosts...)
+	}
+
+	if len(q.addHosts) > 0 {
+		anyChanges = true
+		hosts, err := alterHostsClause("ADD", q.addHosts)
+		if err != nil {
+			return "", errors.WithMessage(err, "invalid host to add")
+		}
+		tokens = append(tokens, hosts...)
+	}
+
+	if q.setValidUntil {
+		anyChanges = true
+		tokens = append(tokens, validUntilClause(q.validUntil))
+	}
+
+	if q.setDefaultRoles {
+		anyChanges = true
+		if len(q.defaultRoles) == 0 {
+			tokens = append(tokens, "DEFAULT", "ROLE", "NONE")
+		} else {
+			tokens = append(tokens, "DEFAULT", "ROLE", strings.Join(backtickAll(q.defaultRoles), ", "))
+		}
+	}
+
+	if q.grantees != nil {
+		anyChanges = true
+		tokens = append(tokens, "GRANTEES", q.grantees.SQLDef())
+	}
+
 	if q.setSettingsProfile != nil {
 		anyChanges = true
 		tokens = append(tokens, "SETTINGS", "PROFILE", quote(*q.setSettingsProfile))
 	} else {
-		if q.oldSettingsProfile != nil && (q.newSettingsProfile == nil || *q.oldSettingsProfile != *q.newSettingsProfile) {
+		if drop := profilesNotIn(q.oldSettingsProfiles, q.newSettingsProfiles); len(drop) > 0 {
 			anyChanges = true
-			tokens = append(tokens, "DROP", "PROFILES", quote(*q.oldSettingsProfile))
+			tokens = append(tokens, "DROP", "PROFILES", strings.Join(quoteAll(drop), ", "))
 		}
-		if q.newSettingsProfile != nil && (q.oldSettingsProfile == nil || *q.newSettingsProfile != *q.oldSettingsProfile) {
+		if add := profilesNotIn(q.newSettingsProfiles, q.oldSettingsProfiles); len(add) > 0 {
 			anyChanges = true
-			tokens = append(tokens, "ADD", "PROFILES", quote(*q.newSettingsProfile))
+			tokens = append(tokens, "ADD", "PROFILES", strings.Join(quoteAll(add), ", "))
 		}
 	}
 
+	if q.comment != nil {
+		anyChanges = true
+		tokens = append(tokens, "COMMENT", quote(*q.comment))
+	}
+
 	if !anyChanges {
 		return "", errors.New("no change to be made")
 	}
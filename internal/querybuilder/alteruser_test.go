@@ -2,6 +2,7 @@ package querybuilder
 
 import (
 	"testing"
+	"time"
 )
 
 func Test_alterUserQueryBuilder_Build(t *testing.T) {
@@ -11,6 +12,7 @@ func Test_alterUserQueryBuilder_Build(t *testing.T) {
 		newSettingsProfile *string
 		setSettingsProfile *string
 		newName            *string
+		identified         string
 		clusterName        *string
 		want               string
 		wantErr            bool
@@ -21,6 +23,12 @@ func Test_alterUserQueryBuilder_Build(t *testing.T) {
 			want:    "ALTER USER `foo` RENAME TO `test`;",
 			wantErr: false,
 		},
+		{
+			name:    "Change name preserving case",
+			newName: strPtr("MyUser"),
+			want:    "ALTER USER `foo` RENAME TO `MyUser`;",
+			wantErr: false,
+		},
 		{
 			name:        "Change name on cluster",
 			newName:     strPtr("test"),
@@ -56,6 +64,35 @@ func Test_alterUserQueryBuilder_Build(t *testing.T) {
 			want:               "ALTER USER `foo` ON CLUSTER 'cluster1' DROP PROFILES 'old' ADD PROFILES 'profile1';",
 			wantErr:            false,
 		},
+		{
+			name:               "Remove profile",
+			oldSettingsProfile: strPtr("old"),
+			want:               "ALTER USER `foo` DROP PROFILES 'old';",
+			wantErr:            false,
+		},
+		{
+			name:               "Change name and replace profile on cluster",
+			newName:            strPtr("test"),
+			newSettingsProfile: strPtr("profile1"),
+			oldSettingsProfile: strPtr("old"),
+			clusterName:        strPtr("cluster1"),
+			want:               "ALTER USER `foo` RENAME TO `test` ON CLUSTER 'cluster1' DROP PROFILES 'old' ADD PROFILES 'profile1';",
+			wantErr:            false,
+		},
+		{
+			name:       "Change password",
+			identified: "IDENTIFIED WITH sha256_hash BY 'abc'",
+			want:       "ALTER USER `foo` IDENTIFIED WITH sha256_hash BY 'abc';",
+			wantErr:    false,
+		},
+		{
+			name:        "Change name and password on cluster",
+			newName:     strPtr("test"),
+			identified:  "IDENTIFIED WITH sha256_hash BY 'abc'",
+			clusterName: strPtr("cluster1"),
+			want:        "ALTER USER `foo` RENAME TO `test` ON CLUSTER 'cluster1' IDENTIFIED WITH sha256_hash BY 'abc';",
+			wantErr:     false,
+		},
 		{
 			name:    "No profile set",
 			want:    "",
@@ -92,12 +129,13 @@ func Test_alterUserQueryBuilder_Build(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			q := &alterUserQueryBuilder{
 				resourceName:       "foo",
-				oldSettingsProfile: tt.oldSettingsProfile,
-				newSettingsProfile: tt.newSettingsProfile,
 				setSettingsProfile: tt.setSettingsProfile,
 				newName:            tt.newName,
+				identified:         tt.identified,
 				clusterName:        tt.clusterName,
 			}
+			q.DropSettingsProfile(tt.oldSettingsProfile)
+			q.AddSettingsProfile(tt.newSettingsProfile)
 			got, err := q.Build()
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Build() error = %v, wantErr %v", err, tt.wantErr)
@@ -109,3 +147,324 @@ func Test_alterUserQueryBuilder_Build(t *testing.T) {
 		})
 	}
 }
+
+func Test_alterUserQueryBuilder_IdentifiedWithBcryptHash(t *testing.T) {
+	got, err := NewAlterUser("foo").Identified(IdentificationBcryptHash, "$2a$12$blah").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	want := "ALTER USER `foo` IDENTIFIED WITH bcrypt_hash BY '$2a$12$blah';"
+	if got != want {
+		t.Errorf("Build() got = %v, want %v", got, want)
+	}
+}
+
+func Test_alterUserQueryBuilder_IdentifiedWithNoPassword(t *testing.T) {
+	got, err := NewAlterUser("foo").IdentifiedWithNoPassword().Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	want := "ALTER USER `foo` IDENTIFIED WITH no_password;"
+	if got != want {
+		t.Errorf("Build() got = %v, want %v", got, want)
+	}
+}
+
+func Test_alterUserQueryBuilder_IdentifiedWithSSLCertCN(t *testing.T) {
+	got, err := NewAlterUser("foo").IdentifiedWithSSLCertCN("foo.example.com").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	want := "ALTER USER `foo` IDENTIFIED WITH ssl_certificate CN 'foo.example.com';"
+	if got != want {
+		t.Errorf("Build() got = %v, want %v", got, want)
+	}
+}
+
+func Test_alterUserQueryBuilder_SetDefaultRoles(t *testing.T) {
+	tests := []struct {
+		name        string
+		roles       []string
+		clusterName *string
+		want        string
+	}{
+		{
+			name:  "Default roles",
+			roles: []string{"reader", "team,ops"},
+			want:  "ALTER USER `foo` DEFAULT ROLE `reader`, `team,ops`;",
+		},
+		{
+			name:  "No default roles",
+			roles: []string{},
+			want:  "ALTER USER `foo` DEFAULT ROLE NONE;",
+		},
+		{
+			name:        "On cluster",
+			roles:       []string{"reader"},
+			clusterName: strPtr("my-cluster"),
+			want:        "ALTER USER `foo` ON CLUSTER 'my-cluster' DEFAULT ROLE `reader`;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewAlterUser("foo").WithCluster(tt.clusterName).SetDefaultRoles(tt.roles).Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Build() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_alterUserQueryBuilder_SetHosts(t *testing.T) {
+	tests := []struct {
+		name        string
+		hosts       []Host
+		clusterName *string
+		want        string
+		wantErr     bool
+	}{
+		{
+			name: "Multiple hosts",
+			hosts: []Host{
+				{Type: HostTypeLocal},
+				{Type: HostTypeIP, Value: "10.0.0.0/8"},
+				{Type: HostTypeRegexp, Value: ".*\\.example\\.com"},
+			},
+			want: "ALTER USER `foo` HOST LOCAL, IP '10.0.0.0/8', REGEXP '.*\\\\.example\\\\.com';",
+		},
+		{
+			name:  "No hosts allows any host",
+			hosts: []Host{},
+			want:  "ALTER USER `foo` HOST ANY;",
+		},
+		{
+			name:        "On cluster",
+			hosts:       []Host{{Type: HostTypeName, Value: "bastion.example.com"}},
+			clusterName: strPtr("my-cluster"),
+			want:        "ALTER USER `foo` ON CLUSTER 'my-cluster' HOST NAME 'bastion.example.com';",
+		},
+		{
+			name:    "NONE with other hosts",
+			hosts:   []Host{{Type: HostTypeNone}, {Type: HostTypeLocal}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewAlterUser("foo").WithCluster(tt.clusterName).SetHosts(tt.hosts).Build()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Build() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_alterUserQueryBuilder_AddDropHosts(t *testing.T) {
+	tests := []struct {
+		name    string
+		add     []Host
+		drop    []Host
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Add only",
+			add:  []Host{{Type: HostTypeLocal}, {Type: HostTypeIP, Value: "10.0.0.0/8"}},
+			want: "ALTER USER `foo` ADD HOST LOCAL, IP '10.0.0.0/8';",
+		},
+		{
+			name: "Drop only",
+			drop: []Host{{Type: HostTypeName, Value: "bastion.example.com"}},
+			want: "ALTER USER `foo` DROP HOST NAME 'bastion.example.com';",
+		},
+		{
+			name: "Add and drop",
+			add:  []Host{{Type: HostTypeIP, Value: "192.168.0.0/16"}},
+			drop: []Host{{Type: HostTypeIP, Value: "10.0.0.0/8"}, {Type: HostTypeLike, Value: "%.example.com"}},
+			want: "ALTER USER `foo` DROP HOST IP '10.0.0.0/8', LIKE '%.example.com' ADD HOST IP '192.168.0.0/16';",
+		},
+		{
+			name:    "Add ANY",
+			add:     []Host{{Type: HostTypeAny}},
+			wantErr: true,
+		},
+		{
+			name:    "Drop NONE",
+			drop:    []Host{{Type: HostTypeNone}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewAlterUser("foo").AddHosts(tt.add).DropHosts(tt.drop).Build()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Build() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_alterUserQueryBuilder_SetValidUntil(t *testing.T) {
+	tests := []struct {
+		name       string
+		validUntil *time.Time
+		want       string
+	}{
+		{
+			name:       "Expiration time",
+			validUntil: timePtr(time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)),
+			want:       "ALTER USER `foo` VALID UNTIL '2030-01-02 03:04:05 UTC';",
+		},
+		{
+			name:       "Expiration time in another timezone",
+			validUntil: timePtr(time.Date(2030, 1, 2, 3, 4, 5, 0, time.FixedZone("EST", -5*3600))),
+			want:       "ALTER USER `foo` VALID UNTIL '2030-01-02 08:04:05 UTC';",
+		},
+		{
+			name:       "No expiration",
+			validUntil: nil,
+			want:       "ALTER USER `foo` VALID UNTIL 'infinity';",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewAlterUser("foo").SetValidUntil(tt.validUntil).Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Build() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_alterUserQueryBuilder_SetGrantees(t *testing.T) {
+	tests := []struct {
+		name        string
+		clusterName *string
+		grantees    Grantees
+		want        string
+	}{
+		{
+			name:     "Any except",
+			grantees: Grantees{Any: true, Except: []string{"admin"}},
+			want:     "ALTER USER `foo` GRANTEES ANY EXCEPT `admin`;",
+		},
+		{
+			name:        "Names on cluster",
+			clusterName: strPtr("my-cluster"),
+			grantees:    Grantees{Names: []string{"john", "reader"}},
+			want:        "ALTER USER `foo` ON CLUSTER 'my-cluster' GRANTEES `john`, `reader`;",
+		},
+		{
+			name:     "None",
+			grantees: Grantees{},
+			want:     "ALTER USER `foo` GRANTEES NONE;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewAlterUser("foo").WithCluster(tt.clusterName).SetGrantees(tt.grantees).Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Build() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_alterUserQueryBuilder_SetComment(t *testing.T) {
+	tests := []struct {
+		name        string
+		clusterName *string
+		newName     *string
+		comment     *string
+		want        string
+		wantErr     bool
+	}{
+		{
+			name:    "Set comment",
+			comment: strPtr("owner: team-data"),
+			want:    "ALTER USER `foo` COMMENT 'owner: team-data';",
+		},
+		{
+			name:    "Clear comment",
+			comment: strPtr(""),
+			want:    "ALTER USER `foo` COMMENT '';",
+		},
+		{
+			name:        "Rename and set comment on cluster",
+			clusterName: strPtr("my-cluster"),
+			newName:     strPtr("bar"),
+			comment:     strPtr("team's user"),
+			want:        "ALTER USER `foo` RENAME TO `bar` ON CLUSTER 'my-cluster' COMMENT 'team\\'s user';",
+		},
+		{
+			name:    "Comment left unchanged",
+			comment: nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewAlterUser("foo").WithCluster(tt.clusterName).RenameTo(tt.newName).SetComment(tt.comment).Build()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Build() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_alterUserQueryBuilder_IdentifiedWithMethods(t *testing.T) {
+	got, err := NewAlterUser("foo").
+		IdentifiedWithMethods([]Authentication{
+			{With: IdentificationSSLCertificate, Value: "foo.example.com"},
+			{With: IdentificationSHA256Hash, Value: "blah"},
+		}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	want := "ALTER USER `foo` IDENTIFIED WITH ssl_certificate CN 'foo.example.com', sha256_hash BY 'blah';"
+	if got != want {
+		t.Errorf("Build() got = %v, want %v", got, want)
+	}
+}
+
+func Test_alterUserQueryBuilder_settingsProfiles(t *testing.T) {
+	got, err := NewAlterUser("foo").
+		DropSettingsProfiles([]string{"a", "b"}).
+		AddSettingsProfiles([]string{"b", "c", "d"}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	want := "ALTER USER `foo` DROP PROFILES 'a' ADD PROFILES 'c', 'd';"
+	if got != want {
+		t.Errorf("Build() got = %v, want %v", got, want)
+	}
+}
+
+func timePtr(val time.Time) *time.Time {
+	return &val
+}
@@ -0,0 +1,34 @@
+package querybuilder
+
+import (
+	"strings"
+)
+
+// ApplyTo is the set of users and roles an access entity, such as a settings profile, applies to.
+type ApplyTo struct {
+	// All makes the entity apply to every user and role but the ones in Except.
+	All    bool
+	Names  []string
+	Except []string
+}
+
+// IsEmpty returns true when the entity doesn't apply to any user or role.
+func (a ApplyTo) IsEmpty() bool {
+	return !a.All && len(a.Names) == 0
+}
+
+// SQLDef returns the part of a TO clause that follows the TO keyword.
+func (a ApplyTo) SQLDef() string {
+	if a.All {
+		if len(a.Except) > 0 {
+			return "ALL EXCEPT " + strings.Join(backtickAll(a.Except), ", ")
+		}
+		return "ALL"
+	}
+
+	if len(a.Names) == 0 {
+		return "NONE"
+	}
+
+	return strings.Join(backtickAll(a.Names), ", ")
+}
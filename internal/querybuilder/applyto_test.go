@@ -0,0 +1,46 @@
+package querybuilder
+
+import (
+	"testing"
+)
+
+func TestApplyTo_SQLDef(t *testing.T) {
+	tests := []struct {
+		name    string
+		applyTo ApplyTo
+		want    string
+	}{
+		{
+			name:    "Empty",
+			applyTo: ApplyTo{},
+			want:    "NONE",
+		},
+		{
+			name:    "Names",
+			applyTo: ApplyTo{Names: []string{"john", "reader"}},
+			want:    "`john`, `reader`",
+		},
+		{
+			name:    "All",
+			applyTo: ApplyTo{All: true},
+			want:    "ALL",
+		},
+		{
+			name:    "All except",
+			applyTo: ApplyTo{All: true, Except: []string{"admin"}},
+			want:    "ALL EXCEPT `admin`",
+		},
+		{
+			name:    "Names are ignored with all",
+			applyTo: ApplyTo{All: true, Names: []string{"john"}},
+			want:    "ALL",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.applyTo.SQLDef(); got != tt.want {
+				t.Errorf("SQLDef() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package querybuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pingcap/errors"
+)
+
+const (
+	IdentificationSSLCertificate Identification = "ssl_certificate"
+)
+
+// Authentication is a single identification method of a user, e.g. a password hash or an SSL certificate CN.
+// Value is the password hash for sha256_hash and bcrypt_hash, the password for plaintext_password and the common
+// name for ssl_certificate. It must be empty for no_password.
+type Authentication struct {
+	With  Identification
+	Value string
+}
+
+func (a *Authentication) SQLDef() (string, error) {
+	if a.With == IdentificationNoPassword {
+		if a.Value != "" {
+			return "", errors.New(fmt.Sprintf("Value must be empty for identification method %s", a.With))
+		}
+		return string(a.With), nil
+	}
+
+	if a.Value == "" {
+		return "", errors.New(fmt.Sprintf("Value can't be empty for identification method %s", a.With))
+	}
+
+	switch a.With {
+	case IdentificationSHA256Hash, IdentificationBcryptHash, IdentificationPlaintext:
+		return fmt.Sprintf("%s BY %s", a.With, quote(a.Value)), nil
+	case IdentificationSSLCertificate:
+		return fmt.Sprintf("%s CN %s", a.With, quote(a.Value)), nil
+	}
+
+	return "", errors.New(fmt.Sprintf("invalid identification method %q", a.With))
+}
+
+// identifiedClause returns the IDENTIFIED clause for the given methods. ClickHouse 24.9 and later accept
+// several methods, any of which can be used to log in, except for no_password which can't be combined with others.
+func identifiedClause(authentications []Authentication) (string, error) {
+	if len(authentications) == 0 {
+		return "", errors.New("at least one identification method is required")
+	}
+
+	defs := make([]string, 0)
+	for _, a := range authentications {
+		if a.With == IdentificationNoPassword && len(authentications) > 1 {
+			return "", errors.New(fmt.Sprintf("identification method %s can't be combined with other methods", a.With))
+		}
+
+		def, err := a.SQLDef()
+		if err != nil {
+			return "", err
+		}
+		defs = append(defs, def)
+	}
+
+	return "IDENTIFIED WITH " + strings.Join(defs, ", "), nil
+}
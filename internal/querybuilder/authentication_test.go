@@ -0,0 +1,66 @@
+package querybuilder
+
+import (
+	"testing"
+)
+
+func TestAuthentication_SQLDef(t *testing.T) {
+	tests := []struct {
+		name           string
+		authentication Authentication
+		want           string
+		wantErr        bool
+	}{
+		{
+			name:           "Password hash",
+			authentication: Authentication{With: IdentificationSHA256Hash, Value: "blah"},
+			want:           "sha256_hash BY 'blah'",
+		},
+		{
+			name:           "Bcrypt password hash",
+			authentication: Authentication{With: IdentificationBcryptHash, Value: "$2a$12$blah"},
+			want:           "bcrypt_hash BY '$2a$12$blah'",
+		},
+		{
+			name:           "Plaintext password",
+			authentication: Authentication{With: IdentificationPlaintext, Value: "changeme"},
+			want:           "plaintext_password BY 'changeme'",
+		},
+		{
+			name:           "No password",
+			authentication: Authentication{With: IdentificationNoPassword},
+			want:           "no_password",
+		},
+		{
+			name:           "No password with a value",
+			authentication: Authentication{With: IdentificationNoPassword, Value: "changeme"},
+			wantErr:        true,
+		},
+		{
+			name:           "SSL certificate",
+			authentication: Authentication{With: IdentificationSSLCertificate, Value: "john's laptop"},
+			want:           "ssl_certificate CN 'john\\'s laptop'",
+		},
+		{
+			name:           "Empty value",
+			authentication: Authentication{With: IdentificationSSLCertificate},
+			wantErr:        true,
+		},
+		{
+			name:           "Unknown method",
+			authentication: Authentication{With: "kerberos", Value: "john"},
+			wantErr:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.authentication.SQLDef()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SQLDef() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("SQLDef() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
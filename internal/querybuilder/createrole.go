@@ -10,16 +10,22 @@ import (
 type CreateRoleQueryBuilder interface {
 	QueryBuilder
 	WithCluster(clusterName *string) CreateRoleQueryBuilder
+	AddSetting(name string, value *string, min *string, max *string, writability *string) CreateRoleQueryBuilder
+	AddRawSetting(name string, value *string, min *string, max *string, writability *string) CreateRoleQueryBuilder
+	WithComment(comment *string) CreateRoleQueryBuilder
 }
 
 type createRoleQueryBuilder struct {
 	resourceName string
 	clusterName  *string
+	settings     []settingData
+	comment      *string
 }
 
 func NewCreateRole(resourceName string) CreateRoleQueryBuilder {
 	return &createRoleQueryBuilder{
 		resourceName: resourceName,
+		settings:     make([]settingData, 0),
 	}
 }
 
@@ -28,6 +34,35 @@ func (q *createRoleQueryBuilder) WithCluster(clusterName *string) CreateRoleQuer
 	return q
 }
 
+func (q *createRoleQueryBuilder) AddSetting(name string, value *string, min *string, max *string, writability *string) CreateRoleQueryBuilder {
+	q.settings = append(q.settings, settingData{
+		Name:        name,
+		Value:       value,
+		Min:         min,
+		Max:         max,
+		Writability: writability,
+	})
+	return q
+}
+
+// AddRawSetting adds a setting whose value, min and max are SQL expressions emitted verbatim, e.g. getSetting('max_threads').
+func (q *createRoleQueryBuilder) AddRawSetting(name string, value *string, min *string, max *string, writability *string) CreateRoleQueryBuilder {
+	q.settings = append(q.settings, settingData{
+		Name:        name,
+		Value:       value,
+		Min:         min,
+		Max:         max,
+		Writability: writability,
+		Raw:         true,
+	})
+	return q
+}
+
+func (q *createRoleQueryBuilder) WithComment(comment *string) CreateRoleQueryBuilder {
+	q.comment = comment
+	return q
+}
+
 func (q *createRoleQueryBuilder) Build() (string, error) {
 	if q.resourceName == "" {
 		return "", errors.New("resourceName cannot be empty for CREATE ROLE queries")
@@ -41,6 +76,21 @@ func (q *createRoleQueryBuilder) Build() (string, error) {
 	if q.clusterName != nil {
 		tokens = append(tokens, "ON", "CLUSTER", quote(*q.clusterName))
 	}
+	if len(q.settings) > 0 {
+		each := make([]string, 0)
+		for _, s := range q.settings {
+			sql, err := s.SQLDef()
+			if err != nil {
+				return "", errors.WithMessage(err, "invalid setting")
+			}
+			each = append(each, sql)
+		}
+
+		tokens = append(tokens, "SETTINGS", strings.Join(each, ", "))
+	}
+	if q.comment != nil {
+		tokens = append(tokens, "COMMENT", quote(*q.comment))
+	}
 
 	return strings.Join(tokens, " ") + ";", nil
 }
@@ -56,3 +56,35 @@ func Test_createrole(t *testing.T) {
 		})
 	}
 }
+
+func Test_createrole_settings(t *testing.T) {
+	got, err := NewCreateRole("reader").
+		WithCluster(strPtr("cluster1")).
+		AddSetting("max_memory_usage", strPtr("1000"), nil, strPtr("2000"), nil).
+		AddSetting("readonly", strPtr("1"), nil, nil, strPtr("CONST")).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	want := "CREATE ROLE `reader` ON CLUSTER 'cluster1' SETTINGS `max_memory_usage` = '1000' MAX '2000', `readonly` = '1' CONST;"
+	if got != want {
+		t.Errorf("Build() got = %v, want %v", got, want)
+	}
+}
+
+func Test_createrole_comment(t *testing.T) {
+	got, err := NewCreateRole("reader").
+		WithCluster(strPtr("cluster1")).
+		AddSetting("readonly", strPtr("1"), nil, nil, nil).
+		WithComment(strPtr("owner: team-data")).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	want := "CREATE ROLE `reader` ON CLUSTER 'cluster1' SETTINGS `readonly` = '1' COMMENT 'owner: team-data';"
+	if got != want {
+		t.Errorf("Build() got = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,97 @@
+package querybuilder
+
+import (
+	"testing"
+)
+
+func Test_createRowPolicyQueryBuilder_Build(t *testing.T) {
+	tests := []struct {
+		name         string
+		resourceName string
+		databaseName string
+		tableName    string
+		clusterName  *string
+		kind         RowPolicyKind
+		using        string
+		to           []string
+		want         string
+		wantErr      bool
+	}{
+		{
+			name:         "Simple policy",
+			resourceName: "policy1",
+			databaseName: "db",
+			tableName:    "tbl",
+			using:        "tenant_id = 1",
+			want:         "CREATE ROW POLICY `policy1` ON `db`.`tbl` FOR SELECT USING tenant_id = 1;",
+		},
+		{
+			name:         "Restrictive policy applied to roles on cluster",
+			resourceName: "policy1",
+			databaseName: "db",
+			tableName:    "tbl",
+			clusterName:  strPtr("cluster1"),
+			kind:         RowPolicyKindRestrictive,
+			using:        "tenant_id = currentUser()",
+			to:           []string{"reader", "john"},
+			want:         "CREATE ROW POLICY `policy1` ON CLUSTER 'cluster1' ON `db`.`tbl` AS RESTRICTIVE FOR SELECT USING tenant_id = currentUser() TO `reader`, `john`;",
+		},
+		{
+			name:         "Permissive policy",
+			resourceName: "policy1",
+			databaseName: "db",
+			tableName:    "tbl",
+			kind:         RowPolicyKindPermissive,
+			using:        "1",
+			want:         "CREATE ROW POLICY `policy1` ON `db`.`tbl` AS PERMISSIVE FOR SELECT USING 1;",
+		},
+		{
+			name:         "Invalid kind",
+			resourceName: "policy1",
+			databaseName: "db",
+			tableName:    "tbl",
+			kind:         "strict",
+			using:        "1",
+			wantErr:      true,
+		},
+		{
+			name:         "Missing USING expression",
+			resourceName: "policy1",
+			databaseName: "db",
+			tableName:    "tbl",
+			wantErr:      true,
+		},
+		{
+			name:         "Missing table",
+			resourceName: "policy1",
+			databaseName: "db",
+			using:        "1",
+			wantErr:      true,
+		},
+		{
+			name:         "Empty name",
+			databaseName: "db",
+			tableName:    "tbl",
+			using:        "1",
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewCreateRowPolicy(tt.resourceName, tt.databaseName, tt.tableName).
+				WithCluster(tt.clusterName).
+				WithKind(tt.kind).
+				Using(tt.using).
+				To(tt.to)
+
+			got, err := q.Build()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Build() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Build() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package querybuilder
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/pingcap/errors"
@@ -11,18 +12,24 @@ type CreateSettingsProfileQueryBuilder interface {
 	QueryBuilder
 	WithCluster(clusterName *string) CreateSettingsProfileQueryBuilder
 	InheritFrom(profileNames []string) CreateSettingsProfileQueryBuilder
+	AddSetting(name string, value *string, min *string, max *string, writability *string) CreateSettingsProfileQueryBuilder
+	AddRawSetting(name string, value *string, min *string, max *string, writability *string) CreateSettingsProfileQueryBuilder
+	To(applyTo ApplyTo) CreateSettingsProfileQueryBuilder
 }
 
 type createSettingsProfileQueryBuilder struct {
 	profileName string
 	clusterName *string
 	inheritFrom []string
+	settings    []settingData
+	applyTo     ApplyTo
 }
 
 func NewCreateSettingsProfile(name string) CreateSettingsProfileQueryBuilder {
 	return &createSettingsProfileQueryBuilder{
 		profileName: name,
 		inheritFrom: make([]string, 0),
+		settings:    make([]settingData, 0),
 	}
 }
 
@@ -31,8 +38,38 @@ func (q *createSettingsProfileQueryBuilder) WithCluster(clusterName *string) Cre
 	return q
 }
 
+// InheritFrom sets the profiles to inherit from. Their order matters, since later profiles override earlier ones.
 func (q *createSettingsProfileQueryBuilder) InheritFrom(profileNames []string) CreateSettingsProfileQueryBuilder {
-	q.inheritFrom = profileNames
+	q.inheritFrom = slices.Clone(profileNames)
+	return q
+}
+
+func (q *createSettingsProfileQueryBuilder) AddSetting(name string, value *string, min *string, max *string, writability *string) CreateSettingsProfileQueryBuilder {
+	q.settings = append(q.settings, settingData{
+		Name:        name,
+		Value:       value,
+		Min:         min,
+		Max:         max,
+		Writability: writability,
+	})
+	return q
+}
+
+// AddRawSetting adds a setting whose value, min and max are SQL expressions emitted verbatim, e.g. getSetting('max_threads').
+func (q *createSettingsProfileQueryBuilder) AddRawSetting(name string, value *string, min *string, max *string, writability *string) CreateSettingsProfileQueryBuilder {
+	q.settings = append(q.settings, settingData{
+		Name:        name,
+		Value:       value,
+		Min:         min,
+		Max:         max,
+		Writability: writability,
+		Raw:         true,
+	})
+	return q
+}
+
+func (q *createSettingsProfileQueryBuilder) To(applyTo ApplyTo) CreateSettingsProfileQueryBuilder {
+	q.applyTo = applyTo
 	return q
 }
 
@@ -50,7 +87,22 @@ func (q *createSettingsProfileQueryBuilder) Build() (string, error) {
 		tokens = append(tokens, "ON", "CLUSTER", quote(*q.clusterName))
 	}
 	if len(q.inheritFrom) > 0 {
-		tokens = append(tokens, "INHERIT", strings.Join(backtickAll(q.inheritFrom), ", "))
+		tokens = append(tokens, inheritAll(q.inheritFrom))
+	}
+	if len(q.settings) > 0 {
+		each := make([]string, 0)
+		for _, s := range q.settings {
+			sql, err := s.SQLDef()
+			if err != nil {
+				return "", errors.WithMessage(err, "invalid setting")
+			}
+			each = append(each, sql)
+		}
+
+		tokens = append(tokens, "SETTINGS", strings.Join(each, ", "))
+	}
+	if !q.applyTo.IsEmpty() {
+		tokens = append(tokens, "TO", q.applyTo.SQLDef())
 	}
 
 	return strings.Join(tokens, " ") + ";", nil
@@ -9,6 +9,9 @@ func Test_createSettingsProfileQueryBuilder_Build(t *testing.T) {
 		name        string
 		profileName string
 		clusterName *string
+		inheritFrom []string
+		settings    []settingData
+		applyTo     ApplyTo
 		want        string
 		wantErr     bool
 	}{
@@ -26,12 +29,54 @@ func Test_createSettingsProfileQueryBuilder_Build(t *testing.T) {
 			want:        "CREATE SETTINGS PROFILE `prf1` ON CLUSTER 'cluster1';",
 			wantErr:     false,
 		},
+		{
+			name:        "settings with constraints",
+			profileName: "prf1",
+			settings: []settingData{
+				{Name: "max_memory_usage", Value: strPtr("1000000"), Min: strPtr("0"), Max: strPtr("2000000"), Writability: strPtr("CONST")},
+				{Name: "readonly", Value: strPtr("1")},
+			},
+			want:    "CREATE SETTINGS PROFILE `prf1` SETTINGS `max_memory_usage` = '1000000' MIN '0' MAX '2000000' CONST, `readonly` = '1';",
+			wantErr: false,
+		},
+		{
+			name:        "invalid setting",
+			profileName: "prf1",
+			settings:    []settingData{{Name: "readonly"}},
+			wantErr:     true,
+		},
+		{
+			name:        "apply to users and roles",
+			profileName: "prf1",
+			applyTo:     ApplyTo{Names: []string{"john", "reader"}},
+			want:        "CREATE SETTINGS PROFILE `prf1` TO `john`, `reader`;",
+			wantErr:     false,
+		},
+		{
+			name:        "inherit and apply to all except",
+			profileName: "prf1",
+			clusterName: strPtr("cluster1"),
+			inheritFrom: []string{"default"},
+			applyTo:     ApplyTo{All: true, Except: []string{"admin"}},
+			want:        "CREATE SETTINGS PROFILE `prf1` ON CLUSTER 'cluster1' INHERIT `default` TO ALL EXCEPT `admin`;",
+			wantErr:     false,
+		},
+		{
+			name:        "inherit several profiles in order",
+			profileName: "prf1",
+			inheritFrom: []string{"web", "analytics", "readonly"},
+			want:        "CREATE SETTINGS PROFILE `prf1` INHERIT `web`, INHERIT `analytics`, INHERIT `readonly`;",
+			wantErr:     false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			q := &createSettingsProfileQueryBuilder{
 				profileName: tt.profileName,
 				clusterName: tt.clusterName,
+				inheritFrom: tt.inheritFrom,
+				settings:    tt.settings,
+				applyTo:     tt.applyTo,
 			}
 			got, err := q.Build()
 			if (err != nil) != tt.wantErr {
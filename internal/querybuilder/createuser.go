@@ -3,6 +3,7 @@ package querybuilder
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/pingcap/errors"
 )
@@ -12,8 +13,15 @@ type CreateUserQueryBuilder interface {
 	QueryBuilder
 	Identified(with Identification, by string) CreateUserQueryBuilder
 	IdentifiedWithSSLCertCN(cn string) CreateUserQueryBuilder
+	IdentifiedWithNoPassword() CreateUserQueryBuilder
+	IdentifiedWithMethods(authentications []Authentication) CreateUserQueryBuilder
 	WithDefaultRole(roleName *string) CreateUserQueryBuilder
 	WithSettingsProfile(profileName *string) CreateUserQueryBuilder
+	WithSettingsProfiles(profileNames []string) CreateUserQueryBuilder
+	WithHosts(hosts []Host) CreateUserQueryBuilder
+	WithValidUntil(validUntil *time.Time) CreateUserQueryBuilder
+	WithGrantees(grantees *Grantees) CreateUserQueryBuilder
+	WithComment(comment *string) CreateUserQueryBuilder
 	WithCluster(clusterName *string) CreateUserQueryBuilder
 }
 
@@ -21,14 +29,24 @@ type Identification string
 
 const (
 	IdentificationSHA256Hash Identification = "sha256_hash"
+	IdentificationBcryptHash Identification = "bcrypt_hash"
+	// IdentificationPlaintext stores the password as is on the server, it should only be used by legacy setups.
+	IdentificationPlaintext Identification = "plaintext_password"
+	// IdentificationNoPassword lets anyone log in as the user without any credentials.
+	IdentificationNoPassword Identification = "no_password"
 )
 
 type createUserQueryBuilder struct {
-	resourceName    string
-	identified      string
-	defaultRole     *string
-	settingsProfile *string
-	clusterName     *string
+	resourceName     string
+	identified       string
+	authentications  []Authentication
+	defaultRole      *string
+	settingsProfiles []string
+	hosts            []Host
+	validUntil       *time.Time
+	grantees         *Grantees
+	comment          *string
+	clusterName      *string
 }
 
 func NewCreateUser(resourceName string) CreateUserQueryBuilder {
@@ -47,13 +65,59 @@ func (q *createUserQueryBuilder) IdentifiedWithSSLCertCN(cn string) CreateUserQu
 	return q
 }
 
+func (q *createUserQueryBuilder) IdentifiedWithNoPassword() CreateUserQueryBuilder {
+	q.identified = fmt.Sprintf("IDENTIFIED WITH %s", IdentificationNoPassword)
+	return q
+}
+
+// IdentifiedWithMethods sets all the identification methods of the user at once. It takes precedence over Identified,
+// IdentifiedWithSSLCertCN and IdentifiedWithNoPassword.
+func (q *createUserQueryBuilder) IdentifiedWithMethods(authentications []Authentication) CreateUserQueryBuilder {
+	q.authentications = authentications
+	return q
+}
+
 func (q *createUserQueryBuilder) WithDefaultRole(roleName *string) CreateUserQueryBuilder {
 	q.defaultRole = roleName
 	return q
 }
 
 func (q *createUserQueryBuilder) WithSettingsProfile(profileName *string) CreateUserQueryBuilder {
-	q.settingsProfile = profileName
+	q.settingsProfiles = nil
+	if profileName != nil {
+		q.settingsProfiles = []string{*profileName}
+	}
+	return q
+}
+
+// WithSettingsProfiles sets all the settings profiles of the user at once, replacing the one set with
+// WithSettingsProfile.
+func (q *createUserQueryBuilder) WithSettingsProfiles(profileNames []string) CreateUserQueryBuilder {
+	q.settingsProfiles = profileNames
+	return q
+}
+
+func (q *createUserQueryBuilder) WithHosts(hosts []Host) CreateUserQueryBuilder {
+	q.hosts = hosts
+	return q
+}
+
+// WithValidUntil sets the expiration time of the user's credentials. Nil means they never expire.
+func (q *createUserQueryBuilder) WithValidUntil(validUntil *time.Time) CreateUserQueryBuilder {
+	q.validUntil = validUntil
+	return q
+}
+
+// WithGrantees sets the users and roles the user can grant its privileges and roles to. Nil keeps the ClickHouse
+// default, i.e. GRANTEES ANY.
+func (q *createUserQueryBuilder) WithGrantees(grantees *Grantees) CreateUserQueryBuilder {
+	q.grantees = grantees
+	return q
+}
+
+// WithComment sets the comment of the user, e.g. to record the team owning it. Nil leaves it out.
+func (q *createUserQueryBuilder) WithComment(comment *string) CreateUserQueryBuilder {
+	q.comment = comment
 	return q
 }
 
@@ -78,15 +142,40 @@ func (q *createUserQueryBuilder) Build() (string, error) {
 	if q.clusterName != nil {
 		tokens = append(tokens, "ON", "CLUSTER", quote(*q.clusterName))
 	}
-	if q.identified != "" {
+	if len(q.authentications) > 0 {
+		identified, err := identifiedClause(q.authentications)
+		if err != nil {
+			return "", errors.WithMessage(err, "invalid identification")
+		}
+		tokens = append(tokens, identified)
+	} else if q.identified != "" {
 		tokens = append(tokens, q.identified)
 	}
-	if q.settingsProfile != nil {
-		tokens = append(tokens, "SETTINGS", "PROFILE", quote(*q.settingsProfile))
+	hosts, err := hostsClause(q.hosts)
+	if err != nil {
+		return "", errors.WithMessage(err, "invalid host")
+	}
+	tokens = append(tokens, hosts...)
+	if q.validUntil != nil {
+		tokens = append(tokens, validUntilClause(q.validUntil))
+	}
+	if len(q.settingsProfiles) > 0 {
+		// Each profile needs its own PROFILE keyword, e.g. SETTINGS PROFILE 'a', PROFILE 'b'.
+		profiles := make([]string, 0)
+		for _, p := range q.settingsProfiles {
+			profiles = append(profiles, "PROFILE "+quote(p))
+		}
+		tokens = append(tokens, "SETTINGS", strings.Join(profiles, ", "))
 	}
 	if q.defaultRole != nil {
 		tokens = append(tokens, "DEFAULT", "ROLE", quote(*q.defaultRole))
 	}
+	if q.grantees != nil {
+		tokens = append(tokens, "GRANTEES", q.grantees.SQLDef())
+	}
+	if q.comment != nil {
+		tokens = append(tokens, "COMMENT", quote(*q.comment))
+	}
 
 	return strings.Join(tokens, " ") + ";", nil
 }
@@ -2,20 +2,28 @@ package querybuilder
 
 import (
 	"testing"
+	"time"
 )
 
 func Test_createuser(t *testing.T) {
 	tests := []struct {
-		name            string
-		resourceName    string
-		identifiedWith  Identification
-		identifiedBy    string
-		sslCN           string
-		defaultRole     string
-		settingsProfile string
-		clusterName     string
-		want            string
-		wantErr         bool
+		name             string
+		resourceName     string
+		identifiedWith   Identification
+		identifiedBy     string
+		sslCN            string
+		noPassword       bool
+		authentications  []Authentication
+		defaultRole      string
+		settingsProfile  string
+		settingsProfiles []string
+		hosts            []Host
+		validUntil       *time.Time
+		grantees         *Grantees
+		comment          *string
+		clusterName      string
+		want             string
+		wantErr          bool
 	}{
 		{
 			name:         "Create user no auth",
@@ -23,6 +31,12 @@ func Test_createuser(t *testing.T) {
 			want:         "CREATE USER IF NOT EXISTS `john`;",
 			wantErr:      false,
 		},
+		{
+			name:         "Create user with mixed case name",
+			resourceName: "MyUser",
+			want:         "CREATE USER IF NOT EXISTS `MyUser`;",
+			wantErr:      false,
+		},
 		{
 			name:           "Create user with password",
 			resourceName:   "john",
@@ -31,6 +45,32 @@ func Test_createuser(t *testing.T) {
 			want:           "CREATE USER IF NOT EXISTS `john` IDENTIFIED WITH sha256_hash BY 'blah';",
 			wantErr:        false,
 		},
+		{
+			name:           "Create user with bcrypt password",
+			resourceName:   "john",
+			identifiedWith: IdentificationBcryptHash,
+			identifiedBy:   "$2a$12$blah",
+			want:           "CREATE USER IF NOT EXISTS `john` IDENTIFIED WITH bcrypt_hash BY '$2a$12$blah';",
+		},
+		{
+			name:           "Create user with plaintext password",
+			resourceName:   "john",
+			identifiedWith: IdentificationPlaintext,
+			identifiedBy:   "changeme",
+			want:           "CREATE USER IF NOT EXISTS `john` IDENTIFIED WITH plaintext_password BY 'changeme';",
+		},
+		{
+			name:         "Create user with no password",
+			resourceName: "john",
+			noPassword:   true,
+			want:         "CREATE USER IF NOT EXISTS `john` IDENTIFIED WITH no_password;",
+		},
+		{
+			name:            "Create user with no password and other methods",
+			resourceName:    "john",
+			authentications: []Authentication{{With: IdentificationNoPassword}, {With: IdentificationSHA256Hash, Value: "blah"}},
+			wantErr:         true,
+		},
 		{
 			name:         "Create user with SSL CN",
 			resourceName: "test",
@@ -38,6 +78,25 @@ func Test_createuser(t *testing.T) {
 			want:         "CREATE USER IF NOT EXISTS `test` IDENTIFIED WITH ssl_certificate CN 'test';",
 			wantErr:      false,
 		},
+		{
+			name:         "Create user with GRANTEES",
+			resourceName: "john",
+			grantees:     &Grantees{Names: []string{"jane", "reader"}},
+			want:         "CREATE USER IF NOT EXISTS `john` GRANTEES `jane`, `reader`;",
+		},
+		{
+			name:         "Create user with GRANTEES NONE",
+			resourceName: "john",
+			grantees:     &Grantees{},
+			want:         "CREATE USER IF NOT EXISTS `john` GRANTEES NONE;",
+		},
+		{
+			name:         "Create user with COMMENT",
+			resourceName: "john",
+			grantees:     &Grantees{},
+			comment:      strPtr("owner: team's data"),
+			want:         "CREATE USER IF NOT EXISTS `john` GRANTEES NONE COMMENT 'owner: team\\'s data';",
+		},
 		{
 			name:         "Create user with SSL CN and DEFAULT ROLE on cluster",
 			resourceName: "test",
@@ -47,6 +106,84 @@ func Test_createuser(t *testing.T) {
 			want:         "CREATE USER IF NOT EXISTS `test` ON CLUSTER 'dev_cluster' IDENTIFIED WITH ssl_certificate CN 'test' DEFAULT ROLE 'reader';",
 			wantErr:      false,
 		},
+		{
+			name:            "Create user with password and settings profile",
+			resourceName:    "john",
+			identifiedWith:  IdentificationSHA256Hash,
+			identifiedBy:    "blah",
+			settingsProfile: "readonly",
+			want:            "CREATE USER IF NOT EXISTS `john` IDENTIFIED WITH sha256_hash BY 'blah' SETTINGS PROFILE 'readonly';",
+			wantErr:         false,
+		},
+		{
+			name:             "Create user with a single settings profile in a list",
+			resourceName:     "john",
+			settingsProfiles: []string{"readonly"},
+			want:             "CREATE USER IF NOT EXISTS `john` SETTINGS PROFILE 'readonly';",
+		},
+		{
+			name:             "Create user with multiple settings profiles",
+			resourceName:     "john",
+			identifiedWith:   IdentificationSHA256Hash,
+			identifiedBy:     "blah",
+			settingsProfiles: []string{"readonly", "team's profile"},
+			want:             "CREATE USER IF NOT EXISTS `john` IDENTIFIED WITH sha256_hash BY 'blah' SETTINGS PROFILE 'readonly', PROFILE 'team\\'s profile';",
+		},
+		{
+			name:         "Create user with HOST LOCAL",
+			resourceName: "admin",
+			hosts:        []Host{{Type: HostTypeLocal}},
+			want:         "CREATE USER IF NOT EXISTS `admin` HOST LOCAL;",
+			wantErr:      false,
+		},
+		{
+			name:           "Create user with password and HOST LOCAL combined with IP and NAME",
+			resourceName:   "admin",
+			identifiedWith: IdentificationSHA256Hash,
+			identifiedBy:   "blah",
+			hosts: []Host{
+				{Type: HostTypeLocal},
+				{Type: HostTypeIP, Value: "10.0.0.0/8"},
+				{Type: HostTypeName, Value: "bastion.example.com"},
+			},
+			want:    "CREATE USER IF NOT EXISTS `admin` IDENTIFIED WITH sha256_hash BY 'blah' HOST LOCAL, IP '10.0.0.0/8', NAME 'bastion.example.com';",
+			wantErr: false,
+		},
+		{
+			name:            "Create user with password and VALID UNTIL",
+			resourceName:    "john",
+			identifiedWith:  IdentificationSHA256Hash,
+			identifiedBy:    "blah",
+			hosts:           []Host{{Type: HostTypeLocal}},
+			validUntil:      timePtr(time.Date(2030, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600))),
+			settingsProfile: "readonly",
+			want:            "CREATE USER IF NOT EXISTS `john` IDENTIFIED WITH sha256_hash BY 'blah' HOST LOCAL VALID UNTIL '2030-01-02 02:04:05 UTC' SETTINGS PROFILE 'readonly';",
+			wantErr:         false,
+		},
+		{
+			name:         "Create user with password and SSL CN",
+			resourceName: "john",
+			authentications: []Authentication{
+				{With: IdentificationSHA256Hash, Value: "blah"},
+				{With: IdentificationSSLCertificate, Value: "john.example.com"},
+			},
+			want:    "CREATE USER IF NOT EXISTS `john` IDENTIFIED WITH sha256_hash BY 'blah', ssl_certificate CN 'john.example.com';",
+			wantErr: false,
+		},
+		{
+			name:            "Create user with an empty password among several methods",
+			resourceName:    "john",
+			authentications: []Authentication{{With: IdentificationSSLCertificate, Value: "john"}, {With: IdentificationSHA256Hash}},
+			want:            "",
+			wantErr:         true,
+		},
+		{
+			name:         "Create user with HOST LOCAL combined with ANY",
+			resourceName: "admin",
+			hosts:        []Host{{Type: HostTypeLocal}, {Type: HostTypeAny}},
+			want:         "",
+			wantErr:      true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -55,8 +192,12 @@ func Test_createuser(t *testing.T) {
 			if tt.clusterName != "" {
 				q = q.WithCluster(&tt.clusterName)
 			}
-			if tt.sslCN != "" {
+			if tt.authentications != nil {
+				q = q.IdentifiedWithMethods(tt.authentications)
+			} else if tt.sslCN != "" {
 				q = q.IdentifiedWithSSLCertCN(tt.sslCN)
+			} else if tt.noPassword {
+				q = q.IdentifiedWithNoPassword()
 			} else if tt.identifiedWith != "" && tt.identifiedBy != "" {
 				q = q.Identified(tt.identifiedWith, tt.identifiedBy)
 			}
@@ -66,6 +207,19 @@ func Test_createuser(t *testing.T) {
 			if tt.settingsProfile != "" {
 				q = q.WithSettingsProfile(&tt.settingsProfile)
 			}
+			if tt.settingsProfiles != nil {
+				q = q.WithSettingsProfiles(tt.settingsProfiles)
+			}
+			if tt.hosts != nil {
+				q = q.WithHosts(tt.hosts)
+			}
+			if tt.validUntil != nil {
+				q = q.WithValidUntil(tt.validUntil)
+			}
+			if tt.grantees != nil {
+				q = q.WithGrantees(tt.grantees)
+			}
+			q = q.WithComment(tt.comment)
 
 			got, err := q.Build()
 			if (err != nil) != tt.wantErr {
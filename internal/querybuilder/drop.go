@@ -16,12 +16,14 @@ const (
 type DropQueryBuilder interface {
 	QueryBuilder
 	WithCluster(clusterName *string) DropQueryBuilder
+	IfExists() DropQueryBuilder
 }
 
 type dropQueryBuilder struct {
 	resourceTypeName string
 	resourceName     string
 	clusterName      *string
+	ifExists         bool
 }
 
 func NewDropRole(resourceName string) DropQueryBuilder {
@@ -45,6 +47,12 @@ func (q *dropQueryBuilder) WithCluster(clusterName *string) DropQueryBuilder {
 	return q
 }
 
+// IfExists makes the query succeed when the resource doesn't exist, e.g. when it was dropped concurrently.
+func (q *dropQueryBuilder) IfExists() DropQueryBuilder {
+	q.ifExists = true
+	return q
+}
+
 func newDrop(resourceTypeName string, resourceName string) DropQueryBuilder {
 	return &dropQueryBuilder{
 		resourceTypeName: resourceTypeName,
@@ -60,9 +68,14 @@ func (q *dropQueryBuilder) Build() (string, error) {
 	tokens := []string{
 		"DROP",
 		q.resourceTypeName,
-		backtick(q.resourceName),
 	}
 
+	if q.ifExists {
+		tokens = append(tokens, "IF", "EXISTS")
+	}
+
+	tokens = append(tokens, backtick(q.resourceName))
+
 	if q.clusterName != nil {
 		tokens = append(tokens, "ON", "CLUSTER", quote(*q.clusterName))
 	}
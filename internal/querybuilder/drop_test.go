@@ -15,6 +15,7 @@ func Test_drop(t *testing.T) {
 		comment      string
 		identified   string
 		clusterName  *string
+		ifExists     bool
 		want         string
 		wantErr      bool
 	}{
@@ -75,6 +76,13 @@ func Test_drop(t *testing.T) {
 			want:         "DROP USER `john`;",
 			wantErr:      false,
 		},
+		{
+			name:         "Drop user with mixed case name",
+			resourceType: resourceTypeUser,
+			resourceName: "MyUser",
+			want:         "DROP USER `MyUser`;",
+			wantErr:      false,
+		},
 		{
 			name:         "Drop user with complex name",
 			resourceType: resourceTypeUser,
@@ -82,6 +90,31 @@ func Test_drop(t *testing.T) {
 			want:         "DROP USER `jo\\`hn`;",
 			wantErr:      false,
 		},
+		{
+			name:         "Drop user if exists",
+			resourceType: resourceTypeUser,
+			resourceName: "john",
+			ifExists:     true,
+			want:         "DROP USER IF EXISTS `john`;",
+			wantErr:      false,
+		},
+		{
+			name:         "Drop user if exists on cluster",
+			resourceType: resourceTypeUser,
+			resourceName: "john",
+			clusterName:  &cluster,
+			ifExists:     true,
+			want:         "DROP USER IF EXISTS `john` ON CLUSTER 'cluster1';",
+			wantErr:      false,
+		},
+		{
+			name:         "Drop settings profile if exists",
+			resourceType: resourceTypeSettingsProfile,
+			resourceName: "prf1",
+			ifExists:     true,
+			want:         "DROP SETTINGS PROFILE IF EXISTS `prf1`;",
+			wantErr:      false,
+		},
 		{
 			name:         "Fail to drop user with empty name",
 			resourceType: resourceTypeUser,
@@ -96,6 +129,7 @@ func Test_drop(t *testing.T) {
 				resourceTypeName: tt.resourceType,
 				resourceName:     tt.resourceName,
 				clusterName:      tt.clusterName,
+				ifExists:         tt.ifExists,
 			}
 
 			got, err := q.Build()
@@ -0,0 +1,60 @@
+package querybuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pingcap/errors"
+)
+
+// DropRowPolicyQueryBuilder is an interface to build DROP ROW POLICY SQL queries (already interpolated).
+// Row policies are scoped to a table, so they can't be dropped using the generic DropQueryBuilder.
+type DropRowPolicyQueryBuilder interface {
+	QueryBuilder
+	WithCluster(clusterName *string) DropRowPolicyQueryBuilder
+}
+
+type dropRowPolicyQueryBuilder struct {
+	resourceName string
+	databaseName string
+	tableName    string
+	clusterName  *string
+}
+
+func NewDropRowPolicy(resourceName string, databaseName string, tableName string) DropRowPolicyQueryBuilder {
+	return &dropRowPolicyQueryBuilder{
+		resourceName: resourceName,
+		databaseName: databaseName,
+		tableName:    tableName,
+	}
+}
+
+func (q *dropRowPolicyQueryBuilder) WithCluster(clusterName *string) DropRowPolicyQueryBuilder {
+	q.clusterName = clusterName
+	return q
+}
+
+func (q *dropRowPolicyQueryBuilder) Build() (string, error) {
+	if q.resourceName == "" {
+		return "", errors.New("resourceName cannot be empty for DROP ROW POLICY queries")
+	}
+
+	if q.databaseName == "" || q.tableName == "" {
+		return "", errors.New("databaseName and tableName cannot be empty for DROP ROW POLICY queries")
+	}
+
+	tokens := []string{
+		"DROP",
+		"ROW",
+		"POLICY",
+		backtick(q.resourceName),
+		"ON",
+		fmt.Sprintf("%s.%s", backtick(q.databaseName), backtick(q.tableName)),
+	}
+
+	if q.clusterName != nil {
+		tokens = append(tokens, "ON", "CLUSTER", quote(*q.clusterName))
+	}
+
+	return strings.Join(tokens, " ") + ";", nil
+}
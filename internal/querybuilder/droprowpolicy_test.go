@@ -0,0 +1,52 @@
+package querybuilder
+
+import (
+	"testing"
+)
+
+func Test_dropRowPolicyQueryBuilder_Build(t *testing.T) {
+	tests := []struct {
+		name         string
+		resourceName string
+		tableName    string
+		clusterName  *string
+		want         string
+		wantErr      bool
+	}{
+		{
+			name:         "Drop row policy",
+			resourceName: "policy1",
+			tableName:    "tbl",
+			want:         "DROP ROW POLICY `policy1` ON `db`.`tbl`;",
+		},
+		{
+			name:         "Drop row policy on cluster",
+			resourceName: "policy1",
+			tableName:    "tbl",
+			clusterName:  strPtr("cluster1"),
+			want:         "DROP ROW POLICY `policy1` ON `db`.`tbl` ON CLUSTER 'cluster1';",
+		},
+		{
+			name:      "Empty name",
+			tableName: "tbl",
+			wantErr:   true,
+		},
+		{
+			name:         "Empty table",
+			resourceName: "policy1",
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewDropRowPolicy(tt.resourceName, "db", tt.tableName).WithCluster(tt.clusterName).Build()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Build() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Build() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -6,12 +6,15 @@ import (
 
 type Field interface {
 	ToString() Field
+	ToUTCString() Field
 	SQLDef() string
 }
 
 type field struct {
-	name     string
-	toString bool
+	name       string
+	expression string
+	toString   bool
+	timezone   string
 }
 
 func NewField(name string) Field {
@@ -20,14 +23,42 @@ func NewField(name string) Field {
 	}
 }
 
+// NewExpressionField returns a field holding the result of the given SQL expression, named 'alias'.
+// The expression is not escaped, so it must not contain user input.
+func NewExpressionField(expression string, alias string) Field {
+	return &field{
+		name:       alias,
+		expression: expression,
+	}
+}
+
 func (f *field) ToString() Field {
 	f.toString = true
 	return f
 }
 
+// ToUTCString converts a DateTime field to its string representation in the UTC timezone,
+// to avoid depending on the server's timezone when parsing it.
+func (f *field) ToUTCString() Field {
+	f.toString = true
+	f.timezone = "UTC"
+	return f
+}
+
 func (f *field) SQLDef() string {
+	expression := backtick(f.name)
+	if f.expression != "" {
+		expression = f.expression
+	}
+
+	if f.toString && f.timezone != "" {
+		return fmt.Sprintf("toString(%s, %s) AS %s", expression, quote(f.timezone), backtick(f.name))
+	}
 	if f.toString {
-		return fmt.Sprintf("toString(%s) AS %s", backtick(f.name), backtick(f.name))
+		return fmt.Sprintf("toString(%s) AS %s", expression, backtick(f.name))
+	}
+	if f.expression != "" {
+		return fmt.Sprintf("%s AS %s", expression, backtick(f.name))
 	}
-	return backtick(f.name)
+	return expression
 }
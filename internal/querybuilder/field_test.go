@@ -6,10 +6,12 @@ import (
 
 func Test_field_SQLDef(t *testing.T) {
 	tests := []struct {
-		name      string
-		fieldName string
-		toString  bool
-		want      string
+		name       string
+		fieldName  string
+		expression string
+		toString   bool
+		timezone   string
+		want       string
 	}{
 		{
 			name:      "Simple field",
@@ -35,12 +37,34 @@ func Test_field_SQLDef(t *testing.T) {
 			toString:  true,
 			want:      "toString(`fie\\`ld1`) AS `fie\\`ld1`",
 		},
+		{
+			name:      "DateTime field with toString in UTC",
+			fieldName: "valid_until",
+			toString:  true,
+			timezone:  "UTC",
+			want:      "toString(`valid_until`, 'UTC') AS `valid_until`",
+		},
+		{
+			name:       "Expression field",
+			fieldName:  "version",
+			expression: "version()",
+			want:       "version() AS `version`",
+		},
+		{
+			name:       "Expression field with toString",
+			fieldName:  "uptime",
+			expression: "uptime()",
+			toString:   true,
+			want:       "toString(uptime()) AS `uptime`",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			f := &field{
-				name:     tt.fieldName,
-				toString: tt.toString,
+				name:       tt.fieldName,
+				expression: tt.expression,
+				toString:   tt.toString,
+				timezone:   tt.timezone,
 			}
 			if got := f.SQLDef(); got != tt.want {
 				t.Errorf("SQLDef() = %v, want %v", got, tt.want)
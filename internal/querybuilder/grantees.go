@@ -0,0 +1,29 @@
+package querybuilder
+
+import (
+	"strings"
+)
+
+// Grantees is the set of users and roles a user can grant its privileges and roles to.
+type Grantees struct {
+	// Any allows granting to every user and role but the ones in Except.
+	Any    bool
+	Names  []string
+	Except []string
+}
+
+// SQLDef returns the part of a GRANTEES clause that follows the GRANTEES keyword.
+func (g Grantees) SQLDef() string {
+	if g.Any {
+		if len(g.Except) > 0 {
+			return "ANY EXCEPT " + strings.Join(backtickAll(g.Except), ", ")
+		}
+		return "ANY"
+	}
+
+	if len(g.Names) == 0 {
+		return "NONE"
+	}
+
+	return strings.Join(backtickAll(g.Names), ", ")
+}
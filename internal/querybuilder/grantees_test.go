@@ -0,0 +1,46 @@
+package querybuilder
+
+import (
+	"testing"
+)
+
+func TestGrantees_SQLDef(t *testing.T) {
+	tests := []struct {
+		name     string
+		grantees Grantees
+		want     string
+	}{
+		{
+			name:     "Empty",
+			grantees: Grantees{},
+			want:     "NONE",
+		},
+		{
+			name:     "Names",
+			grantees: Grantees{Names: []string{"john", "reader"}},
+			want:     "`john`, `reader`",
+		},
+		{
+			name:     "Any",
+			grantees: Grantees{Any: true},
+			want:     "ANY",
+		},
+		{
+			name:     "Any except",
+			grantees: Grantees{Any: true, Except: []string{"admin", "guest"}},
+			want:     "ANY EXCEPT `admin`, `guest`",
+		},
+		{
+			name:     "Names are ignored with any",
+			grantees: Grantees{Any: true, Names: []string{"john"}},
+			want:     "ANY",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.grantees.SQLDef(); got != tt.want {
+				t.Errorf("SQLDef() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
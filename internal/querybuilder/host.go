@@ -0,0 +1,95 @@
+package querybuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pingcap/errors"
+)
+
+type HostType string
+
+const (
+	HostTypeLocal  HostType = "LOCAL"
+	HostTypeIP     HostType = "IP"
+	HostTypeName   HostType = "NAME"
+	HostTypeRegexp HostType = "REGEXP"
+	HostTypeLike   HostType = "LIKE"
+	HostTypeAny    HostType = "ANY"
+	HostTypeNone   HostType = "NONE"
+)
+
+// Host is a single entry of the HOST clause of CREATE/ALTER USER queries.
+// Value is ignored for LOCAL, ANY and NONE.
+type Host struct {
+	Type  HostType
+	Value string
+}
+
+func (h *Host) SQLDef() (string, error) {
+	switch h.Type {
+	case HostTypeLocal, HostTypeAny, HostTypeNone:
+		return string(h.Type), nil
+	case HostTypeIP, HostTypeName, HostTypeRegexp, HostTypeLike:
+		if h.Value == "" {
+			return "", errors.New(fmt.Sprintf("Value can't be empty for HOST %s", h.Type))
+		}
+		return fmt.Sprintf("%s %s", h.Type, quote(h.Value)), nil
+	}
+
+	return "", errors.New(fmt.Sprintf("invalid host type %q", h.Type))
+}
+
+// ValidateHosts checks that the given hosts can be combined in a single HOST clause.
+// ANY and NONE must be used alone, while LOCAL, IP, NAME, REGEXP and LIKE can be freely mixed.
+func ValidateHosts(hosts []Host) error {
+	for _, h := range hosts {
+		if _, err := h.SQLDef(); err != nil {
+			return err
+		}
+
+		if (h.Type == HostTypeAny || h.Type == HostTypeNone) && len(hosts) > 1 {
+			return errors.New(fmt.Sprintf("HOST %s cannot be combined with other hosts", h.Type))
+		}
+	}
+
+	return nil
+}
+
+// hostsClause returns the HOST clause tokens for the given hosts, or nil if no host is set.
+func hostsClause(hosts []Host) ([]string, error) {
+	if len(hosts) == 0 {
+		return nil, nil
+	}
+
+	if err := ValidateHosts(hosts); err != nil {
+		return nil, err
+	}
+
+	each := make([]string, 0)
+	for _, h := range hosts {
+		sql, err := h.SQLDef()
+		if err != nil {
+			return nil, err
+		}
+		each = append(each, sql)
+	}
+
+	return []string{"HOST", strings.Join(each, ", ")}, nil
+}
+
+// alterHostsClause returns the ADD HOST or DROP HOST clause tokens for the given hosts, depending on the action.
+func alterHostsClause(action string, hosts []Host) ([]string, error) {
+	for _, h := range hosts {
+		if h.Type == HostTypeAny || h.Type == HostTypeNone {
+			return nil, errors.New(fmt.Sprintf("HOST %s cannot be used with %s HOST", h.Type, action))
+		}
+	}
+
+	clause, err := hostsClause(hosts)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]string{action}, clause...), nil
+}
@@ -0,0 +1,115 @@
+package querybuilder
+
+import (
+	"testing"
+)
+
+func Test_ValidateHosts(t *testing.T) {
+	tests := []struct {
+		name    string
+		hosts   []Host
+		wantErr bool
+	}{
+		{
+			name:    "No hosts",
+			hosts:   nil,
+			wantErr: false,
+		},
+		{
+			name:    "Only LOCAL",
+			hosts:   []Host{{Type: HostTypeLocal}},
+			wantErr: false,
+		},
+		{
+			name: "LOCAL with IP, NAME, REGEXP and LIKE",
+			hosts: []Host{
+				{Type: HostTypeLocal},
+				{Type: HostTypeIP, Value: "192.168.0.1"},
+				{Type: HostTypeName, Value: "example.com"},
+				{Type: HostTypeRegexp, Value: ".*\\.example\\.com"},
+				{Type: HostTypeLike, Value: "%.example.com"},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Only ANY",
+			hosts:   []Host{{Type: HostTypeAny}},
+			wantErr: false,
+		},
+		{
+			name:    "LOCAL with ANY",
+			hosts:   []Host{{Type: HostTypeLocal}, {Type: HostTypeAny}},
+			wantErr: true,
+		},
+		{
+			name:    "LOCAL with NONE",
+			hosts:   []Host{{Type: HostTypeNone}, {Type: HostTypeLocal}},
+			wantErr: true,
+		},
+		{
+			name:    "IP without value",
+			hosts:   []Host{{Type: HostTypeIP}},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid type",
+			hosts:   []Host{{Type: "SUBNET", Value: "10.0.0.0/8"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateHosts(tt.hosts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateHosts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_host_SQLDef(t *testing.T) {
+	tests := []struct {
+		name    string
+		host    Host
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "LOCAL",
+			host: Host{Type: HostTypeLocal},
+			want: "LOCAL",
+		},
+		{
+			name: "LOCAL ignores value",
+			host: Host{Type: HostTypeLocal, Value: "foo"},
+			want: "LOCAL",
+		},
+		{
+			name: "IP",
+			host: Host{Type: HostTypeIP, Value: "::1"},
+			want: "IP '::1'",
+		},
+		{
+			name: "NAME with quote",
+			host: Host{Type: HostTypeName, Value: "it's"},
+			want: "NAME 'it\\'s'",
+		},
+		{
+			name:    "LIKE without value",
+			host:    Host{Type: HostTypeLike},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.host.SQLDef()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SQLDef() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("SQLDef() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
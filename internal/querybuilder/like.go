@@ -0,0 +1,37 @@
+package querybuilder
+
+import (
+	"strings"
+)
+
+// likeEscaper escapes the characters having a special meaning in LIKE patterns.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// WhereLike matches the rows where the given field matches the LIKE pattern, case-sensitively.
+// The pattern is used as is: use EscapeLike or LikePrefix to match a literal value.
+func WhereLike(fieldName string, pattern string) Where {
+	return &simpleWhere{
+		field:    fieldName,
+		value:    pattern,
+		operator: "LIKE",
+	}
+}
+
+// WhereILike is the case-insensitive version of WhereLike.
+func WhereILike(fieldName string, pattern string) Where {
+	return &simpleWhere{
+		field:    fieldName,
+		value:    pattern,
+		operator: "ILIKE",
+	}
+}
+
+// EscapeLike returns a LIKE pattern matching the given literal value, escaping its % and _ wildcards.
+func EscapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
+
+// LikePrefix returns a LIKE pattern matching the values starting with the given literal prefix.
+func LikePrefix(prefix string) string {
+	return EscapeLike(prefix) + "%"
+}
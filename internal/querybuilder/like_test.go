@@ -0,0 +1,59 @@
+package querybuilder
+
+import (
+	"testing"
+)
+
+func Test_WhereLike(t *testing.T) {
+	tests := []struct {
+		name  string
+		where Where
+		want  string
+	}{
+		{
+			name:  "Pattern with wildcards",
+			where: WhereLike("name", "tenant_%"),
+			want:  "`name` LIKE 'tenant_%'",
+		},
+		{
+			name:  "Case insensitive",
+			where: WhereILike("name", "Tenant%"),
+			want:  "`name` ILIKE 'Tenant%'",
+		},
+		{
+			name:  "Literal prefix with wildcards",
+			where: WhereLike("name", LikePrefix("50%_raw")),
+			want:  "`name` LIKE '50\\\\%\\\\_raw%'",
+		},
+		{
+			name:  "Literal value with quote and backslash",
+			where: WhereILike("name", EscapeLike(`o'neil\`)),
+			want:  "`name` ILIKE 'o\\'neil\\\\\\\\'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.where.Clause(); got != tt.want {
+				t.Errorf("Clause() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_EscapeLike(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "reader", want: "reader"},
+		{value: "50%_raw", want: `50\%\_raw`},
+		{value: `a\b`, want: `a\\b`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := EscapeLike(tt.value); got != tt.want {
+				t.Errorf("EscapeLike() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package querybuilder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OrWhere matches the rows matching any of the given clauses.
+func OrWhere(clauses ...Where) Where {
+	return &orWhere{
+		clauses: clauses,
+	}
+}
+
+type orWhere struct {
+	clauses []Where
+}
+
+func (s *orWhere) Clause() string {
+	tokens := make([]string, 0)
+
+	for _, c := range s.clauses {
+		tokens = append(tokens, c.Clause())
+	}
+
+	return fmt.Sprintf("(%s)", strings.Join(tokens, " OR "))
+}
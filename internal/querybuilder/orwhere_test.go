@@ -0,0 +1,46 @@
+package querybuilder
+
+import (
+	"testing"
+)
+
+func Test_OrWhere_String(t *testing.T) {
+	tests := []struct {
+		name  string
+		where Where
+		want  string
+	}{
+		{
+			name:  "2 clauses",
+			where: OrWhere(WhereMock("clause1"), WhereMock("clause2")),
+			want:  "(clause1 OR clause2)",
+		},
+		{
+			name:  "1 clause",
+			where: OrWhere(WhereMock("clause1")),
+			want:  "(clause1)",
+		},
+		{
+			name:  "nested in and",
+			where: AndWhere(WhereMock("clause1"), OrWhere(WhereMock("clause2"), WhereMock("clause3"))),
+			want:  "(clause1 AND (clause2 OR clause3))",
+		},
+		{
+			name:  "and nested in or",
+			where: OrWhere(AndWhere(WhereMock("clause1"), WhereMock("clause2")), WhereMock("clause3")),
+			want:  "((clause1 AND clause2) OR clause3)",
+		},
+		{
+			name:  "equals clauses",
+			where: OrWhere(WhereEquals("user_name", "john"), WhereEquals("role_name", "john")),
+			want:  "(`user_name` = 'john' OR `role_name` = 'john')",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.where.Clause(); got != tt.want {
+				t.Errorf("String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
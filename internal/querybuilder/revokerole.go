@@ -10,12 +10,14 @@ import (
 type RevokeRoleQueryBuilder interface {
 	QueryBuilder
 	WithCluster(clusterName *string) RevokeRoleQueryBuilder
+	AdminOptionOnly() RevokeRoleQueryBuilder
 }
 
 type revokeRoleQueryBuilder struct {
-	roleName    string
-	from        string
-	clusterName *string
+	roleName        string
+	from            string
+	clusterName     *string
+	adminOptionOnly bool
 }
 
 func RevokeRole(roleName string, from string) RevokeRoleQueryBuilder {
@@ -30,6 +32,12 @@ func (q *revokeRoleQueryBuilder) WithCluster(clusterName *string) RevokeRoleQuer
 	return q
 }
 
+// AdminOptionOnly revokes the admin option of the grant, while keeping the role granted.
+func (q *revokeRoleQueryBuilder) AdminOptionOnly() RevokeRoleQueryBuilder {
+	q.adminOptionOnly = true
+	return q
+}
+
 func (q *revokeRoleQueryBuilder) Build() (string, error) {
 	if q.roleName == "" {
 		return "", errors.New("RoleName cannot be empty")
@@ -45,6 +53,10 @@ func (q *revokeRoleQueryBuilder) Build() (string, error) {
 		tokens = append(tokens, "ON", "CLUSTER", quote(*q.clusterName))
 	}
 
+	if q.adminOptionOnly {
+		tokens = append(tokens, "ADMIN", "OPTION", "FOR")
+	}
+
 	tokens = append(tokens, backtick(q.roleName), "FROM", backtick(q.from))
 
 	return strings.Join(tokens, " ") + ";", nil
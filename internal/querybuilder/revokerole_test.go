@@ -58,3 +58,32 @@ func Test_revokeRoleQueryBuilder_Build(t *testing.T) {
 		})
 	}
 }
+
+func Test_revokeRoleQueryBuilder_AdminOptionOnly(t *testing.T) {
+	tests := []struct {
+		name        string
+		clusterName *string
+		want        string
+	}{
+		{
+			name: "Revoke admin option",
+			want: "REVOKE ADMIN OPTION FOR `test` FROM `user`;",
+		},
+		{
+			name:        "Revoke admin option on cluster",
+			clusterName: strPtr("cluster1"),
+			want:        "REVOKE ON CLUSTER 'cluster1' ADMIN OPTION FOR `test` FROM `user`;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RevokeRole("test", "user").WithCluster(tt.clusterName).AdminOptionOnly().Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Build() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package querybuilder
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/pingcap/errors"
@@ -14,21 +15,41 @@ const (
 	DESC OrderDirection = "DESC"
 )
 
+// ClusterReads controls which replicas SELECT queries run on a cluster read from.
+type ClusterReads string
+
+const (
+	// ClusterReadsAnyReplica reads from one replica of each shard, chosen by the load balancing of the server.
+	ClusterReadsAnyReplica ClusterReads = "any_replica"
+	// ClusterReadsAllReplicas reads from all the replicas, and removes duplicate rows.
+	ClusterReadsAllReplicas ClusterReads = "all_replicas"
+	// ClusterReadsFirstReplica always reads from the first available replica of each shard, in the cluster definition order.
+	ClusterReadsFirstReplica ClusterReads = "first_replica"
+)
+
 // SelectQueryBuilder is an interface to build SELECT SQL queries (already interpolated).
 type SelectQueryBuilder interface {
 	QueryBuilder
 	Where(...Where) SelectQueryBuilder
 	WithCluster(clusterName *string) SelectQueryBuilder
+	WithClusterReads(mode ClusterReads) SelectQueryBuilder
 	OrderBy(column Field, order OrderDirection) SelectQueryBuilder
+	Limit(n uint64) SelectQueryBuilder
 }
 
 type selectQueryBuilder struct {
-	tableName      string
-	fields         []Field
-	where          Where
-	clusterName    *string
-	orderBy        Field
-	orderDirection *OrderDirection
+	tableName    string
+	fields       []Field
+	where        Where
+	clusterName  *string
+	clusterReads ClusterReads
+	orderBy      []orderBy
+	limit        *uint64
+}
+
+type orderBy struct {
+	column    Field
+	direction OrderDirection
 }
 
 func NewSelect(fields []Field, from string) SelectQueryBuilder {
@@ -38,6 +59,12 @@ func NewSelect(fields []Field, from string) SelectQueryBuilder {
 	}
 }
 
+// NewCount returns a builder counting the rows of the table, in a column named 'cnt'.
+// It is meant for existence checks, which don't depend on the columns of the table.
+func NewCount(from string) SelectQueryBuilder {
+	return NewSelect([]Field{NewExpressionField("count()", "cnt")}, from)
+}
+
 func (q *selectQueryBuilder) Where(where ...Where) SelectQueryBuilder {
 	q.where = AndWhere(where...)
 	return q
@@ -48,9 +75,21 @@ func (q *selectQueryBuilder) WithCluster(clusterName *string) SelectQueryBuilder
 	return q
 }
 
+// WithClusterReads sets which replicas are read from when a cluster is set. Defaults to ClusterReadsAnyReplica.
+func (q *selectQueryBuilder) WithClusterReads(mode ClusterReads) SelectQueryBuilder {
+	q.clusterReads = mode
+	return q
+}
+
+// OrderBy sorts the rows by the column. Calling it several times sorts by each column in turn.
 func (q *selectQueryBuilder) OrderBy(column Field, order OrderDirection) SelectQueryBuilder {
-	q.orderBy = column
-	q.orderDirection = &order
+	q.orderBy = append(q.orderBy, orderBy{column: column, direction: order})
+	return q
+}
+
+// Limit caps the number of rows returned by the query.
+func (q *selectQueryBuilder) Limit(n uint64) SelectQueryBuilder {
+	q.limit = &n
 	return q
 }
 
@@ -75,28 +114,47 @@ func (q *selectQueryBuilder) Build() (string, error) {
 		}
 		tableName := strings.Join(tokens, ".")
 
-		if q.clusterName != nil {
-			from = fmt.Sprintf("cluster(%s, %s)", quote(*q.clusterName), tableName)
-		} else {
+		switch {
+		case q.clusterName == nil:
 			from = tableName
+		case q.clusterReads == ClusterReadsAllReplicas:
+			from = fmt.Sprintf("clusterAllReplicas(%s, %s)", quote(*q.clusterName), tableName)
+		default:
+			from = fmt.Sprintf("cluster(%s, %s)", quote(*q.clusterName), tableName)
 		}
 	}
 
-	tokens := []string{
-		"SELECT",
-		strings.Join(fields, ", "),
-		"FROM",
-		from,
+	tokens := []string{"SELECT"}
+
+	// Replicas in sync return the same rows.
+	if q.clusterName != nil && q.clusterReads == ClusterReadsAllReplicas {
+		tokens = append(tokens, "DISTINCT")
 	}
 
+	tokens = append(tokens, strings.Join(fields, ", "), "FROM", from)
+
 	// Handle WHERE
 	if q.where != nil {
 		tokens = append(tokens, "WHERE", q.where.Clause())
 	}
 
 	// ORDER BY
-	if q.orderBy != nil && q.orderDirection != nil {
-		tokens = append(tokens, "ORDER BY", q.orderBy.SQLDef(), string(*q.orderDirection))
+	if len(q.orderBy) > 0 {
+		columns := make([]string, 0, len(q.orderBy))
+		for _, o := range q.orderBy {
+			columns = append(columns, fmt.Sprintf("%s %s", o.column.SQLDef(), o.direction))
+		}
+		tokens = append(tokens, "ORDER BY", strings.Join(columns, ", "))
+	}
+
+	// LIMIT
+	if q.limit != nil {
+		tokens = append(tokens, "LIMIT", strconv.FormatUint(*q.limit, 10))
+	}
+
+	// Don't prefer the replica the query is sent to, so that the same replica is read whatever the server connected to.
+	if q.clusterName != nil && q.clusterReads == ClusterReadsFirstReplica {
+		tokens = append(tokens, "SETTINGS", "load_balancing = 'in_order', prefer_localhost_replica = 0")
 	}
 
 	return strings.Join(tokens, " ") + ";", nil
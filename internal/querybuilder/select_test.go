@@ -7,6 +7,7 @@ import (
 func Test_selectQueryBuilder_Build(t *testing.T) {
 	orderBy := NewField("col1")
 	orderDirection := ASC
+	limit := uint64(100)
 
 	tests := []struct {
 		name     string
@@ -14,8 +15,10 @@ func Test_selectQueryBuilder_Build(t *testing.T) {
 		where    []Where
 		from     string
 		cluster  string
+		reads    ClusterReads
 		orderCol *Field
 		orderDir *OrderDirection
+		limit    *uint64
 		want     string
 		wantErr  bool
 	}{
@@ -34,6 +37,39 @@ func Test_selectQueryBuilder_Build(t *testing.T) {
 			want:    "SELECT `name` FROM cluster('cluster1', `users`);",
 			wantErr: false,
 		},
+		{
+			name:    "Select With Cluster from any replica",
+			fields:  []Field{NewField("name")},
+			from:    "users",
+			cluster: "cluster1",
+			reads:   ClusterReadsAnyReplica,
+			want:    "SELECT `name` FROM cluster('cluster1', `users`);",
+		},
+		{
+			name:    "Select With Cluster from all replicas",
+			fields:  []Field{NewField("name")},
+			from:    "users",
+			cluster: "cluster1",
+			reads:   ClusterReadsAllReplicas,
+			want:    "SELECT DISTINCT `name` FROM clusterAllReplicas('cluster1', `users`);",
+		},
+		{
+			name:     "Select With Cluster from the first replica",
+			fields:   []Field{NewField("name")},
+			from:     "users",
+			cluster:  "cluster1",
+			reads:    ClusterReadsFirstReplica,
+			orderCol: &orderBy,
+			orderDir: &orderDirection,
+			want:     "SELECT `name` FROM cluster('cluster1', `users`) ORDER BY `col1` ASC SETTINGS load_balancing = 'in_order', prefer_localhost_replica = 0;",
+		},
+		{
+			name:   "Cluster reads without cluster",
+			fields: []Field{NewField("name")},
+			from:   "users",
+			reads:  ClusterReadsAllReplicas,
+			want:   "SELECT `name` FROM `users`;",
+		},
 		{
 			name:    "Select two fields",
 			fields:  []Field{NewField("name"), NewField("surname")},
@@ -74,6 +110,35 @@ func Test_selectQueryBuilder_Build(t *testing.T) {
 			want:     "SELECT `name` FROM `users` WHERE (mock_where_clause) ORDER BY `col1` ASC;",
 			wantErr:  false,
 		},
+		{
+			name:   "Select with limit",
+			fields: []Field{NewField("name")},
+			from:   "users",
+			limit:  &limit,
+			want:   "SELECT `name` FROM `users` LIMIT 100;",
+		},
+		{
+			name:     "Select with limit after cluster, where and order by",
+			fields:   []Field{NewField("name")},
+			where:    []Where{whereMock{"mock_where_clause"}},
+			cluster:  "cluster1",
+			orderCol: &orderBy,
+			orderDir: &orderDirection,
+			limit:    &limit,
+			from:     "users",
+			want:     "SELECT `name` FROM cluster('cluster1', `users`) WHERE (mock_where_clause) ORDER BY `col1` ASC LIMIT 100;",
+		},
+		{
+			name:     "Select with limit before settings",
+			fields:   []Field{NewField("name")},
+			from:     "users",
+			cluster:  "cluster1",
+			reads:    ClusterReadsFirstReplica,
+			orderCol: &orderBy,
+			orderDir: &orderDirection,
+			limit:    &limit,
+			want:     "SELECT `name` FROM cluster('cluster1', `users`) ORDER BY `col1` ASC LIMIT 100 SETTINGS load_balancing = 'in_order', prefer_localhost_replica = 0;",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -84,9 +149,15 @@ func Test_selectQueryBuilder_Build(t *testing.T) {
 			if tt.cluster != "" {
 				q = q.WithCluster(&tt.cluster)
 			}
+			if tt.reads != "" {
+				q = q.WithClusterReads(tt.reads)
+			}
 			if tt.orderCol != nil && tt.orderDir != nil {
 				q = q.OrderBy(*tt.orderCol, *tt.orderDir)
 			}
+			if tt.limit != nil {
+				q = q.Limit(*tt.limit)
+			}
 			got, err := q.Build()
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Build() error = %v, wantErr %v", err, tt.wantErr)
@@ -98,3 +169,58 @@ func Test_selectQueryBuilder_Build(t *testing.T) {
 		})
 	}
 }
+
+func Test_selectQueryBuilder_OrderBy(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(q SelectQueryBuilder) SelectQueryBuilder
+		want  string
+	}{
+		{
+			name: "Order by one column descending",
+			build: func(q SelectQueryBuilder) SelectQueryBuilder {
+				return q.OrderBy(NewField("name"), DESC)
+			},
+			want: "SELECT `name` FROM `users` ORDER BY `name` DESC;",
+		},
+		{
+			name: "Order by two columns",
+			build: func(q SelectQueryBuilder) SelectQueryBuilder {
+				return q.OrderBy(NewField("index"), ASC).OrderBy(NewField("name"), DESC)
+			},
+			want: "SELECT `name` FROM `users` ORDER BY `index` ASC, `name` DESC;",
+		},
+		{
+			name: "Order by two columns with a limit",
+			build: func(q SelectQueryBuilder) SelectQueryBuilder {
+				return q.OrderBy(NewField("index"), ASC).OrderBy(NewField("name"), ASC).Limit(1)
+			},
+			want: "SELECT `name` FROM `users` ORDER BY `index` ASC, `name` ASC LIMIT 1;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.build(NewSelect([]Field{NewField("name")}, "users")).Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Build() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_NewCount(t *testing.T) {
+	clusterName := "cluster1"
+
+	got, err := NewCount("system.users").WithCluster(&clusterName).Where(WhereEquals("name", "john")).Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	want := "SELECT count() AS `cnt` FROM cluster('cluster1', `system`.`users`) WHERE (`name` = 'john');"
+	if got != want {
+		t.Errorf("Build() got = %q, want %q", got, want)
+	}
+}
@@ -19,6 +19,8 @@ type settingData struct {
 	Min         *string
 	Max         *string
 	Writability *string
+	// Raw makes Value, Min and Max be emitted verbatim as SQL expressions rather than as string literals.
+	Raw bool
 }
 
 type setting interface {
@@ -38,16 +40,21 @@ func (s *settingData) SQLDef() (string, error) {
 		return "", errors.New(fmt.Sprintf("Invalid value for Writability. Can be %q, %q or %q", writabilityConst, writabilityWritable, writabilityChangeable))
 	}
 
+	render := quote
+	if s.Raw {
+		render = func(value string) string { return value }
+	}
+
 	singleSetting := make([]string, 0)
 	singleSetting = append(singleSetting, backtick(s.Name))
 	if s.Value != nil {
-		singleSetting = append(singleSetting, "=", quote(*s.Value))
+		singleSetting = append(singleSetting, "=", render(*s.Value))
 	}
 	if s.Min != nil {
-		singleSetting = append(singleSetting, "MIN", quote(*s.Min))
+		singleSetting = append(singleSetting, "MIN", render(*s.Min))
 	}
 	if s.Max != nil {
-		singleSetting = append(singleSetting, "MAX", quote(*s.Max))
+		singleSetting = append(singleSetting, "MAX", render(*s.Max))
 	}
 	if s.Writability != nil {
 		singleSetting = append(singleSetting, *s.Writability)
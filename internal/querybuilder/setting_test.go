@@ -75,6 +75,37 @@ func Test_setting_SQLDef(t *testing.T) {
 			want:    "`test` = '50' MIN '10' MAX '100'",
 			wantErr: false,
 		},
+		{
+			name: "Custom setting with a numeric looking value stays a string",
+			setting: &settingData{
+				Name:  "SQL_tenant",
+				Value: strPtr("42"),
+			},
+			want:    "`SQL_tenant` = '42'",
+			wantErr: false,
+		},
+		{
+			name: "Raw value",
+			setting: &settingData{
+				Name:  "log_queries",
+				Value: strPtr("1"),
+				Raw:   true,
+			},
+			want:    "`log_queries` = 1",
+			wantErr: false,
+		},
+		{
+			name: "Raw value, min and max",
+			setting: &settingData{
+				Name:  "max_threads",
+				Value: strPtr("getSetting('max_threads')"),
+				Min:   strPtr("1"),
+				Max:   strPtr("16"),
+				Raw:   true,
+			},
+			want:    "`max_threads` = getSetting('max_threads') MIN 1 MAX 16",
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -2,6 +2,7 @@ package querybuilder
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 )
 
@@ -21,10 +22,39 @@ func backtickAll(s []string) []string {
 	return ret
 }
 
+// inheritAll returns the INHERIT elements of a settings profile, in the given order. Each profile needs its own
+// INHERIT keyword, otherwise the following names are parsed as settings.
+func inheritAll(profileNames []string) string {
+	ret := make([]string, 0)
+	for _, p := range profileNames {
+		ret = append(ret, "INHERIT "+backtick(p))
+	}
+	return strings.Join(ret, ", ")
+}
+
 func quote(s string) string {
 	return fmt.Sprintf("'%s'", strings.ReplaceAll(backslash(s), "'", "\\'"))
 }
 
+func quoteAll(s []string) []string {
+	ret := make([]string, 0)
+	for _, p := range s {
+		ret = append(ret, quote(p))
+	}
+	return ret
+}
+
+// profilesNotIn returns the profiles of a that are not in b, without duplicates and in their original order.
+func profilesNotIn(a []string, b []string) []string {
+	ret := make([]string, 0)
+	for _, p := range a {
+		if !slices.Contains(b, p) && !slices.Contains(ret, p) {
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
+
 func backslash(s string) string {
 	return strings.ReplaceAll(s, "\\", "\\\\")
 }
@@ -0,0 +1,16 @@
+package querybuilder
+
+import (
+	"fmt"
+	"time"
+)
+
+// validUntilClause returns the VALID UNTIL clause for the given expiration time, or 'infinity' if it is nil.
+// The time is sent in UTC with an explicit timezone, so that it doesn't depend on the server's timezone.
+func validUntilClause(validUntil *time.Time) string {
+	if validUntil == nil {
+		return fmt.Sprintf("VALID UNTIL %s", quote("infinity"))
+	}
+
+	return fmt.Sprintf("VALID UNTIL %s", quote(validUntil.UTC().Format(time.DateTime)+" UTC"))
+}
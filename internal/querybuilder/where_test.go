@@ -15,6 +15,11 @@ func Test_SimpleWhere_Clause(t *testing.T) {
 			where: WhereEquals("name", "mark"),
 			want:  "`name` = 'mark'",
 		},
+		{
+			name:  "String with mixed case",
+			where: WhereEquals("name", "MyUser"),
+			want:  "`name` = 'MyUser'",
+		},
 		{
 			name:  "Numeric",
 			where: WhereEquals("age", 3),
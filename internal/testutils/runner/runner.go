@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
 	"github.com/hashicorp/terraform-plugin-testing/statecheck"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
 
@@ -29,6 +30,19 @@ type TestCase struct {
 
 	CheckNotExistsFunc  func(ctx context.Context, dbopsClient dbops.Client, clusterName *string, attrs map[string]string) (bool, error)
 	CheckAttributesFunc func(ctx context.Context, dbopsClient dbops.Client, clusterName *string, attrs map[string]interface{}) error
+
+	// ImportStateVerify adds a step importing the resource and checking the imported state matches the created one.
+	ImportStateVerify bool
+	// ImportStateVerifyIgnore lists the attributes that can't be imported, such as write-only ones.
+	ImportStateVerifyIgnore []string
+
+	// UpdatedResource, when set, adds a step applying it after Resource and checking the resource is updated in place.
+	UpdatedResource string
+	// PreUpdateFunc, when set, is run before applying UpdatedResource, for example to change objects out of band.
+	PreUpdateFunc func(ctx context.Context, dbopsClient dbops.Client, clusterName *string) error
+	// UpdatedResourceAddress, when set, is the address of the resource UpdatedResource updates in place instead, e.g.
+	// a dependency. The resource at ResourceAddress is then expected to be left unchanged.
+	UpdatedResourceAddress string
 }
 
 func RunTests(t *testing.T, tests []TestCase) {
@@ -68,6 +82,73 @@ func RunTests(t *testing.T, tests []TestCase) {
 				t.Fatal(err)
 			}
 
+			steps := []resource.TestStep{
+				{
+					// Combine the provider definition and the resourcePtr definition.
+					Config: fmt.Sprintf("%s\n%s", providerCfg, tc.Resource),
+					ConfigStateChecks: []statecheck.StateCheck{
+						// Compare the state with the actual resource.
+						internalstatecheck.NewGetAttributes(tc.ResourceAddress, func(attrs map[string]interface{}) error {
+							return tc.CheckAttributesFunc(ctx, dbopsClient, tc.ClusterName, attrs)
+						}),
+					},
+				},
+			}
+
+			if tc.UpdatedResource != "" {
+				planChecks := []plancheck.PlanCheck{
+					plancheck.ExpectResourceAction(tc.ResourceAddress, plancheck.ResourceActionUpdate),
+				}
+				if tc.UpdatedResourceAddress != "" {
+					planChecks = []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(tc.UpdatedResourceAddress, plancheck.ResourceActionUpdate),
+						plancheck.ExpectResourceAction(tc.ResourceAddress, plancheck.ResourceActionNoop),
+					}
+				}
+
+				steps = append(steps, resource.TestStep{
+					PreConfig: func() {
+						if tc.PreUpdateFunc == nil {
+							return
+						}
+						if err := tc.PreUpdateFunc(ctx, dbopsClient, tc.ClusterName); err != nil {
+							t.Fatal(err)
+						}
+					},
+					Config: fmt.Sprintf("%s\n%s", providerCfg, tc.UpdatedResource),
+					ConfigPlanChecks: resource.ConfigPlanChecks{
+						PreApply: planChecks,
+					},
+					ConfigStateChecks: []statecheck.StateCheck{
+						internalstatecheck.NewGetAttributes(tc.ResourceAddress, func(attrs map[string]interface{}) error {
+							return tc.CheckAttributesFunc(ctx, dbopsClient, tc.ClusterName, attrs)
+						}),
+					},
+				})
+			}
+
+			if tc.ImportStateVerify {
+				steps = append(steps, resource.TestStep{
+					Config:                  fmt.Sprintf("%s\n%s", providerCfg, tc.Resource),
+					ResourceName:            tc.ResourceAddress,
+					ImportState:             true,
+					ImportStateVerify:       true,
+					ImportStateVerifyIgnore: tc.ImportStateVerifyIgnore,
+					ImportStateIdFunc: func(s *terraform.State) (string, error) {
+						r, ok := s.RootModule().Resources[tc.ResourceAddress]
+						if !ok {
+							return "", fmt.Errorf("root module has no resource %q", tc.ResourceAddress)
+						}
+
+						if tc.ClusterName != nil {
+							return fmt.Sprintf("%s:%s", *tc.ClusterName, r.Primary.ID), nil
+						}
+
+						return r.Primary.ID, nil
+					},
+				})
+			}
+
 			t.Run(tc.Name, func(t *testing.T) {
 				resource.Test(t, resource.TestCase{
 					ProtoV6ProviderFactories: factories.ProviderFactories(),
@@ -89,18 +170,7 @@ func RunTests(t *testing.T, tests []TestCase) {
 
 						return fmt.Errorf("root module has no resource %q", tc.ResourceAddress)
 					},
-					Steps: []resource.TestStep{
-						{
-							// Combine the provider definition and the resourcePtr definition.
-							Config: fmt.Sprintf("%s\n%s", providerCfg, tc.Resource),
-							ConfigStateChecks: []statecheck.StateCheck{
-								// Compare the state with the actual resource.
-								internalstatecheck.NewGetAttributes(tc.ResourceAddress, func(attrs map[string]interface{}) error {
-									return tc.CheckAttributesFunc(ctx, dbopsClient, tc.ClusterName, attrs)
-								}),
-							},
-						},
-					},
+					Steps: steps,
 				})
 			})
 		}()
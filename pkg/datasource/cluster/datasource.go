@@ -0,0 +1,121 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
+)
+
+var _ datasource.DataSource = &DataSource{}
+
+type DataSource struct {
+	client dbops.Client
+}
+
+func NewDataSource() datasource.DataSource { return &DataSource{} }
+
+func (d *DataSource) Metadata(_ context.Context, _ datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = "clickhousedbops_cluster"
+}
+
+func (d *DataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "The replicas of a cluster, as configured on the server the provider is connected to. Useful to decide whether to set cluster_name on the other resources.",
+		Attributes: map[string]schema.Attribute{
+			"cluster_name": schema.StringAttribute{
+				Required:    true,
+				Description: "Name of the cluster to describe.",
+			},
+			"replica_count": schema.Int64Attribute{
+				Computed:    true,
+				Description: "Number of replicas of the cluster, over all its shards.",
+			},
+			"replicas": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "The replicas of the cluster, ordered by shard and replica.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"shard_num": schema.Int64Attribute{
+							Computed:    true,
+							Description: "The number of the shard, starting from 1.",
+						},
+						"replica_num": schema.Int64Attribute{
+							Computed:    true,
+							Description: "The number of the replica in its shard, starting from 1.",
+						},
+						"host_name": schema.StringAttribute{
+							Computed:    true,
+							Description: "The host name of the replica, as configured.",
+						},
+						"is_local": schema.BoolAttribute{
+							Computed:    true,
+							Description: "Whether the replica is the server the provider is connected to.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *DataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	c, ok := req.ProviderData.(dbops.Client)
+	if !ok || c == nil {
+		resp.Diagnostics.AddError("Configuration Error", "Provider did not supply dbops client")
+		return
+	}
+	d.client = c
+}
+
+type dsModel struct {
+	ClusterName  types.String   `tfsdk:"cluster_name"`
+	ReplicaCount types.Int64    `tfsdk:"replica_count"`
+	Replicas     []replicaModel `tfsdk:"replicas"`
+}
+
+type replicaModel struct {
+	ShardNum   types.Int64  `tfsdk:"shard_num"`
+	ReplicaNum types.Int64  `tfsdk:"replica_num"`
+	HostName   types.String `tfsdk:"host_name"`
+	IsLocal    types.Bool   `tfsdk:"is_local"`
+}
+
+func (d *DataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data dsModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	replicas, err := d.client.GetClusterTopology(ctx, data.ClusterName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed", fmt.Sprintf("listing cluster replicas failed: %v", err))
+		return
+	}
+
+	if len(replicas) == 0 {
+		resp.Diagnostics.AddError("Cluster not found", fmt.Sprintf("cluster %q is not configured on the server", data.ClusterName.ValueString()))
+		return
+	}
+
+	data.ReplicaCount = types.Int64Value(int64(len(replicas)))
+	data.Replicas = make([]replicaModel, 0, len(replicas))
+	for _, r := range replicas {
+		data.Replicas = append(data.Replicas, replicaModel{
+			ShardNum:   types.Int64Value(int64(r.ShardNum)),
+			ReplicaNum: types.Int64Value(int64(r.ReplicaNum)),
+			HostName:   types.StringValue(r.HostName),
+			IsLocal:    types.BoolValue(r.IsLocal),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,138 @@
+package effectivegrants
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
+)
+
+var _ datasource.DataSource = &DataSource{}
+
+type DataSource struct {
+	client dbops.Client
+}
+
+func NewDataSource() datasource.DataSource { return &DataSource{} }
+
+func (d *DataSource) Metadata(_ context.Context, _ datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = "clickhousedbops_effective_grants"
+}
+
+func (d *DataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Privileges a user has, either granted directly or inherited through the roles granted to it. Partial revokes are not applied.",
+		Attributes: map[string]schema.Attribute{
+			"user_name": schema.StringAttribute{
+				Required:    true,
+				Description: "Name of the user to resolve the privileges of.",
+			},
+			"cluster_name": schema.StringAttribute{
+				Optional:    true,
+				Description: "Cluster name for lookups on replicated/localfile setups.",
+			},
+			"roles": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "Roles granted to the user, directly or through other roles.",
+			},
+			"grants": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Privileges granted to the user or to any of its roles, without duplicates.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"access_type": schema.StringAttribute{
+							Computed:    true,
+							Description: "The granted privilege.",
+						},
+						"database": schema.StringAttribute{
+							Computed:    true,
+							Description: "The database the privilege is granted on, null for all databases.",
+						},
+						"table": schema.StringAttribute{
+							Computed:    true,
+							Description: "The table the privilege is granted on, null for all tables.",
+						},
+						"column": schema.StringAttribute{
+							Computed:    true,
+							Description: "The column the privilege is granted on, null for all columns.",
+						},
+						"grant_option": schema.BoolAttribute{
+							Computed:    true,
+							Description: "Whether the privilege can be granted to others.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *DataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	c, ok := req.ProviderData.(dbops.Client)
+	if !ok || c == nil {
+		resp.Diagnostics.AddError("Configuration Error", "Provider did not supply dbops client")
+		return
+	}
+	d.client = c
+}
+
+type dsModel struct {
+	UserName    types.String `tfsdk:"user_name"`
+	ClusterName types.String `tfsdk:"cluster_name"`
+	Roles       []string     `tfsdk:"roles"`
+	Grants      []grantModel `tfsdk:"grants"`
+}
+
+type grantModel struct {
+	AccessType  types.String `tfsdk:"access_type"`
+	Database    types.String `tfsdk:"database"`
+	Table       types.String `tfsdk:"table"`
+	Column      types.String `tfsdk:"column"`
+	GrantOption types.Bool   `tfsdk:"grant_option"`
+}
+
+func (d *DataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data dsModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	name := data.UserName.ValueString()
+	if name == "" {
+		resp.Diagnostics.AddError("Invalid input", "user_name must not be empty")
+		return
+	}
+
+	effective, err := d.client.GetEffectiveGrants(ctx, name, data.ClusterName.ValueStringPointer())
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed", fmt.Sprintf("lookup of %q failed: %v", name, err))
+		return
+	}
+	if effective == nil {
+		resp.Diagnostics.AddError("Not found", fmt.Sprintf("user %q not found", name))
+		return
+	}
+
+	data.Roles = effective.Roles
+	data.Grants = make([]grantModel, 0, len(effective.Grants))
+	for _, g := range effective.Grants {
+		data.Grants = append(data.Grants, grantModel{
+			AccessType:  types.StringValue(g.AccessType),
+			Database:    types.StringPointerValue(g.DatabaseName),
+			Table:       types.StringPointerValue(g.TableName),
+			Column:      types.StringPointerValue(g.ColumnName),
+			GrantOption: types.BoolValue(g.GrantOption),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,103 @@
+package roles
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
+)
+
+var _ datasource.DataSource = &DataSource{}
+
+type DataSource struct {
+	client dbops.Client
+}
+
+func NewDataSource() datasource.DataSource { return &DataSource{} }
+
+func (d *DataSource) Metadata(_ context.Context, _ datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = "clickhousedbops_roles"
+}
+
+func (d *DataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "All the roles of the ClickHouse instance, ordered by name.",
+		Attributes: map[string]schema.Attribute{
+			"cluster_name": schema.StringAttribute{
+				Optional:    true,
+				Description: "Cluster name for lookups on replicated/localfile setups.",
+			},
+			"name_prefix": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only return the roles whose name starts with this prefix. It is matched literally: % and _ are not wildcards.",
+			},
+			"roles": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "The roles.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Computed:    true,
+							Description: "The system-assigned ID of the role.",
+						},
+						"name": schema.StringAttribute{
+							Computed:    true,
+							Description: "The name of the role.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *DataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	c, ok := req.ProviderData.(dbops.Client)
+	if !ok || c == nil {
+		resp.Diagnostics.AddError("Configuration Error", "Provider did not supply dbops client")
+		return
+	}
+	d.client = c
+}
+
+type dsModel struct {
+	ClusterName types.String `tfsdk:"cluster_name"`
+	NamePrefix  types.String `tfsdk:"name_prefix"`
+	Roles       []roleModel  `tfsdk:"roles"`
+}
+
+type roleModel struct {
+	ID   types.String `tfsdk:"id"`
+	Name types.String `tfsdk:"name"`
+}
+
+func (d *DataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data dsModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	roles, err := d.client.ListRoles(ctx, data.NamePrefix.ValueString(), data.ClusterName.ValueStringPointer())
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed", fmt.Sprintf("listing roles failed: %v", err))
+		return
+	}
+
+	data.Roles = make([]roleModel, 0, len(roles))
+	for _, r := range roles {
+		data.Roles = append(data.Roles, roleModel{
+			ID:   types.StringValue(r.ID),
+			Name: types.StringValue(r.Name),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
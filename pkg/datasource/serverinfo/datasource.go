@@ -0,0 +1,137 @@
+package serverinfo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
+)
+
+var _ datasource.DataSource = &DataSource{}
+
+type DataSource struct {
+	client dbops.Client
+}
+
+func NewDataSource() datasource.DataSource { return &DataSource{} }
+
+func (d *DataSource) Metadata(_ context.Context, _ datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = "clickhousedbops_server_info"
+}
+
+func (d *DataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Information about the ClickHouse server the provider is connected to, useful to adapt the configuration to the server version and to report bugs.",
+		Attributes: map[string]schema.Attribute{
+			"cluster_name": schema.StringAttribute{
+				Optional:    true,
+				Description: "Cluster name for lookups on replicated/localfile setups. Only used to list the user directories of all the replicas.",
+			},
+			"version": schema.StringAttribute{
+				Computed:    true,
+				Description: "Full version of the server, such as 25.3.2.39.",
+			},
+			"uptime": schema.Int64Attribute{
+				Computed:    true,
+				Description: "Number of seconds the server has been running for.",
+			},
+			"access_storage_types": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "Types of the storages for users, roles and the other access entities, such as users_xml, local_directory or replicated, in the order they are searched in.",
+			},
+			"user_directories": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "The storages for users, roles and the other access entities, in the order they are searched in.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Computed:    true,
+							Description: "The name of the user directory.",
+						},
+						"type": schema.StringAttribute{
+							Computed:    true,
+							Description: "The type of storage.",
+						},
+						"precedence": schema.Int64Attribute{
+							Computed:    true,
+							Description: "The order the directory is searched in, the lowest first.",
+						},
+						"read_only": schema.BoolAttribute{
+							Computed:    true,
+							Description: "Whether the entities in the directory can't be changed with SQL queries, e.g. users_xml.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *DataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	c, ok := req.ProviderData.(dbops.Client)
+	if !ok || c == nil {
+		resp.Diagnostics.AddError("Configuration Error", "Provider did not supply dbops client")
+		return
+	}
+	d.client = c
+}
+
+type dsModel struct {
+	ClusterName        types.String         `tfsdk:"cluster_name"`
+	Version            types.String         `tfsdk:"version"`
+	Uptime             types.Int64          `tfsdk:"uptime"`
+	AccessStorageTypes []types.String       `tfsdk:"access_storage_types"`
+	UserDirectories    []userDirectoryModel `tfsdk:"user_directories"`
+}
+
+type userDirectoryModel struct {
+	Name       types.String `tfsdk:"name"`
+	Type       types.String `tfsdk:"type"`
+	Precedence types.Int64  `tfsdk:"precedence"`
+	ReadOnly   types.Bool   `tfsdk:"read_only"`
+}
+
+func (d *DataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data dsModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	version, err := d.client.ServerVersion(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed", fmt.Sprintf("getting server version failed: %v", err))
+		return
+	}
+
+	directories, err := d.client.UserDirectories(ctx, data.ClusterName.ValueStringPointer())
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed", fmt.Sprintf("listing user directories failed: %v", err))
+		return
+	}
+
+	data.Version = types.StringValue(version.Version)
+	data.Uptime = types.Int64Value(int64(version.Uptime.Seconds()))
+
+	data.AccessStorageTypes = make([]types.String, 0, len(directories))
+	data.UserDirectories = make([]userDirectoryModel, 0, len(directories))
+	for _, ud := range directories {
+		data.AccessStorageTypes = append(data.AccessStorageTypes, types.StringValue(ud.Type))
+		data.UserDirectories = append(data.UserDirectories, userDirectoryModel{
+			Name:       types.StringValue(ud.Name),
+			Type:       types.StringValue(ud.Type),
+			Precedence: types.Int64Value(int64(ud.Precedence)),
+			ReadOnly:   types.BoolValue(ud.ReadOnly),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
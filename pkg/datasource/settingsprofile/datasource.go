@@ -37,7 +37,17 @@ func (d *DataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp
 			},
 			"cluster_name": schema.StringAttribute{
 				Optional:    true,
-				Description: "Cluster name for lookups on replicated/localfile setups.",
+				Description: "Cluster name for lookups on replicated/localfile setups. The settings profile is looked up on all the replicas of the cluster, and found if any of them has it.",
+			},
+			"associated_users": schema.SetAttribute{
+				ElementType: types.StringType,
+				Computed:    true,
+				Description: "Names of the users the settings profile is associated to.",
+			},
+			"associated_roles": schema.SetAttribute{
+				ElementType: types.StringType,
+				Computed:    true,
+				Description: "Names of the roles the settings profile is associated to.",
 			},
 		},
 	}
@@ -59,6 +69,8 @@ type dsModel struct {
 	Name        types.String `tfsdk:"name"`
 	ClusterName types.String `tfsdk:"cluster_name"`
 	ID          types.String `tfsdk:"id"`
+	Users       types.Set    `tfsdk:"associated_users"`
+	Roles       types.Set    `tfsdk:"associated_roles"`
 }
 
 func (d *DataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
@@ -85,6 +97,23 @@ func (d *DataSource) Read(ctx context.Context, req datasource.ReadRequest, resp
 	}
 
 	data.ID = types.StringValue(sp.ID)
+
+	associations, err := d.client.ListSettingsProfileAssociations(ctx, sp.Name, valueOrNil(data.ClusterName))
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed", fmt.Sprintf("listing the associations of %q failed: %v", name, err))
+		return
+	}
+
+	users, diags := types.SetValueFrom(ctx, types.StringType, associations.Users)
+	resp.Diagnostics.Append(diags...)
+	roles, diags := types.SetValueFrom(ctx, types.StringType, associations.Roles)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Users = users
+	data.Roles = roles
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -0,0 +1,142 @@
+package user
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
+)
+
+var _ datasource.DataSource = &DataSource{}
+
+type DataSource struct {
+	client dbops.Client
+}
+
+func NewDataSource() datasource.DataSource { return &DataSource{} }
+
+func (d *DataSource) Metadata(_ context.Context, _ datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = "clickhousedbops_user"
+}
+
+func (d *DataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "An existing user, e.g. one managed outside of Terraform, looked up by name or UUID.",
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Name of the user to look up. Exactly one of name and id is required.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+					stringvalidator.ExactlyOneOf(path.MatchRoot("id")),
+				},
+			},
+			"id": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "UUID of the user to look up. Exactly one of name and id is required.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"cluster_name": schema.StringAttribute{
+				Optional:    true,
+				Description: "Cluster name for lookups on replicated/localfile setups.",
+			},
+			"settings_profiles": schema.SetAttribute{
+				ElementType: types.StringType,
+				Computed:    true,
+				Description: "Names of the settings profiles associated to the user directly.",
+			},
+			"default_roles": schema.SetAttribute{
+				ElementType: types.StringType,
+				Computed:    true,
+				Description: "Names of the default roles of the user. Null when they couldn't be read.",
+			},
+		},
+	}
+}
+
+func (d *DataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	c, ok := req.ProviderData.(dbops.Client)
+	if !ok || c == nil {
+		resp.Diagnostics.AddError("Configuration Error", "Provider did not supply dbops client")
+		return
+	}
+	d.client = c
+}
+
+type dsModel struct {
+	Name             types.String `tfsdk:"name"`
+	ID               types.String `tfsdk:"id"`
+	ClusterName      types.String `tfsdk:"cluster_name"`
+	SettingsProfiles types.Set    `tfsdk:"settings_profiles"`
+	DefaultRoles     types.Set    `tfsdk:"default_roles"`
+}
+
+func (d *DataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data dsModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var (
+		user *dbops.User
+		ref  string
+		err  error
+	)
+	if !data.ID.IsNull() {
+		ref = data.ID.ValueString()
+		user, err = d.client.GetUserByUUID(ctx, ref, data.ClusterName.ValueStringPointer())
+	} else {
+		ref = data.Name.ValueString()
+		user, err = d.client.GetUserByName(ctx, ref, data.ClusterName.ValueStringPointer())
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed", fmt.Sprintf("lookup of %q failed: %v", ref, err))
+		return
+	}
+	if user == nil {
+		resp.Diagnostics.AddError("Not found", fmt.Sprintf("user %q not found", ref))
+		return
+	}
+
+	data.Name = types.StringValue(user.Name)
+	data.ID = types.StringValue(user.ID)
+
+	profiles, diags := types.SetValueFrom(ctx, types.StringType, nonNil(user.SettingsProfiles))
+	resp.Diagnostics.Append(diags...)
+	data.SettingsProfiles = profiles
+
+	data.DefaultRoles = types.SetNull(types.StringType)
+	if user.DefaultRoles != nil {
+		roles, diags := types.SetValueFrom(ctx, types.StringType, user.DefaultRoles)
+		resp.Diagnostics.Append(diags...)
+		data.DefaultRoles = roles
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// nonNil returns an empty list instead of nil, so that it's converted to an empty set rather than a null one.
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
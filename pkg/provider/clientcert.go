@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+)
+
+const pemPrefix = "-----BEGIN"
+
+// clientCertAuth returns the client certificate authentication configured in auth_config.
+func clientCertAuth(data Model) (*clickhouseclient.ClientCertAuth, error) {
+	if data.AuthConfig.ClientCertificate.IsNull() || data.AuthConfig.ClientPrivateKey.IsNull() {
+		return nil, fmt.Errorf("invalid configuration: the %q authentication strategy requires both client_certificate and client_private_key to be set", authStrategyClientCert)
+	}
+
+	if !data.AuthConfig.Password.IsNull() {
+		return nil, fmt.Errorf("invalid configuration: password can't be set with the %q authentication strategy", authStrategyClientCert)
+	}
+
+	certPEM, err := readPEM(data.AuthConfig.ClientCertificate.ValueString())
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: cannot read client_certificate: %w", err)
+	}
+
+	keyPEM, err := readPEM(data.AuthConfig.ClientPrivateKey.ValueString())
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: cannot read client_private_key: %w", err)
+	}
+
+	certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: client_certificate and client_private_key are not a valid key pair: %w", err)
+	}
+
+	auth := &clickhouseclient.ClientCertAuth{
+		Username:    data.AuthConfig.Username.ValueString(),
+		Certificate: certificate,
+	}
+
+	valid, errorStrings := auth.ValidateConfig()
+	if !valid {
+		return nil, fmt.Errorf("invalid configuration: invalid authentication strategy configuration. %s", strings.Join(errorStrings, ", "))
+	}
+
+	return auth, nil
+}
+
+// readPEM returns value if it is PEM encoded, or the content of the file at path value otherwise.
+func readPEM(value string) ([]byte, error) {
+	if strings.HasPrefix(strings.TrimSpace(value), pemPrefix) {
+		return []byte(value), nil
+	}
+
+	return os.ReadFile(value)
+}
@@ -0,0 +1,224 @@
+package provider
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// testClientCertificate returns a self signed certificate and its private key, PEM encoded.
+func testClientCertificate(t *testing.T, commonName string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey() error = %v", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	return string(certPEM), string(keyPEM)
+}
+
+func Test_newNativeClientConfig_clientCert(t *testing.T) {
+	certPEM, keyPEM := testClientCertificate(t, "john")
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	if err := os.WriteFile(certFile, []byte(certPEM), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.WriteFile(keyFile, []byte(keyPEM), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		protocol    string
+		certificate types.String
+		privateKey  types.String
+		password    types.String
+		wantErr     bool
+	}{
+		{
+			name:        "PEM strings",
+			protocol:    protocolNativeSecure,
+			certificate: types.StringValue(certPEM),
+			privateKey:  types.StringValue(keyPEM),
+			password:    types.StringNull(),
+		},
+		{
+			name:        "File paths",
+			protocol:    protocolNativeSecure,
+			certificate: types.StringValue(certFile),
+			privateKey:  types.StringValue(keyFile),
+			password:    types.StringNull(),
+		},
+		{
+			name:        "Missing private key",
+			protocol:    protocolNativeSecure,
+			certificate: types.StringValue(certPEM),
+			privateKey:  types.StringNull(),
+			password:    types.StringNull(),
+			wantErr:     true,
+		},
+		{
+			name:        "Missing file",
+			protocol:    protocolNativeSecure,
+			certificate: types.StringValue(filepath.Join(dir, "missing.crt")),
+			privateKey:  types.StringValue(keyFile),
+			password:    types.StringNull(),
+			wantErr:     true,
+		},
+		{
+			name:        "Mismatched key",
+			protocol:    protocolNativeSecure,
+			certificate: types.StringValue(certPEM),
+			privateKey:  types.StringValue(certPEM),
+			password:    types.StringNull(),
+			wantErr:     true,
+		},
+		{
+			name:        "Password set",
+			protocol:    protocolNativeSecure,
+			certificate: types.StringValue(certPEM),
+			privateKey:  types.StringValue(keyPEM),
+			password:    types.StringValue("secret"),
+			wantErr:     true,
+		},
+		{
+			name:        "Without TLS",
+			protocol:    protocolNative,
+			certificate: types.StringValue(certPEM),
+			privateKey:  types.StringValue(keyPEM),
+			password:    types.StringNull(),
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := newNativeClientConfig(Model{
+				Protocol: types.StringValue(tt.protocol),
+				Host:     types.StringValue("localhost"),
+				Port:     types.Int32Value(9440),
+				AuthConfig: AuthConfig{
+					Strategy:          types.StringValue(authStrategyClientCert),
+					Username:          types.StringValue("john"),
+					Password:          tt.password,
+					ClientCertificate: tt.certificate,
+					ClientPrivateKey:  tt.privateKey,
+				},
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newNativeClientConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if config.UserPasswordAuth != nil {
+				t.Errorf("newNativeClientConfig() UserPasswordAuth = %+v, want nil", config.UserPasswordAuth)
+			}
+			if config.ClientCertAuth == nil || config.ClientCertAuth.Username != "john" || len(config.ClientCertAuth.Certificate.Certificate) == 0 {
+				t.Errorf("newNativeClientConfig() ClientCertAuth = %+v, want certificate for john", config.ClientCertAuth)
+			}
+		})
+	}
+}
+
+func Test_newClickhouseClient_httpsClientCert(t *testing.T) {
+	certPEM, keyPEM := testClientCertificate(t, "john")
+
+	var gotUser, gotCertAuth, gotCN string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser = r.Header.Get("X-ClickHouse-User")
+		gotCertAuth = r.Header.Get("X-ClickHouse-SSL-Certificate-Auth")
+		if len(r.TLS.PeerCertificates) > 0 {
+			gotCN = r.TLS.PeerCertificates[0].Subject.CommonName
+		}
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+	port, err := strconv.Atoi(serverURL.Port())
+	if err != nil {
+		t.Fatalf("strconv.Atoi() error = %v", err)
+	}
+
+	data := Model{
+		Protocol: types.StringValue(protocolHTTPS),
+		Host:     types.StringValue(serverURL.Hostname()),
+		Port:     types.Int32Value(int32(port)),
+		AuthConfig: AuthConfig{
+			Strategy:          types.StringValue(authStrategyClientCert),
+			Username:          types.StringValue("john"),
+			Password:          types.StringNull(),
+			ClientCertificate: types.StringValue(certPEM),
+			ClientPrivateKey:  types.StringValue(keyPEM),
+		},
+		TLSConfig: &TLSConfig{
+			InsecureSkipVerify: types.BoolValue(true),
+		},
+	}
+
+	client, err := (&Provider{}).newClickhouseClient(data)
+	if err != nil {
+		t.Fatalf("newClickhouseClient() error = %v", err)
+	}
+
+	err = client.Exec(context.Background(), "SELECT 1")
+	if err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+
+	if gotUser != "john" || gotCertAuth != "on" {
+		t.Errorf("headers got user = %q, certificate auth = %q, want %q and %q", gotUser, gotCertAuth, "john", "on")
+	}
+	if gotCN != "john" {
+		t.Errorf("client certificate common name got = %q, want %q", gotCN, "john")
+	}
+
+	data.Protocol = types.StringValue(protocolHTTP)
+	if _, err := (&Provider{}).newClickhouseClient(data); err == nil {
+		t.Errorf("newClickhouseClient() expected error for client certificate over http")
+	}
+}
@@ -0,0 +1,26 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
+)
+
+// jwtAuth returns the JWT authentication configured in auth_config.
+func jwtAuth(data Model) (*clickhouseclient.JWTAuth, error) {
+	if !data.AuthConfig.Password.IsNull() || !data.AuthConfig.ClientCertificate.IsNull() {
+		return nil, fmt.Errorf("invalid configuration: password and client_certificate can't be set with the %q authentication strategy", authStrategyJWT)
+	}
+
+	auth := &clickhouseclient.JWTAuth{
+		AccessToken: data.AuthConfig.AccessToken.ValueString(),
+	}
+
+	valid, errorStrings := auth.ValidateConfig()
+	if !valid {
+		return nil, fmt.Errorf("invalid configuration: the %q authentication strategy requires access_token to be set. %s", authStrategyJWT, strings.Join(errorStrings, ", "))
+	}
+
+	return auth, nil
+}
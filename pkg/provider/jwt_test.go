@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func Test_newClickhouseClient_jwt(t *testing.T) {
+	var gotAuthorization string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuthorization = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+	port, err := strconv.Atoi(serverURL.Port())
+	if err != nil {
+		t.Fatalf("strconv.Atoi() error = %v", err)
+	}
+
+	data := Model{
+		Protocol: types.StringValue(protocolHTTPS),
+		Host:     types.StringValue(serverURL.Hostname()),
+		Port:     types.Int32Value(int32(port)),
+		AuthConfig: AuthConfig{
+			Strategy:          types.StringValue(authStrategyJWT),
+			Username:          types.StringNull(),
+			Password:          types.StringNull(),
+			ClientCertificate: types.StringNull(),
+			AccessToken:       types.StringValue("token"),
+		},
+		TLSConfig: &TLSConfig{
+			InsecureSkipVerify: types.BoolValue(true),
+		},
+	}
+
+	client, err := (&Provider{}).newClickhouseClient(data)
+	if err != nil {
+		t.Fatalf("newClickhouseClient() error = %v", err)
+	}
+
+	err = client.Exec(context.Background(), "SELECT 1")
+	if err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+
+	if gotAuthorization != "Bearer token" {
+		t.Errorf("Authorization header got = %q, want %q", gotAuthorization, "Bearer token")
+	}
+}
+
+func Test_newClickhouseClient_jwtErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		protocol    string
+		accessToken types.String
+		password    types.String
+	}{
+		{
+			name:        "Over http",
+			protocol:    protocolHTTP,
+			accessToken: types.StringValue("token"),
+			password:    types.StringNull(),
+		},
+		{
+			name:        "Over native",
+			protocol:    protocolNative,
+			accessToken: types.StringValue("token"),
+			password:    types.StringNull(),
+		},
+		{
+			name:        "Over nativesecure",
+			protocol:    protocolNativeSecure,
+			accessToken: types.StringValue("token"),
+			password:    types.StringNull(),
+		},
+		{
+			name:        "Missing access token",
+			protocol:    protocolHTTPS,
+			accessToken: types.StringNull(),
+			password:    types.StringNull(),
+		},
+		{
+			name:        "Password set",
+			protocol:    protocolHTTPS,
+			accessToken: types.StringValue("token"),
+			password:    types.StringValue("secret"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&Provider{}).newClickhouseClient(Model{
+				Protocol: types.StringValue(tt.protocol),
+				Host:     types.StringValue("localhost"),
+				AuthConfig: AuthConfig{
+					Strategy:          types.StringValue(authStrategyJWT),
+					Username:          types.StringNull(),
+					Password:          tt.password,
+					ClientCertificate: types.StringNull(),
+					AccessToken:       tt.accessToken,
+				},
+			})
+			if err == nil {
+				t.Errorf("newClickhouseClient() expected error")
+			}
+		})
+	}
+}
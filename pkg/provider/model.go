@@ -6,19 +6,54 @@ import (
 
 // Model describes the provider data model.
 type Model struct {
-	Protocol   types.String `tfsdk:"protocol"`
-	Host       types.String `tfsdk:"host"`
-	Port       types.Int32  `tfsdk:"port"`
-	AuthConfig AuthConfig   `tfsdk:"auth_config"`
-	TLSConfig  *TLSConfig   `tfsdk:"tls_config"`
+	Protocol                 types.String  `tfsdk:"protocol"`
+	Host                     types.String  `tfsdk:"host"`
+	Port                     types.Int32   `tfsdk:"port"`
+	AuthConfig               AuthConfig    `tfsdk:"auth_config"`
+	TLSConfig                *TLSConfig    `tfsdk:"tls_config"`
+	AllowRename              types.Bool    `tfsdk:"allow_rename"`
+	ReservedSettingsProfiles types.List    `tfsdk:"reserved_settings_profiles"`
+	NamePrefix               types.String  `tfsdk:"name_prefix"`
+	DefaultCluster           types.String  `tfsdk:"default_cluster"`
+	ValidateSQL              types.Bool    `tfsdk:"validate_sql"`
+	ClusterReads             types.String  `tfsdk:"cluster_reads"`
+	UserAgent                types.String  `tfsdk:"user_agent"`
+	HTTPHeaders              types.Map     `tfsdk:"http_headers"`
+	MaxRetries               types.Int32   `tfsdk:"max_retries"`
+	RetryMinDelay            types.String  `tfsdk:"retry_min_delay"`
+	QueryTimeout             types.String  `tfsdk:"query_timeout"`
+	MaxOpenConns             types.Int32   `tfsdk:"max_open_conns"`
+	MaxIdleConns             types.Int32   `tfsdk:"max_idle_conns"`
+	ConnMaxLifetime          types.String  `tfsdk:"conn_max_lifetime"`
+	Database                 types.String  `tfsdk:"database"`
+	Settings                 types.Map     `tfsdk:"settings"`
+	PreStatements            types.List    `tfsdk:"pre_statements"`
+	Compression              types.String  `tfsdk:"compression"`
+	NativeConfig             *NativeConfig `tfsdk:"native_config"`
+	HTTPConfig               *HTTPConfig   `tfsdk:"http_config"`
 }
 
 type AuthConfig struct {
-	Strategy types.String `tfsdk:"strategy"`
-	Username types.String `tfsdk:"username"`
-	Password types.String `tfsdk:"password"`
+	Strategy          types.String `tfsdk:"strategy"`
+	Username          types.String `tfsdk:"username"`
+	Password          types.String `tfsdk:"password"`
+	ClientCertificate types.String `tfsdk:"client_certificate"`
+	ClientPrivateKey  types.String `tfsdk:"client_private_key"`
+	AccessToken       types.String `tfsdk:"access_token"`
 }
 
 type TLSConfig struct {
-	InsecureSkipVerify types.Bool `tfsdk:"insecure_skip_verify"`
+	InsecureSkipVerify types.Bool   `tfsdk:"insecure_skip_verify"`
+	CACert             types.String `tfsdk:"tls_ca_cert"`
+}
+
+type NativeConfig struct {
+	BlockBufferSize types.Int32 `tfsdk:"block_buffer_size"`
+}
+
+type HTTPConfig struct {
+	ReadAfterCreateRetries types.Int32  `tfsdk:"read_after_create_retries"`
+	ProxyURL               types.String `tfsdk:"proxy_url"`
+	ProxyUsername          types.String `tfsdk:"proxy_username"`
+	ProxyPassword          types.String `tfsdk:"proxy_password"`
 }
@@ -0,0 +1,44 @@
+package provider
+
+import (
+	"fmt"
+)
+
+// defaultPorts are the ports ClickHouse listens to by default for each protocol.
+var defaultPorts = map[string]uint16{
+	protocolNative:       9000,
+	protocolNativeSecure: 9440,
+	protocolHTTP:         8123,
+	protocolHTTPS:        8443,
+}
+
+// resolvePort returns the configured port, or the default port of the configured protocol when it is not set.
+func resolvePort(data Model) (uint16, error) {
+	if data.Port.IsNull() || data.Port.IsUnknown() {
+		return defaultPorts[data.Protocol.ValueString()], nil
+	}
+
+	portVal := data.Port.ValueInt32()
+	if portVal <= 0 || portVal > 65535 {
+		return 0, fmt.Errorf("invalid configuration: invalid port %s", data.Port.String())
+	}
+
+	return uint16(portVal), nil
+}
+
+// portWarning returns a warning if the configured port is the default port of another protocol,
+// e.g. 9000 with https, which is most likely a misconfiguration. The configured port is used anyway.
+func portWarning(data Model) string {
+	if data.Port.IsNull() || data.Port.IsUnknown() {
+		return ""
+	}
+
+	protocol := data.Protocol.ValueString()
+	for _, other := range availableProtocols {
+		if other != protocol && int32(defaultPorts[other]) == data.Port.ValueInt32() {
+			return fmt.Sprintf("Port %d is the default port of the %s protocol, but the %s protocol is configured, whose default port is %d. Please double check the port and protocol attributes.", defaultPorts[other], other, protocol, defaultPorts[protocol])
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,131 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func Test_resolvePort(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol string
+		port     types.Int32
+		want     uint16
+		wantErr  bool
+	}{
+		{
+			name:     "Native default",
+			protocol: protocolNative,
+			port:     types.Int32Null(),
+			want:     9000,
+		},
+		{
+			name:     "Native secure default",
+			protocol: protocolNativeSecure,
+			port:     types.Int32Null(),
+			want:     9440,
+		},
+		{
+			name:     "HTTP default",
+			protocol: protocolHTTP,
+			port:     types.Int32Null(),
+			want:     8123,
+		},
+		{
+			name:     "HTTPS default",
+			protocol: protocolHTTPS,
+			port:     types.Int32Null(),
+			want:     8443,
+		},
+		{
+			name:     "Explicit port is kept",
+			protocol: protocolHTTPS,
+			port:     types.Int32Value(443),
+			want:     443,
+		},
+		{
+			name:     "Explicit port of another protocol is kept",
+			protocol: protocolHTTPS,
+			port:     types.Int32Value(9000),
+			want:     9000,
+		},
+		{
+			name:     "Zero port",
+			protocol: protocolNative,
+			port:     types.Int32Value(0),
+			wantErr:  true,
+		},
+		{
+			name:     "Port out of range",
+			protocol: protocolNative,
+			port:     types.Int32Value(70000),
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolvePort(Model{
+				Protocol: types.StringValue(tt.protocol),
+				Port:     tt.port,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolvePort() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolvePort() got = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_portWarning(t *testing.T) {
+	tests := []struct {
+		name        string
+		protocol    string
+		port        types.Int32
+		wantWarning bool
+	}{
+		{
+			name:        "Native port with https",
+			protocol:    protocolHTTPS,
+			port:        types.Int32Value(9000),
+			wantWarning: true,
+		},
+		{
+			name:        "HTTP port with native",
+			protocol:    protocolNative,
+			port:        types.Int32Value(8123),
+			wantWarning: true,
+		},
+		{
+			name:        "Matching default port",
+			protocol:    protocolHTTPS,
+			port:        types.Int32Value(8443),
+			wantWarning: false,
+		},
+		{
+			name:        "Custom port",
+			protocol:    protocolHTTPS,
+			port:        types.Int32Value(443),
+			wantWarning: false,
+		},
+		{
+			name:        "Port not set",
+			protocol:    protocolHTTPS,
+			port:        types.Int32Null(),
+			wantWarning: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := portWarning(Model{
+				Protocol: types.StringValue(tt.protocol),
+				Port:     tt.port,
+			})
+			if (got != "") != tt.wantWarning {
+				t.Errorf("portWarning() = %q, wantWarning %v", got, tt.wantWarning)
+			}
+		})
+	}
+}
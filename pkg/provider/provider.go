@@ -9,26 +9,39 @@ import (
 	"strings"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int32validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	tfresource "github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 
 	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/clickhouseclient"
 	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/pkg/datasource/cluster"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/pkg/datasource/effectivegrants"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/pkg/datasource/roles"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/pkg/datasource/serverinfo"
 	settingsprofileds "github.com/ClickHouse/terraform-provider-clickhousedbops/pkg/datasource/settingsprofile"
+	userds "github.com/ClickHouse/terraform-provider-clickhousedbops/pkg/datasource/user"
 	"github.com/ClickHouse/terraform-provider-clickhousedbops/pkg/project"
 	"github.com/ClickHouse/terraform-provider-clickhousedbops/pkg/resource/database"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/pkg/resource/defaultsettingsprofile"
 	"github.com/ClickHouse/terraform-provider-clickhousedbops/pkg/resource/grantprivilege"
 	"github.com/ClickHouse/terraform-provider-clickhousedbops/pkg/resource/grantrole"
 	"github.com/ClickHouse/terraform-provider-clickhousedbops/pkg/resource/role"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/pkg/resource/rowpolicy"
 	"github.com/ClickHouse/terraform-provider-clickhousedbops/pkg/resource/setting"
 	"github.com/ClickHouse/terraform-provider-clickhousedbops/pkg/resource/settingsprofile"
 	"github.com/ClickHouse/terraform-provider-clickhousedbops/pkg/resource/settingsprofileassociation"
 	"github.com/ClickHouse/terraform-provider-clickhousedbops/pkg/resource/user"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/pkg/resource/usergrantees"
 )
 
 const (
@@ -37,8 +50,28 @@ const (
 	protocolHTTP         = "http"
 	protocolHTTPS        = "https"
 
-	authStrategyPassword  = "password"
-	authStrategyBasicAuth = "basicauth"
+	authStrategyPassword   = "password"
+	authStrategyBasicAuth  = "basicauth"
+	authStrategyClientCert = "clientcert"
+	authStrategyJWT        = "jwt"
+
+	compressionNone  = "none"
+	compressionGzip  = "gzip"
+	compressionLZ4   = "lz4"
+	compressionLZ4HC = "lz4hc"
+	compressionZSTD  = "zstd"
+
+	unixSocketPrefix = "unix://"
+
+	defaultReadAfterCreateRetries = 3
+	readAfterCreateBackoff        = 500 * time.Millisecond
+
+	defaultMaxRetries    = 3
+	defaultRetryMinDelay = time.Second
+
+	// defaultQueryTimeout is above distributed_ddl_task_timeout (180s by default), which ClickHouse waits for before
+	// failing an ON CLUSTER statement, so slow cluster DDL is not cancelled while still running on the server.
+	defaultQueryTimeout = 5 * time.Minute
 
 	defaultInitAttempts = 4
 	defaultInitBackoff  = 2 * time.Second
@@ -47,7 +80,8 @@ const (
 
 var (
 	availableProtocols      = []string{protocolNative, protocolNativeSecure, protocolHTTP, protocolHTTPS}
-	availableAuthStrategies = []string{authStrategyPassword, authStrategyBasicAuth}
+	availableAuthStrategies = []string{authStrategyPassword, authStrategyBasicAuth, authStrategyClientCert, authStrategyJWT}
+	availableCompressions   = []string{compressionNone, compressionGzip, compressionLZ4, compressionLZ4HC, compressionZSTD}
 )
 
 // Ensure Provider satisfies various provider interfaces.
@@ -73,11 +107,11 @@ func (p *Provider) Schema(ctx context.Context, req provider.SchemaRequest, resp
 			},
 			"host": schema.StringAttribute{
 				Required:    true,
-				Description: "The hostname to use to connect to the clickhouse instance",
+				Description: "The hostname to use to connect to the clickhouse instance. With the native protocol, it can also be the path of a unix socket in the form unix:///path/to/socket",
 			},
 			"port": schema.Int32Attribute{
-				Required:    true,
-				Description: "The port to use to connect to the clickhouse instance",
+				Optional:    true,
+				Description: "The port to use to connect to the clickhouse instance. Defaults to 9000 for native, 9440 for nativesecure, 8123 for http and 8443 for https. Ignored when connecting through a unix socket",
 			},
 			"auth_config": schema.SingleNestedAttribute{
 				Attributes: map[string]schema.Attribute{
@@ -89,8 +123,8 @@ func (p *Provider) Schema(ctx context.Context, req provider.SchemaRequest, resp
 						},
 					},
 					"username": schema.StringAttribute{
-						Required:    true,
-						Description: "The username to use to authenticate to ClickHouse",
+						Optional:    true,
+						Description: fmt.Sprintf("The username to use to authenticate to ClickHouse. Required by all the strategies but %q", authStrategyJWT),
 						Validators: []validator.String{
 							stringvalidator.LengthAtLeast(1),
 						},
@@ -102,6 +136,31 @@ func (p *Provider) Schema(ctx context.Context, req provider.SchemaRequest, resp
 							stringvalidator.LengthAtLeast(1),
 						},
 					},
+					"client_certificate": schema.StringAttribute{
+						Optional:    true,
+						Description: fmt.Sprintf("PEM encoded client certificate, or path of a file containing it, to authenticate to ClickHouse with the %q strategy. The user must be identified with the common name of the certificate.", authStrategyClientCert),
+						Validators: []validator.String{
+							stringvalidator.LengthAtLeast(1),
+							stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("client_private_key")),
+						},
+					},
+					"client_private_key": schema.StringAttribute{
+						Optional:    true,
+						Sensitive:   true,
+						Description: fmt.Sprintf("PEM encoded private key of client_certificate, or path of a file containing it, to authenticate to ClickHouse with the %q strategy.", authStrategyClientCert),
+						Validators: []validator.String{
+							stringvalidator.LengthAtLeast(1),
+							stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("client_certificate")),
+						},
+					},
+					"access_token": schema.StringAttribute{
+						Optional:    true,
+						Sensitive:   true,
+						Description: fmt.Sprintf("JSON Web Token sent as a bearer token to authenticate to ClickHouse Cloud with the %q strategy. Only supported with the %s protocol.", authStrategyJWT, protocolHTTPS),
+						Validators: []validator.String{
+							stringvalidator.LengthAtLeast(1),
+						},
+					},
 				},
 				Required:    true,
 				Description: "Authentication configuration",
@@ -110,12 +169,184 @@ func (p *Provider) Schema(ctx context.Context, req provider.SchemaRequest, resp
 				Attributes: map[string]schema.Attribute{
 					"insecure_skip_verify": schema.BoolAttribute{
 						Optional:    true,
-						Description: "Skip TLS cert verification when using the https protocol. This is insecure!",
+						Description: "Skip TLS cert verification when using the https or nativesecure protocol. This is insecure!",
+					},
+					"tls_ca_cert": schema.StringAttribute{
+						Optional:    true,
+						Description: "PEM encoded CA certificates, or the path of a file containing them, used to verify the server certificate instead of the system CA pool. Useful when the server certificate is signed by a private CA.",
+						Validators: []validator.String{
+							stringvalidator.LengthAtLeast(1),
+						},
 					},
 				},
 				Optional:    true,
 				Description: "TLS configuration options",
 			},
+			"allow_rename": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Whether users, roles and settings profiles can be renamed in place. When false, changing the name of any of them fails and a new resource has to be created instead. Defaults to true.",
+			},
+			"reserved_settings_profiles": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: fmt.Sprintf("Names of the built-in settings profiles the provider refuses to create, rename or drop, so that they are not destroyed by accident. Defaults to %s. Set to an empty list to manage any settings profile.", strings.Join(dbops.DefaultReservedSettingsProfiles, " and ")),
+				Validators: []validator.List{
+					listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
+			"default_cluster": schema.StringAttribute{
+				Optional:    true,
+				Description: "Name of the cluster resources and data sources run their queries on when their own cluster_name is null. A cluster_name set on a resource takes precedence. Leave it null when using a ClickHouse Cloud cluster, or 'replicated' storage for user_directory.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"name_prefix": schema.StringAttribute{
+				Optional:    true,
+				Description: "Prefix added to the names of the users, roles, settings profiles and row policies managed by the provider, and to the references to them such as grantees, default roles or role grants. It is removed from the names read back, so that configurations and imports use short names while the server uses prefixed ones, e.g. to reuse modules across tenants. Databases and the reserved settings profiles are not prefixed.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"validate_sql": schema.BoolAttribute{
+				Optional:    true,
+				Description: "When true, the queries generated for resources supporting it are sent to the server with EXPLAIN AST during plan, so that syntax errors are reported before applying. Defaults to false.",
+			},
+			"cluster_reads": schema.StringAttribute{
+				Optional:    true,
+				Description: "Which replicas are read from when checking the state of resources having a cluster_name. With any_replica, every read goes to a single replica picked by the server, which is the cheapest but can return stale data right after a change if the replicas are not in sync. With all_replicas, every replica is queried through clusterAllReplicas and the results merged, so that objects missing on one replica are still found; this is slower and puts more load on large clusters. With first_replica, reads are always sent to the first replica of each shard, giving consistent results between plans at the cost of not spreading the load. Valid options are: any_replica, all_replicas, first_replica. Defaults to any_replica.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(dbops.ClusterReadsAnyReplica, dbops.ClusterReadsAllReplicas, dbops.ClusterReadsFirstReplica),
+				},
+			},
+			"user_agent": schema.StringAttribute{
+				Optional:    true,
+				Description: fmt.Sprintf("User-Agent header sent with every request when using http or https. With native or nativesecure, it is reported as the client name instead. Defaults to %s/<version>.", project.FullName()),
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"http_headers": schema.MapAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Headers added to every request when using http or https, e.g. to authenticate with a gateway in front of ClickHouse. An Authorization header replaces the basic auth credentials. The other headers set by the provider, such as User-Agent, take precedence. The values are redacted from the logs. Ignored with native or nativesecure.",
+				Validators: []validator.Map{
+					mapvalidator.KeysAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
+			"max_retries": schema.Int32Attribute{
+				Optional:    true,
+				Description: fmt.Sprintf("Number of times a query is retried when it fails with a network error or, with http or https, a 503 response. Statements that change the server, such as CREATE, ALTER or GRANT, are only retried when the connection could not be established or the request was rejected with a 503, since any other network error may arrive after the server already ran them. Errors returned by ClickHouse for the query itself, such as syntax or permission errors, are never retried. Set to 0 to disable retries. Defaults to %d.", defaultMaxRetries),
+				Validators: []validator.Int32{
+					int32validator.Between(0, 20),
+				},
+			},
+			"retry_min_delay": schema.StringAttribute{
+				Optional:    true,
+				Description: fmt.Sprintf("How long to wait before the first retry of a failed query, as a duration such as 500ms or 2s. The wait is doubled after each attempt. Defaults to %s.", defaultRetryMinDelay),
+			},
+			"query_timeout": schema.StringAttribute{
+				Optional:    true,
+				Description: fmt.Sprintf("Maximum time a single query can run for, as a duration such as 90s or 5m. Queries not completing in time are cancelled and reported as an error. ON CLUSTER statements wait for every replica for up to the server side distributed_ddl_task_timeout (180s by default): a lower query_timeout cancels them on the client while they keep running on the server, so the change may be applied even though it is reported as failed. Keep it above distributed_ddl_task_timeout when using cluster_name. Set to 0s to disable the deadline. Defaults to %s.", defaultQueryTimeout),
+			},
+			"max_open_conns": schema.Int32Attribute{
+				Optional:    true,
+				Description: "Maximum number of connections opened to ClickHouse at the same time. Queries wait for a free connection once it's reached, which keeps a highly parallel apply below the max_connections of the server. Defaults to max_idle_conns + 5.",
+				Validators: []validator.Int32{
+					int32validator.AtLeast(1),
+				},
+			},
+			"max_idle_conns": schema.Int32Attribute{
+				Optional:    true,
+				Description: "Maximum number of unused connections kept open to be reused by later queries. Defaults to 5.",
+				Validators: []validator.Int32{
+					int32validator.AtLeast(1),
+				},
+			},
+			"conn_max_lifetime": schema.StringAttribute{
+				Optional:    true,
+				Description: "How long a connection is reused for before being closed, as a duration such as 10m or 1h. With http or https, connections are closed after being unused for that long instead. Defaults to 1h.",
+			},
+			"database": schema.StringAttribute{
+				Optional:    true,
+				Description: "Default database of the queries run by the provider. Defaults to the default database of the user, or default with native and nativesecure.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"settings": schema.MapAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "Settings applied to the session of every query run by the provider, such as readonly = \"0\" or allow_experimental_* settings. With http or https they are sent as query parameters.",
+				Validators: []validator.Map{
+					mapvalidator.KeysAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
+			"pre_statements": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "Statements run in order before every DDL query of the provider, in the same session, such as SET allow_experimental_statistics = 1 when a setting must be enabled with a SET statement. With native and nativesecure they run on a dedicated connection shared with the DDL query. With http or https they share an HTTP session with the DDL query, so all the requests must reach the same server: load balancers must route requests with the same session_id query parameter to the same server.",
+				Validators: []validator.List{
+					listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
+			"compression": schema.StringAttribute{
+				Optional:    true,
+				Description: fmt.Sprintf("Compression method of the data exchanged with the server, which speeds up reading large system tables over slow links. With http or https, the server is asked to compress its responses. %s is only supported with http or https, and %s only with native or nativesecure. Valid options are: %s. Data is not compressed when not set.", compressionGzip, compressionLZ4HC, strings.Join(availableCompressions, ", ")),
+				Validators: []validator.String{
+					stringvalidator.OneOf(availableCompressions...),
+				},
+			},
+			"native_config": schema.SingleNestedAttribute{
+				Attributes: map[string]schema.Attribute{
+					"block_buffer_size": schema.Int32Attribute{
+						Optional:    true,
+						Description: "Number of blocks to buffer while reading query results. Higher values speed up reads of large system tables at the cost of memory.",
+						Validators: []validator.Int32{
+							int32validator.Between(1, 255),
+						},
+					},
+				},
+				Optional:    true,
+				Description: "Options for the native and nativesecure protocols. Ignored when using http or https.",
+			},
+			"http_config": schema.SingleNestedAttribute{
+				Attributes: map[string]schema.Attribute{
+					"read_after_create_retries": schema.Int32Attribute{
+						Optional:    true,
+						Description: fmt.Sprintf("Number of times to retry reading back an object right after creating it, in case the read hits a replica that is not in sync yet (e.g. behind a load balancer). Defaults to %d.", defaultReadAfterCreateRetries),
+						Validators: []validator.Int32{
+							int32validator.Between(0, 20),
+						},
+					},
+					"proxy_url": schema.StringAttribute{
+						Optional:    true,
+						Description: "URL of the proxy to send requests through, such as http://proxy.example.com:3128. Credentials of the proxy are set with proxy_username and proxy_password, separately from the ones of ClickHouse in auth_config.",
+						Validators: []validator.String{
+							stringvalidator.LengthAtLeast(1),
+						},
+					},
+					"proxy_username": schema.StringAttribute{
+						Optional:    true,
+						Description: "Username to authenticate to the proxy with basic auth. Requires proxy_url.",
+						Validators: []validator.String{
+							stringvalidator.LengthAtLeast(1),
+							stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("proxy_url")),
+						},
+					},
+					"proxy_password": schema.StringAttribute{
+						Optional:    true,
+						Sensitive:   true,
+						Description: "Password to authenticate to the proxy with basic auth. Requires proxy_username.",
+						Validators: []validator.String{
+							stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("proxy_username")),
+						},
+					},
+				},
+				Optional:    true,
+				Description: "Options for the http and https protocols. Ignored when using native or nativesecure.",
+			},
 		},
 	}
 }
@@ -134,13 +365,17 @@ func (p *Provider) Configure(ctx context.Context, req provider.ConfigureRequest,
 		return
 	}
 
+	if warning := portWarning(data); warning != "" {
+		resp.Diagnostics.AddAttributeWarning(path.Root("port"), "Unexpected port for protocol", warning)
+	}
+
 	clickhouseClient, err := p.newClickhouseClientWithRetry(ctx, data)
 	if err != nil {
 		resp.Diagnostics.AddError("error initializing clickhouse client", fmt.Sprintf("%+v\n", err))
 		return
 	}
 
-	dbopsClient, err := dbops.NewClient(clickhouseClient)
+	dbopsClient, err := dbops.NewClient(clickhouseClient, dbopsOptions(data)...)
 	if err != nil {
 		resp.Diagnostics.AddError("error initializing dbops client", fmt.Sprintf("%+v\n", err))
 		return
@@ -204,102 +439,495 @@ func (p *Provider) newClickhouseClient(data Model) (clickhouseclient.ClickhouseC
 		case protocolNative:
 			fallthrough
 		case protocolNativeSecure:
-			var auth *clickhouseclient.UserPasswordAuth
-			switch data.AuthConfig.Strategy.ValueString() {
-			case authStrategyPassword:
-				auth = &clickhouseclient.UserPasswordAuth{
-					Username: data.AuthConfig.Username.ValueString(),
-				}
-
-				if !data.AuthConfig.Password.IsNull() {
-					auth.Password = data.AuthConfig.Password.ValueString()
-				}
-
-				valid, errorStrings := auth.ValidateConfig()
-				if !valid {
-					return nil, fmt.Errorf("invalid configuration: invalid authentication strategy configuration. %s", strings.Join(errorStrings, ", "))
-				}
-			default:
-				return nil, fmt.Errorf("invalid configuration: invalid authentication strategy %q. %s protocol only supports %q", data.AuthConfig.Strategy, protocolNative, authStrategyPassword)
-			}
-
-			var port uint16
-			{
-				if !data.Port.IsUnknown() {
-					portVal := data.Port.ValueInt32()
-					if portVal <= 0 || portVal > 65535 {
-						return nil, fmt.Errorf("invalid configuration: invalid port %s", data.Port.String())
-					}
-
-					port = uint16(portVal)
-				}
+			var config clickhouseclient.NativeClientConfig
+			config, err = newNativeClientConfig(data)
+			if err != nil {
+				return nil, err
 			}
 
-			clickhouseClient, err = clickhouseclient.NewNativeClient(clickhouseclient.NativeClientConfig{
-				Host:             data.Host.ValueString(),
-				Port:             port,
-				UserPasswordAuth: auth,
-				EnableTLS:        data.Protocol.ValueString() == protocolNativeSecure,
-			})
+			clickhouseClient, err = clickhouseclient.NewNativeClient(config)
 		case protocolHTTP:
 			fallthrough
 		case protocolHTTPS:
-			var auth *clickhouseclient.BasicAuth
-			switch data.AuthConfig.Strategy.ValueString() {
-			case authStrategyBasicAuth:
-				auth = &clickhouseclient.BasicAuth{
-					Username: data.AuthConfig.Username.ValueString(),
-				}
-
-				if !data.AuthConfig.Password.IsNull() {
-					auth.Password = data.AuthConfig.Password.ValueString()
-				}
-
-				valid, errorStrings := auth.ValidateConfig()
-				if !valid {
-					return nil, fmt.Errorf("invalid configuration: invalid authentication strategy configuration. %s", strings.Join(errorStrings, ", "))
-				}
-			default:
-				return nil, fmt.Errorf("invalid configuration: invalid authentication strategy %q. %s protocol only supports %q", data.AuthConfig.Strategy, protocolHTTP, authStrategyBasicAuth)
+			var config clickhouseclient.HTTPClientConfig
+			config, err = newHTTPClientConfig(data)
+			if err != nil {
+				return nil, err
 			}
 
-			var port uint16
-			{
-				if !data.Port.IsUnknown() {
-					portVal := data.Port.ValueInt32()
-					if portVal <= 0 || portVal > 65535 {
-						return nil, fmt.Errorf("invalid configuration: invalid port %s", data.Port.String())
-					}
+			clickhouseClient, err = clickhouseclient.NewHTTPClient(config)
+		default:
+			return nil, fmt.Errorf("invalid configuration: unsupported protocol %q", data.Protocol.ValueString())
+		}
+	}
 
-					port = uint16(portVal)
-				}
-			}
+	return clickhouseClient, err
+}
 
-			var tlsConfig *tls.Config
-			protocol := "http"
-			if data.Protocol.ValueString() == protocolHTTPS {
-				protocol = "https"
-				tlsConfig = &tls.Config{} //nolint:gosec
-				if data.TLSConfig != nil && !data.TLSConfig.InsecureSkipVerify.IsNull() {
-					tlsConfig.InsecureSkipVerify = data.TLSConfig.InsecureSkipVerify.ValueBool()
-				}
-			}
+func newHTTPClientConfig(data Model) (clickhouseclient.HTTPClientConfig, error) {
+	if strings.HasPrefix(data.Host.ValueString(), unixSocketPrefix) {
+		return clickhouseclient.HTTPClientConfig{}, fmt.Errorf("invalid configuration: unix sockets are only supported by the %s protocol", protocolNative)
+	}
+
+	var auth *clickhouseclient.BasicAuth
+	var certAuth *clickhouseclient.ClientCertAuth
+	var tokenAuth *clickhouseclient.JWTAuth
+	switch data.AuthConfig.Strategy.ValueString() {
+	case authStrategyClientCert:
+		if data.Protocol.ValueString() != protocolHTTPS {
+			return clickhouseclient.HTTPClientConfig{}, fmt.Errorf("invalid configuration: the %q authentication strategy requires the %s protocol", authStrategyClientCert, protocolHTTPS)
+		}
+
+		var err error
+		certAuth, err = clientCertAuth(data)
+		if err != nil {
+			return clickhouseclient.HTTPClientConfig{}, err
+		}
+	case authStrategyJWT:
+		if data.Protocol.ValueString() != protocolHTTPS {
+			return clickhouseclient.HTTPClientConfig{}, fmt.Errorf("invalid configuration: the %q authentication strategy requires the %s protocol", authStrategyJWT, protocolHTTPS)
+		}
+
+		var err error
+		tokenAuth, err = jwtAuth(data)
+		if err != nil {
+			return clickhouseclient.HTTPClientConfig{}, err
+		}
+	case authStrategyBasicAuth:
+		auth = &clickhouseclient.BasicAuth{
+			Username: data.AuthConfig.Username.ValueString(),
+		}
+
+		if !data.AuthConfig.Password.IsNull() {
+			auth.Password = data.AuthConfig.Password.ValueString()
+		}
+
+		valid, errorStrings := auth.ValidateConfig()
+		if !valid {
+			return clickhouseclient.HTTPClientConfig{}, fmt.Errorf("invalid configuration: invalid authentication strategy configuration. %s", strings.Join(errorStrings, ", "))
+		}
+	default:
+		return clickhouseclient.HTTPClientConfig{}, fmt.Errorf("invalid configuration: invalid authentication strategy %q. %s protocol only supports %q, %q and %q", data.AuthConfig.Strategy, protocolHTTP, authStrategyBasicAuth, authStrategyClientCert, authStrategyJWT)
+	}
+
+	port, err := resolvePort(data)
+	if err != nil {
+		return clickhouseclient.HTTPClientConfig{}, err
+	}
+
+	var tlsConfig *tls.Config
+	protocol := "http"
+	if data.Protocol.ValueString() == protocolHTTPS {
+		protocol = "https"
+
+		tlsConfig, err = newTLSConfig(data.TLSConfig)
+		if err != nil {
+			return clickhouseclient.HTTPClientConfig{}, err
+		}
+	}
+
+	config := clickhouseclient.HTTPClientConfig{
+		Protocol:       protocol,
+		Host:           data.Host.ValueString(),
+		Port:           port,
+		BasicAuth:      auth,
+		ClientCertAuth: certAuth,
+		JWTAuth:        tokenAuth,
+		TLSConfig:      tlsConfig,
+		UserAgent:      userAgent(data),
+		Headers:        httpHeaders(data),
+		Database:       data.Database.ValueString(),
+		Settings:       sessionSettings(data),
+		PreStatements:  preStatements(data),
+	}
+
+	config.MaxRetries, config.RetryBackoff, err = retryConfig(data)
+	if err != nil {
+		return clickhouseclient.HTTPClientConfig{}, err
+	}
+
+	config.QueryTimeout, err = queryTimeout(data)
+	if err != nil {
+		return clickhouseclient.HTTPClientConfig{}, err
+	}
 
-			config := clickhouseclient.HTTPClientConfig{
-				Protocol:  protocol,
-				Host:      data.Host.ValueString(),
-				Port:      port,
-				BasicAuth: auth,
-				TLSConfig: tlsConfig,
+	config.MaxOpenConns, config.MaxIdleConns, config.ConnMaxLifetime, err = poolConfig(data)
+	if err != nil {
+		return clickhouseclient.HTTPClientConfig{}, err
+	}
+
+	config.ProxyURL, config.ProxyUsername, config.ProxyPassword, err = proxyConfig(data.HTTPConfig)
+	if err != nil {
+		return clickhouseclient.HTTPClientConfig{}, err
+	}
+
+	config.Compression, err = httpCompression(data)
+	if err != nil {
+		return clickhouseclient.HTTPClientConfig{}, err
+	}
+
+	return config, nil
+}
+
+func newNativeClientConfig(data Model) (clickhouseclient.NativeClientConfig, error) {
+	var auth *clickhouseclient.UserPasswordAuth
+	var certAuth *clickhouseclient.ClientCertAuth
+	switch data.AuthConfig.Strategy.ValueString() {
+	case authStrategyClientCert:
+		if data.Protocol.ValueString() != protocolNativeSecure {
+			return clickhouseclient.NativeClientConfig{}, fmt.Errorf("invalid configuration: the %q authentication strategy requires the %s protocol", authStrategyClientCert, protocolNativeSecure)
+		}
+
+		var err error
+		certAuth, err = clientCertAuth(data)
+		if err != nil {
+			return clickhouseclient.NativeClientConfig{}, err
+		}
+	case authStrategyPassword:
+		auth = &clickhouseclient.UserPasswordAuth{
+			Username: data.AuthConfig.Username.ValueString(),
+		}
+
+		if !data.AuthConfig.Password.IsNull() {
+			auth.Password = data.AuthConfig.Password.ValueString()
+		}
+
+		valid, errorStrings := auth.ValidateConfig()
+		if !valid {
+			return clickhouseclient.NativeClientConfig{}, fmt.Errorf("invalid configuration: invalid authentication strategy configuration. %s", strings.Join(errorStrings, ", "))
+		}
+	case authStrategyJWT:
+		return clickhouseclient.NativeClientConfig{}, fmt.Errorf("invalid configuration: the %q authentication strategy requires the %s protocol", authStrategyJWT, protocolHTTPS)
+	default:
+		return clickhouseclient.NativeClientConfig{}, fmt.Errorf("invalid configuration: invalid authentication strategy %q. %s protocol only supports %q and %q", data.AuthConfig.Strategy, protocolNative, authStrategyPassword, authStrategyClientCert)
+	}
+
+	maxRetries, retryBackoff, err := retryConfig(data)
+	if err != nil {
+		return clickhouseclient.NativeClientConfig{}, err
+	}
+
+	timeout, err := queryTimeout(data)
+	if err != nil {
+		return clickhouseclient.NativeClientConfig{}, err
+	}
+
+	maxOpenConns, maxIdleConns, connMaxLifetime, err := poolConfig(data)
+	if err != nil {
+		return clickhouseclient.NativeClientConfig{}, err
+	}
+
+	compression, err := nativeCompression(data)
+	if err != nil {
+		return clickhouseclient.NativeClientConfig{}, err
+	}
+
+	if socketPath, ok := strings.CutPrefix(data.Host.ValueString(), unixSocketPrefix); ok {
+		if data.Protocol.ValueString() != protocolNative {
+			return clickhouseclient.NativeClientConfig{}, fmt.Errorf("invalid configuration: unix sockets are only supported by the %s protocol", protocolNative)
+		}
+
+		if socketPath == "" {
+			return clickhouseclient.NativeClientConfig{}, fmt.Errorf("invalid configuration: empty unix socket path")
+		}
+
+		config := clickhouseclient.NativeClientConfig{
+			SocketPath:       socketPath,
+			UserPasswordAuth: auth,
+			ClientCertAuth:   certAuth,
+			UserAgent:        userAgent(data),
+			MaxRetries:       maxRetries,
+			RetryBackoff:     retryBackoff,
+			QueryTimeout:     timeout,
+			MaxOpenConns:     maxOpenConns,
+			MaxIdleConns:     maxIdleConns,
+			ConnMaxLifetime:  connMaxLifetime,
+			Database:         data.Database.ValueString(),
+			Settings:         sessionSettings(data),
+			PreStatements:    preStatements(data),
+			Compression:      compression,
+		}
+
+		return withNativeConfig(config, data.NativeConfig)
+	}
+
+	port, err := resolvePort(data)
+	if err != nil {
+		return clickhouseclient.NativeClientConfig{}, err
+	}
+
+	var tlsConfig *tls.Config
+	if data.Protocol.ValueString() == protocolNativeSecure {
+		tlsConfig, err = newTLSConfig(data.TLSConfig)
+		if err != nil {
+			return clickhouseclient.NativeClientConfig{}, err
+		}
+	}
+
+	config := clickhouseclient.NativeClientConfig{
+		Host:             data.Host.ValueString(),
+		Port:             port,
+		UserPasswordAuth: auth,
+		ClientCertAuth:   certAuth,
+		EnableTLS:        data.Protocol.ValueString() == protocolNativeSecure,
+		TLSConfig:        tlsConfig,
+		UserAgent:        userAgent(data),
+		MaxRetries:       maxRetries,
+		RetryBackoff:     retryBackoff,
+		QueryTimeout:     timeout,
+		MaxOpenConns:     maxOpenConns,
+		MaxIdleConns:     maxIdleConns,
+		ConnMaxLifetime:  connMaxLifetime,
+		Database:         data.Database.ValueString(),
+		Settings:         sessionSettings(data),
+		PreStatements:    preStatements(data),
+		Compression:      compression,
+	}
+
+	return withNativeConfig(config, data.NativeConfig)
+}
+
+// sessionSettings returns the settings to apply to the session of every query.
+func sessionSettings(data Model) map[string]string {
+	if data.Settings.IsNull() || data.Settings.IsUnknown() {
+		return nil
+	}
+
+	settings := make(map[string]string)
+	for name, value := range data.Settings.Elements() {
+		if v, ok := value.(types.String); ok && !v.IsNull() && !v.IsUnknown() {
+			settings[name] = v.ValueString()
+		}
+	}
+
+	return settings
+}
+
+// httpHeaders returns the headers to add to every HTTP request.
+func httpHeaders(data Model) map[string]string {
+	if data.HTTPHeaders.IsNull() || data.HTTPHeaders.IsUnknown() {
+		return nil
+	}
+
+	headers := make(map[string]string)
+	for name, value := range data.HTTPHeaders.Elements() {
+		if v, ok := value.(types.String); ok && !v.IsNull() && !v.IsUnknown() {
+			headers[name] = v.ValueString()
+		}
+	}
+
+	return headers
+}
+
+// preStatements returns the statements to run before every DDL query.
+func preStatements(data Model) []string {
+	if data.PreStatements.IsNull() || data.PreStatements.IsUnknown() {
+		return nil
+	}
+
+	statements := make([]string, 0)
+	for _, value := range data.PreStatements.Elements() {
+		if v, ok := value.(types.String); ok && !v.IsNull() && !v.IsUnknown() {
+			statements = append(statements, v.ValueString())
+		}
+	}
+
+	return statements
+}
+
+// httpCompression returns the compression method of the http client set by the compression attribute.
+func httpCompression(data Model) (string, error) {
+	if data.Compression.IsNull() || data.Compression.IsUnknown() {
+		return "", nil
+	}
+
+	if data.Compression.ValueString() == compressionLZ4HC {
+		return "", fmt.Errorf("invalid configuration: the %q compression is only supported by the %s and %s protocols", compressionLZ4HC, protocolNative, protocolNativeSecure)
+	}
+
+	return data.Compression.ValueString(), nil
+}
+
+// nativeCompression returns the compression method of the native client set by the compression attribute.
+func nativeCompression(data Model) (string, error) {
+	if data.Compression.IsNull() || data.Compression.IsUnknown() {
+		return "", nil
+	}
+
+	if data.Compression.ValueString() == compressionGzip {
+		return "", fmt.Errorf("invalid configuration: the %q compression is only supported by the %s and %s protocols", compressionGzip, protocolHTTP, protocolHTTPS)
+	}
+
+	return data.Compression.ValueString(), nil
+}
+
+// withNativeConfig applies the options of the native_config block to the given config.
+func withNativeConfig(config clickhouseclient.NativeClientConfig, nativeConfig *NativeConfig) (clickhouseclient.NativeClientConfig, error) {
+	if nativeConfig == nil {
+		return config, nil
+	}
+
+	if !nativeConfig.BlockBufferSize.IsNull() {
+		blockBufferSize := nativeConfig.BlockBufferSize.ValueInt32()
+		if blockBufferSize <= 0 || blockBufferSize > 255 {
+			return clickhouseclient.NativeClientConfig{}, fmt.Errorf("invalid configuration: invalid block_buffer_size %d", blockBufferSize)
+		}
+
+		config.BlockBufferSize = uint8(blockBufferSize)
+	}
+
+	return config, nil
+}
+
+// retryConfig returns the number of retries and the initial backoff for failed queries.
+func retryConfig(data Model) (int, time.Duration, error) {
+	maxRetries := defaultMaxRetries
+	if !data.MaxRetries.IsNull() && !data.MaxRetries.IsUnknown() {
+		maxRetries = int(data.MaxRetries.ValueInt32())
+	}
+
+	minDelay := defaultRetryMinDelay
+	if !data.RetryMinDelay.IsNull() && !data.RetryMinDelay.IsUnknown() {
+		var err error
+		minDelay, err = time.ParseDuration(data.RetryMinDelay.ValueString())
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid configuration: invalid retry_min_delay %q: %w", data.RetryMinDelay.ValueString(), err)
+		}
+	}
+
+	return maxRetries, minDelay, nil
+}
+
+// queryTimeout returns the deadline of every query.
+func queryTimeout(data Model) (time.Duration, error) {
+	if data.QueryTimeout.IsNull() || data.QueryTimeout.IsUnknown() {
+		return defaultQueryTimeout, nil
+	}
+
+	timeout, err := time.ParseDuration(data.QueryTimeout.ValueString())
+	if err != nil {
+		return 0, fmt.Errorf("invalid configuration: invalid query_timeout %q: %w", data.QueryTimeout.ValueString(), err)
+	}
+
+	if timeout < 0 {
+		return 0, fmt.Errorf("invalid configuration: query_timeout %q can't be negative", data.QueryTimeout.ValueString())
+	}
+
+	return timeout, nil
+}
+
+// poolConfig returns the maximum number of open and idle connections and their maximum lifetime. Zero values mean
+// the defaults of the client.
+func poolConfig(data Model) (int, int, time.Duration, error) {
+	maxOpenConns := 0
+	if !data.MaxOpenConns.IsNull() && !data.MaxOpenConns.IsUnknown() {
+		maxOpenConns = int(data.MaxOpenConns.ValueInt32())
+	}
+
+	maxIdleConns := 0
+	if !data.MaxIdleConns.IsNull() && !data.MaxIdleConns.IsUnknown() {
+		maxIdleConns = int(data.MaxIdleConns.ValueInt32())
+	}
+
+	if maxOpenConns > 0 && maxIdleConns > maxOpenConns {
+		return 0, 0, 0, fmt.Errorf("invalid configuration: max_idle_conns %d can't be greater than max_open_conns %d", maxIdleConns, maxOpenConns)
+	}
+
+	var connMaxLifetime time.Duration
+	if !data.ConnMaxLifetime.IsNull() && !data.ConnMaxLifetime.IsUnknown() {
+		var err error
+		connMaxLifetime, err = time.ParseDuration(data.ConnMaxLifetime.ValueString())
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid configuration: invalid conn_max_lifetime %q: %w", data.ConnMaxLifetime.ValueString(), err)
+		}
+
+		if connMaxLifetime <= 0 {
+			return 0, 0, 0, fmt.Errorf("invalid configuration: conn_max_lifetime %q must be positive", data.ConnMaxLifetime.ValueString())
+		}
+	}
+
+	return maxOpenConns, maxIdleConns, connMaxLifetime, nil
+}
+
+// proxyConfig returns the URL and credentials of the proxy set in the http_config block.
+func proxyConfig(httpConfig *HTTPConfig) (string, string, string, error) {
+	if httpConfig == nil {
+		return "", "", "", nil
+	}
+
+	proxyURL := httpConfig.ProxyURL.ValueString()
+	username := httpConfig.ProxyUsername.ValueString()
+	password := httpConfig.ProxyPassword.ValueString()
+
+	if proxyURL == "" && (username != "" || password != "") {
+		return "", "", "", fmt.Errorf("invalid configuration: proxy_username and proxy_password can only be used with proxy_url")
+	}
+	if username == "" && password != "" {
+		return "", "", "", fmt.Errorf("invalid configuration: proxy_password requires proxy_username")
+	}
+
+	return proxyURL, username, password, nil
+}
+
+// userAgent returns the User-Agent to identify the provider with.
+func userAgent(data Model) string {
+	if !data.UserAgent.IsNull() && !data.UserAgent.IsUnknown() {
+		return data.UserAgent.ValueString()
+	}
+
+	return defaultUserAgent()
+}
+
+func defaultUserAgent() string {
+	return fmt.Sprintf("%s/%s", project.FullName(), project.Version())
+}
+
+// dbopsOptions returns the dbops client options matching the provider configuration.
+func dbopsOptions(data Model) []dbops.Option {
+	opts := make([]dbops.Option, 0)
+
+	if !data.AllowRename.IsNull() {
+		opts = append(opts, dbops.WithAllowRename(data.AllowRename.ValueBool()))
+	}
+
+	if !data.ReservedSettingsProfiles.IsNull() && !data.ReservedSettingsProfiles.IsUnknown() {
+		names := make([]string, 0)
+		for _, value := range data.ReservedSettingsProfiles.Elements() {
+			if v, ok := value.(types.String); ok && !v.IsNull() && !v.IsUnknown() {
+				names = append(names, v.ValueString())
 			}
+		}
 
-			clickhouseClient, err = clickhouseclient.NewHTTPClient(config)
-		default:
-			return nil, fmt.Errorf("invalid configuration: unsupported protocol %q", data.Protocol.ValueString())
+		opts = append(opts, dbops.WithReservedSettingsProfiles(names))
+	}
+
+	if !data.DefaultCluster.IsNull() {
+		opts = append(opts, dbops.WithDefaultCluster(data.DefaultCluster.ValueString()))
+	}
+
+	if !data.NamePrefix.IsNull() {
+		opts = append(opts, dbops.WithNamePrefix(data.NamePrefix.ValueString()))
+	}
+
+	if data.ValidateSQL.ValueBool() {
+		opts = append(opts, dbops.WithSQLValidation())
+	}
+
+	if !data.ClusterReads.IsNull() {
+		opts = append(opts, dbops.WithClusterReads(data.ClusterReads.ValueString()))
+	}
+
+	switch data.Protocol.ValueString() {
+	case protocolHTTP, protocolHTTPS:
+		retries := defaultReadAfterCreateRetries
+		if data.HTTPConfig != nil && !data.HTTPConfig.ReadAfterCreateRetries.IsNull() {
+			retries = int(data.HTTPConfig.ReadAfterCreateRetries.ValueInt32())
 		}
+
+		opts = append(opts, dbops.WithReadAfterCreateRetries(retries, readAfterCreateBackoff))
 	}
 
-	return clickhouseClient, err
+	return opts
 }
 
 func isRetryableInitError(err error) bool {
@@ -339,12 +967,20 @@ func (p *Provider) Resources(ctx context.Context) []func() tfresource.Resource {
 		settingsprofile.NewResource,
 		setting.NewResource,
 		settingsprofileassociation.NewResource,
+		defaultsettingsprofile.NewResource,
+		rowpolicy.NewResource,
+		usergrantees.NewResource,
 	}
 }
 
 func (p *Provider) DataSources(ctx context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		settingsprofileds.NewDataSource,
+		effectivegrants.NewDataSource,
+		roles.NewDataSource,
+		serverinfo.NewDataSource,
+		cluster.NewDataSource,
+		userds.NewDataSource,
 	}
 }
 
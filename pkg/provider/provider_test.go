@@ -0,0 +1,709 @@
+package provider
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func Test_newNativeClientConfig(t *testing.T) {
+	baseModel := func() Model {
+		return Model{
+			Protocol: types.StringValue(protocolNative),
+			Host:     types.StringValue("localhost"),
+			Port:     types.Int32Value(9000),
+			AuthConfig: AuthConfig{
+				Strategy: types.StringValue(authStrategyPassword),
+				Username: types.StringValue("default"),
+				Password: types.StringNull(),
+			},
+		}
+	}
+
+	tests := []struct {
+		name                string
+		compression         types.String
+		nativeConfig        *NativeConfig
+		wantBlockBufferSize uint8
+		wantCompression     string
+		wantErr             bool
+	}{
+		{
+			name:                "No native config",
+			nativeConfig:        nil,
+			wantBlockBufferSize: 0,
+			wantCompression:     "",
+		},
+		{
+			name:        "Block buffer size and compression are propagated",
+			compression: types.StringValue(compressionZSTD),
+			nativeConfig: &NativeConfig{
+				BlockBufferSize: types.Int32Value(16),
+			},
+			wantBlockBufferSize: 16,
+			wantCompression:     compressionZSTD,
+		},
+		{
+			name:            "Provider compression",
+			compression:     types.StringValue(compressionZSTD),
+			wantCompression: compressionZSTD,
+		},
+		{
+			name:            "Provider lz4hc compression",
+			compression:     types.StringValue(compressionLZ4HC),
+			wantCompression: compressionLZ4HC,
+		},
+		{
+			name:        "Gzip compression is not supported",
+			compression: types.StringValue(compressionGzip),
+			wantErr:     true,
+		},
+		{
+			name: "Block buffer size out of range",
+			nativeConfig: &NativeConfig{
+				BlockBufferSize: types.Int32Value(1000),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := baseModel()
+			data.Compression = tt.compression
+			data.NativeConfig = tt.nativeConfig
+
+			got, err := newNativeClientConfig(data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newNativeClientConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.BlockBufferSize != tt.wantBlockBufferSize {
+				t.Errorf("BlockBufferSize got = %d, want %d", got.BlockBufferSize, tt.wantBlockBufferSize)
+			}
+			if got.Compression != tt.wantCompression {
+				t.Errorf("Compression got = %q, want %q", got.Compression, tt.wantCompression)
+			}
+		})
+	}
+}
+
+func Test_newNativeClientConfig_unixSocket(t *testing.T) {
+	tests := []struct {
+		name           string
+		protocol       string
+		host           string
+		wantSocketPath string
+		wantErr        bool
+	}{
+		{
+			name:           "Socket path is propagated",
+			protocol:       protocolNative,
+			host:           "unix:///var/run/clickhouse-server/clickhouse.sock",
+			wantSocketPath: "/var/run/clickhouse-server/clickhouse.sock",
+		},
+		{
+			name:           "TCP host has no socket path",
+			protocol:       protocolNative,
+			host:           "localhost",
+			wantSocketPath: "",
+		},
+		{
+			name:     "Empty socket path",
+			protocol: protocolNative,
+			host:     "unix://",
+			wantErr:  true,
+		},
+		{
+			name:     "Socket with TLS",
+			protocol: protocolNativeSecure,
+			host:     "unix:///var/run/clickhouse-server/clickhouse.sock",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := Model{
+				Protocol: types.StringValue(tt.protocol),
+				Host:     types.StringValue(tt.host),
+				Port:     types.Int32Value(9000),
+				AuthConfig: AuthConfig{
+					Strategy: types.StringValue(authStrategyPassword),
+					Username: types.StringValue("default"),
+					Password: types.StringNull(),
+				},
+			}
+			if tt.wantSocketPath != "" {
+				data.Port = types.Int32Null()
+			}
+
+			got, err := newNativeClientConfig(data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newNativeClientConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.SocketPath != tt.wantSocketPath {
+				t.Errorf("SocketPath got = %q, want %q", got.SocketPath, tt.wantSocketPath)
+			}
+		})
+	}
+}
+
+func Test_newClickhouseClient_userAgent(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent types.String
+		want      string
+	}{
+		{
+			name:      "Default user agent",
+			userAgent: types.StringNull(),
+			want:      defaultUserAgent(),
+		},
+		{
+			name:      "Custom user agent",
+			userAgent: types.StringValue("platform-team/2.0"),
+			want:      "platform-team/2.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("User-Agent")
+			}))
+			defer server.Close()
+
+			serverURL, err := url.Parse(server.URL)
+			if err != nil {
+				t.Fatalf("url.Parse() error = %v", err)
+			}
+			port, err := strconv.Atoi(serverURL.Port())
+			if err != nil {
+				t.Fatalf("strconv.Atoi() error = %v", err)
+			}
+
+			data := Model{
+				Protocol:  types.StringValue(protocolHTTP),
+				Host:      types.StringValue(serverURL.Hostname()),
+				Port:      types.Int32Value(int32(port)),
+				UserAgent: tt.userAgent,
+				AuthConfig: AuthConfig{
+					Strategy: types.StringValue(authStrategyBasicAuth),
+					Username: types.StringValue("default"),
+					Password: types.StringNull(),
+				},
+			}
+
+			client, err := (&Provider{}).newClickhouseClient(data)
+			if err != nil {
+				t.Fatalf("newClickhouseClient() error = %v", err)
+			}
+
+			err = client.Exec(context.Background(), "SELECT 1")
+			if err != nil {
+				t.Fatalf("Exec() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("User-Agent got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_newClickhouseClient_httpHeaders(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("X-Gateway-Token")
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+	port, err := strconv.Atoi(serverURL.Port())
+	if err != nil {
+		t.Fatalf("strconv.Atoi() error = %v", err)
+	}
+
+	data := Model{
+		Protocol: types.StringValue(protocolHTTP),
+		Host:     types.StringValue(serverURL.Hostname()),
+		Port:     types.Int32Value(int32(port)),
+		HTTPHeaders: types.MapValueMust(types.StringType, map[string]attr.Value{
+			"X-Gateway-Token": types.StringValue("abc"),
+		}),
+		AuthConfig: AuthConfig{
+			Strategy: types.StringValue(authStrategyBasicAuth),
+			Username: types.StringValue("default"),
+			Password: types.StringNull(),
+		},
+	}
+
+	client, err := (&Provider{}).newClickhouseClient(data)
+	if err != nil {
+		t.Fatalf("newClickhouseClient() error = %v", err)
+	}
+
+	err = client.Exec(context.Background(), "SELECT 1")
+	if err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+
+	if got != "abc" {
+		t.Errorf("X-Gateway-Token got = %q, want %q", got, "abc")
+	}
+}
+
+func Test_newClickhouseClient_session(t *testing.T) {
+	tests := []struct {
+		name     string
+		database types.String
+		settings types.Map
+		want     url.Values
+	}{
+		{
+			name:     "No session options",
+			database: types.StringNull(),
+			settings: types.MapNull(types.StringType),
+			want:     url.Values{},
+		},
+		{
+			name:     "Database and settings",
+			database: types.StringValue("analytics"),
+			settings: types.MapValueMust(types.StringType, map[string]attr.Value{
+				"readonly":                   types.StringValue("0"),
+				"allow_experimental_feature": types.StringValue("1"),
+			}),
+			want: url.Values{
+				"database":                   []string{"analytics"},
+				"readonly":                   []string{"0"},
+				"allow_experimental_feature": []string{"1"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got url.Values
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.URL.Query()
+			}))
+			defer server.Close()
+
+			serverURL, err := url.Parse(server.URL)
+			if err != nil {
+				t.Fatalf("url.Parse() error = %v", err)
+			}
+			port, err := strconv.Atoi(serverURL.Port())
+			if err != nil {
+				t.Fatalf("strconv.Atoi() error = %v", err)
+			}
+
+			data := Model{
+				Protocol: types.StringValue(protocolHTTP),
+				Host:     types.StringValue(serverURL.Hostname()),
+				Port:     types.Int32Value(int32(port)),
+				Database: tt.database,
+				Settings: tt.settings,
+				AuthConfig: AuthConfig{
+					Strategy: types.StringValue(authStrategyBasicAuth),
+					Username: types.StringValue("default"),
+					Password: types.StringNull(),
+				},
+			}
+
+			client, err := (&Provider{}).newClickhouseClient(data)
+			if err != nil {
+				t.Fatalf("newClickhouseClient() error = %v", err)
+			}
+
+			err = client.Exec(context.Background(), "SELECT 1")
+			if err != nil {
+				t.Fatalf("Exec() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("query parameters got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_newClickhouseClient_preStatements(t *testing.T) {
+	type request struct {
+		query     string
+		sessionID string
+	}
+
+	var got []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = append(got, request{query: string(body), sessionID: r.URL.Query().Get("session_id")})
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+	port, err := strconv.Atoi(serverURL.Port())
+	if err != nil {
+		t.Fatalf("strconv.Atoi() error = %v", err)
+	}
+
+	data := Model{
+		Protocol: types.StringValue(protocolHTTP),
+		Host:     types.StringValue(serverURL.Hostname()),
+		Port:     types.Int32Value(int32(port)),
+		PreStatements: types.ListValueMust(types.StringType, []attr.Value{
+			types.StringValue("SET allow_experimental_statistics = 1"),
+			types.StringValue("SET allow_statistics_optimize = 1"),
+		}),
+		AuthConfig: AuthConfig{
+			Strategy: types.StringValue(authStrategyBasicAuth),
+			Username: types.StringValue("default"),
+			Password: types.StringNull(),
+		},
+	}
+
+	client, err := (&Provider{}).newClickhouseClient(data)
+	if err != nil {
+		t.Fatalf("newClickhouseClient() error = %v", err)
+	}
+
+	for range 2 {
+		err = client.Exec(context.Background(), "CREATE ROLE foo")
+		if err != nil {
+			t.Fatalf("Exec() error = %v", err)
+		}
+	}
+
+	wantQueries := []string{
+		"SET allow_experimental_statistics = 1",
+		"SET allow_statistics_optimize = 1",
+		"CREATE ROLE foo",
+		"SET allow_experimental_statistics = 1",
+		"SET allow_statistics_optimize = 1",
+		"CREATE ROLE foo",
+	}
+	if len(got) != len(wantQueries) {
+		t.Fatalf("requests got = %v, want queries %q", got, wantQueries)
+	}
+	for idx, req := range got {
+		if req.query != wantQueries[idx] {
+			t.Errorf("request %d query got = %q, want %q", idx, req.query, wantQueries[idx])
+		}
+		if req.sessionID == "" || req.sessionID != got[idx-idx%3].sessionID {
+			t.Errorf("request %d session_id got = %q, want the one of its pre statements %q", idx, req.sessionID, got[idx-idx%3].sessionID)
+		}
+	}
+	if got[0].sessionID == got[3].sessionID {
+		t.Errorf("session_id of separate queries got = %q, want distinct sessions", got[0].sessionID)
+	}
+}
+
+func Test_newClickhouseClient_proxy(t *testing.T) {
+	var gotProxyAuth, gotAuth, gotHost string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotProxyAuth = r.Header.Get("Proxy-Authorization")
+		gotAuth = r.Header.Get("Authorization")
+		gotHost = r.Host
+	}))
+	defer proxy.Close()
+
+	data := Model{
+		Protocol: types.StringValue(protocolHTTP),
+		Host:     types.StringValue("clickhouse.internal"),
+		Port:     types.Int32Value(8123),
+		AuthConfig: AuthConfig{
+			Strategy: types.StringValue(authStrategyBasicAuth),
+			Username: types.StringValue("default"),
+			Password: types.StringValue("clickhouse-secret"),
+		},
+		HTTPConfig: &HTTPConfig{
+			ProxyURL:      types.StringValue(proxy.URL),
+			ProxyUsername: types.StringValue("proxy-user"),
+			ProxyPassword: types.StringValue("proxy-secret"),
+		},
+	}
+
+	client, err := (&Provider{}).newClickhouseClient(data)
+	if err != nil {
+		t.Fatalf("newClickhouseClient() error = %v", err)
+	}
+
+	err = client.Exec(context.Background(), "SELECT 1")
+	if err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+
+	basicAuth := func(username, password string) string {
+		req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+		req.SetBasicAuth(username, password)
+		return req.Header.Get("Authorization")
+	}
+
+	if gotHost != "clickhouse.internal:8123" {
+		t.Errorf("Host got = %q, want %q", gotHost, "clickhouse.internal:8123")
+	}
+	if want := basicAuth("proxy-user", "proxy-secret"); gotProxyAuth != want {
+		t.Errorf("Proxy-Authorization got = %q, want %q", gotProxyAuth, want)
+	}
+	if want := basicAuth("default", "clickhouse-secret"); gotAuth != want {
+		t.Errorf("Authorization got = %q, want %q", gotAuth, want)
+	}
+}
+
+func Test_proxyConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		httpConfig *HTTPConfig
+		wantURL    string
+		wantErr    bool
+	}{
+		{
+			name:       "No http config",
+			httpConfig: nil,
+			wantURL:    "",
+		},
+		{
+			name: "Proxy without credentials",
+			httpConfig: &HTTPConfig{
+				ProxyURL:      types.StringValue("http://proxy:3128"),
+				ProxyUsername: types.StringNull(),
+				ProxyPassword: types.StringNull(),
+			},
+			wantURL: "http://proxy:3128",
+		},
+		{
+			name: "Credentials without proxy",
+			httpConfig: &HTTPConfig{
+				ProxyURL:      types.StringNull(),
+				ProxyUsername: types.StringValue("proxy-user"),
+				ProxyPassword: types.StringValue("proxy-secret"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "Password without username",
+			httpConfig: &HTTPConfig{
+				ProxyURL:      types.StringValue("http://proxy:3128"),
+				ProxyUsername: types.StringNull(),
+				ProxyPassword: types.StringValue("proxy-secret"),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotURL, _, _, err := proxyConfig(tt.httpConfig)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("proxyConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotURL != tt.wantURL {
+				t.Errorf("proxyConfig() url = %q, want %q", gotURL, tt.wantURL)
+			}
+		})
+	}
+}
+
+func Test_retryConfig(t *testing.T) {
+	tests := []struct {
+		name           string
+		maxRetries     types.Int32
+		retryMinDelay  types.String
+		wantMaxRetries int
+		wantBackoff    time.Duration
+		wantErr        bool
+	}{
+		{
+			name:           "Defaults",
+			maxRetries:     types.Int32Null(),
+			retryMinDelay:  types.StringNull(),
+			wantMaxRetries: defaultMaxRetries,
+			wantBackoff:    defaultRetryMinDelay,
+		},
+		{
+			name:           "Custom values",
+			maxRetries:     types.Int32Value(5),
+			retryMinDelay:  types.StringValue("250ms"),
+			wantMaxRetries: 5,
+			wantBackoff:    250 * time.Millisecond,
+		},
+		{
+			name:           "Retries disabled",
+			maxRetries:     types.Int32Value(0),
+			retryMinDelay:  types.StringNull(),
+			wantMaxRetries: 0,
+			wantBackoff:    defaultRetryMinDelay,
+		},
+		{
+			name:          "Invalid delay",
+			maxRetries:    types.Int32Null(),
+			retryMinDelay: types.StringValue("soon"),
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxRetries, backoff, err := retryConfig(Model{
+				MaxRetries:    tt.maxRetries,
+				RetryMinDelay: tt.retryMinDelay,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("retryConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if maxRetries != tt.wantMaxRetries {
+				t.Errorf("retryConfig() maxRetries = %d, want %d", maxRetries, tt.wantMaxRetries)
+			}
+			if backoff != tt.wantBackoff {
+				t.Errorf("retryConfig() backoff = %s, want %s", backoff, tt.wantBackoff)
+			}
+		})
+	}
+}
+
+func Test_queryTimeout(t *testing.T) {
+	tests := []struct {
+		name         string
+		queryTimeout types.String
+		want         time.Duration
+		wantErr      bool
+	}{
+		{
+			name:         "Default",
+			queryTimeout: types.StringNull(),
+			want:         defaultQueryTimeout,
+		},
+		{
+			name:         "Custom value",
+			queryTimeout: types.StringValue("5m"),
+			want:         5 * time.Minute,
+		},
+		{
+			name:         "Disabled",
+			queryTimeout: types.StringValue("0s"),
+			want:         0,
+		},
+		{
+			name:         "Invalid duration",
+			queryTimeout: types.StringValue("forever"),
+			wantErr:      true,
+		},
+		{
+			name:         "Negative duration",
+			queryTimeout: types.StringValue("-1s"),
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := queryTimeout(Model{QueryTimeout: tt.queryTimeout})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("queryTimeout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("queryTimeout() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_httpCompression(t *testing.T) {
+	tests := []struct {
+		name        string
+		compression types.String
+		want        string
+		wantErr     bool
+	}{
+		{
+			name:        "Not set",
+			compression: types.StringNull(),
+			want:        "",
+		},
+		{
+			name:        "Gzip",
+			compression: types.StringValue(compressionGzip),
+			want:        compressionGzip,
+		},
+		{
+			name:        "Lz4hc is not supported",
+			compression: types.StringValue(compressionLZ4HC),
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := httpCompression(Model{Compression: tt.compression})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("httpCompression() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("httpCompression() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_poolConfig(t *testing.T) {
+	tests := []struct {
+		name                string
+		data                Model
+		wantMaxOpenConns    int
+		wantMaxIdleConns    int
+		wantConnMaxLifetime time.Duration
+		wantErr             bool
+	}{
+		{
+			name: "Defaults",
+			data: Model{MaxOpenConns: types.Int32Null(), MaxIdleConns: types.Int32Null(), ConnMaxLifetime: types.StringNull()},
+		},
+		{
+			name:                "Custom pool",
+			data:                Model{MaxOpenConns: types.Int32Value(4), MaxIdleConns: types.Int32Value(2), ConnMaxLifetime: types.StringValue("10m")},
+			wantMaxOpenConns:    4,
+			wantMaxIdleConns:    2,
+			wantConnMaxLifetime: 10 * time.Minute,
+		},
+		{
+			name:    "More idle than open connections",
+			data:    Model{MaxOpenConns: types.Int32Value(2), MaxIdleConns: types.Int32Value(4), ConnMaxLifetime: types.StringNull()},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid lifetime",
+			data:    Model{MaxOpenConns: types.Int32Null(), MaxIdleConns: types.Int32Null(), ConnMaxLifetime: types.StringValue("forever")},
+			wantErr: true,
+		},
+		{
+			name:    "Zero lifetime",
+			data:    Model{MaxOpenConns: types.Int32Null(), MaxIdleConns: types.Int32Null(), ConnMaxLifetime: types.StringValue("0s")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxOpenConns, maxIdleConns, connMaxLifetime, err := poolConfig(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("poolConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if maxOpenConns != tt.wantMaxOpenConns || maxIdleConns != tt.wantMaxIdleConns || connMaxLifetime != tt.wantConnMaxLifetime {
+				t.Errorf("poolConfig() = %d, %d, %s, want %d, %d, %s", maxOpenConns, maxIdleConns, connMaxLifetime, tt.wantMaxOpenConns, tt.wantMaxIdleConns, tt.wantConnMaxLifetime)
+			}
+		})
+	}
+}
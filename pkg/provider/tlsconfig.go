@@ -0,0 +1,35 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+)
+
+// newTLSConfig returns the tls.Config used to connect to the server with the options of the tls_config block.
+func newTLSConfig(config *TLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{} //nolint:gosec
+	if config == nil {
+		return tlsConfig, nil
+	}
+
+	if !config.InsecureSkipVerify.IsNull() {
+		tlsConfig.InsecureSkipVerify = config.InsecureSkipVerify.ValueBool()
+	}
+
+	if !config.CACert.IsNull() {
+		caPEM, err := readPEM(config.CACert.ValueString())
+		if err != nil {
+			return nil, fmt.Errorf("invalid configuration: cannot read tls_ca_cert: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("invalid configuration: tls_ca_cert does not contain any PEM encoded certificate")
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
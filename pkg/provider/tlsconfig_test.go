@@ -0,0 +1,151 @@
+package provider
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func Test_newHTTPClientConfig_tlsCACert(t *testing.T) {
+	caPEM, _ := testClientCertificate(t, "private-ca")
+
+	dir := t.TempDir()
+	caPath := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(caPath, []byte(caPEM), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	wantPool := x509.NewCertPool()
+	wantPool.AppendCertsFromPEM([]byte(caPEM))
+
+	tests := []struct {
+		name               string
+		tlsConfig          *TLSConfig
+		wantRootCAs        bool
+		insecureSkipVerify bool
+		wantErr            bool
+	}{
+		{
+			name:      "No CA uses the system pool",
+			tlsConfig: nil,
+		},
+		{
+			name:        "Inline CA",
+			tlsConfig:   &TLSConfig{CACert: types.StringValue(caPEM)},
+			wantRootCAs: true,
+		},
+		{
+			name:        "CA file",
+			tlsConfig:   &TLSConfig{CACert: types.StringValue(caPath)},
+			wantRootCAs: true,
+		},
+		{
+			name: "CA along with insecure_skip_verify",
+			tlsConfig: &TLSConfig{
+				CACert:             types.StringValue(caPEM),
+				InsecureSkipVerify: types.BoolValue(true),
+			},
+			wantRootCAs:        true,
+			insecureSkipVerify: true,
+		},
+		{
+			name:      "Missing CA file",
+			tlsConfig: &TLSConfig{CACert: types.StringValue(filepath.Join(dir, "missing.pem"))},
+			wantErr:   true,
+		},
+		{
+			name:      "Invalid PEM",
+			tlsConfig: &TLSConfig{CACert: types.StringValue("-----BEGIN CERTIFICATE-----\nnot a certificate\n-----END CERTIFICATE-----\n")},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := Model{
+				Protocol: types.StringValue(protocolHTTPS),
+				Host:     types.StringValue("localhost"),
+				Port:     types.Int32Value(8443),
+				AuthConfig: AuthConfig{
+					Strategy: types.StringValue(authStrategyBasicAuth),
+					Username: types.StringValue("default"),
+					Password: types.StringNull(),
+				},
+				TLSConfig: tt.tlsConfig,
+			}
+
+			config, err := newHTTPClientConfig(data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newHTTPClientConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if config.TLSConfig == nil {
+				t.Fatalf("newHTTPClientConfig() TLSConfig is nil")
+			}
+			if tt.wantRootCAs && !config.TLSConfig.RootCAs.Equal(wantPool) {
+				t.Errorf("newHTTPClientConfig() RootCAs does not contain the configured CA")
+			}
+			if !tt.wantRootCAs && config.TLSConfig.RootCAs != nil {
+				t.Errorf("newHTTPClientConfig() RootCAs got = %v, want nil", config.TLSConfig.RootCAs)
+			}
+			if config.TLSConfig.InsecureSkipVerify != tt.insecureSkipVerify {
+				t.Errorf("newHTTPClientConfig() InsecureSkipVerify got = %v, want %v", config.TLSConfig.InsecureSkipVerify, tt.insecureSkipVerify)
+			}
+		})
+	}
+}
+
+func Test_newClickhouseClient_httpsPrivateCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+	port, err := strconv.Atoi(serverURL.Port())
+	if err != nil {
+		t.Fatalf("strconv.Atoi() error = %v", err)
+	}
+
+	data := Model{
+		Protocol: types.StringValue(protocolHTTPS),
+		Host:     types.StringValue(serverURL.Hostname()),
+		Port:     types.Int32Value(int32(port)),
+		AuthConfig: AuthConfig{
+			Strategy: types.StringValue(authStrategyBasicAuth),
+			Username: types.StringValue("default"),
+			Password: types.StringNull(),
+		},
+	}
+
+	client, err := (&Provider{}).newClickhouseClient(data)
+	if err != nil {
+		t.Fatalf("newClickhouseClient() error = %v", err)
+	}
+	if err := client.Exec(context.Background(), "SELECT 1"); err == nil {
+		t.Fatalf("Exec() expected error verifying a certificate signed by an unknown authority")
+	}
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	data.TLSConfig = &TLSConfig{CACert: types.StringValue(string(caPEM))}
+
+	client, err = (&Provider{}).newClickhouseClient(data)
+	if err != nil {
+		t.Fatalf("newClickhouseClient() error = %v", err)
+	}
+	if err := client.Exec(context.Background(), "SELECT 1"); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+}
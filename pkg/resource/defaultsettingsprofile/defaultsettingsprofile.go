@@ -0,0 +1,224 @@
+package defaultsettingsprofile
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
+)
+
+//go:embed defaultsettingsprofile.md
+var defaultSettingsProfileResourceDescription string
+
+var (
+	_ resource.Resource                = &Resource{}
+	_ resource.ResourceWithConfigure   = &Resource{}
+	_ resource.ResourceWithImportState = &Resource{}
+	_ resource.ResourceWithModifyPlan  = &Resource{}
+)
+
+func NewResource() resource.Resource {
+	return &Resource{}
+}
+
+type Resource struct {
+	client dbops.Client
+}
+
+func (r *Resource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_default_settings_profile"
+}
+
+func (r *Resource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"cluster_name": schema.StringAttribute{
+				Optional:    true,
+				Description: "Name of the cluster to create the resource into. If omitted, resource will be created on the replica hit by the query.\nThis field must be left null when using a ClickHouse Cloud cluster.\nWhen using a self hosted ClickHouse instance, this field should only be set when there is more than one replica and you are not using 'replicated' storage for user_directory.\n",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"settings_profile_id": schema.StringAttribute{
+				Required:    true,
+				Description: "ID of the settings profile to apply to all users and roles",
+			},
+		},
+		MarkdownDescription: defaultSettingsProfileResourceDescription,
+	}
+}
+
+func (r *Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		// If the entire plan is null, the resource is planned for destruction.
+		return
+	}
+
+	if r.client != nil {
+		var clusterName types.String
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("cluster_name"), &clusterName)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		isReplicatedStorage, err := r.client.IsReplicatedStorage(ctx, clusterName.ValueStringPointer())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Checking if service is using replicated storage",
+				fmt.Sprintf("%+v\n", err),
+			)
+			return
+		}
+
+		if isReplicatedStorage {
+			var config DefaultSettingsProfile
+			diags := req.Config.Get(ctx, &config)
+			resp.Diagnostics.Append(diags...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+
+			// DefaultSettingsProfile cannot specify 'cluster_name' or apply will fail.
+			if !config.ClusterName.IsNull() || r.client.DefaultClusterName() != nil {
+				resp.Diagnostics.AddWarning(
+					"Invalid configuration",
+					"Your ClickHouse cluster is using Replicated storage, please remove the 'cluster_name' attribute from your resource definition, or the 'default_cluster' provider attribute, if you encounter any errors.",
+				)
+			}
+		}
+	}
+}
+
+func (r *Resource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	r.client = req.ProviderData.(dbops.Client)
+}
+
+func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan DefaultSettingsProfile
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.SetProfileForAll(ctx, plan.SettingsProfileID.ValueString(), plan.ClusterName.ValueStringPointer())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Applying Settings Profile to All",
+			fmt.Sprintf("%+v\n", err),
+		)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *Resource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state DefaultSettingsProfile
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	profile, err := r.client.GetProfileForAll(ctx, state.ClusterName.ValueStringPointer())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Settings Profile Applied to All",
+			fmt.Sprintf("%+v\n", err),
+		)
+		return
+	}
+
+	if profile == nil || profile.ID != state.SettingsProfileID.ValueString() {
+		// The profile is no longer applied to all users and roles.
+		resp.State.RemoveResource(ctx)
+	}
+}
+
+func (r *Resource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state DefaultSettingsProfile
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Release the ALL binding before handing it to the new profile.
+	err := r.client.ClearProfileForAll(ctx, state.SettingsProfileID.ValueString(), state.ClusterName.ValueStringPointer())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Removing Settings Profile from All",
+			fmt.Sprintf("%+v\n", err),
+		)
+		return
+	}
+
+	err = r.client.SetProfileForAll(ctx, plan.SettingsProfileID.ValueString(), plan.ClusterName.ValueStringPointer())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Applying Settings Profile to All",
+			fmt.Sprintf("%+v\n", err),
+		)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state DefaultSettingsProfile
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.ClearProfileForAll(ctx, state.SettingsProfileID.ValueString(), state.ClusterName.ValueStringPointer())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Removing Settings Profile from All",
+			fmt.Sprintf("%+v\n", err),
+		)
+		return
+	}
+}
+
+func (r *Resource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// req.ID can either be in the form <cluster name>:<settings profile id> or just <settings profile id>
+
+	// Check if cluster name is specified
+	ref := req.ID
+	var clusterName *string
+	if strings.Contains(req.ID, ":") {
+		clusterName = &strings.Split(req.ID, ":")[0]
+		ref = strings.Split(req.ID, ":")[1]
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("settings_profile_id"), ref)...)
+
+	if clusterName != nil {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("cluster_name"), clusterName)...)
+	}
+}
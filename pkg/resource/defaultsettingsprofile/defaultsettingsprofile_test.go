@@ -0,0 +1,90 @@
+package defaultsettingsprofile_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/testutils/resourcebuilder"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/testutils/runner"
+)
+
+const (
+	resourceType = "clickhousedbops_default_settings_profile"
+	resourceName = "foo"
+)
+
+func TestDefaultSettingsProfile_acceptance(t *testing.T) {
+	settingsProfile := resourcebuilder.New("clickhousedbops_settings_profile", "profile1").
+		WithStringAttribute("name", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
+
+	checkNotExistsFunc := func(ctx context.Context, dbopsClient dbops.Client, clusterName *string, attrs map[string]string) (bool, error) {
+		settingsProfileID := attrs["settings_profile_id"]
+		if settingsProfileID == "" {
+			return false, fmt.Errorf("settings_profile_id attribute was not set")
+		}
+
+		profile, err := dbopsClient.GetProfileForAll(ctx, clusterName)
+		if err != nil {
+			return false, err
+		}
+
+		return profile != nil && profile.ID == settingsProfileID, nil
+	}
+
+	checkAttributesFunc := func(ctx context.Context, dbopsClient dbops.Client, clusterName *string, attrs map[string]interface{}) error {
+		settingsProfileID := attrs["settings_profile_id"]
+		if settingsProfileID == nil {
+			return fmt.Errorf("settings_profile_id attribute was not set")
+		}
+
+		profile, err := dbopsClient.GetProfileForAll(ctx, clusterName)
+		if err != nil {
+			return err
+		}
+
+		if profile == nil {
+			return fmt.Errorf("no settings profile is applied to all")
+		}
+
+		if profile.ID != settingsProfileID.(string) {
+			return fmt.Errorf("expected settings profile %q to be applied to all, was %q", settingsProfileID.(string), profile.ID)
+		}
+
+		return nil
+	}
+
+	tests := []runner.TestCase{
+		{
+			Name:     "Apply settings profile to all using Native protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "native",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithResourceFieldReference("settings_profile_id", "clickhousedbops_settings_profile", "profile1", "id").
+				AddDependency(settingsProfile.Build()).
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+		{
+			Name:     "Apply settings profile to all using HTTP protocol on a cluster using replicated storage",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-replicated.xml"},
+			Protocol: "http",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithResourceFieldReference("settings_profile_id", "clickhousedbops_settings_profile", "profile1", "id").
+				AddDependency(settingsProfile.Build()).
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+	}
+
+	runner.RunTests(t, tests)
+}
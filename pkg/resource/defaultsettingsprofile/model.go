@@ -0,0 +1,10 @@
+package defaultsettingsprofile
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type DefaultSettingsProfile struct {
+	ClusterName       types.String `tfsdk:"cluster_name"`
+	SettingsProfileID types.String `tfsdk:"settings_profile_id"`
+}
@@ -7,10 +7,12 @@ import (
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
@@ -150,6 +152,13 @@ func (r *Resource) Schema(_ context.Context, _ resource.SchemaRequest, resp *res
 					boolplanmodifier.RequiresReplace(),
 				},
 			},
+			"partial_revokes": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "Privileges revoked out of band from a subset of the granted privilege, for example `SELECT ON db.table` when `SELECT` was granted on the whole `db` database. The privilege is granted again on the next apply to restore it in full.",
+				// Always planned empty, so that any partial revoke found during read shows up as drift.
+				Default: listdefault.StaticValue(types.ListValueMust(types.StringType, []attr.Value{})),
+			},
 		},
 		MarkdownDescription: grantPrivilegeDescription,
 	}
@@ -191,7 +200,7 @@ func (r *Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanReques
 	}
 
 	if r.client != nil {
-		isReplicatedStorage, err := r.client.IsReplicatedStorage(ctx)
+		isReplicatedStorage, err := r.client.IsReplicatedStorage(ctx, config.ClusterName.ValueStringPointer())
 		if err != nil {
 			resp.Diagnostics.AddError(
 				"Error Checking if service is using replicated storage",
@@ -202,10 +211,10 @@ func (r *Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanReques
 
 		if isReplicatedStorage {
 			// GrantPrivilege cannot specify 'cluster_name' or apply will fail.
-			if !config.ClusterName.IsNull() {
+			if !config.ClusterName.IsNull() || r.client.DefaultClusterName() != nil {
 				resp.Diagnostics.AddWarning(
 					"Invalid configuration",
-					"Your ClickHouse cluster is using Replicated storage for grants, please remove the 'cluster_name' attribute from your GrantPrivilege resource definition if you encounter any errors.",
+					"Your ClickHouse cluster is using Replicated storage for grants, please remove the 'cluster_name' attribute from your GrantPrivilege resource definition, or the 'default_cluster' provider attribute, if you encounter any errors.",
 				)
 			}
 		}
@@ -337,6 +346,7 @@ This is a configuration error that prevents further actions. Please note that th
 		GranteeUserName: types.StringPointerValue(createdGrant.GranteeUserName),
 		GranteeRoleName: types.StringPointerValue(createdGrant.GranteeRoleName),
 		GrantOption:     types.BoolValue(createdGrant.GrantOption),
+		PartialRevokes:  types.ListValueMust(types.StringType, []attr.Value{}),
 	}
 
 	diags = resp.State.Set(ctx, state)
@@ -363,7 +373,8 @@ func (r *Resource) Read(ctx context.Context, req resource.ReadRequest, resp *res
 		return
 	}
 
-	if grant != nil {
+	// A partial revoke means the privilege was revoked from a broader grant, so it is not granted anymore.
+	if grant != nil && !grant.IsPartialRevoke {
 		state.Privilege = types.StringValue(grant.AccessType)
 		state.Database = types.StringPointerValue(grant.DatabaseName)
 		state.Table = types.StringPointerValue(grant.TableName)
@@ -372,6 +383,17 @@ func (r *Resource) Read(ctx context.Context, req resource.ReadRequest, resp *res
 		state.GranteeRoleName = types.StringPointerValue(grant.GranteeRoleName)
 		state.GrantOption = types.BoolValue(grant.GrantOption)
 
+		// The privilege is still granted, but parts of it might have been revoked since.
+		revokes, err := r.getPartialRevokes(ctx, state)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Reading ClickHouse Privilege Grant",
+				"Could not read partial revokes, unexpected error: "+err.Error(),
+			)
+			return
+		}
+		state.PartialRevokes = revokes
+
 		diags = resp.State.Set(ctx, &state)
 		resp.Diagnostics.Append(diags...)
 	} else {
@@ -379,8 +401,60 @@ func (r *Resource) Read(ctx context.Context, req resource.ReadRequest, resp *res
 	}
 }
 
+// Update restores a privilege that was partially revoked, since all the other attributes require replacement.
 func (r *Resource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	panic("Update of grant privilege resource is not supported")
+	var state GrantPrivilege
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Granting the privilege again clears the partial revokes carved out of it.
+	_, err := r.client.GrantPrivilege(ctx, dbops.GrantPrivilege{
+		AccessType:      state.Privilege.ValueString(),
+		DatabaseName:    state.Database.ValueStringPointer(),
+		TableName:       state.Table.ValueStringPointer(),
+		ColumnName:      state.Column.ValueStringPointer(),
+		GranteeUserName: state.GranteeUserName.ValueStringPointer(),
+		GranteeRoleName: state.GranteeRoleName.ValueStringPointer(),
+		GrantOption:     state.GrantOption.ValueBool(),
+	}, state.ClusterName.ValueStringPointer())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating ClickHouse Privilege Grant",
+			"Could not grant privilege again, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	revokes, err := r.getPartialRevokes(ctx, state)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating ClickHouse Privilege Grant",
+			"Could not read partial revokes, unexpected error: "+err.Error(),
+		)
+		return
+	}
+	state.PartialRevokes = revokes
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// getPartialRevokes returns the partial revokes carved out of the privilege granted by the resource.
+func (r *Resource) getPartialRevokes(ctx context.Context, state GrantPrivilege) (types.List, error) {
+	grants, err := r.client.GetAllGrantsForGrantee(ctx, state.GranteeUserName.ValueStringPointer(), state.GranteeRoleName.ValueStringPointer(), state.ClusterName.ValueStringPointer())
+	if err != nil {
+		return types.ListNull(types.StringType), err
+	}
+
+	values := make([]attr.Value, 0)
+	for _, revoke := range partialRevokes(state, grants) {
+		values = append(values, types.StringValue(revoke))
+	}
+
+	return types.ListValueMust(types.StringType, values), nil
 }
 
 func (r *Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
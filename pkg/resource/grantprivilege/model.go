@@ -13,4 +13,5 @@ type GrantPrivilege struct {
 	GranteeUserName types.String `tfsdk:"grantee_user_name"`
 	GranteeRoleName types.String `tfsdk:"grantee_role_name"`
 	GrantOption     types.Bool   `tfsdk:"grant_option"`
+	PartialRevokes  types.List   `tfsdk:"partial_revokes"`
 }
@@ -0,0 +1,74 @@
+package grantprivilege
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
+)
+
+// partialRevokes returns the partial revokes carved out of the privilege granted by the resource, among all the
+// grants of the same grantee, in a form such as "SELECT ON db.table".
+func partialRevokes(state GrantPrivilege, grants []dbops.GrantPrivilege) []string {
+	granted := dbops.GrantPrivilege{
+		AccessType:      state.Privilege.ValueString(),
+		DatabaseName:    state.Database.ValueStringPointer(),
+		TableName:       state.Table.ValueStringPointer(),
+		ColumnName:      state.Column.ValueStringPointer(),
+		GranteeUserName: state.GranteeUserName.ValueStringPointer(),
+		GranteeRoleName: state.GranteeRoleName.ValueStringPointer(),
+		GrantOption:     state.GrantOption.ValueBool(),
+	}
+
+	ret := make([]string, 0)
+	for _, g := range grants {
+		if !g.IsPartialRevoke {
+			continue
+		}
+
+		if g.GrantOption && !granted.GrantOption {
+			// Only the grant option was revoked, and the resource doesn't grant it.
+			continue
+		}
+
+		revoked := GrantPrivilege{
+			Privilege:       types.StringValue(g.AccessType),
+			Database:        types.StringPointerValue(g.DatabaseName),
+			Table:           types.StringPointerValue(g.TableName),
+			Column:          types.StringPointerValue(g.ColumnName),
+			GranteeUserName: types.StringPointerValue(g.GranteeUserName),
+			GranteeRoleName: types.StringPointerValue(g.GranteeRoleName),
+		}
+
+		// The revoke is carved out of the resource when the granted privilege covers the revoked one.
+		if overlaps(revoked, granted) {
+			ret = append(ret, describePartialRevoke(g))
+		}
+	}
+
+	return ret
+}
+
+func describePartialRevoke(g dbops.GrantPrivilege) string {
+	privilege := g.AccessType
+	if g.ColumnName != nil {
+		privilege = fmt.Sprintf("%s(%s)", privilege, *g.ColumnName)
+	}
+
+	if g.GrantOption {
+		privilege = fmt.Sprintf("GRANT OPTION FOR %s", privilege)
+	}
+
+	database := "*"
+	if g.DatabaseName != nil {
+		database = *g.DatabaseName
+	}
+
+	table := "*"
+	if g.TableName != nil {
+		table = *g.TableName
+	}
+
+	return fmt.Sprintf("%s ON %s.%s", privilege, database, table)
+}
@@ -0,0 +1,129 @@
+package grantprivilege
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
+)
+
+func Test_partialRevokes(t *testing.T) {
+	state := GrantPrivilege{
+		Privilege:       types.StringValue("SELECT"),
+		Database:        types.StringValue("db"),
+		Table:           types.StringNull(),
+		Column:          types.StringNull(),
+		GranteeUserName: types.StringValue("john"),
+		GranteeRoleName: types.StringNull(),
+		GrantOption:     types.BoolValue(false),
+	}
+
+	grant := dbops.GrantPrivilege{
+		AccessType:      "SELECT",
+		DatabaseName:    toStrPtr("db"),
+		GranteeUserName: toStrPtr("john"),
+	}
+
+	tests := []struct {
+		name   string
+		state  GrantPrivilege
+		grants []dbops.GrantPrivilege
+		want   []string
+	}{
+		{
+			name:   "No partial revokes",
+			state:  state,
+			grants: []dbops.GrantPrivilege{grant},
+			want:   []string{},
+		},
+		{
+			name:  "Table revoked from database grant",
+			state: state,
+			grants: []dbops.GrantPrivilege{
+				grant,
+				{AccessType: "SELECT", DatabaseName: toStrPtr("db"), TableName: toStrPtr("secret"), GranteeUserName: toStrPtr("john"), IsPartialRevoke: true},
+			},
+			want: []string{"SELECT ON db.secret"},
+		},
+		{
+			name:  "Column revoked from database grant",
+			state: state,
+			grants: []dbops.GrantPrivilege{
+				grant,
+				{AccessType: "SELECT", DatabaseName: toStrPtr("db"), TableName: toStrPtr("users"), ColumnName: toStrPtr("password"), GranteeUserName: toStrPtr("john"), IsPartialRevoke: true},
+			},
+			want: []string{"SELECT(password) ON db.users"},
+		},
+		{
+			name:  "Revoke in another database",
+			state: state,
+			grants: []dbops.GrantPrivilege{
+				grant,
+				{AccessType: "SELECT", DatabaseName: toStrPtr("other"), TableName: toStrPtr("secret"), GranteeUserName: toStrPtr("john"), IsPartialRevoke: true},
+			},
+			want: []string{},
+		},
+		{
+			name:  "Revoke of another privilege",
+			state: state,
+			grants: []dbops.GrantPrivilege{
+				grant,
+				{AccessType: "INSERT", DatabaseName: toStrPtr("db"), TableName: toStrPtr("secret"), GranteeUserName: toStrPtr("john"), IsPartialRevoke: true},
+			},
+			want: []string{},
+		},
+		{
+			name:  "Grant option revoked when not granted",
+			state: state,
+			grants: []dbops.GrantPrivilege{
+				grant,
+				{AccessType: "SELECT", DatabaseName: toStrPtr("db"), TableName: toStrPtr("secret"), GranteeUserName: toStrPtr("john"), GrantOption: true, IsPartialRevoke: true},
+			},
+			want: []string{},
+		},
+		{
+			name: "Grant option revoked when granted",
+			state: GrantPrivilege{
+				Privilege:       types.StringValue("SELECT"),
+				Database:        types.StringValue("db"),
+				Table:           types.StringNull(),
+				Column:          types.StringNull(),
+				GranteeUserName: types.StringValue("john"),
+				GranteeRoleName: types.StringNull(),
+				GrantOption:     types.BoolValue(true),
+			},
+			grants: []dbops.GrantPrivilege{
+				{AccessType: "SELECT", DatabaseName: toStrPtr("db"), GranteeUserName: toStrPtr("john"), GrantOption: true},
+				{AccessType: "SELECT", DatabaseName: toStrPtr("db"), TableName: toStrPtr("secret"), GranteeUserName: toStrPtr("john"), GrantOption: true, IsPartialRevoke: true},
+			},
+			want: []string{"GRANT OPTION FOR SELECT ON db.secret"},
+		},
+		{
+			name: "Database revoked from global grant",
+			state: GrantPrivilege{
+				Privilege:       types.StringValue("SELECT"),
+				Database:        types.StringNull(),
+				Table:           types.StringNull(),
+				Column:          types.StringNull(),
+				GranteeUserName: types.StringNull(),
+				GranteeRoleName: types.StringValue("reader"),
+				GrantOption:     types.BoolValue(false),
+			},
+			grants: []dbops.GrantPrivilege{
+				{AccessType: "SELECT", GranteeRoleName: toStrPtr("reader")},
+				{AccessType: "SELECT", DatabaseName: toStrPtr("system"), GranteeRoleName: toStrPtr("reader"), IsPartialRevoke: true},
+			},
+			want: []string{"SELECT ON system.*"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := partialRevokes(tt.state, tt.grants)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("partialRevokes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
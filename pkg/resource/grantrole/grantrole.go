@@ -57,9 +57,13 @@ func (r *Resource) Schema(_ context.Context, _ resource.SchemaRequest, resp *res
 			},
 			"role_name": schema.StringAttribute{
 				Required:    true,
-				Description: "Name of the role to be granted",
+				Description: "Name of the role to be granted. Can be changed without recreating the resource: if the granted role was renamed to the new name, only the state is updated, otherwise the new role is granted and the previous one revoked.",
+			},
+			"role_id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The system-assigned ID of the granted role, used to find the grant again after the role is renamed.",
 				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
+					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
 			"grantee_user_name": schema.StringAttribute{
@@ -93,9 +97,20 @@ func (r *Resource) Schema(_ context.Context, _ resource.SchemaRequest, resp *res
 			"admin_option": schema.BoolAttribute{
 				Optional:    true,
 				Computed:    true,
-				Description: "If true, the grantee will be able to grant `role_name` to other `users` or `roles`.",
+				Description: "If true, the grantee will be able to grant `role_name` to other `users` or `roles`. Can be changed without revoking the role.",
 				PlanModifiers: []planmodifier.Bool{
-					boolplanmodifier.RequiresReplace(),
+					// When not configured, the admin option read from the server is kept, instead of being revoked
+					// whenever another attribute changes.
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"grantee_settings_profile": schema.StringAttribute{
+				Optional:    true,
+				Description: "Name of a `settings profile` to add to the settings profiles of `grantee_user_name` together with the grant. It is removed when the grant is revoked. Can only be set when granting to a user, and can be changed without revoking the role.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+					stringvalidator.ConflictsWith(path.Expressions{path.MatchRoot("grantee_role_name")}...),
+					stringvalidator.AlsoRequires(path.Expressions{path.MatchRoot("grantee_user_name")}...),
 				},
 			},
 		},
@@ -109,8 +124,31 @@ func (r *Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanReques
 		return
 	}
 
+	if !req.State.Raw.IsNull() {
+		var plan, state GrantRole
+		resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if !plan.RoleName.Equal(state.RoleName) {
+			if state.RoleID.IsNull() {
+				// Without the ID of the granted role, the grant can't be followed to another role.
+				resp.RequiresReplace = append(resp.RequiresReplace, path.Root("role_name"))
+			}
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("role_id"), types.StringUnknown())...)
+		}
+	}
+
 	if r.client != nil {
-		isReplicatedStorage, err := r.client.IsReplicatedStorage(ctx)
+		var clusterName types.String
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("cluster_name"), &clusterName)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		isReplicatedStorage, err := r.client.IsReplicatedStorage(ctx, clusterName.ValueStringPointer())
 		if err != nil {
 			resp.Diagnostics.AddError(
 				"Error Checking if service is using replicated storage",
@@ -128,10 +166,10 @@ func (r *Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanReques
 			}
 
 			// GrantRole cannot specify 'cluster_name' or apply will fail.
-			if !config.ClusterName.IsNull() {
+			if !config.ClusterName.IsNull() || r.client.DefaultClusterName() != nil {
 				resp.Diagnostics.AddWarning(
 					"Invalid configuration",
-					"Your ClickHouse cluster is using Replicated storage for role grants, please remove the 'cluster_name' attribute from your GrantRole resource definition if you encounter any errors.",
+					"Your ClickHouse cluster is using Replicated storage for role grants, please remove the 'cluster_name' attribute from your GrantRole resource definition, or the 'default_cluster' provider attribute, if you encounter any errors.",
 				)
 			}
 		}
@@ -172,6 +210,39 @@ func makeGrantID(cluster *string, role string, user *string, granteeRole *string
 	return types.StringValue(hex.EncodeToString(sum[:]))
 }
 
+// grantedRoleName returns the current name of the granted role, or nil if the role doesn't exist anymore.
+// The role is looked up by ID when known, so that the grant is still found after the role is renamed.
+func (r *Resource) grantedRoleName(ctx context.Context, state GrantRole) (*string, error) {
+	if state.RoleID.IsNull() || state.RoleID.IsUnknown() {
+		return state.RoleName.ValueStringPointer(), nil
+	}
+
+	role, err := r.client.GetRole(ctx, state.RoleID.ValueString(), state.ClusterName.ValueStringPointer())
+	if err != nil {
+		return nil, err
+	}
+
+	if role == nil {
+		return nil, nil
+	}
+
+	return &role.Name, nil
+}
+
+// grantedRoleID returns the ID of the role with the given name, or null if there is no such role.
+func (r *Resource) grantedRoleID(ctx context.Context, roleName string, clusterName *string) (types.String, error) {
+	role, err := r.client.FindRoleByName(ctx, roleName, clusterName)
+	if err != nil {
+		return types.StringNull(), err
+	}
+
+	if role == nil {
+		return types.StringNull(), nil
+	}
+
+	return types.StringValue(role.ID), nil
+}
+
 func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var plan GrantRole
 	diags := req.Plan.Get(ctx, &plan)
@@ -187,18 +258,30 @@ func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp
 		AdminOption:     plan.AdminOption.ValueBool(),
 	}
 
-	createdGrant, err := r.client.GrantRole(ctx, grant, plan.ClusterName.ValueStringPointer())
+	var createdGrant *dbops.GrantRole
+	var err error
+	if !plan.GranteeSettingsProfile.IsNull() {
+		createdGrant, err = r.client.GrantRoleWithSettingsProfile(ctx, grant, plan.GranteeSettingsProfile.ValueString(), plan.ClusterName.ValueStringPointer())
+	} else {
+		createdGrant, err = r.client.GrantRole(ctx, grant, plan.ClusterName.ValueStringPointer())
+	}
 	if err != nil {
 		resp.Diagnostics.AddError("Error Creating ClickHouse Role Grant", fmt.Sprintf("%+v\n", err))
 		return
 	}
 
 	state := GrantRole{
-		ClusterName:     plan.ClusterName,
-		RoleName:        types.StringValue(createdGrant.RoleName),
-		GranteeUserName: types.StringPointerValue(createdGrant.GranteeUserName),
-		GranteeRoleName: types.StringPointerValue(createdGrant.GranteeRoleName),
-		AdminOption:     types.BoolValue(createdGrant.AdminOption),
+		ClusterName:            plan.ClusterName,
+		RoleName:               types.StringValue(createdGrant.RoleName),
+		GranteeUserName:        types.StringPointerValue(createdGrant.GranteeUserName),
+		GranteeRoleName:        types.StringPointerValue(createdGrant.GranteeRoleName),
+		AdminOption:            types.BoolValue(createdGrant.AdminOption),
+		GranteeSettingsProfile: plan.GranteeSettingsProfile,
+	}
+	state.RoleID, err = r.grantedRoleID(ctx, createdGrant.RoleName, plan.ClusterName.ValueStringPointer())
+	if err != nil {
+		resp.Diagnostics.AddError("Error Reading ClickHouse Role", fmt.Sprintf("%+v\n", err))
+		return
 	}
 	state.ID = makeGrantID(state.ClusterName.ValueStringPointer(), state.RoleName.ValueString(), state.GranteeUserName.ValueStringPointer(), state.GranteeRoleName.ValueStringPointer(), state.AdminOption.ValueBool())
 
@@ -214,7 +297,19 @@ func (r *Resource) Read(ctx context.Context, req resource.ReadRequest, resp *res
 		return
 	}
 
-	grant, err := r.client.GetGrantRole(ctx, state.RoleName.ValueString(), state.GranteeUserName.ValueStringPointer(), state.GranteeRoleName.ValueStringPointer(), state.ClusterName.ValueStringPointer())
+	roleName, err := r.grantedRoleName(ctx, state)
+	if err != nil {
+		resp.Diagnostics.AddError("Error Reading ClickHouse Role", fmt.Sprintf("%+v\n", err))
+		return
+	}
+
+	if roleName == nil {
+		// The granted role was dropped, and its grants with it.
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	grant, err := r.client.GetGrantRole(ctx, *roleName, state.GranteeUserName.ValueStringPointer(), state.GranteeRoleName.ValueStringPointer(), state.ClusterName.ValueStringPointer())
 	if err != nil {
 		resp.Diagnostics.AddError("Error Reading ClickHouse Role Grant", fmt.Sprintf("%+v\n", err))
 		return
@@ -225,18 +320,133 @@ func (r *Resource) Read(ctx context.Context, req resource.ReadRequest, resp *res
 		return
 	}
 
-	state.RoleName = types.StringValue(grant.RoleName)
+	if state.RoleID.IsNull() {
+		// State written before the role ID was tracked.
+		state.RoleID, err = r.grantedRoleID(ctx, grant.RoleName, state.ClusterName.ValueStringPointer())
+		if err != nil {
+			resp.Diagnostics.AddError("Error Reading ClickHouse Role", fmt.Sprintf("%+v\n", err))
+			return
+		}
+	}
+
+	// role_name is left as is when the role was renamed out of band, since the grant still exists and follows the role.
 	state.GranteeUserName = types.StringPointerValue(grant.GranteeUserName)
 	state.GranteeRoleName = types.StringPointerValue(grant.GranteeRoleName)
 	state.AdminOption = types.BoolValue(grant.AdminOption)
 	state.ID = makeGrantID(state.ClusterName.ValueStringPointer(), state.RoleName.ValueString(), state.GranteeUserName.ValueStringPointer(), state.GranteeRoleName.ValueStringPointer(), state.AdminOption.ValueBool())
 
+	if !state.GranteeSettingsProfile.IsNull() && grant.GranteeUserName != nil {
+		user, err := r.client.GetUserByName(ctx, *grant.GranteeUserName, state.ClusterName.ValueStringPointer())
+		if err != nil {
+			resp.Diagnostics.AddError("Error Reading ClickHouse User", fmt.Sprintf("%+v\n", err))
+			return
+		}
+
+		if user == nil || !user.HasSettingProfile(state.GranteeSettingsProfile.ValueString()) {
+			// Profile was removed out of band, plan to add it back.
+			state.GranteeSettingsProfile = types.StringNull()
+		}
+	}
+
 	diags = resp.State.Set(ctx, state)
 	resp.Diagnostics.Append(diags...)
 }
 
 func (r *Resource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	panic("Update of grant resource is not supported")
+	var plan, state GrantRole
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// All the other attributes require replacement, so only role_name, admin_option and grantee_settings_profile can
+	// change here.
+	roleName, err := r.grantedRoleName(ctx, state)
+	if err != nil {
+		resp.Diagnostics.AddError("Error Reading ClickHouse Role", fmt.Sprintf("%+v\n", err))
+		return
+	}
+
+	if roleName == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	if *roleName != plan.RoleName.ValueString() {
+		// The grant now targets another role: grant it and revoke the previous one. When the granted role was only
+		// renamed, the names match and there is nothing to change on the server.
+		_, err = r.client.GrantRole(ctx, dbops.GrantRole{
+			RoleName:        plan.RoleName.ValueString(),
+			GranteeUserName: state.GranteeUserName.ValueStringPointer(),
+			GranteeRoleName: state.GranteeRoleName.ValueStringPointer(),
+			AdminOption:     plan.AdminOption.ValueBool(),
+		}, state.ClusterName.ValueStringPointer())
+		if err != nil {
+			resp.Diagnostics.AddError("Error Creating ClickHouse Role Grant", fmt.Sprintf("%+v\n", err))
+			return
+		}
+
+		err = r.client.RevokeGrantRole(ctx, *roleName, state.GranteeUserName.ValueStringPointer(), state.GranteeRoleName.ValueStringPointer(), state.ClusterName.ValueStringPointer())
+		if err != nil {
+			resp.Diagnostics.AddError("Error Deleting ClickHouse Role Grant", fmt.Sprintf("%+v\n", err))
+			return
+		}
+	}
+
+	profileChanged := !plan.GranteeSettingsProfile.Equal(state.GranteeSettingsProfile)
+	if profileChanged {
+		_, err := r.client.UpdateUserSettingsProfile(ctx, state.GranteeUserName.ValueString(), state.GranteeSettingsProfile.ValueStringPointer(), plan.GranteeSettingsProfile.ValueStringPointer(), state.ClusterName.ValueStringPointer())
+		if err != nil {
+			resp.Diagnostics.AddError("Error Updating ClickHouse User Settings Profile", fmt.Sprintf("%+v\n", err))
+			return
+		}
+	}
+
+	grant, err := r.client.UpdateGrantRole(ctx, dbops.GrantRole{
+		RoleName:        plan.RoleName.ValueString(),
+		GranteeUserName: state.GranteeUserName.ValueStringPointer(),
+		GranteeRoleName: state.GranteeRoleName.ValueStringPointer(),
+		AdminOption:     plan.AdminOption.ValueBool(),
+	}, state.ClusterName.ValueStringPointer())
+	if err != nil {
+		if profileChanged {
+			// Roll back the settings profile change, so that the grant and the profile stay consistent.
+			_, rollbackErr := r.client.UpdateUserSettingsProfile(ctx, state.GranteeUserName.ValueString(), plan.GranteeSettingsProfile.ValueStringPointer(), state.GranteeSettingsProfile.ValueStringPointer(), state.ClusterName.ValueStringPointer())
+			if rollbackErr != nil {
+				resp.Diagnostics.AddError("Error Rolling Back ClickHouse User Settings Profile", fmt.Sprintf("%+v\n", rollbackErr))
+			}
+		}
+		resp.Diagnostics.AddError("Error Updating ClickHouse Role Grant", fmt.Sprintf("%+v\n", err))
+		return
+	}
+
+	if grant == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.RoleName = types.StringValue(grant.RoleName)
+	state.RoleID = plan.RoleID
+	if state.RoleID.IsUnknown() {
+		state.RoleID, err = r.grantedRoleID(ctx, grant.RoleName, state.ClusterName.ValueStringPointer())
+		if err != nil {
+			resp.Diagnostics.AddError("Error Reading ClickHouse Role", fmt.Sprintf("%+v\n", err))
+			return
+		}
+	}
+	state.AdminOption = types.BoolValue(grant.AdminOption)
+	state.GranteeSettingsProfile = plan.GranteeSettingsProfile
+	state.ID = makeGrantID(state.ClusterName.ValueStringPointer(), state.RoleName.ValueString(), state.GranteeUserName.ValueStringPointer(), state.GranteeRoleName.ValueStringPointer(), state.AdminOption.ValueBool())
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
 }
 
 func (r *Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -247,7 +457,22 @@ func (r *Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp
 		return
 	}
 
-	err := r.client.RevokeGrantRole(ctx, state.RoleName.ValueString(), state.GranteeUserName.ValueStringPointer(), state.GranteeRoleName.ValueStringPointer(), state.ClusterName.ValueStringPointer())
+	roleName, err := r.grantedRoleName(ctx, state)
+	if err != nil {
+		resp.Diagnostics.AddError("Error Reading ClickHouse Role", fmt.Sprintf("%+v\n", err))
+		return
+	}
+
+	if roleName == nil {
+		// The granted role doesn't exist anymore, try with the name it was granted with.
+		roleName = state.RoleName.ValueStringPointer()
+	}
+
+	if !state.GranteeSettingsProfile.IsNull() && !state.GranteeUserName.IsNull() {
+		err = r.client.RevokeGrantRoleWithSettingsProfile(ctx, *roleName, state.GranteeUserName.ValueString(), state.GranteeSettingsProfile.ValueString(), state.ClusterName.ValueStringPointer())
+	} else {
+		err = r.client.RevokeGrantRole(ctx, *roleName, state.GranteeUserName.ValueStringPointer(), state.GranteeRoleName.ValueStringPointer(), state.ClusterName.ValueStringPointer())
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Deleting ClickHouse Role Grant",
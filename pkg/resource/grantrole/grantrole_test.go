@@ -16,8 +16,10 @@ const (
 	resourceName = "foo"
 
 	roleName        = "role1"
+	renamedRoleName = "role2"
 	granteeRoleName = "grantee"
 	granteeUserName = "user1"
+	profileName     = "profile1"
 )
 
 func TestGrantRole_acceptance(t *testing.T) {
@@ -32,6 +34,7 @@ func TestGrantRole_acceptance(t *testing.T) {
 		WithStringAttribute("name", granteeUserName).
 		WithFunction("password_sha256_hash_wo", "sha256", "test").
 		WithIntAttribute("password_sha256_hash_wo_version", 1)
+	profileResource := resourcebuilder.New("clickhousedbops_settings_profile", profileName).WithStringAttribute("name", profileName)
 
 	checkNotExistsFunc := func(ctx context.Context, dbopsClient dbops.Client, clusterName *string, attrs map[string]string) (bool, error) {
 		roleName := attrs["role_name"]
@@ -108,6 +111,17 @@ func TestGrantRole_acceptance(t *testing.T) {
 			return fmt.Errorf("wrong value for admin_option attribute")
 		}
 
+		if attrs["grantee_settings_profile"] != nil {
+			user, err := dbopsClient.GetUserByName(ctx, *granteeUserName, clusterName)
+			if err != nil {
+				return err
+			}
+
+			if user == nil || !user.HasSettingProfile(attrs["grantee_settings_profile"].(string)) {
+				return fmt.Errorf("settings profile %q was not set on the grantee user", attrs["grantee_settings_profile"].(string))
+			}
+		}
+
 		return nil
 	}
 
@@ -159,6 +173,80 @@ func TestGrantRole_acceptance(t *testing.T) {
 			CheckNotExistsFunc:  checkNotExistsFunc,
 			CheckAttributesFunc: checkAttributesFunc,
 		},
+		{
+			Name:     "Toggle admin option in place using Native protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "native",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithResourceFieldReference("role_name", "clickhousedbops_role", roleName, "name").
+				WithResourceFieldReference("grantee_user_name", "clickhousedbops_user", granteeUserName, "name").
+				WithBoolAttribute("admin_option", false).
+				AddDependency(roleResource.Build()).
+				AddDependency(granteeUserResource.Build()).
+				Build(),
+			UpdatedResource: resourcebuilder.New(resourceType, resourceName).
+				WithResourceFieldReference("role_name", "clickhousedbops_role", roleName, "name").
+				WithResourceFieldReference("grantee_user_name", "clickhousedbops_user", granteeUserName, "name").
+				WithBoolAttribute("admin_option", true).
+				AddDependency(roleResource.Build()).
+				AddDependency(granteeUserResource.Build()).
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+		{
+			Name:     "Follow granted role renamed out of band using Native protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "native",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithResourceFieldReference("role_name", "clickhousedbops_role", roleName, "name").
+				WithResourceFieldReference("grantee_user_name", "clickhousedbops_user", granteeUserName, "name").
+				AddDependency(roleResource.Build()).
+				AddDependency(granteeUserResource.Build()).
+				Build(),
+			PreUpdateFunc: func(ctx context.Context, dbopsClient dbops.Client, clusterName *string) error {
+				role, err := dbopsClient.FindRoleByName(ctx, roleName, clusterName)
+				if err != nil {
+					return err
+				}
+				if role == nil {
+					return fmt.Errorf("role %q was not found", roleName)
+				}
+
+				_, err = dbopsClient.UpdateRole(ctx, dbops.Role{ID: role.ID, Name: renamedRoleName}, clusterName)
+				return err
+			},
+			// The grant is updated in place, not recreated, when the configuration catches up with the new name.
+			UpdatedResource: resourcebuilder.New(resourceType, resourceName).
+				WithResourceFieldReference("role_name", "clickhousedbops_role", roleName, "name").
+				WithResourceFieldReference("grantee_user_name", "clickhousedbops_user", granteeUserName, "name").
+				AddDependency(resourcebuilder.New("clickhousedbops_role", roleName).WithStringAttribute("name", renamedRoleName).Build()).
+				AddDependency(granteeUserResource.Build()).
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+		{
+			Name:     "Grant role to user with settings profile using Native protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "native",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithResourceFieldReference("role_name", "clickhousedbops_role", roleName, "name").
+				WithResourceFieldReference("grantee_user_name", "clickhousedbops_user", granteeUserName, "name").
+				WithResourceFieldReference("grantee_settings_profile", "clickhousedbops_settings_profile", profileName, "name").
+				AddDependency(roleResource.Build()).
+				AddDependency(granteeUserResource.Build()).
+				AddDependency(profileResource.Build()).
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
 		// Single replica, HTTP
 		{
 			Name:     "Grant role to another role using HTTP protocol on a single replica",
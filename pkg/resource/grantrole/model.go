@@ -5,10 +5,12 @@ import (
 )
 
 type GrantRole struct {
-	ClusterName     types.String `tfsdk:"cluster_name"`
-	ID              types.String `tfsdk:"id"`
-	RoleName        types.String `tfsdk:"role_name"`
-	GranteeUserName types.String `tfsdk:"grantee_user_name"`
-	GranteeRoleName types.String `tfsdk:"grantee_role_name"`
-	AdminOption     types.Bool   `tfsdk:"admin_option"`
+	ClusterName            types.String `tfsdk:"cluster_name"`
+	ID                     types.String `tfsdk:"id"`
+	RoleName               types.String `tfsdk:"role_name"`
+	RoleID                 types.String `tfsdk:"role_id"`
+	GranteeUserName        types.String `tfsdk:"grantee_user_name"`
+	GranteeRoleName        types.String `tfsdk:"grantee_role_name"`
+	AdminOption            types.Bool   `tfsdk:"admin_option"`
+	GranteeSettingsProfile types.String `tfsdk:"grantee_settings_profile"`
 }
@@ -0,0 +1,121 @@
+// Package inlinesettings converts the settings declared inline in the role and settings profile resources.
+package inlinesettings
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
+)
+
+// Setting is the model of an element of the settings attribute.
+type Setting struct {
+	Name        types.String `tfsdk:"name"`
+	Value       types.String `tfsdk:"value"`
+	Min         types.String `tfsdk:"min"`
+	Max         types.String `tfsdk:"max"`
+	Writability types.String `tfsdk:"writability"`
+	Raw         types.Bool   `tfsdk:"raw"`
+}
+
+// AttrTypes are the attribute types of an element of the settings attribute.
+var AttrTypes = map[string]attr.Type{
+	"name":        types.StringType,
+	"value":       types.StringType,
+	"min":         types.StringType,
+	"max":         types.StringType,
+	"writability": types.StringType,
+	"raw":         types.BoolType,
+}
+
+// ToDBOps returns the dbops settings matching the given settings attribute, or nil when it is null or unknown.
+func ToDBOps(ctx context.Context, list types.List) ([]dbops.Setting, diag.Diagnostics) {
+	if list.IsNull() || list.IsUnknown() {
+		return nil, nil
+	}
+
+	var diags diag.Diagnostics
+	settings := make([]Setting, 0)
+	diags.Append(list.ElementsAs(ctx, &settings, false)...)
+
+	ret := make([]dbops.Setting, 0, len(settings))
+	for _, s := range settings {
+		ret = append(ret, dbops.Setting{
+			Name:        s.Name.ValueString(),
+			Value:       s.Value.ValueStringPointer(),
+			Min:         s.Min.ValueStringPointer(),
+			Max:         s.Max.ValueStringPointer(),
+			Writability: s.Writability.ValueStringPointer(),
+			Raw:         s.Raw.ValueBool(),
+		})
+	}
+
+	return ret, diags
+}
+
+// FromDBOps returns the settings attribute matching the settings read from ClickHouse. The values of the configured
+// settings that ClickHouse reports differently (e.g. true as 1) keep their configured spelling, and raw, which is
+// never reported, is kept as configured.
+func FromDBOps(configured types.List, settings []dbops.Setting) types.List {
+	elements := make([]attr.Value, 0, len(settings))
+	for i, s := range settings {
+		element, _ := types.ObjectValue(AttrTypes, map[string]attr.Value{
+			"name":        types.StringValue(s.Name),
+			"value":       types.StringPointerValue(dbops.SettingValue(configuredValue(configured, i, s.Name, "value"), s.Value)),
+			"min":         types.StringPointerValue(dbops.SettingValue(configuredValue(configured, i, s.Name, "min"), s.Min)),
+			"max":         types.StringPointerValue(dbops.SettingValue(configuredValue(configured, i, s.Name, "max"), s.Max)),
+			"writability": types.StringPointerValue(s.Writability),
+			"raw":         configuredRaw(configured, i, s.Name),
+		})
+		elements = append(elements, element)
+	}
+
+	list, _ := types.ListValue(types.ObjectType{AttrTypes: AttrTypes}, elements)
+	return list
+}
+
+// configuredValue returns the given attribute of the setting at index idx of the settings list, if that setting has
+// the given name.
+func configuredValue(settings types.List, idx int, name string, attribute string) *string {
+	value, ok := configuredAttribute(settings, idx, name, attribute).(types.String)
+	if !ok {
+		return nil
+	}
+
+	return value.ValueStringPointer()
+}
+
+// configuredRaw returns the raw attribute of the setting at index idx of the settings list, if that setting has the
+// given name, or null.
+func configuredRaw(settings types.List, idx int, name string) types.Bool {
+	raw, ok := configuredAttribute(settings, idx, name, "raw").(types.Bool)
+	if !ok {
+		return types.BoolNull()
+	}
+
+	return raw
+}
+
+// configuredAttribute returns the given attribute of the setting at index idx of the settings list, if that setting
+// has the given name, or nil.
+func configuredAttribute(settings types.List, idx int, name string, attribute string) attr.Value {
+	elements := settings.Elements()
+	if idx >= len(elements) {
+		return nil
+	}
+
+	element, ok := elements[idx].(types.Object)
+	if !ok {
+		return nil
+	}
+
+	attributes := element.Attributes()
+	if settingName, ok := attributes["name"].(types.String); !ok || settingName.ValueString() != name {
+		return nil
+	}
+
+	return attributes[attribute]
+}
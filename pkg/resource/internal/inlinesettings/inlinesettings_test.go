@@ -0,0 +1,95 @@
+package inlinesettings
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
+)
+
+func Test_FromDBOps(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+	element := func(name string, value string, raw types.Bool) attr.Value {
+		obj, _ := types.ObjectValue(AttrTypes, map[string]attr.Value{
+			"name":        types.StringValue(name),
+			"value":       types.StringValue(value),
+			"min":         types.StringNull(),
+			"max":         types.StringNull(),
+			"writability": types.StringNull(),
+			"raw":         raw,
+		})
+		return obj
+	}
+	list := func(elements ...attr.Value) types.List {
+		l, _ := types.ListValue(types.ObjectType{AttrTypes: AttrTypes}, elements)
+		return l
+	}
+
+	tests := []struct {
+		name       string
+		configured types.List
+		reported   []dbops.Setting
+		want       types.List
+	}{
+		{
+			name:       "Configured spelling is kept",
+			configured: list(element("log_queries", "true", types.BoolNull())),
+			reported:   []dbops.Setting{{Name: "log_queries", Value: strPtr("1")}},
+			want:       list(element("log_queries", "true", types.BoolNull())),
+		},
+		{
+			name:       "Changed value is reported",
+			configured: list(element("max_threads", "4", types.BoolNull())),
+			reported:   []dbops.Setting{{Name: "max_threads", Value: strPtr("8")}},
+			want:       list(element("max_threads", "8", types.BoolNull())),
+		},
+		{
+			name:       "Different setting at the same index is reported",
+			configured: list(element("log_queries", "true", types.BoolNull())),
+			reported:   []dbops.Setting{{Name: "readonly", Value: strPtr("1")}},
+			want:       list(element("readonly", "1", types.BoolNull())),
+		},
+		{
+			name:       "Raw is kept as configured",
+			configured: list(element("SQL_tenant", "42", types.BoolValue(true))),
+			reported:   []dbops.Setting{{Name: "SQL_tenant", Value: strPtr("42")}},
+			want:       list(element("SQL_tenant", "42", types.BoolValue(true))),
+		},
+		{
+			name:       "Raw of a different setting at the same index is not kept",
+			configured: list(element("SQL_tenant", "42", types.BoolValue(true))),
+			reported:   []dbops.Setting{{Name: "readonly", Value: strPtr("1")}},
+			want:       list(element("readonly", "1", types.BoolNull())),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FromDBOps(tt.configured, tt.reported)
+			if !got.Equal(tt.want) {
+				t.Errorf("FromDBOps() = %v, want %v", got, tt.want)
+			}
+
+			settings, diags := ToDBOps(context.Background(), got)
+			if diags.HasError() {
+				t.Fatalf("ToDBOps() diags = %v", diags)
+			}
+			if len(settings) != len(tt.reported) || settings[0].Name != tt.reported[0].Name {
+				t.Errorf("ToDBOps() = %+v, want the settings named as %+v", settings, tt.reported)
+			}
+		})
+	}
+}
+
+func Test_ToDBOps_null(t *testing.T) {
+	settings, diags := ToDBOps(context.Background(), types.ListNull(types.ObjectType{AttrTypes: AttrTypes}))
+	if diags.HasError() {
+		t.Fatalf("ToDBOps() diags = %v", diags)
+	}
+	if !reflect.DeepEqual(settings, []dbops.Setting(nil)) {
+		t.Errorf("ToDBOps() = %+v, want nil", settings)
+	}
+}
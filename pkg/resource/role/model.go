@@ -8,4 +8,8 @@ type Role struct {
 	ClusterName types.String `tfsdk:"cluster_name"`
 	ID          types.String `tfsdk:"id"`
 	Name        types.String `tfsdk:"name"`
+	Settings    types.List   `tfsdk:"settings"`
+	Comment     types.String `tfsdk:"comment"`
+
+	PreventDestroyOnDrift types.Bool `tfsdk:"prevent_destroy_on_drift"`
 }
@@ -7,14 +7,19 @@ import (
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 
 	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/pkg/resource/internal/inlinesettings"
 )
 
 //go:embed role.md
@@ -57,19 +62,98 @@ func (r *Resource) Schema(_ context.Context, _ resource.SchemaRequest, resp *res
 				Required:    true,
 				Description: "Name of the role",
 			},
+			"settings": schema.ListNestedAttribute{
+				Optional:    true,
+				Description: "Settings of the role, in the order they are applied. When null, settings are not managed by this resource",
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Required:    true,
+							Description: "Name of the setting",
+						},
+						"value": schema.StringAttribute{
+							Optional:    true,
+							Description: "Value for the setting",
+						},
+						"min": schema.StringAttribute{
+							Optional:    true,
+							Description: "Min Value for the setting",
+						},
+						"max": schema.StringAttribute{
+							Optional:    true,
+							Description: "Max Value for the setting",
+						},
+						"writability": schema.StringAttribute{
+							Optional:    true,
+							Description: "Writability attribute for the setting",
+							Validators: []validator.String{
+								stringvalidator.OneOf(
+									"CONST",
+									"WRITABLE",
+									"CHANGEABLE_IN_READONLY",
+								),
+							},
+						},
+						"raw": schema.BoolAttribute{
+							Optional:    true,
+							Description: "When true, value, min and max are sent as they are, as SQL literals or expressions, rather than quoted as strings. When false, they are sent unquoted only for settings with a numeric or boolean type in system.settings, so the values of String and custom settings stay strings. Defaults to false",
+						},
+					},
+				},
+			},
+			"comment": schema.StringAttribute{
+				Optional:    true,
+				Description: "Comment of the role, for example to record the team owning it. When null, the comment is not managed by this resource. Comments on roles are only supported by recent ClickHouse versions: with older ones, this attribute is ignored and a warning is reported during plan",
+			},
+			"prevent_destroy_on_drift": schema.BoolAttribute{
+				Optional:    true,
+				Description: "When true, refreshing fails instead of dropping the role from the state when it can't be found with its ID anymore, but a role with the same name exists, e.g. because it was dropped and created again outside of Terraform. The role then needs to be reviewed, and imported again or removed from the state manually. Defaults to false.",
+			},
 		},
 		MarkdownDescription: roleResourceDescription,
 	}
 }
 
 func (r *Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Changing or dropping a role kept in a read-only user directory, e.g. users.xml, would fail on apply.
+	if r.client != nil && !req.State.Raw.IsNull() && !req.Plan.Raw.Equal(req.State.Raw) {
+		var name, clusterName types.String
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("name"), &name)...)
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("cluster_name"), &clusterName)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		storage, err := r.client.ReadOnlyAccessStorage(ctx, dbops.AccessEntityRole, name.ValueString(), clusterName.ValueStringPointer())
+		if err != nil {
+			resp.Diagnostics.AddError("Error Checking the Access Storage of the Role", fmt.Sprintf("%+v\n", err))
+			return
+		}
+		if storage != "" {
+			resp.Diagnostics.AddError(
+				"Role Kept in a Read-Only Access Storage",
+				fmt.Sprintf("The role %q is kept in the read-only %q user directory, so it can't be changed or destroyed with SQL queries. Change it in the server configuration instead, or remove it from the Terraform state.", name.ValueString(), storage),
+			)
+			return
+		}
+	}
+
 	if req.Plan.Raw.IsNull() {
 		// If the entire plan is null, the resource is planned for destruction.
 		return
 	}
 
 	if r.client != nil {
-		isReplicatedStorage, err := r.client.IsReplicatedStorage(ctx)
+		var clusterName types.String
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("cluster_name"), &clusterName)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		isReplicatedStorage, err := r.client.IsReplicatedStorage(ctx, clusterName.ValueStringPointer())
 		if err != nil {
 			resp.Diagnostics.AddError(
 				"Error Checking if service is using replicated storage",
@@ -78,6 +162,31 @@ func (r *Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanReques
 			return
 		}
 
+		var comment types.String
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("comment"), &comment)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if !comment.IsNull() {
+			supported, err := r.client.SupportsRoleComment(ctx, clusterName.ValueStringPointer())
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error Checking if service supports comments on roles",
+					fmt.Sprintf("%+v\n", err),
+				)
+				return
+			}
+
+			if !supported {
+				resp.Diagnostics.AddAttributeWarning(
+					path.Root("comment"),
+					"Role comments not supported",
+					"Your ClickHouse version doesn't support comments on roles, the 'comment' attribute will be ignored.",
+				)
+			}
+		}
+
 		if isReplicatedStorage {
 			var config Role
 			diags := req.Config.Get(ctx, &config)
@@ -87,10 +196,10 @@ func (r *Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanReques
 			}
 
 			// Role cannot specify 'cluster_name' or apply will fail.
-			if !config.ClusterName.IsNull() {
+			if !config.ClusterName.IsNull() || r.client.DefaultClusterName() != nil {
 				resp.Diagnostics.AddWarning(
 					"Invalid configuration",
-					"Your ClickHouse cluster is using Replicated storage for roles, please remove the 'cluster_name' attribute from your Role resource definition if you encounter any errors.",
+					"Your ClickHouse cluster is using Replicated storage for roles, please remove the 'cluster_name' attribute from your Role resource definition, or the 'default_cluster' provider attribute, if you encounter any errors.",
 				)
 			}
 		}
@@ -113,7 +222,13 @@ func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp
 		return
 	}
 
-	createdRole, err := r.client.CreateRole(ctx, dbops.Role{Name: plan.Name.ValueString()}, plan.ClusterName.ValueStringPointer())
+	role, diags := roleFromModel(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createdRole, err := r.client.CreateRole(ctx, role, plan.ClusterName.ValueStringPointer())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Creating ClickHouse Role",
@@ -124,10 +239,14 @@ func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp
 
 	state := Role{
 		ClusterName: plan.ClusterName,
-		ID:          types.StringValue(createdRole.ID),
-		Name:        types.StringValue(createdRole.Name),
+		Settings:    plan.Settings,
+		Comment:     plan.Comment,
+
+		PreventDestroyOnDrift: plan.PreventDestroyOnDrift,
 	}
 
+	modelFromApiResponse(&state, *createdRole)
+
 	diags = resp.State.Set(ctx, state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -153,13 +272,33 @@ func (r *Resource) Read(ctx context.Context, req resource.ReadRequest, resp *res
 	}
 
 	if role != nil {
-		state.Name = types.StringValue(role.Name)
+		modelFromApiResponse(&state, *role)
 
 		diags = resp.State.Set(ctx, &state)
 		resp.Diagnostics.Append(diags...)
-	} else {
-		resp.State.RemoveResource(ctx)
+		return
 	}
+
+	if state.PreventDestroyOnDrift.ValueBool() {
+		// A role with the same name but another ID was changed outside of Terraform rather than deleted.
+		id, err := r.client.AccessEntityID(ctx, dbops.AccessEntityRole, state.Name.ValueString(), state.ClusterName.ValueStringPointer())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Reading ClickHouse Role",
+				fmt.Sprintf("%+v\n", err),
+			)
+			return
+		}
+		if id != "" {
+			resp.Diagnostics.AddError(
+				"ClickHouse Role Changed Outside of Terraform",
+				fmt.Sprintf("The role %q was not found with ID %q, but a role with the same name exists with ID %q. Review the role, then import it again or remove it from the Terraform state, or set prevent_destroy_on_drift to false to replace it.", state.Name.ValueString(), state.ID.ValueString(), id),
+			)
+			return
+		}
+	}
+
+	resp.State.RemoveResource(ctx)
 }
 
 func (r *Resource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
@@ -176,11 +315,14 @@ func (r *Resource) Update(ctx context.Context, req resource.UpdateRequest, resp
 		return
 	}
 
-	// Settings profile is changed.
-	role, err := r.client.UpdateRole(ctx, dbops.Role{
-		ID:   state.ID.ValueString(),
-		Name: plan.Name.ValueString(),
-	}, plan.ClusterName.ValueStringPointer())
+	role, diags := roleFromModel(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	role.ID = state.ID.ValueString()
+
+	editedRole, err := r.client.UpdateRole(ctx, role, plan.ClusterName.ValueStringPointer())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Updating ClickHouse Role",
@@ -189,9 +331,17 @@ func (r *Resource) Update(ctx context.Context, req resource.UpdateRequest, resp
 		return
 	}
 
-	state.Name = types.StringValue(role.Name)
-	diags = resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
+	if editedRole != nil {
+		state.Settings = plan.Settings
+		state.Comment = plan.Comment
+		state.PreventDestroyOnDrift = plan.PreventDestroyOnDrift
+		modelFromApiResponse(&state, *editedRole)
+
+		diags = resp.State.Set(ctx, &state)
+		resp.Diagnostics.Append(diags...)
+	} else {
+		resp.State.RemoveResource(ctx)
+	}
 }
 
 func (r *Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -247,3 +397,33 @@ func (r *Resource) ImportState(ctx context.Context, req resource.ImportStateRequ
 		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("cluster_name"), clusterName)...)
 	}
 }
+
+func modelFromApiResponse(state *Role, role dbops.Role) {
+	state.ID = types.StringValue(role.ID)
+	state.Name = types.StringValue(role.Name)
+
+	// Settings are only tracked when managed by this resource.
+	if !state.Settings.IsNull() {
+		state.Settings = inlinesettings.FromDBOps(state.Settings, role.Settings)
+	}
+
+	// The comment is only tracked when managed by this resource and supported by the server.
+	if !state.Comment.IsNull() && role.Comment != nil {
+		state.Comment = types.StringValue(*role.Comment)
+	}
+}
+
+// roleFromModel returns the dbops role matching the given model, without ID.
+func roleFromModel(ctx context.Context, model Role) (dbops.Role, diag.Diagnostics) {
+	role := dbops.Role{
+		Name:    model.Name.ValueString(),
+		Comment: model.Comment.ValueStringPointer(),
+	}
+
+	var diags diag.Diagnostics
+	settings, settingsDiags := inlinesettings.ToDBOps(ctx, model.Settings)
+	diags.Append(settingsDiags...)
+	role.Settings = settings
+
+	return role, diags
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/zclconf/go-cty/cty"
 
 	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
 	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/testutils/nilcompare"
@@ -57,9 +58,31 @@ func TestRole_acceptance(t *testing.T) {
 			return fmt.Errorf("wrong value for cluster_name attribute")
 		}
 
+		if attrs["settings"] != nil {
+			attrsSettings := attrs["settings"].([]interface{})
+			if len(attrsSettings) != len(role.Settings) {
+				return fmt.Errorf("wrong value for settings attribute")
+			}
+
+			for i, s := range attrsSettings {
+				setting := s.(map[string]interface{})
+				if setting["name"] != role.Settings[i].Name ||
+					!nilcompare.NilCompare(role.Settings[i].Value, setting["value"]) {
+					return fmt.Errorf("wrong value for settings attribute")
+				}
+			}
+		}
+
+		// Comment is only checked when managed and supported by the server.
+		if attrs["comment"] != nil && role.Comment != nil && attrs["comment"].(string) != *role.Comment {
+			return fmt.Errorf("expected comment to be %q, was %q", *role.Comment, attrs["comment"].(string))
+		}
+
 		return nil
 	}
 
+	commentRoleName := acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
+
 	tests := []runner.TestCase{
 		{
 			Name:     "Create Role using Native protocol on a single replica",
@@ -73,6 +96,51 @@ func TestRole_acceptance(t *testing.T) {
 			CheckNotExistsFunc:  checkNotExistsFunc,
 			CheckAttributesFunc: checkAttributesFunc,
 		},
+		{
+			Name:     "Create Role with settings using Native protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "native",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithStringAttribute("name", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)).
+				WithListAttribute("settings", []cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"name":        cty.StringVal("max_memory_usage"),
+						"value":       cty.StringVal("1000000"),
+						"min":         cty.StringVal("0"),
+						"max":         cty.StringVal("2000000"),
+						"writability": cty.StringVal("CONST"),
+					}),
+					cty.ObjectVal(map[string]cty.Value{
+						"name":        cty.StringVal("max_threads"),
+						"value":       cty.StringVal("4"),
+						"min":         cty.NullVal(cty.String),
+						"max":         cty.NullVal(cty.String),
+						"writability": cty.NullVal(cty.String),
+					}),
+				}).
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+		{
+			Name:     "Create and update Role comment using Native protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "native",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithStringAttribute("name", commentRoleName).
+				WithStringAttribute("comment", "owner: team-data").
+				Build(),
+			UpdatedResource: resourcebuilder.New(resourceType, resourceName).
+				WithStringAttribute("name", commentRoleName).
+				WithStringAttribute("comment", "owner: team-ops, managed by terraform").
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
 		{
 			Name:     "Create Role using HTTP protocol on a single replica",
 			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
@@ -0,0 +1,17 @@
+package rowpolicy
+
+import (
+	"strings"
+	"unicode"
+)
+
+// normalizeExpression removes whitespace from the given expression, so that expressions differing only in whitespace
+// are compared without asking the server to parse them.
+func normalizeExpression(expression string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, expression)
+}
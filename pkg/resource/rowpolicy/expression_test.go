@@ -0,0 +1,31 @@
+package rowpolicy
+
+import (
+	"testing"
+)
+
+func Test_normalizeExpression(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		want       string
+	}{
+		{
+			name:       "No whitespace",
+			expression: "a=1",
+			want:       "a=1",
+		},
+		{
+			name:       "Spaces, tabs and newlines",
+			expression: " a = 1\n\tAND b = 2 ",
+			want:       "a=1ANDb=2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeExpression(tt.expression); got != tt.want {
+				t.Errorf("normalizeExpression() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package rowpolicy
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type RowPolicy struct {
+	ClusterName     types.String `tfsdk:"cluster_name"`
+	ID              types.String `tfsdk:"id"`
+	Name            types.String `tfsdk:"name"`
+	Database        types.String `tfsdk:"database"`
+	Table           types.String `tfsdk:"table"`
+	Kind            types.String `tfsdk:"kind"`
+	UsingExpression types.String `tfsdk:"using_expression"`
+	ApplyTo         types.Set    `tfsdk:"apply_to"`
+}
@@ -0,0 +1,412 @@
+package rowpolicy
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
+)
+
+//go:embed rowpolicy.md
+var rowPolicyResourceDescription string
+
+const (
+	kindPermissive  = "permissive"
+	kindRestrictive = "restrictive"
+)
+
+var (
+	_ resource.Resource                = &Resource{}
+	_ resource.ResourceWithConfigure   = &Resource{}
+	_ resource.ResourceWithImportState = &Resource{}
+	_ resource.ResourceWithModifyPlan  = &Resource{}
+)
+
+func NewResource() resource.Resource {
+	return &Resource{}
+}
+
+type Resource struct {
+	client dbops.Client
+}
+
+func (r *Resource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_row_policy"
+}
+
+func (r *Resource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"cluster_name": schema.StringAttribute{
+				Optional:    true,
+				Description: "Name of the cluster to create the resource into. If omitted, resource will be created on the replica hit by the query.\nThis field must be left null when using a ClickHouse Cloud cluster.\nWhen using a self hosted ClickHouse instance, this field should only be set when there is more than one replica and you are not using 'replicated' storage for user_directory.\n",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The system-assigned ID for the row policy",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "Name of the row policy",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"database": schema.StringAttribute{
+				Required:    true,
+				Description: "Name of the database of the table the row policy applies to",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"table": schema.StringAttribute{
+				Required:    true,
+				Description: "Name of the table the row policy applies to",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"kind": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Whether the row policy is 'permissive' or 'restrictive'. Defaults to 'permissive'.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+				Validators: []validator.String{
+					stringvalidator.OneOf(kindPermissive, kindRestrictive),
+				},
+			},
+			"using_expression": schema.StringAttribute{
+				Required:    true,
+				Description: "Condition rows have to match to be returned by SELECT queries",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"apply_to": schema.SetAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Names of the roles and users the row policy applies to",
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+				},
+			},
+		},
+		MarkdownDescription: rowPolicyResourceDescription,
+	}
+}
+
+func (r *Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		// If the entire plan is null, the resource is planned for destruction.
+		return
+	}
+
+	if r.client != nil {
+		var clusterName types.String
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("cluster_name"), &clusterName)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		isReplicatedStorage, err := r.client.IsReplicatedStorage(ctx, clusterName.ValueStringPointer())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Checking if service is using replicated storage",
+				fmt.Sprintf("%+v\n", err),
+			)
+			return
+		}
+
+		if isReplicatedStorage {
+			var config RowPolicy
+			diags := req.Config.Get(ctx, &config)
+			resp.Diagnostics.Append(diags...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+
+			// Row policy cannot specify 'cluster_name' or apply will fail.
+			if !config.ClusterName.IsNull() || r.client.DefaultClusterName() != nil {
+				resp.Diagnostics.AddWarning(
+					"Invalid configuration",
+					"Your ClickHouse cluster is using Replicated storage for row policies, please remove the 'cluster_name' attribute from your RowPolicy resource definition, or the 'default_cluster' provider attribute, if you encounter any errors.",
+				)
+			}
+		}
+
+		var plan RowPolicy
+		diags := req.Plan.Get(ctx, &plan)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if plan.Name.IsUnknown() || plan.Database.IsUnknown() || plan.Table.IsUnknown() || plan.UsingExpression.IsUnknown() || plan.ApplyTo.IsUnknown() {
+			// Can't validate the query until all values are known.
+			return
+		}
+		for _, name := range plan.ApplyTo.Elements() {
+			if name.IsUnknown() {
+				return
+			}
+		}
+
+		rowPolicy, diags := toDBOps(ctx, plan)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if err := r.client.ValidateRowPolicy(ctx, rowPolicy, plan.ClusterName.ValueStringPointer()); err != nil {
+			resp.Diagnostics.AddError(
+				"Invalid Row Policy",
+				fmt.Sprintf("%+v\n", err),
+			)
+		}
+	}
+}
+
+func (r *Resource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	r.client = req.ProviderData.(dbops.Client)
+}
+
+func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan RowPolicy
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	rowPolicy, diags := toDBOps(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createdRowPolicy, err := r.client.CreateRowPolicy(ctx, rowPolicy, plan.ClusterName.ValueStringPointer())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Creating ClickHouse Row Policy",
+			fmt.Sprintf("%+v\n", err),
+		)
+		return
+	}
+
+	if createdRowPolicy == nil {
+		resp.Diagnostics.AddError(
+			"Error Creating ClickHouse Row Policy",
+			"The row policy was not found after creation",
+		)
+		return
+	}
+
+	state := plan
+	state.ID = types.StringValue(createdRowPolicy.ID)
+	state.Kind = types.StringValue(kindFromDBOps(createdRowPolicy))
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *Resource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state RowPolicy
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	rowPolicy, err := r.client.GetRowPolicy(ctx, state.ID.ValueString(), state.ClusterName.ValueStringPointer())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading ClickHouse Row Policy",
+			fmt.Sprintf("%+v\n", err),
+		)
+		return
+	}
+
+	if rowPolicy == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.Name = types.StringValue(rowPolicy.Name)
+	state.Database = types.StringValue(rowPolicy.DatabaseName)
+	state.Table = types.StringValue(rowPolicy.TableName)
+	state.Kind = types.StringValue(kindFromDBOps(rowPolicy))
+
+	// ClickHouse reformats the expression, keep the configured one unless it was changed.
+	if normalizeExpression(rowPolicy.UsingExpression) != normalizeExpression(state.UsingExpression.ValueString()) {
+		same, err := r.client.SameRowPolicyExpression(ctx, rowPolicy.UsingExpression, state.UsingExpression.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Reading ClickHouse Row Policy",
+				fmt.Sprintf("%+v\n", err),
+			)
+			return
+		}
+		if !same {
+			state.UsingExpression = types.StringValue(rowPolicy.UsingExpression)
+		}
+	}
+
+	if len(rowPolicy.ApplyTo) > 0 {
+		applyTo, diags := types.SetValueFrom(ctx, types.StringType, rowPolicy.ApplyTo)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		state.ApplyTo = applyTo
+	} else {
+		state.ApplyTo = types.SetNull(types.StringType)
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *Resource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state RowPolicy
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	rowPolicy, diags := toDBOps(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	rowPolicy.ID = state.ID.ValueString()
+
+	updated, err := r.client.UpdateRowPolicy(ctx, rowPolicy, plan.ClusterName.ValueStringPointer())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating ClickHouse Row Policy",
+			fmt.Sprintf("%+v\n", err),
+		)
+		return
+	}
+
+	if updated == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state = plan
+	state.ID = types.StringValue(updated.ID)
+	state.Kind = types.StringValue(kindFromDBOps(updated))
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state RowPolicy
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.DeleteRowPolicy(ctx, state.ID.ValueString(), state.ClusterName.ValueStringPointer())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Deleting ClickHouse Row Policy",
+			fmt.Sprintf("%+v\n", err),
+		)
+		return
+	}
+}
+
+func (r *Resource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// req.ID can either be in the form <cluster name>:<row policy ID> or just <row policy ID>
+	ref := req.ID
+	var clusterName *string
+	if strings.Contains(req.ID, ":") {
+		parts := strings.SplitN(req.ID, ":", 2)
+		clusterName = &parts[0]
+		ref = parts[1]
+	}
+
+	rowPolicy, err := r.client.GetRowPolicy(ctx, ref, clusterName)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Cannot find row policy",
+			fmt.Sprintf("%+v\n", err),
+		)
+		return
+	}
+
+	if rowPolicy == nil {
+		resp.Diagnostics.AddError(
+			"Cannot find row policy",
+			fmt.Sprintf("row policy with id %q was not found", ref),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), rowPolicy.ID)...)
+
+	if clusterName != nil {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("cluster_name"), clusterName)...)
+	}
+}
+
+func toDBOps(ctx context.Context, model RowPolicy) (dbops.RowPolicy, diag.Diagnostics) {
+	rowPolicy := dbops.RowPolicy{
+		Name:            model.Name.ValueString(),
+		DatabaseName:    model.Database.ValueString(),
+		TableName:       model.Table.ValueString(),
+		Restrictive:     model.Kind.ValueString() == kindRestrictive,
+		UsingExpression: model.UsingExpression.ValueString(),
+	}
+
+	var diags diag.Diagnostics
+	if !model.ApplyTo.IsNull() && !model.ApplyTo.IsUnknown() {
+		diags = model.ApplyTo.ElementsAs(ctx, &rowPolicy.ApplyTo, false)
+	}
+
+	return rowPolicy, diags
+}
+
+func kindFromDBOps(rowPolicy *dbops.RowPolicy) string {
+	if rowPolicy.Restrictive {
+		return kindRestrictive
+	}
+
+	return kindPermissive
+}
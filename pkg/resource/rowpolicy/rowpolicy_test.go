@@ -0,0 +1,122 @@
+package rowpolicy_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/testutils/nilcompare"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/testutils/resourcebuilder"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/testutils/runner"
+)
+
+const (
+	resourceType = "clickhousedbops_row_policy"
+	resourceName = "foo"
+)
+
+func TestRowPolicy_acceptance(t *testing.T) {
+	clusterName := "cluster1"
+
+	checkNotExistsFunc := func(ctx context.Context, dbopsClient dbops.Client, clusterName *string, attrs map[string]string) (bool, error) {
+		id := attrs["id"]
+		if id == "" {
+			return false, fmt.Errorf("id attribute was not set")
+		}
+		rowPolicy, err := dbopsClient.GetRowPolicy(ctx, id, clusterName)
+		return rowPolicy != nil, err
+	}
+
+	checkAttributesFunc := func(ctx context.Context, dbopsClient dbops.Client, clusterName *string, attrs map[string]interface{}) error {
+		id := attrs["id"]
+		if id == nil {
+			return fmt.Errorf("id was nil")
+		}
+
+		rowPolicy, err := dbopsClient.GetRowPolicy(ctx, id.(string), clusterName)
+		if err != nil {
+			return err
+		}
+
+		if rowPolicy == nil {
+			return fmt.Errorf("row policy with id %q was not found", id)
+		}
+
+		// Check state fields are aligned with the row policy we retrieved from CH.
+		if attrs["name"].(string) != rowPolicy.Name {
+			return fmt.Errorf("expected name to be %q, was %q", rowPolicy.Name, attrs["name"].(string))
+		}
+		if attrs["database"].(string) != rowPolicy.DatabaseName {
+			return fmt.Errorf("expected database to be %q, was %q", rowPolicy.DatabaseName, attrs["database"].(string))
+		}
+		if attrs["table"].(string) != rowPolicy.TableName {
+			return fmt.Errorf("expected table to be %q, was %q", rowPolicy.TableName, attrs["table"].(string))
+		}
+		if (attrs["kind"].(string) == "restrictive") != rowPolicy.Restrictive {
+			return fmt.Errorf("wrong value for kind attribute")
+		}
+
+		if !nilcompare.NilCompare(clusterName, attrs["cluster_name"]) {
+			return fmt.Errorf("wrong value for cluster_name attribute")
+		}
+
+		return nil
+	}
+
+	newResource := func() *resourcebuilder.ResourceBuilder {
+		return resourcebuilder.New(resourceType, resourceName).
+			WithStringAttribute("name", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)).
+			WithStringAttribute("database", "default").
+			WithStringAttribute("table", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)).
+			WithStringAttribute("using_expression", "1 = 1 and 2 = 2")
+	}
+
+	tests := []runner.TestCase{
+		{
+			Name:                "Create Row Policy using Native protocol on a single replica",
+			ChEnv:               map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol:            "native",
+			Resource:            newResource().Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+		{
+			Name:                "Create restrictive Row Policy using HTTP protocol on a single replica",
+			ChEnv:               map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol:            "http",
+			Resource:            newResource().WithStringAttribute("kind", "restrictive").Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+		{
+			Name:                "Create Row Policy using Native protocol on a cluster using replicated storage",
+			ChEnv:               map[string]string{"CONFIGFILE": "config-replicated.xml"},
+			Protocol:            "native",
+			Resource:            newResource().Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+		{
+			Name:                "Create Row Policy using HTTP protocol on a cluster using localfile storage",
+			ChEnv:               map[string]string{"CONFIGFILE": "config-localfile.xml"},
+			ClusterName:         &clusterName,
+			Protocol:            "http",
+			Resource:            newResource().WithStringAttribute("cluster_name", clusterName).Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+	}
+
+	runner.RunTests(t, tests)
+}
@@ -12,4 +12,5 @@ type Setting struct {
 	Min               types.String `tfsdk:"min"`
 	Max               types.String `tfsdk:"max"`
 	Writability       types.String `tfsdk:"writability"`
+	Raw               types.Bool   `tfsdk:"raw"`
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
@@ -116,6 +117,13 @@ func (r *Resource) Schema(_ context.Context, _ resource.SchemaRequest, resp *res
 					),
 				},
 			},
+			"raw": schema.BoolAttribute{
+				Description: "When true, value, min and max are sent as they are, as SQL literals or expressions, e.g. 1 or '42', rather than quoted as strings. When false, they are sent unquoted only for settings with a numeric or boolean type in system.settings, so the values of String and custom settings, like SQL_tenant, stay strings. ClickHouse stores the result of expressions, which must be the configured value to avoid a diff. Defaults to false.",
+				Optional:    true,
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
+			},
 		},
 		MarkdownDescription: settingResourceDescription,
 	}
@@ -128,7 +136,13 @@ func (r *Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanReques
 	}
 
 	if r.client != nil {
-		isReplicatedStorage, err := r.client.IsReplicatedStorage(ctx)
+		var clusterName types.String
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("cluster_name"), &clusterName)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		isReplicatedStorage, err := r.client.IsReplicatedStorage(ctx, clusterName.ValueStringPointer())
 		if err != nil {
 			resp.Diagnostics.AddError(
 				"Error Checking if service is using replicated storage",
@@ -146,13 +160,41 @@ func (r *Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanReques
 			}
 
 			// Setting cannot specify 'cluster_name' or apply will fail.
-			if !config.ClusterName.IsNull() {
+			if !config.ClusterName.IsNull() || r.client.DefaultClusterName() != nil {
 				resp.Diagnostics.AddWarning(
 					"Invalid configuration",
-					"Your ClickHouse cluster is using Replicated storage, please remove the 'cluster_name' attribute from your Setting resource definition if you encounter any errors.",
+					"Your ClickHouse cluster is using Replicated storage, please remove the 'cluster_name' attribute from your Setting resource definition, or the 'default_cluster' provider attribute, if you encounter any errors.",
 				)
 			}
 		}
+
+		var plan Setting
+		diags := req.Plan.Get(ctx, &plan)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if plan.Name.IsUnknown() || plan.Value.IsUnknown() || plan.Min.IsUnknown() || plan.Max.IsUnknown() || plan.Writability.IsUnknown() || plan.Raw.IsUnknown() {
+			// Can't validate the query until all values are known.
+			return
+		}
+
+		setting := dbops.Setting{
+			Name:        plan.Name.ValueString(),
+			Value:       plan.Value.ValueStringPointer(),
+			Min:         plan.Min.ValueStringPointer(),
+			Max:         plan.Max.ValueStringPointer(),
+			Writability: plan.Writability.ValueStringPointer(),
+			Raw:         plan.Raw.ValueBool(),
+		}
+
+		if err := r.client.ValidateSetting(ctx, setting, plan.ClusterName.ValueStringPointer()); err != nil {
+			resp.Diagnostics.AddError(
+				"Invalid Setting",
+				fmt.Sprintf("%+v\n", err),
+			)
+		}
 	}
 }
 
@@ -178,6 +220,7 @@ func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp
 		Min:         plan.Min.ValueStringPointer(),
 		Max:         plan.Max.ValueStringPointer(),
 		Writability: plan.Writability.ValueStringPointer(),
+		Raw:         plan.Raw.ValueBool(),
 	}
 
 	createdSetting, err := r.client.CreateSetting(ctx, plan.SettingsProfileID.ValueString(), setting, plan.ClusterName.ValueStringPointer())
@@ -192,6 +235,10 @@ func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp
 	state := Setting{
 		ClusterName:       plan.ClusterName,
 		SettingsProfileID: plan.SettingsProfileID,
+		Value:             plan.Value,
+		Min:               plan.Min,
+		Max:               plan.Max,
+		Raw:               plan.Raw,
 	}
 
 	modelFromApiResponse(&state, *createdSetting)
@@ -254,8 +301,9 @@ func (r *Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp
 
 func modelFromApiResponse(state *Setting, settingsProfile dbops.Setting) {
 	state.Name = types.StringValue(settingsProfile.Name)
-	state.Value = types.StringPointerValue(settingsProfile.Value)
-	state.Min = types.StringPointerValue(settingsProfile.Min)
-	state.Max = types.StringPointerValue(settingsProfile.Max)
+	// Keep the configured spelling of values ClickHouse reports differently, e.g. true reported as 1.
+	state.Value = types.StringPointerValue(dbops.SettingValue(state.Value.ValueStringPointer(), settingsProfile.Value))
+	state.Min = types.StringPointerValue(dbops.SettingValue(state.Min.ValueStringPointer(), settingsProfile.Min))
+	state.Max = types.StringPointerValue(dbops.SettingValue(state.Max.ValueStringPointer(), settingsProfile.Max))
 	state.Writability = types.StringPointerValue(settingsProfile.Writability)
 }
@@ -76,15 +76,15 @@ func TestSettingsProfileSettings_acceptance(t *testing.T) {
 			return fmt.Errorf("expected name to be %q, was %q", setting.Name, attrs["name"].(string))
 		}
 
-		if !nilcompare.NilCompare(setting.Value, attrs["value"]) {
+		if !sameSettingValue(setting.Value, attrs["value"]) {
 			return fmt.Errorf("wrong value for value attribute")
 		}
 
-		if !nilcompare.NilCompare(setting.Min, attrs["min"]) {
+		if !sameSettingValue(setting.Min, attrs["min"]) {
 			return fmt.Errorf("wrong value for min attribute")
 		}
 
-		if !nilcompare.NilCompare(setting.Max, attrs["max"]) {
+		if !sameSettingValue(setting.Max, attrs["max"]) {
 			return fmt.Errorf("wrong value for max attribute")
 		}
 
@@ -111,6 +111,21 @@ func TestSettingsProfileSettings_acceptance(t *testing.T) {
 			CheckNotExistsFunc:  checkNotExistsFunc,
 			CheckAttributesFunc: checkAttributesFunc,
 		},
+		{
+			Name:     "Create boolean Settings Profile Setting using Native protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "native",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				AddDependency(settingProfileBuilder.Build()).
+				WithResourceFieldReference("settings_profile_id", "clickhousedbops_settings_profile", "profile1", "id").
+				WithStringAttribute("name", "log_queries").
+				WithStringAttribute("value", "true").
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
 		{
 			Name:     "Create Settings Profile setting using HTTP protocol on a single replica",
 			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
@@ -202,3 +217,15 @@ func TestSettingsProfileSettings_acceptance(t *testing.T) {
 
 	runner.RunTests(t, tests)
 }
+
+// sameSettingValue compares a setting value read from ClickHouse with the value of a state attribute, which keeps
+// the configured spelling of booleans.
+func sameSettingValue(value *string, attribute interface{}) bool {
+	if attribute == nil {
+		return value == nil
+	}
+
+	configured := attribute.(string)
+
+	return dbops.SameSettingValue(value, &configured)
+}
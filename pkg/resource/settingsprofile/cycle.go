@@ -0,0 +1,46 @@
+package settingsprofile
+
+// findInheritanceCycle returns the inheritance path leading back to the profile being planned, or nil if
+// inheriting from the given profiles does not create a cycle.
+// selfNames holds the planned name of the profile first, followed by its current name when it is being renamed, since
+// other profiles still reference the current name until the rename is applied.
+// getInheritFrom returns the profiles a given profile currently inherits from.
+func findInheritanceCycle(selfNames []string, inheritFrom []string, getInheritFrom func(name string) []string) []string {
+	if len(selfNames) == 0 {
+		return nil
+	}
+
+	isSelf := func(name string) bool {
+		for _, n := range selfNames {
+			if n == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	visited := make(map[string]bool)
+
+	var walk func(path []string, parents []string) []string
+	walk = func(path []string, parents []string) []string {
+		for _, parent := range parents {
+			next := append(append(make([]string, 0, len(path)+1), path...), parent)
+			if isSelf(parent) {
+				return next
+			}
+
+			if visited[parent] {
+				continue
+			}
+			visited[parent] = true
+
+			if cycle := walk(next, getInheritFrom(parent)); cycle != nil {
+				return cycle
+			}
+		}
+
+		return nil
+	}
+
+	return walk([]string{selfNames[0]}, inheritFrom)
+}
@@ -0,0 +1,76 @@
+package settingsprofile
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_findInheritanceCycle(t *testing.T) {
+	graph := map[string][]string{
+		"a": {"b"},
+		"b": {"c"},
+		"c": nil,
+		"d": {"a", "c"},
+		"e": {"e2"},
+	}
+	getInheritFrom := func(name string) []string {
+		return graph[name]
+	}
+
+	tests := []struct {
+		name        string
+		selfNames   []string
+		inheritFrom []string
+		want        []string
+	}{
+		{
+			name:        "No parents",
+			selfNames:   []string{"new"},
+			inheritFrom: nil,
+			want:        nil,
+		},
+		{
+			name:        "No cycle",
+			selfNames:   []string{"new"},
+			inheritFrom: []string{"a", "d"},
+			want:        nil,
+		},
+		{
+			name:        "Inheriting from itself",
+			selfNames:   []string{"a"},
+			inheritFrom: []string{"a"},
+			want:        []string{"a", "a"},
+		},
+		{
+			name:        "Direct cycle",
+			selfNames:   []string{"c"},
+			inheritFrom: []string{"b"},
+			want:        []string{"c", "b", "c"},
+		},
+		{
+			name:        "Indirect cycle",
+			selfNames:   []string{"c"},
+			inheritFrom: []string{"d"},
+			want:        []string{"c", "d", "a", "b", "c"},
+		},
+		{
+			name:        "Cycle through the current name of a renamed profile",
+			selfNames:   []string{"c_renamed", "c"},
+			inheritFrom: []string{"a"},
+			want:        []string{"c_renamed", "a", "b", "c"},
+		},
+		{
+			name:        "Unknown parent",
+			selfNames:   []string{"c"},
+			inheritFrom: []string{"missing"},
+			want:        nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findInheritanceCycle(tt.selfNames, tt.inheritFrom, getInheritFrom); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findInheritanceCycle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,8 +5,14 @@ import (
 )
 
 type SettingsProfile struct {
-	ClusterName types.String `tfsdk:"cluster_name"`
-	ID          types.String `tfsdk:"id"`
-	Name        types.String `tfsdk:"name"`
-	InheritFrom types.List   `tfsdk:"inherit_from"`
+	ClusterName   types.String `tfsdk:"cluster_name"`
+	ID            types.String `tfsdk:"id"`
+	Name          types.String `tfsdk:"name"`
+	InheritFrom   types.List   `tfsdk:"inherit_from"`
+	ApplyTo       types.Set    `tfsdk:"apply_to"`
+	ApplyToAll    types.Bool   `tfsdk:"apply_to_all"`
+	ApplyToExcept types.Set    `tfsdk:"apply_to_except"`
+	Settings      types.List   `tfsdk:"settings"`
+
+	PreventDestroyOnDrift types.Bool `tfsdk:"prevent_destroy_on_drift"`
 }
@@ -8,7 +8,10 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -18,6 +21,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/types"
 
 	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/pkg/resource/internal/inlinesettings"
 )
 
 //go:embed settingsprofile.md
@@ -66,24 +70,121 @@ func (r *Resource) Schema(_ context.Context, _ resource.SchemaRequest, resp *res
 			"inherit_from": schema.ListAttribute{
 				Optional:    true,
 				ElementType: types.StringType,
-				Description: "List of setting profile names to inherit from",
+				Description: "List of setting profile names to inherit from, in order: settings of later profiles override the ones of earlier profiles",
 				Validators: []validator.List{
 					listvalidator.SizeAtLeast(1),
 				},
 			},
+			"apply_to": schema.SetAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Names of the users and roles the settings profile applies to",
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+					setvalidator.ConflictsWith(path.MatchRoot("apply_to_all")),
+				},
+			},
+			"apply_to_all": schema.BoolAttribute{
+				Optional:    true,
+				Description: "When true, the settings profile applies to all users and roles, except the ones in apply_to_except",
+			},
+			"apply_to_except": schema.SetAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Names of the users and roles the settings profile doesn't apply to. Requires apply_to_all to be true",
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+					setvalidator.AlsoRequires(path.MatchRoot("apply_to_all")),
+				},
+			},
+			"settings": schema.ListNestedAttribute{
+				Optional:    true,
+				Description: "Settings of the settings profile, in the order they are applied. When null, settings are not managed by this resource and can be managed with the clickhousedbops_setting resource instead",
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Required:    true,
+							Description: "Name of the setting",
+						},
+						"value": schema.StringAttribute{
+							Optional:    true,
+							Description: "Value for the setting",
+						},
+						"min": schema.StringAttribute{
+							Optional:    true,
+							Description: "Min Value for the setting",
+						},
+						"max": schema.StringAttribute{
+							Optional:    true,
+							Description: "Max Value for the setting",
+						},
+						"writability": schema.StringAttribute{
+							Optional:    true,
+							Description: "Writability attribute for the setting",
+							Validators: []validator.String{
+								stringvalidator.OneOf(
+									"CONST",
+									"WRITABLE",
+									"CHANGEABLE_IN_READONLY",
+								),
+							},
+						},
+						"raw": schema.BoolAttribute{
+							Optional:    true,
+							Description: "When true, value, min and max are sent as they are, as SQL literals or expressions, rather than quoted as strings. When false, they are sent unquoted only for settings with a numeric or boolean type in system.settings, so the values of String and custom settings stay strings. Defaults to false",
+						},
+					},
+				},
+			},
+			"prevent_destroy_on_drift": schema.BoolAttribute{
+				Optional:    true,
+				Description: "When true, refreshing fails instead of dropping the settings profile from the state when it can't be found with its ID anymore, but a settings profile with the same name exists, e.g. because it was dropped and created again outside of Terraform. The settings profile then needs to be reviewed, and imported again or removed from the state manually. Defaults to false.",
+			},
 		},
 		MarkdownDescription: settingsProfileResourceDescription,
 	}
 }
 
 func (r *Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Changing or dropping a settings profile kept in a read-only user directory, e.g. users.xml, would fail on apply.
+	if r.client != nil && !req.State.Raw.IsNull() && !req.Plan.Raw.Equal(req.State.Raw) {
+		var name, clusterName types.String
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("name"), &name)...)
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("cluster_name"), &clusterName)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		storage, err := r.client.ReadOnlyAccessStorage(ctx, dbops.AccessEntitySettingsProfile, name.ValueString(), clusterName.ValueStringPointer())
+		if err != nil {
+			resp.Diagnostics.AddError("Error Checking the Access Storage of the Settings Profile", fmt.Sprintf("%+v\n", err))
+			return
+		}
+		if storage != "" {
+			resp.Diagnostics.AddError(
+				"Settings Profile Kept in a Read-Only Access Storage",
+				fmt.Sprintf("The settings profile %q is kept in the read-only %q user directory, so it can't be changed or destroyed with SQL queries. Change it in the server configuration instead, or remove it from the Terraform state.", name.ValueString(), storage),
+			)
+			return
+		}
+	}
+
 	if req.Plan.Raw.IsNull() {
 		// If the entire plan is null, the resource is planned for destruction.
 		return
 	}
 
 	if r.client != nil {
-		isReplicatedStorage, err := r.client.IsReplicatedStorage(ctx)
+		var clusterName types.String
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("cluster_name"), &clusterName)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		isReplicatedStorage, err := r.client.IsReplicatedStorage(ctx, clusterName.ValueStringPointer())
 		if err != nil {
 			resp.Diagnostics.AddError(
 				"Error Checking if service is using replicated storage",
@@ -101,13 +202,67 @@ func (r *Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanReques
 			}
 
 			// SettingsProfile cannot specify 'cluster_name' or apply will fail.
-			if !config.ClusterName.IsNull() {
+			if !config.ClusterName.IsNull() || r.client.DefaultClusterName() != nil {
 				resp.Diagnostics.AddWarning(
 					"Invalid configuration",
-					"Your ClickHouse cluster is using Replicated storage, please remove the 'cluster_name' attribute from your SettingsProfile resource definition if you encounter any errors.",
+					"Your ClickHouse cluster is using Replicated storage, please remove the 'cluster_name' attribute from your SettingsProfile resource definition, or the 'default_cluster' provider attribute, if you encounter any errors.",
 				)
 			}
 		}
+
+		r.checkInheritanceCycle(ctx, req, resp)
+	}
+}
+
+// checkInheritanceCycle fails the plan if the planned inherit_from would make the profile inherit from itself,
+// either directly or through the profiles it inherits from.
+func (r *Resource) checkInheritanceCycle(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	var plan SettingsProfile
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.Name.IsUnknown() || plan.InheritFrom.IsNull() || plan.InheritFrom.IsUnknown() {
+		return
+	}
+
+	inherit := make([]string, 0)
+	resp.Diagnostics.Append(plan.InheritFrom.ElementsAs(ctx, &inherit, true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	selfNames := []string{plan.Name.ValueString()}
+	if !req.State.Raw.IsNull() {
+		var state SettingsProfile
+		diags = req.State.Get(ctx, &state)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if state.Name.ValueString() != plan.Name.ValueString() {
+			selfNames = append(selfNames, state.Name.ValueString())
+		}
+	}
+
+	cycle := findInheritanceCycle(selfNames, inherit, func(name string) []string {
+		profile, err := r.client.FindSettingsProfileByName(ctx, name, plan.ClusterName.ValueStringPointer())
+		if err != nil || profile == nil {
+			// Profile does not exist yet (or can't be read), so it can't be part of an existing inheritance chain.
+			return nil
+		}
+		return profile.InheritFrom
+	})
+
+	if cycle != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("inherit_from"),
+			"Settings Profile Inheritance Cycle",
+			fmt.Sprintf("Settings profile %q cannot inherit from the given profiles because it would create an inheritance cycle: %s", plan.Name.ValueString(), strings.Join(cycle, " -> ")),
+		)
 	}
 }
 
@@ -127,18 +282,12 @@ func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp
 		return
 	}
 
-	inherit := make([]string, 0)
-
-	resp.Diagnostics.Append(plan.InheritFrom.ElementsAs(ctx, &inherit, false)...)
+	profile, diags := profileFromModel(ctx, plan)
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	profile := dbops.SettingsProfile{
-		Name:        plan.Name.ValueString(),
-		InheritFrom: inherit,
-	}
-
 	createdSettingsProfile, err := r.client.CreateSettingsProfile(ctx, profile, plan.ClusterName.ValueStringPointer())
 	if err != nil {
 		resp.Diagnostics.AddError(
@@ -149,7 +298,13 @@ func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp
 	}
 
 	state := SettingsProfile{
-		ClusterName: plan.ClusterName,
+		ClusterName:   plan.ClusterName,
+		ApplyTo:       plan.ApplyTo,
+		ApplyToAll:    plan.ApplyToAll,
+		ApplyToExcept: plan.ApplyToExcept,
+		Settings:      plan.Settings,
+
+		PreventDestroyOnDrift: plan.PreventDestroyOnDrift,
 	}
 
 	modelFromApiResponse(&state, *createdSettingsProfile)
@@ -183,9 +338,29 @@ func (r *Resource) Read(ctx context.Context, req resource.ReadRequest, resp *res
 
 		diags = resp.State.Set(ctx, &state)
 		resp.Diagnostics.Append(diags...)
-	} else {
-		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	if state.PreventDestroyOnDrift.ValueBool() {
+		// A profile with the same name but another ID was changed outside of Terraform rather than deleted.
+		id, err := r.client.AccessEntityID(ctx, dbops.AccessEntitySettingsProfile, state.Name.ValueString(), state.ClusterName.ValueStringPointer())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Reading ClickHouse SettingsProfile",
+				fmt.Sprintf("%+v\n", err),
+			)
+			return
+		}
+		if id != "" {
+			resp.Diagnostics.AddError(
+				"ClickHouse SettingsProfile Changed Outside of Terraform",
+				fmt.Sprintf("The settings profile %q was not found with ID %q, but a settings profile with the same name exists with ID %q. Review the settings profile, then import it again or remove it from the Terraform state, or set prevent_destroy_on_drift to false to replace it.", state.Name.ValueString(), state.ID.ValueString(), id),
+			)
+			return
+		}
 	}
+
+	resp.State.RemoveResource(ctx)
 }
 
 func (r *Resource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
@@ -202,17 +377,12 @@ func (r *Resource) Update(ctx context.Context, req resource.UpdateRequest, resp
 		return
 	}
 
-	inherit := make([]string, 0)
-	resp.Diagnostics.Append(plan.InheritFrom.ElementsAs(ctx, &inherit, false)...)
+	profile, diags := profileFromModel(ctx, plan)
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
-
-	profile := dbops.SettingsProfile{
-		ID:          state.ID.ValueString(),
-		Name:        plan.Name.ValueString(),
-		InheritFrom: inherit,
-	}
+	profile.ID = state.ID.ValueString()
 
 	editedProfile, err := r.client.UpdateSettingsProfile(ctx, profile, plan.ClusterName.ValueStringPointer())
 	if err != nil {
@@ -223,6 +393,11 @@ func (r *Resource) Update(ctx context.Context, req resource.UpdateRequest, resp
 		return
 	}
 	if editedProfile != nil {
+		state.ApplyTo = plan.ApplyTo
+		state.ApplyToAll = plan.ApplyToAll
+		state.ApplyToExcept = plan.ApplyToExcept
+		state.Settings = plan.Settings
+		state.PreventDestroyOnDrift = plan.PreventDestroyOnDrift
 		modelFromApiResponse(&state, *editedProfile)
 
 		diags = resp.State.Set(ctx, &state)
@@ -300,4 +475,70 @@ func modelFromApiResponse(state *SettingsProfile, settingsProfile dbops.Settings
 	} else {
 		state.InheritFrom = types.ListNull(types.StringType)
 	}
+
+	// The users and roles the profile applies to are only tracked when managed by this resource, so that they don't
+	// conflict with the clickhousedbops_default_settings_profile resource.
+	if managesApplyTo(*state) {
+		if settingsProfile.ApplyToAll {
+			state.ApplyToAll = types.BoolValue(true)
+		} else if !state.ApplyToAll.IsNull() {
+			state.ApplyToAll = types.BoolValue(false)
+		}
+
+		state.ApplyTo = stringSetValue(settingsProfile.ApplyTo)
+		state.ApplyToExcept = stringSetValue(settingsProfile.ApplyToExcept)
+	}
+
+	// Settings are only tracked when managed by this resource, so that they don't conflict with
+	// clickhousedbops_setting resources.
+	if !state.Settings.IsNull() {
+		state.Settings = inlinesettings.FromDBOps(state.Settings, settingsProfile.Settings)
+	}
+}
+
+// managesApplyTo returns true when any of the apply_to, apply_to_all and apply_to_except attributes is set.
+func managesApplyTo(model SettingsProfile) bool {
+	return !model.ApplyToAll.IsNull() || !model.ApplyTo.IsNull() || !model.ApplyToExcept.IsNull()
+}
+
+// profileFromModel returns the dbops settings profile matching the given model, without ID.
+func profileFromModel(ctx context.Context, model SettingsProfile) (dbops.SettingsProfile, diag.Diagnostics) {
+	profile := dbops.SettingsProfile{
+		Name:        model.Name.ValueString(),
+		InheritFrom: make([]string, 0),
+		ApplyToAll:  model.ApplyToAll.ValueBool(),
+	}
+	if managesApplyTo(model) {
+		profile.ApplyTo = make([]string, 0)
+		profile.ApplyToExcept = make([]string, 0)
+	}
+
+	var diags diag.Diagnostics
+	diags.Append(model.InheritFrom.ElementsAs(ctx, &profile.InheritFrom, false)...)
+	if !model.ApplyTo.IsNull() && !model.ApplyTo.IsUnknown() {
+		diags.Append(model.ApplyTo.ElementsAs(ctx, &profile.ApplyTo, false)...)
+	}
+	if !model.ApplyToExcept.IsNull() && !model.ApplyToExcept.IsUnknown() {
+		diags.Append(model.ApplyToExcept.ElementsAs(ctx, &profile.ApplyToExcept, false)...)
+	}
+	settings, settingsDiags := inlinesettings.ToDBOps(ctx, model.Settings)
+	diags.Append(settingsDiags...)
+	profile.Settings = settings
+
+	return profile, diags
+}
+
+// stringSetValue returns a set with the given values, or a null set when there are none.
+func stringSetValue(values []string) types.Set {
+	if len(values) == 0 {
+		return types.SetNull(types.StringType)
+	}
+
+	elements := make([]attr.Value, 0)
+	for _, v := range values {
+		elements = append(elements, types.StringValue(v))
+	}
+
+	set, _ := types.SetValue(types.StringType, elements)
+	return set
 }
@@ -74,6 +74,29 @@ func TestSettingsprofile_acceptance(t *testing.T) {
 			}
 		}
 
+		// Check settings
+
+		if attrs["settings"] != nil {
+			attrsSettings := attrs["settings"].([]interface{})
+			if len(attrsSettings) != len(profile.Settings) {
+				return fmt.Errorf("wrong value for settings attribute")
+			}
+
+			for i, s := range attrsSettings {
+				setting := s.(map[string]interface{})
+				if setting["name"] != profile.Settings[i].Name ||
+					!nilcompare.NilCompare(profile.Settings[i].Value, setting["value"]) {
+					return fmt.Errorf("wrong value for settings attribute")
+				}
+			}
+		}
+
+		// Check apply_to_all
+
+		if attrs["apply_to_all"] != nil && attrs["apply_to_all"].(bool) != profile.ApplyToAll {
+			return fmt.Errorf("wrong value for apply_to_all attribute")
+		}
+
 		return nil
 	}
 
@@ -91,6 +114,48 @@ func TestSettingsprofile_acceptance(t *testing.T) {
 			CheckNotExistsFunc:  checkNotExistsFunc,
 			CheckAttributesFunc: checkAttributesFunc,
 		},
+		{
+			Name:     "Create Settings Profile applying to all users using Native protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "native",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithStringAttribute("name", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)).
+				WithBoolAttribute("apply_to_all", true).
+				WithListAttribute("apply_to_except", []cty.Value{cty.StringVal("default")}).
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+		{
+			Name:     "Create Settings Profile with settings using HTTP protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "http",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithStringAttribute("name", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)).
+				WithListAttribute("settings", []cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"name":        cty.StringVal("max_memory_usage"),
+						"value":       cty.StringVal("1000000"),
+						"min":         cty.StringVal("0"),
+						"max":         cty.StringVal("2000000"),
+						"writability": cty.StringVal("CONST"),
+					}),
+					cty.ObjectVal(map[string]cty.Value{
+						"name":        cty.StringVal("max_threads"),
+						"value":       cty.StringVal("4"),
+						"min":         cty.NullVal(cty.String),
+						"max":         cty.NullVal(cty.String),
+						"writability": cty.NullVal(cty.String),
+					}),
+				}).
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
 		{
 			Name:     "Create Settings Profile using HTTP protocol on a single replica",
 			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
@@ -5,8 +5,10 @@ import (
 )
 
 type SettingsProfileAssociation struct {
-	ClusterName       types.String `tfsdk:"cluster_name"`
-	SettingsProfileID types.String `tfsdk:"settings_profile_id"`
-	RoleID            types.String `tfsdk:"role_id"`
-	UserID            types.String `tfsdk:"user_id"`
+	ClusterName        types.String `tfsdk:"cluster_name"`
+	SettingsProfileID  types.String `tfsdk:"settings_profile_id"`
+	SettingsProfileIDs types.Set    `tfsdk:"settings_profile_ids"`
+	RoleID             types.String `tfsdk:"role_id"`
+	UserID             types.String `tfsdk:"user_id"`
+	ReplaceExisting    types.Bool   `tfsdk:"replace_existing"`
 }
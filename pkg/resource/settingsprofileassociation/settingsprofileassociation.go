@@ -4,15 +4,21 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"slices"
 
 	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 
 	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
 )
@@ -49,12 +55,24 @@ func (r *Resource) Schema(_ context.Context, _ resource.SchemaRequest, resp *res
 				},
 			},
 			"settings_profile_id": schema.StringAttribute{
-				Required:    true,
+				Optional:    true,
 				Description: "ID of the settings profile to associate",
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("settings_profile_ids")),
+				},
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
+			"settings_profile_ids": schema.SetAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "IDs of several settings profiles to associate. Changing it adds and drops profiles in place. Can't be set together with settings_profile_id or replace_existing.",
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+					setvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
 			"role_id": schema.StringAttribute{
 				Optional:    true,
 				Description: "ID of the SettingsProfileAssociation to associate the Settings profile to",
@@ -75,6 +93,13 @@ func (r *Resource) Schema(_ context.Context, _ resource.SchemaRequest, resp *res
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
+			"replace_existing": schema.BoolAttribute{
+				Optional:    true,
+				Description: "If true, the settings profile replaces all the other settings profiles and settings of the role when associated, instead of being added to them. Can only be set with role_id.",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
+			},
 		},
 		MarkdownDescription: settingsprofileassociationResourceDescription,
 	}
@@ -86,8 +111,38 @@ func (r *Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanReques
 		return
 	}
 
+	var cfg SettingsProfileAssociation
+	resp.Diagnostics.Append(req.Config.Get(ctx, &cfg)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if cfg.ReplaceExisting.ValueBool() && cfg.RoleID.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("replace_existing"),
+			"Invalid Configuration",
+			"'replace_existing' can only be set to true when associating the settings profile to a role.",
+		)
+		return
+	}
+
+	if cfg.ReplaceExisting.ValueBool() && !cfg.SettingsProfileIDs.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("replace_existing"),
+			"Invalid Configuration",
+			"'replace_existing' can only be set to true when associating a single settings profile with 'settings_profile_id'.",
+		)
+		return
+	}
+
 	if r.client != nil {
-		isReplicatedStorage, err := r.client.IsReplicatedStorage(ctx)
+		var clusterName types.String
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("cluster_name"), &clusterName)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		isReplicatedStorage, err := r.client.IsReplicatedStorage(ctx, clusterName.ValueStringPointer())
 		if err != nil {
 			resp.Diagnostics.AddError(
 				"Error Checking if service is using replicated storage",
@@ -105,10 +160,10 @@ func (r *Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanReques
 			}
 
 			// SettingsProfileAssociation cannot specify 'cluster_name' or apply will fail.
-			if !config.ClusterName.IsNull() {
+			if !config.ClusterName.IsNull() || r.client.DefaultClusterName() != nil {
 				resp.Diagnostics.AddWarning(
 					"Invalid configuration",
-					"Your ClickHouse cluster is using Replicated storage, please remove the 'cluster_name' attribute from your resource definition if you encounter any errors.",
+					"Your ClickHouse cluster is using Replicated storage, please remove the 'cluster_name' attribute from your resource definition, or the 'default_cluster' provider attribute, if you encounter any errors.",
 				)
 			}
 		}
@@ -131,7 +186,19 @@ func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp
 		return
 	}
 
-	err := r.client.AssociateSettingsProfile(ctx, plan.SettingsProfileID.ValueString(), plan.RoleID.ValueStringPointer(), plan.UserID.ValueStringPointer(), plan.ClusterName.ValueStringPointer())
+	var err error
+	if plan.ReplaceExisting.ValueBool() {
+		err = r.client.ReplaceRoleSettingsProfiles(ctx, plan.SettingsProfileID.ValueString(), plan.RoleID.ValueString(), plan.ClusterName.ValueStringPointer())
+	} else if !plan.SettingsProfileIDs.IsNull() {
+		ids, diags := settingsProfileIDs(ctx, plan.SettingsProfileIDs)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		err = r.client.AssociateSettingsProfiles(ctx, ids, plan.RoleID.ValueStringPointer(), plan.UserID.ValueStringPointer(), plan.ClusterName.ValueStringPointer())
+	} else {
+		err = r.client.AssociateSettingsProfile(ctx, plan.SettingsProfileID.ValueString(), plan.RoleID.ValueStringPointer(), plan.UserID.ValueStringPointer(), plan.ClusterName.ValueStringPointer())
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Associating Settings Profile to Role",
@@ -142,10 +209,12 @@ func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp
 	}
 
 	state := SettingsProfileAssociation{
-		ClusterName:       plan.ClusterName,
-		SettingsProfileID: plan.SettingsProfileID,
-		RoleID:            plan.RoleID,
-		UserID:            plan.UserID,
+		ClusterName:        plan.ClusterName,
+		SettingsProfileID:  plan.SettingsProfileID,
+		SettingsProfileIDs: plan.SettingsProfileIDs,
+		RoleID:             plan.RoleID,
+		UserID:             plan.UserID,
+		ReplaceExisting:    plan.ReplaceExisting,
 	}
 
 	diags = resp.State.Set(ctx, state)
@@ -163,21 +232,8 @@ func (r *Resource) Read(ctx context.Context, req resource.ReadRequest, resp *res
 		return
 	}
 
-	// Get settings profile.
-	settingsProfile, err := r.client.GetSettingsProfile(ctx, state.SettingsProfileID.ValueString(), state.ClusterName.ValueStringPointer())
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Getting Settings Profile",
-			fmt.Sprintf("%+v\n", err),
-		)
-		return
-	}
-
-	if settingsProfile == nil {
-		// Settings profile was deleted, so association was deleted too.
-		resp.State.RemoveResource(ctx)
-		return
-	}
+	// hasProfile tells if the settings profile is associated to the role or user of the resource.
+	var hasProfile func(settingsProfile *dbops.SettingsProfile) bool
 
 	if !state.RoleID.IsUnknown() && !state.RoleID.IsNull() {
 		role, err := r.client.GetRole(ctx, state.RoleID.ValueString(), state.ClusterName.ValueStringPointer())
@@ -190,10 +246,14 @@ func (r *Resource) Read(ctx context.Context, req resource.ReadRequest, resp *res
 			return
 		}
 
-		if role == nil || !role.HasSettingProfile(settingsProfile.Name) {
+		if role == nil {
 			resp.State.RemoveResource(ctx)
 			return
 		}
+
+		hasProfile = func(settingsProfile *dbops.SettingsProfile) bool {
+			return role.HasSettingProfile(settingsProfile.Name)
+		}
 	} else if !state.UserID.IsUnknown() && !state.UserID.IsNull() {
 		ref := state.UserID.ValueString()
 
@@ -212,15 +272,117 @@ func (r *Resource) Read(ctx context.Context, req resource.ReadRequest, resp *res
 			resp.Diagnostics.AddError("Error Getting User", fmt.Sprintf("%+v\n", getErr))
 			return
 		}
-		if user == nil || !user.HasSettingProfile(settingsProfile.Name) {
+
+		if user == nil {
 			resp.State.RemoveResource(ctx)
 			return
 		}
+
+		hasProfile = func(settingsProfile *dbops.SettingsProfile) bool {
+			return user.HasSettingProfile(settingsProfile.Name)
+		}
+	} else {
+		return
+	}
+
+	ids := []string{state.SettingsProfileID.ValueString()}
+	if !state.SettingsProfileIDs.IsNull() {
+		ids, diags = settingsProfileIDs(ctx, state.SettingsProfileIDs)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	associated := make([]string, 0)
+	for _, id := range ids {
+		settingsProfile, err := r.client.GetSettingsProfile(ctx, id, state.ClusterName.ValueStringPointer())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Getting Settings Profile",
+				fmt.Sprintf("%+v\n", err),
+			)
+			return
+		}
+
+		// A deleted settings profile is not associated anymore either.
+		if settingsProfile != nil && hasProfile(settingsProfile) {
+			associated = append(associated, id)
+		}
+	}
+
+	if len(associated) == 0 {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	if !state.SettingsProfileIDs.IsNull() && len(associated) != len(ids) {
+		// Only the profiles still associated are kept, so that Terraform plans to associate the others again.
+		state.SettingsProfileIDs = stringSetValue(associated)
+		resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
 	}
 }
 
 func (r *Resource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	panic("Update operation is not supported for clickhousedbops_settings_profile_association resource")
+	var plan, state SettingsProfileAssociation
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// All the other attributes require replacement: the association is only left unchanged in the state, unless the
+	// plan unexpectedly asks for a different one.
+	if !plan.ClusterName.Equal(state.ClusterName) ||
+		!plan.SettingsProfileID.Equal(state.SettingsProfileID) ||
+		!plan.RoleID.Equal(state.RoleID) ||
+		!plan.UserID.Equal(state.UserID) ||
+		!plan.ReplaceExisting.Equal(state.ReplaceExisting) {
+		resp.Diagnostics.AddError(
+			"Error Updating ClickHouse Settings Profile Association",
+			"Settings profile associations can't be updated in place, they have to be replaced instead.",
+		)
+		return
+	}
+
+	if !plan.SettingsProfileIDs.Equal(state.SettingsProfileIDs) {
+		planIDs, diags := settingsProfileIDs(ctx, plan.SettingsProfileIDs)
+		resp.Diagnostics.Append(diags...)
+		stateIDs, diags := settingsProfileIDs(ctx, state.SettingsProfileIDs)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		removed := make([]string, 0)
+		for _, id := range stateIDs {
+			if !slices.Contains(planIDs, id) {
+				removed = append(removed, id)
+			}
+		}
+
+		if len(removed) > 0 {
+			err := r.client.DisassociateSettingsProfiles(ctx, removed, state.RoleID.ValueStringPointer(), state.UserID.ValueStringPointer(), state.ClusterName.ValueStringPointer())
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error Updating ClickHouse Settings Profile Association",
+					fmt.Sprintf("%+v\n", err),
+				)
+				return
+			}
+		}
+
+		err := r.client.AssociateSettingsProfiles(ctx, planIDs, plan.RoleID.ValueStringPointer(), plan.UserID.ValueStringPointer(), plan.ClusterName.ValueStringPointer())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Updating ClickHouse Settings Profile Association",
+				fmt.Sprintf("%+v\n", err),
+			)
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
 func (r *Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -231,7 +393,17 @@ func (r *Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp
 		return
 	}
 
-	err := r.client.DisassociateSettingsProfile(ctx, state.SettingsProfileID.ValueString(), state.RoleID.ValueStringPointer(), state.UserID.ValueStringPointer(), state.ClusterName.ValueStringPointer())
+	var err error
+	if !state.SettingsProfileIDs.IsNull() {
+		ids, diags := settingsProfileIDs(ctx, state.SettingsProfileIDs)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		err = r.client.DisassociateSettingsProfiles(ctx, ids, state.RoleID.ValueStringPointer(), state.UserID.ValueStringPointer(), state.ClusterName.ValueStringPointer())
+	} else {
+		err = r.client.DisassociateSettingsProfile(ctx, state.SettingsProfileID.ValueString(), state.RoleID.ValueStringPointer(), state.UserID.ValueStringPointer(), state.ClusterName.ValueStringPointer())
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Deleting ClickHouse SettingsProfileAssociation",
@@ -240,3 +412,27 @@ func (r *Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp
 		return
 	}
 }
+
+// settingsProfileIDs returns the IDs in the 'settings_profile_ids' attribute, or none when it is null.
+func settingsProfileIDs(ctx context.Context, ids types.Set) ([]string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if ids.IsNull() || ids.IsUnknown() {
+		return nil, diags
+	}
+
+	elements := make([]string, 0)
+	diags.Append(ids.ElementsAs(ctx, &elements, false)...)
+
+	return elements, diags
+}
+
+// stringSetValue returns a set with the given values.
+func stringSetValue(values []string) types.Set {
+	elements := make([]attr.Value, 0)
+	for _, v := range values {
+		elements = append(elements, types.StringValue(v))
+	}
+
+	set, _ := types.SetValue(types.StringType, elements)
+	return set
+}
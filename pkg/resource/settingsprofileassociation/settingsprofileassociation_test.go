@@ -24,6 +24,10 @@ func TestSettingsProfileAssociation_acceptance(t *testing.T) {
 	settingsProfile := resourcebuilder.New("clickhousedbops_settings_profile", "profile1").
 		WithStringAttribute("name", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
 
+	// The same settings profile, renamed.
+	renamedSettingsProfile := resourcebuilder.New("clickhousedbops_settings_profile", "profile1").
+		WithStringAttribute("name", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
+
 	role := resourcebuilder.New("clickhousedbops_role", "role").
 		WithStringAttribute("name", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
 
@@ -168,6 +172,44 @@ func TestSettingsProfileAssociation_acceptance(t *testing.T) {
 			CheckNotExistsFunc:  checkNotExistsFunc,
 			CheckAttributesFunc: checkAttributesFunc,
 		},
+		{
+			Name:     "Rename settings profile associated to role using Native protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "native",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithResourceFieldReference("settings_profile_id", "clickhousedbops_settings_profile", "profile1", "id").
+				WithResourceFieldReference("role_id", "clickhousedbops_role", "role", "id").
+				AddDependency(role.Build()).
+				AddDependency(settingsProfile.Build()).
+				Build(),
+			UpdatedResource: resourcebuilder.New(resourceType, resourceName).
+				WithResourceFieldReference("settings_profile_id", "clickhousedbops_settings_profile", "profile1", "id").
+				WithResourceFieldReference("role_id", "clickhousedbops_role", "role", "id").
+				AddDependency(role.Build()).
+				AddDependency(renamedSettingsProfile.Build()).
+				Build(),
+			UpdatedResourceAddress: "clickhousedbops_settings_profile.profile1",
+			ResourceName:           resourceName,
+			ResourceAddress:        fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:     checkNotExistsFunc,
+			CheckAttributesFunc:    checkAttributesFunc,
+		},
+		{
+			Name:     "Replace settings profiles of role using HTTP protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "http",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithResourceFieldReference("settings_profile_id", "clickhousedbops_settings_profile", "profile1", "id").
+				WithResourceFieldReference("role_id", "clickhousedbops_role", "role", "id").
+				WithBoolAttribute("replace_existing", true).
+				AddDependency(role.Build()).
+				AddDependency(settingsProfile.Build()).
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
 		{
 			Name:     "Assign settings profile to user using HTTP protocol on a single replica",
 			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
@@ -0,0 +1,132 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
+)
+
+// authenticationsFromModel returns the identification methods in the 'authentication' attribute, or nil when they
+// are not set. Password hashes are write-only, so they are only available when reading the configuration.
+func authenticationsFromModel(ctx context.Context, authentications types.List) ([]dbops.UserAuthentication, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if authentications.IsNull() || authentications.IsUnknown() {
+		return nil, diags
+	}
+
+	elements := make([]Authentication, 0)
+	diags.Append(authentications.ElementsAs(ctx, &elements, false)...)
+
+	ret := make([]dbops.UserAuthentication, 0)
+	for _, a := range elements {
+		value := a.PasswordSha256Hash.ValueString()
+		switch a.Type.ValueString() {
+		case dbops.AuthTypeBcryptPassword:
+			value = a.PasswordBcryptHash.ValueString()
+		case dbops.AuthTypeSSLCertificate:
+			value = a.SSLCertificateCN.ValueString()
+		}
+
+		ret = append(ret, dbops.UserAuthentication{
+			Type:  a.Type.ValueString(),
+			Value: value,
+		})
+	}
+
+	return ret, diags
+}
+
+// authenticationsToModel returns the value of the 'authentication' attribute matching the given identification
+// methods. Password hashes are never read back, so 'password_sha256_hash_wo' and 'password_bcrypt_hash_wo' are
+// always null.
+func authenticationsToModel(authentications []dbops.UserAuthentication) types.List {
+	elements := make([]attr.Value, 0)
+	for _, a := range authentications {
+		cn := types.StringNull()
+		if a.Type == dbops.AuthTypeSSLCertificate {
+			cn = types.StringValue(a.Value)
+		}
+
+		element, _ := types.ObjectValue(authenticationAttrTypes, map[string]attr.Value{
+			"type":                    types.StringValue(a.Type),
+			"password_sha256_hash_wo": types.StringNull(),
+			"password_bcrypt_hash_wo": types.StringNull(),
+			"ssl_certificate_cn":      cn,
+		})
+		elements = append(elements, element)
+	}
+
+	list, _ := types.ListValue(types.ObjectType{AttrTypes: authenticationAttrTypes}, elements)
+	return list
+}
+
+// sameAuthentications returns true if both lists have the same identification methods, regardless of their order.
+// Password hashes are ignored, since they can't be read back.
+func sameAuthentications(a []dbops.UserAuthentication, b []dbops.UserAuthentication) bool {
+	key := func(authentications []dbops.UserAuthentication) []string {
+		ret := make([]string, 0)
+		for _, auth := range authentications {
+			if auth.Type == dbops.AuthTypeSSLCertificate {
+				ret = append(ret, auth.Type+" "+auth.Value)
+			} else {
+				ret = append(ret, auth.Type)
+			}
+		}
+		slices.Sort(ret)
+		return ret
+	}
+
+	return slices.Equal(key(a), key(b))
+}
+
+// validateAuthentications checks every entry of the 'authentication' attribute only sets the field required by its type.
+// Unknown values are skipped, as they can't be checked before applying.
+func validateAuthentications(ctx context.Context, authentications types.List) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if authentications.IsNull() || authentications.IsUnknown() {
+		return diags
+	}
+
+	elements := make([]Authentication, 0)
+	diags.Append(authentications.ElementsAs(ctx, &elements, false)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	for idx, a := range elements {
+		attrPath := path.Root("authentication").AtListIndex(idx)
+		authType := a.Type.ValueString()
+
+		values := map[string]types.String{
+			"password_sha256_hash_wo": a.PasswordSha256Hash,
+			"password_bcrypt_hash_wo": a.PasswordBcryptHash,
+			"ssl_certificate_cn":      a.SSLCertificateCN,
+		}
+
+		required := "password_sha256_hash_wo"
+		switch authType {
+		case dbops.AuthTypeBcryptPassword:
+			required = "password_bcrypt_hash_wo"
+		case dbops.AuthTypeSSLCertificate:
+			required = "ssl_certificate_cn"
+		}
+
+		for _, name := range []string{"password_sha256_hash_wo", "password_bcrypt_hash_wo", "ssl_certificate_cn"} {
+			if name == required && values[name].IsNull() {
+				diags.AddAttributeError(attrPath.AtName(name), "Invalid Authentication", fmt.Sprintf("'%s' is required for authentication type %s.", name, authType))
+			}
+			if name != required && !values[name].IsNull() {
+				diags.AddAttributeError(attrPath.AtName(name), "Invalid Authentication", fmt.Sprintf("'%s' can't be set for authentication type %s.", name, authType))
+			}
+		}
+	}
+
+	return diags
+}
@@ -0,0 +1,134 @@
+package user
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
+)
+
+func Test_sameAuthentications(t *testing.T) {
+	tests := []struct {
+		name string
+		a    []dbops.UserAuthentication
+		b    []dbops.UserAuthentication
+		want bool
+	}{
+		{
+			name: "Different order, password hash ignored",
+			a: []dbops.UserAuthentication{
+				{Type: dbops.AuthTypeSHA256Password, Value: "hash"},
+				{Type: dbops.AuthTypeSSLCertificate, Value: "john.example.com"},
+			},
+			b: []dbops.UserAuthentication{
+				{Type: dbops.AuthTypeSSLCertificate, Value: "john.example.com"},
+				{Type: dbops.AuthTypeSHA256Password},
+			},
+			want: true,
+		},
+		{
+			name: "Different common name",
+			a:    []dbops.UserAuthentication{{Type: dbops.AuthTypeSSLCertificate, Value: "john.example.com"}},
+			b:    []dbops.UserAuthentication{{Type: dbops.AuthTypeSSLCertificate, Value: "jane.example.com"}},
+			want: false,
+		},
+		{
+			name: "Method removed out of band",
+			a: []dbops.UserAuthentication{
+				{Type: dbops.AuthTypeSHA256Password},
+				{Type: dbops.AuthTypeSSLCertificate, Value: "john.example.com"},
+			},
+			b:    []dbops.UserAuthentication{{Type: dbops.AuthTypeSHA256Password}},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sameAuthentications(tt.a, tt.b); got != tt.want {
+				t.Errorf("sameAuthentications() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_validateAuthentications(t *testing.T) {
+	hash := "057ba03d6c44104863dc7361fe4578965d1887360f90a0895882e58a6248fc86"
+
+	bcryptHash := "$2a$12$Pq1hLsTMjwRCEdJIt1d0OOLZa/qwgE5xeptULjvuHP6t8e2vP0WbK"
+
+	element := func(authType string, password types.String, bcryptPassword types.String, cn types.String) attr.Value {
+		value, _ := types.ObjectValue(authenticationAttrTypes, map[string]attr.Value{
+			"type":                    types.StringValue(authType),
+			"password_sha256_hash_wo": password,
+			"password_bcrypt_hash_wo": bcryptPassword,
+			"ssl_certificate_cn":      cn,
+		})
+		return value
+	}
+
+	tests := []struct {
+		name       string
+		elements   []attr.Value
+		wantErrors int
+	}{
+		{
+			name: "Password and SSL certificate",
+			elements: []attr.Value{
+				element(dbops.AuthTypeSHA256Password, types.StringValue(hash), types.StringNull(), types.StringNull()),
+				element(dbops.AuthTypeSSLCertificate, types.StringNull(), types.StringNull(), types.StringValue("john.example.com")),
+			},
+			wantErrors: 0,
+		},
+		{
+			name: "Unknown common name",
+			elements: []attr.Value{
+				element(dbops.AuthTypeSSLCertificate, types.StringNull(), types.StringNull(), types.StringUnknown()),
+			},
+			wantErrors: 0,
+		},
+		{
+			name: "Missing password",
+			elements: []attr.Value{
+				element(dbops.AuthTypeSHA256Password, types.StringNull(), types.StringNull(), types.StringNull()),
+			},
+			wantErrors: 1,
+		},
+		{
+			name: "Bcrypt password",
+			elements: []attr.Value{
+				element(dbops.AuthTypeBcryptPassword, types.StringNull(), types.StringValue(bcryptHash), types.StringNull()),
+			},
+			wantErrors: 0,
+		},
+		{
+			name: "SHA256 hash with a bcrypt password",
+			elements: []attr.Value{
+				element(dbops.AuthTypeBcryptPassword, types.StringValue(hash), types.StringNull(), types.StringNull()),
+			},
+			wantErrors: 2,
+		},
+		{
+			name: "Common name with a password and password with a certificate",
+			elements: []attr.Value{
+				element(dbops.AuthTypeSHA256Password, types.StringValue(hash), types.StringNull(), types.StringValue("john.example.com")),
+				element(dbops.AuthTypeSSLCertificate, types.StringValue(hash), types.StringNull(), types.StringNull()),
+			},
+			wantErrors: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, diags := types.ListValue(types.ObjectType{AttrTypes: authenticationAttrTypes}, tt.elements)
+			if diags.HasError() {
+				t.Fatalf("ListValue() diags = %v", diags)
+			}
+
+			if got := validateAuthentications(context.Background(), list).ErrorsCount(); got != tt.wantErrors {
+				t.Errorf("validateAuthentications() errors = %d, want %d", got, tt.wantErrors)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package user
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ resource.ConfigValidator = authMethodsValidator{}
+
+// authMethodsValidator checks that exactly one identification method is configured for the user.
+// It runs when the configuration is validated, so it doesn't need a connection to the server.
+type authMethodsValidator struct{}
+
+func (v authMethodsValidator) Description(_ context.Context) string {
+	return "Exactly one of 'authentication', 'ssl_certificate_cn', 'password_sha256_hash_wo', 'password_bcrypt_hash_wo', 'password_plaintext_wo' or 'no_password' must be specified."
+}
+
+func (v authMethodsValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v authMethodsValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var cfg User
+	if diags := req.Config.Get(ctx, &cfg); diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+
+	methods := []types.String{cfg.PasswordSha256Hash, cfg.PasswordBcryptHash, cfg.PasswordPlaintext, cfg.SSLCertificateCN}
+
+	// Values coming from other resources are only known at apply time.
+	if cfg.Authentications.IsUnknown() || cfg.NoPassword.IsUnknown() {
+		return
+	}
+	for _, value := range methods {
+		if value.IsUnknown() {
+			return
+		}
+	}
+
+	methodsSet := 0
+	for _, value := range methods {
+		if !value.IsNull() {
+			methodsSet++
+		}
+	}
+	// no_password = false is the same as not setting it.
+	noPassword := cfg.NoPassword.ValueBool()
+	if noPassword {
+		methodsSet++
+	}
+
+	if !cfg.Authentications.IsNull() {
+		// Conflicts with the single method attributes are reported by their validators.
+		if noPassword {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("no_password"),
+				"Invalid Authentication Configuration",
+				"'no_password' can't be combined with 'authentication'.",
+			)
+		}
+		return
+	}
+
+	if methodsSet != 1 {
+		for _, attribute := range []string{"ssl_certificate_cn", "password_sha256_hash_wo", "password_bcrypt_hash_wo", "password_plaintext_wo", "no_password"} {
+			resp.Diagnostics.AddAttributeError(path.Root(attribute), "Invalid Authentication Configuration", v.Description(ctx))
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package user
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// userConfig returns a configuration of the user resource with the given attributes set, and all the others null.
+func userConfig(t *testing.T, values map[string]tftypes.Value) tfsdk.Config {
+	t.Helper()
+
+	ctx := context.Background()
+	schemaResp := resource.SchemaResponse{}
+	(&Resource{}).Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	if schemaResp.Diagnostics.HasError() {
+		t.Fatalf("Schema() diagnostics = %v", schemaResp.Diagnostics)
+	}
+
+	objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	attributes := map[string]tftypes.Value{}
+	for name, attributeType := range objectType.AttributeTypes {
+		attributes[name] = tftypes.NewValue(attributeType, nil)
+	}
+	for name, value := range values {
+		attributes[name] = value
+	}
+
+	return tfsdk.Config{
+		Schema: schemaResp.Schema,
+		Raw:    tftypes.NewValue(objectType, attributes),
+	}
+}
+
+func Test_authMethodsValidator(t *testing.T) {
+	hash := "057ba03d6c44104863dc7361fe4578965d1887360f90a0895882e58a6248fc86"
+
+	tests := []struct {
+		name    string
+		values  map[string]tftypes.Value
+		wantErr bool
+	}{
+		{
+			name:    "No identification method",
+			values:  map[string]tftypes.Value{},
+			wantErr: true,
+		},
+		{
+			name: "Password hash",
+			values: map[string]tftypes.Value{
+				"password_sha256_hash_wo": tftypes.NewValue(tftypes.String, hash),
+			},
+		},
+		{
+			name: "Password hash and common name",
+			values: map[string]tftypes.Value{
+				"password_sha256_hash_wo": tftypes.NewValue(tftypes.String, hash),
+				"ssl_certificate_cn":      tftypes.NewValue(tftypes.String, "john.example.com"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "No password",
+			values: map[string]tftypes.Value{
+				"no_password": tftypes.NewValue(tftypes.Bool, true),
+			},
+		},
+		{
+			name: "No password set to false only",
+			values: map[string]tftypes.Value{
+				"no_password": tftypes.NewValue(tftypes.Bool, false),
+			},
+			wantErr: true,
+		},
+		{
+			name: "No password and password hash",
+			values: map[string]tftypes.Value{
+				"no_password":             tftypes.NewValue(tftypes.Bool, true),
+				"password_sha256_hash_wo": tftypes.NewValue(tftypes.String, hash),
+			},
+			wantErr: true,
+		},
+		{
+			name: "Unknown password hash",
+			values: map[string]tftypes.Value{
+				"password_sha256_hash_wo": tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := resource.ValidateConfigResponse{}
+			authMethodsValidator{}.ValidateResource(context.Background(), resource.ValidateConfigRequest{Config: userConfig(t, tt.values)}, &resp)
+			if resp.Diagnostics.HasError() != tt.wantErr {
+				t.Errorf("ValidateResource() diagnostics = %v, wantErr %v", resp.Diagnostics, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package user
+
+const (
+	authTypeSHA256Password    = "sha256_password"
+	authTypeBcryptPassword    = "bcrypt_password"
+	authTypePlaintextPassword = "plaintext_password"
+	authTypeNoPassword        = "no_password"
+	authTypeSSLCertificate    = "ssl_certificate"
+)
+
+// expectedAuthType returns the authentication method ClickHouse should report for the user in the given state,
+// or an empty string when it can't be determined, e.g. when the password hash was set without a version.
+// Passwords are write-only, so a bcrypt or plaintext password is told apart from a SHA256 one by the method
+// reported when it was last read.
+// Users with several identification methods are checked against the 'authentication' attribute instead.
+func expectedAuthType(state User) string {
+	if !state.Authentications.IsNull() {
+		return ""
+	}
+
+	if !state.SSLCertificateCN.IsNull() && !state.SSLCertificateCN.IsUnknown() {
+		return authTypeSSLCertificate
+	}
+
+	if state.NoPassword.ValueBool() {
+		return authTypeNoPassword
+	}
+
+	if !state.PasswordSha256HashVersion.IsNull() && !state.PasswordSha256HashVersion.IsUnknown() {
+		switch state.AuthType.ValueString() {
+		case authTypeBcryptPassword, authTypePlaintextPassword:
+			return state.AuthType.ValueString()
+		}
+		return authTypeSHA256Password
+	}
+
+	return ""
+}
@@ -0,0 +1,55 @@
+package user
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func Test_expectedAuthType(t *testing.T) {
+	tests := []struct {
+		name  string
+		state User
+		want  string
+	}{
+		{
+			name:  "SSL certificate",
+			state: User{SSLCertificateCN: types.StringValue("john.example.com")},
+			want:  authTypeSSLCertificate,
+		},
+		{
+			name:  "No password",
+			state: User{NoPassword: types.BoolValue(true)},
+			want:  authTypeNoPassword,
+		},
+		{
+			name:  "Versioned SHA256 password",
+			state: User{PasswordSha256HashVersion: types.Int32Value(1), AuthType: types.StringValue(authTypeSHA256Password)},
+			want:  authTypeSHA256Password,
+		},
+		{
+			name:  "Versioned bcrypt password",
+			state: User{PasswordSha256HashVersion: types.Int32Value(1), AuthType: types.StringValue(authTypeBcryptPassword)},
+			want:  authTypeBcryptPassword,
+		},
+		{
+			name:  "Versioned plaintext password",
+			state: User{PasswordSha256HashVersion: types.Int32Value(1), AuthType: types.StringValue(authTypePlaintextPassword)},
+			want:  authTypePlaintextPassword,
+		},
+		{
+			name:  "Password without version",
+			state: User{AuthType: types.StringValue(authTypeSHA256Password)},
+			want:  "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := tt.state
+			state.Authentications = types.ListNull(types.ObjectType{AttrTypes: authenticationAttrTypes})
+			if got := expectedAuthType(state); got != tt.want {
+				t.Errorf("expectedAuthType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
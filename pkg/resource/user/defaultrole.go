@@ -0,0 +1,29 @@
+package user
+
+import (
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// defaultRoleFromServer returns the value of 'default_role' matching the default roles of the user.
+// The default role is only tracked when configured, since other default roles may be set by
+// clickhousedbops_grant_role resources. The configured role is kept as long as it is still a default role of the user.
+// Otherwise the user's default role is only reported when it has exactly one: the attribute becomes null, so that
+// Terraform plans to set the configured default role again.
+func defaultRoleFromServer(managed types.String, defaultRoles []string) types.String {
+	if managed.IsNull() || managed.IsUnknown() {
+		return types.StringNull()
+	}
+
+	// Default roles couldn't be read.
+	if defaultRoles == nil || slices.Contains(defaultRoles, managed.ValueString()) {
+		return managed
+	}
+
+	if len(defaultRoles) == 1 {
+		return types.StringValue(defaultRoles[0])
+	}
+
+	return types.StringNull()
+}
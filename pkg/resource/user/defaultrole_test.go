@@ -0,0 +1,66 @@
+package user
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func Test_defaultRoleFromServer(t *testing.T) {
+	tests := []struct {
+		name         string
+		managed      types.String
+		defaultRoles []string
+		want         types.String
+	}{
+		{
+			name:         "Default role unchanged",
+			managed:      types.StringValue("reader"),
+			defaultRoles: []string{"reader"},
+			want:         types.StringValue("reader"),
+		},
+		{
+			name:         "Default role among several",
+			managed:      types.StringValue("reader"),
+			defaultRoles: []string{"writer", "reader"},
+			want:         types.StringValue("reader"),
+		},
+		{
+			name:         "Default role changed",
+			managed:      types.StringValue("reader"),
+			defaultRoles: []string{"writer"},
+			want:         types.StringValue("writer"),
+		},
+		{
+			name:         "Default role removed",
+			managed:      types.StringValue("reader"),
+			defaultRoles: []string{},
+			want:         types.StringNull(),
+		},
+		{
+			name:         "Default role replaced by several",
+			managed:      types.StringValue("reader"),
+			defaultRoles: []string{"writer", "admin"},
+			want:         types.StringNull(),
+		},
+		{
+			name:         "Default roles couldn't be read",
+			managed:      types.StringValue("reader"),
+			defaultRoles: nil,
+			want:         types.StringValue("reader"),
+		},
+		{
+			name:         "Not managed",
+			managed:      types.StringNull(),
+			defaultRoles: []string{"writer"},
+			want:         types.StringNull(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := defaultRoleFromServer(tt.managed, tt.defaultRoles); !got.Equal(tt.want) {
+				t.Errorf("defaultRoleFromServer() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
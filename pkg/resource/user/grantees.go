@@ -0,0 +1,94 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
+)
+
+const (
+	granteesAny  = "ANY"
+	granteesNone = "NONE"
+)
+
+// granteesFromModel returns the grantees in the 'grantees' attribute, or nil when grantees are not managed by the resource.
+func granteesFromModel(ctx context.Context, grantees types.Set) (*dbops.UserGrantees, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if grantees.IsNull() || grantees.IsUnknown() {
+		return nil, diags
+	}
+
+	names := make([]string, 0)
+	diags.Append(grantees.ElementsAs(ctx, &names, false)...)
+
+	switch {
+	case slices.Contains(names, granteesAny):
+		return &dbops.UserGrantees{Any: true}, diags
+	case slices.Contains(names, granteesNone):
+		return &dbops.UserGrantees{}, diags
+	default:
+		return &dbops.UserGrantees{Names: names}, diags
+	}
+}
+
+// granteesFromServer returns the value of the 'grantees' attribute matching the grantees of the user.
+// Grantees are only tracked when managed by this resource, and were read successfully. ANY EXCEPT can't be expressed
+// with the attribute: it becomes null, so that Terraform plans to set the configured grantees again.
+func granteesFromServer(current types.Set, grantees *dbops.UserGrantees) types.Set {
+	if current.IsNull() || current.IsUnknown() || grantees == nil {
+		return current
+	}
+
+	names := grantees.Names
+	switch {
+	case grantees.Any && len(grantees.Except) > 0:
+		return types.SetNull(types.StringType)
+	case grantees.Any:
+		names = []string{granteesAny}
+	case len(grantees.Names) == 0:
+		names = []string{granteesNone}
+	}
+
+	elements := make([]attr.Value, 0)
+	for _, n := range names {
+		elements = append(elements, types.StringValue(n))
+	}
+
+	set, _ := types.SetValue(types.StringType, elements)
+	return set
+}
+
+// validateGrantees checks ANY and NONE are not combined with other grantees in the 'grantees' attribute.
+func validateGrantees(grantees types.Set) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if grantees.IsNull() || grantees.IsUnknown() {
+		return diags
+	}
+
+	if len(grantees.Elements()) < 2 {
+		return diags
+	}
+
+	// Unknown names are checked once known.
+	names := make([]string, 0)
+	for _, e := range grantees.Elements() {
+		if name, ok := e.(types.String); ok && !name.IsUnknown() {
+			names = append(names, name.ValueString())
+		}
+	}
+
+	for _, keyword := range []string{granteesAny, granteesNone} {
+		if slices.Contains(names, keyword) {
+			diags.AddAttributeError(path.Root("grantees"), "Invalid Grantees", fmt.Sprintf("%s can't be combined with other grantees.", keyword))
+		}
+	}
+
+	return diags
+}
@@ -0,0 +1,152 @@
+package user
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
+)
+
+func granteesSet(names ...string) types.Set {
+	elements := make([]attr.Value, 0)
+	for _, n := range names {
+		elements = append(elements, types.StringValue(n))
+	}
+	set, _ := types.SetValue(types.StringType, elements)
+	return set
+}
+
+func Test_granteesFromModel(t *testing.T) {
+	tests := []struct {
+		name     string
+		grantees types.Set
+		want     *dbops.UserGrantees
+	}{
+		{
+			name:     "Not managed",
+			grantees: types.SetNull(types.StringType),
+			want:     nil,
+		},
+		{
+			name:     "Any",
+			grantees: granteesSet("ANY"),
+			want:     &dbops.UserGrantees{Any: true},
+		},
+		{
+			name:     "None",
+			grantees: granteesSet("NONE"),
+			want:     &dbops.UserGrantees{},
+		},
+		{
+			name:     "Names",
+			grantees: granteesSet("jane", "reader"),
+			want:     &dbops.UserGrantees{Names: []string{"jane", "reader"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, diags := granteesFromModel(context.Background(), tt.grantees)
+			if diags.HasError() {
+				t.Fatalf("granteesFromModel() diags = %v", diags)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("granteesFromModel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_granteesFromServer(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  types.Set
+		grantees *dbops.UserGrantees
+		want     types.Set
+	}{
+		{
+			name:     "Not managed",
+			current:  types.SetNull(types.StringType),
+			grantees: &dbops.UserGrantees{Names: []string{"jane"}},
+			want:     types.SetNull(types.StringType),
+		},
+		{
+			name:     "Grantees couldn't be read",
+			current:  granteesSet("jane"),
+			grantees: nil,
+			want:     granteesSet("jane"),
+		},
+		{
+			name:     "Any",
+			current:  granteesSet("jane"),
+			grantees: &dbops.UserGrantees{Any: true},
+			want:     granteesSet("ANY"),
+		},
+		{
+			name:     "Any except",
+			current:  granteesSet("ANY"),
+			grantees: &dbops.UserGrantees{Any: true, Except: []string{"jane"}},
+			want:     types.SetNull(types.StringType),
+		},
+		{
+			name:     "None",
+			current:  granteesSet("jane"),
+			grantees: &dbops.UserGrantees{},
+			want:     granteesSet("NONE"),
+		},
+		{
+			name:     "Names in another order",
+			current:  granteesSet("jane", "reader"),
+			grantees: &dbops.UserGrantees{Names: []string{"reader", "jane"}},
+			want:     granteesSet("jane", "reader"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := granteesFromServer(tt.current, tt.grantees); !got.Equal(tt.want) {
+				t.Errorf("granteesFromServer() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_validateGrantees(t *testing.T) {
+	tests := []struct {
+		name     string
+		grantees types.Set
+		wantErr  bool
+	}{
+		{
+			name:     "Not managed",
+			grantees: types.SetNull(types.StringType),
+		},
+		{
+			name:     "Any alone",
+			grantees: granteesSet("ANY"),
+		},
+		{
+			name:     "Names",
+			grantees: granteesSet("jane", "reader"),
+		},
+		{
+			name:     "Any with names",
+			grantees: granteesSet("ANY", "jane"),
+			wantErr:  true,
+		},
+		{
+			name:     "None with names",
+			grantees: granteesSet("NONE", "jane"),
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diags := validateGrantees(tt.grantees); diags.HasError() != tt.wantErr {
+				t.Errorf("validateGrantees() diags = %v, wantErr %v", diags, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package user
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
+)
+
+// hostsFromModel returns the hosts in the 'host' attribute, or nil when hosts are not managed by the resource.
+func hostsFromModel(ctx context.Context, hosts types.List) ([]dbops.UserHost, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if hosts.IsNull() || hosts.IsUnknown() {
+		return nil, diags
+	}
+
+	elements := make([]Host, 0)
+	diags.Append(hosts.ElementsAs(ctx, &elements, false)...)
+
+	ret := make([]dbops.UserHost, 0)
+	for _, h := range elements {
+		values := make([]string, 0)
+		if !h.Values.IsNull() && !h.Values.IsUnknown() {
+			diags.Append(h.Values.ElementsAs(ctx, &values, false)...)
+		}
+
+		ret = append(ret, dbops.UserHost{
+			Type:   h.Type.ValueString(),
+			Values: values,
+		})
+	}
+
+	return ret, diags
+}
+
+// hostsToModel returns the value of the 'host' attribute matching the given hosts.
+func hostsToModel(hosts []dbops.UserHost) types.List {
+	elements := make([]attr.Value, 0)
+	for _, h := range hosts {
+		values := types.ListNull(types.StringType)
+		if len(h.Values) > 0 {
+			valueElements := make([]attr.Value, 0)
+			for _, v := range h.Values {
+				valueElements = append(valueElements, types.StringValue(v))
+			}
+			values, _ = types.ListValue(types.StringType, valueElements)
+		}
+
+		element, _ := types.ObjectValue(hostAttrTypes, map[string]attr.Value{
+			"type":   types.StringValue(h.Type),
+			"values": values,
+		})
+		elements = append(elements, element)
+	}
+
+	list, _ := types.ListValue(types.ObjectType{AttrTypes: hostAttrTypes}, elements)
+	return list
+}
+
+// validateHosts checks the 'host' attribute can be turned into a valid HOST clause.
+func validateHosts(hosts []dbops.UserHost) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for idx, h := range hosts {
+		attrPath := path.Root("host").AtListIndex(idx)
+
+		switch h.Type {
+		case dbops.HostTypeLocal, dbops.HostTypeAny, dbops.HostTypeNone:
+			if len(h.Values) > 0 {
+				diags.AddAttributeError(attrPath.AtName("values"), "Invalid Host", fmt.Sprintf("'values' can't be set for host type %s.", h.Type))
+			}
+			if (h.Type == dbops.HostTypeAny || h.Type == dbops.HostTypeNone) && len(hosts) > 1 {
+				diags.AddAttributeError(attrPath.AtName("type"), "Invalid Host", fmt.Sprintf("Host type %s can't be combined with other hosts.", h.Type))
+			}
+		default:
+			if len(h.Values) == 0 {
+				diags.AddAttributeError(attrPath.AtName("values"), "Invalid Host", fmt.Sprintf("'values' is required for host type %s.", h.Type))
+			}
+		}
+	}
+
+	return diags
+}
@@ -1,6 +1,7 @@
 package user
 
 import (
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -10,7 +11,44 @@ type User struct {
 	Name                      types.String `tfsdk:"name"`
 	DefaultRole               types.String `tfsdk:"default_role"`
 	SettingsProfile           types.String `tfsdk:"settings_profile"`
+	SettingsProfiles          types.Set    `tfsdk:"settings_profiles"`
 	SSLCertificateCN          types.String `tfsdk:"ssl_certificate_cn"`
 	PasswordSha256Hash        types.String `tfsdk:"password_sha256_hash_wo"`
+	PasswordBcryptHash        types.String `tfsdk:"password_bcrypt_hash_wo"`
+	PasswordPlaintext         types.String `tfsdk:"password_plaintext_wo"`
+	NoPassword                types.Bool   `tfsdk:"no_password"`
 	PasswordSha256HashVersion types.Int32  `tfsdk:"password_sha256_hash_wo_version"`
+	Expired                   types.Bool   `tfsdk:"expired"`
+	AuthType                  types.String `tfsdk:"auth_type"`
+	Hosts                     types.List   `tfsdk:"host"`
+	ValidUntil                types.String `tfsdk:"valid_until"`
+	Authentications           types.List   `tfsdk:"authentication"`
+	Grantees                  types.Set    `tfsdk:"grantees"`
+	Comment                   types.String `tfsdk:"comment"`
+	PreventDestroyOnDrift     types.Bool   `tfsdk:"prevent_destroy_on_drift"`
+	RevokeGrantsOnDestroy     types.Bool   `tfsdk:"revoke_grants_on_destroy"`
+}
+
+type Host struct {
+	Type   types.String `tfsdk:"type"`
+	Values types.List   `tfsdk:"values"`
+}
+
+var hostAttrTypes = map[string]attr.Type{
+	"type":   types.StringType,
+	"values": types.ListType{ElemType: types.StringType},
+}
+
+type Authentication struct {
+	Type               types.String `tfsdk:"type"`
+	PasswordSha256Hash types.String `tfsdk:"password_sha256_hash_wo"`
+	PasswordBcryptHash types.String `tfsdk:"password_bcrypt_hash_wo"`
+	SSLCertificateCN   types.String `tfsdk:"ssl_certificate_cn"`
+}
+
+var authenticationAttrTypes = map[string]attr.Type{
+	"type":                    types.StringType,
+	"password_sha256_hash_wo": types.StringType,
+	"password_bcrypt_hash_wo": types.StringType,
+	"ssl_certificate_cn":      types.StringType,
 }
@@ -0,0 +1,80 @@
+package user
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
+)
+
+// fakeClient returns the given user from GetUserByName. Calling any other method of dbops.Client panics.
+type fakeClient struct {
+	dbops.Client
+	user *dbops.User
+}
+
+func (c *fakeClient) GetUserByName(_ context.Context, _ string, _ *string) (*dbops.User, error) {
+	return c.user, nil
+}
+
+func TestResource_Read_restrictedSystemUsers(t *testing.T) {
+	ctx := context.Background()
+	r := &Resource{client: &fakeClient{user: &dbops.User{Name: "john", Partial: true}}}
+
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+
+	state := tfsdk.State{
+		Schema: schemaResp.Schema,
+		Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+	}
+	for p, v := range map[string]any{
+		"id":                              types.StringValue("john"),
+		"name":                            types.StringValue("john"),
+		"auth_type":                       types.StringValue(authTypeNoPassword),
+		"no_password":                     types.BoolValue(true),
+		"password_sha256_hash_wo_version": types.Int32Value(1),
+		"settings_profile":                types.StringValue("readonly"),
+		"default_role":                    types.StringValue("reader"),
+	} {
+		if diags := state.SetAttribute(ctx, path.Root(p), v); diags.HasError() {
+			t.Fatalf("SetAttribute(%q) error = %v", p, diags)
+		}
+	}
+
+	req := resource.ReadRequest{State: state}
+	resp := resource.ReadResponse{State: state}
+	r.Read(ctx, req, &resp)
+
+	if resp.Diagnostics.HasError() || resp.Diagnostics.WarningsCount() > 0 {
+		t.Fatalf("Read() diagnostics = %v, want none", resp.Diagnostics)
+	}
+
+	var got User
+	if diags := resp.State.Get(ctx, &got); diags.HasError() {
+		t.Fatalf("State.Get() error = %v", diags)
+	}
+
+	// Nothing but the name could be read, so no drift is reported.
+	if !got.NoPassword.Equal(types.BoolValue(true)) {
+		t.Errorf("Read() no_password = %v, want true", got.NoPassword)
+	}
+	if !got.PasswordSha256HashVersion.Equal(types.Int32Value(1)) {
+		t.Errorf("Read() password_sha256_hash_wo_version = %v, want 1", got.PasswordSha256HashVersion)
+	}
+	if !got.AuthType.Equal(types.StringValue(authTypeNoPassword)) {
+		t.Errorf("Read() auth_type = %v, want %q", got.AuthType, authTypeNoPassword)
+	}
+	if !got.SettingsProfile.Equal(types.StringValue("readonly")) {
+		t.Errorf("Read() settings_profile = %v, want readonly", got.SettingsProfile)
+	}
+	if !got.DefaultRole.Equal(types.StringValue("reader")) {
+		t.Errorf("Read() default_role = %v, want reader", got.DefaultRole)
+	}
+}
@@ -0,0 +1,60 @@
+package user
+
+import (
+	"context"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// managedSettingsProfile returns the value of 'settings_profile' matching the profiles associated to the user.
+// The profile is only tracked when managed by this resource, and kept as long as it is still associated to the user.
+// Otherwise the user's profile is only reported when it has exactly one, as there is no way to tell which one is
+// managed among several: the attribute becomes null, so that Terraform plans to associate the configured profile again.
+func managedSettingsProfile(managed types.String, profiles []string) types.String {
+	if managed.IsNull() || managed.IsUnknown() {
+		return managed
+	}
+
+	if slices.Contains(profiles, managed.ValueString()) {
+		return managed
+	}
+
+	if len(profiles) == 1 {
+		return types.StringValue(profiles[0])
+	}
+
+	return types.StringNull()
+}
+
+// settingsProfilesFromModel returns the profiles in the 'settings_profiles' attribute, or nil when they are not managed
+// by the resource.
+func settingsProfilesFromModel(ctx context.Context, profiles types.Set) ([]string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if profiles.IsNull() || profiles.IsUnknown() {
+		return nil, diags
+	}
+
+	names := make([]string, 0)
+	diags.Append(profiles.ElementsAs(ctx, &names, false)...)
+
+	return names, diags
+}
+
+// settingsProfilesFromServer returns the value of 'settings_profiles' matching the profiles associated to the user.
+// The profiles are only tracked when managed by this resource, and were read successfully.
+func settingsProfilesFromServer(current types.Set, profiles []string) types.Set {
+	if current.IsNull() || current.IsUnknown() || profiles == nil {
+		return current
+	}
+
+	elements := make([]attr.Value, 0)
+	for _, p := range profiles {
+		elements = append(elements, types.StringValue(p))
+	}
+
+	set, _ := types.SetValue(types.StringType, elements)
+	return set
+}
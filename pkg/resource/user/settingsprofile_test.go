@@ -0,0 +1,116 @@
+package user
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func Test_managedSettingsProfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		managed  types.String
+		profiles []string
+		want     types.String
+	}{
+		{
+			name:     "Managed profile still associated",
+			managed:  types.StringValue("readonly"),
+			profiles: []string{"readonly"},
+			want:     types.StringValue("readonly"),
+		},
+		{
+			name:     "Managed profile removed",
+			managed:  types.StringValue("readonly"),
+			profiles: []string{},
+			want:     types.StringNull(),
+		},
+		{
+			name:     "Managed profile changed",
+			managed:  types.StringValue("readonly"),
+			profiles: []string{"analytics"},
+			want:     types.StringValue("analytics"),
+		},
+		{
+			name:     "Managed profile among several",
+			managed:  types.StringValue("readonly"),
+			profiles: []string{"analytics", "readonly", "limits"},
+			want:     types.StringValue("readonly"),
+		},
+		{
+			name:     "Managed profile replaced by several",
+			managed:  types.StringValue("readonly"),
+			profiles: []string{"analytics", "limits"},
+			want:     types.StringNull(),
+		},
+		{
+			name:     "Not managed with a single profile",
+			managed:  types.StringNull(),
+			profiles: []string{"analytics"},
+			want:     types.StringNull(),
+		},
+		{
+			name:     "Not managed with several profiles",
+			managed:  types.StringNull(),
+			profiles: []string{"analytics", "limits"},
+			want:     types.StringNull(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := managedSettingsProfile(tt.managed, tt.profiles); !got.Equal(tt.want) {
+				t.Errorf("managedSettingsProfile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_settingsProfilesFromServer(t *testing.T) {
+	set := func(names ...string) types.Set {
+		elements := make([]attr.Value, 0)
+		for _, n := range names {
+			elements = append(elements, types.StringValue(n))
+		}
+		return types.SetValueMust(types.StringType, elements)
+	}
+
+	tests := []struct {
+		name     string
+		current  types.Set
+		profiles []string
+		want     types.Set
+	}{
+		{
+			name:     "Managed profiles unchanged",
+			current:  set("analytics", "limits"),
+			profiles: []string{"limits", "analytics"},
+			want:     set("analytics", "limits"),
+		},
+		{
+			name:     "Managed profile removed out of band",
+			current:  set("analytics", "limits"),
+			profiles: []string{"analytics"},
+			want:     set("analytics"),
+		},
+		{
+			name:     "Profile added out of band",
+			current:  set("analytics"),
+			profiles: []string{"analytics", "readonly"},
+			want:     set("analytics", "readonly"),
+		},
+		{
+			name:     "Not managed",
+			current:  types.SetNull(types.StringType),
+			profiles: []string{"analytics"},
+			want:     types.SetNull(types.StringType),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := settingsProfilesFromServer(tt.current, tt.profiles); !got.Equal(tt.want) {
+				t.Errorf("settingsProfilesFromServer() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
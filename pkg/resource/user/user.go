@@ -5,18 +5,22 @@ import (
 	_ "embed"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
-	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/pingcap/errors"
 
 	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
 )
@@ -25,9 +29,10 @@ import (
 var userResourceDescription string
 
 var (
-	_ resource.Resource               = &Resource{}
-	_ resource.ResourceWithConfigure  = &Resource{}
-	_ resource.ResourceWithModifyPlan = &Resource{}
+	_ resource.Resource                     = &Resource{}
+	_ resource.ResourceWithConfigure        = &Resource{}
+	_ resource.ResourceWithModifyPlan       = &Resource{}
+	_ resource.ResourceWithConfigValidators = &Resource{}
 )
 
 func NewResource() resource.Resource {
@@ -64,8 +69,9 @@ func (r *Resource) Schema(_ context.Context, _ resource.SchemaRequest, resp *res
 				Description: "Name of the user",
 			},
 			"ssl_certificate_cn": schema.StringAttribute{
-				Optional:    true,
-				Description: "CN of the SSL certificate to be used for the user (mutually exclusive with password_sha256_hash_wo).",
+				Optional:           true,
+				Description:        "CN of the SSL certificate to be used for the user (mutually exclusive with password_sha256_hash_wo and password_bcrypt_hash_wo).",
+				DeprecationMessage: "Use an 'authentication' entry of type ssl_certificate instead.",
 				PlanModifiers: []planmodifier.String{
 					// preserves user-specified value across refresh when API doesn't echo it
 					stringplanmodifier.UseStateForUnknown(),
@@ -73,47 +79,225 @@ func (r *Resource) Schema(_ context.Context, _ resource.SchemaRequest, resp *res
 				Validators: []validator.String{
 					// prevent setting both fields together (attribute-level)
 					stringvalidator.ConflictsWith(path.MatchRoot("password_sha256_hash_wo")),
+					stringvalidator.ConflictsWith(path.MatchRoot("password_bcrypt_hash_wo")),
+					stringvalidator.ConflictsWith(path.MatchRoot("authentication")),
 				},
 			},
 			"password_sha256_hash_wo": schema.StringAttribute{
+				Optional:           true,
+				Description:        "SHA256 hash of the password to be set for the user (write-only, mutually exclusive with ssl_certificate_cn and password_bcrypt_hash_wo).",
+				DeprecationMessage: "Use an 'authentication' entry of type sha256_password instead.",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(regexp.MustCompile(`^[a-fA-F0-9]{64}$`), "password_sha256_hash must be a valid SHA256 hash"),
+					stringvalidator.ConflictsWith(path.MatchRoot("ssl_certificate_cn")),
+					stringvalidator.ConflictsWith(path.MatchRoot("password_bcrypt_hash_wo")),
+					stringvalidator.ConflictsWith(path.MatchRoot("authentication")),
+				},
+				WriteOnly: true,
+			},
+			"password_bcrypt_hash_wo": schema.StringAttribute{
 				Optional:    true,
-				Description: "SHA256 hash of the password to be set for the user (write-only, mutually exclusive with ssl_certificate_cn).",
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
+				Description: "Bcrypt hash of the password to be set for the user, such as the output of htpasswd -nbBC 12 (write-only, mutually exclusive with ssl_certificate_cn and password_sha256_hash_wo).",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(regexp.MustCompile(`^\$2[aby]\$\d{2}\$[./A-Za-z0-9]{53}$`), "password_bcrypt_hash must be a valid bcrypt hash"),
+					stringvalidator.ConflictsWith(path.MatchRoot("ssl_certificate_cn")),
+					stringvalidator.ConflictsWith(path.MatchRoot("password_sha256_hash_wo")),
+					stringvalidator.ConflictsWith(path.MatchRoot("authentication")),
 				},
+				WriteOnly: true,
+			},
+			"password_plaintext_wo": schema.StringAttribute{
+				Optional:    true,
+				Description: "Password to be set for the user, stored as is by ClickHouse (write-only, mutually exclusive with the other identification methods). The password can be read by anyone with access to the server's access storage, e.g. the users.xml file or the local_directory storage, and is sent in clear text when the user is created: only use it for legacy setups, and prefer password_sha256_hash_wo or password_bcrypt_hash_wo otherwise.",
 				Validators: []validator.String{
-					stringvalidator.RegexMatches(regexp.MustCompile(`^[a-fA-F0-9]{64}$`), "password_sha256_hash must be a valid SHA256 hash"),
+					stringvalidator.LengthAtLeast(1),
 					stringvalidator.ConflictsWith(path.MatchRoot("ssl_certificate_cn")),
+					stringvalidator.ConflictsWith(path.MatchRoot("password_sha256_hash_wo")),
+					stringvalidator.ConflictsWith(path.MatchRoot("password_bcrypt_hash_wo")),
+					stringvalidator.ConflictsWith(path.MatchRoot("authentication")),
 				},
 				WriteOnly: true,
 			},
+			"no_password": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Whether the user can log in without any credentials (mutually exclusive with the other identification methods). Anyone able to connect to the server can log in as this user, so only use it for ephemeral test users, ideally restricted with the host attribute.",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
+			},
 			"password_sha256_hash_wo_version": schema.Int32Attribute{
 				Optional:    true,
-				Description: "Version of the password_sha256_hash_wo field. Bump this value to require a force update of the password on the user.",
-				PlanModifiers: []planmodifier.Int32{
-					int32planmodifier.RequiresReplace(),
+				Description: "Version of the passwords set in password_sha256_hash_wo, password_bcrypt_hash_wo, password_plaintext_wo or in the authentication entries. Bump this value to change the password of the user in place.",
+			},
+			"authentication": schema.ListNestedAttribute{
+				Optional:    true,
+				Description: "Identification methods of the user, any of which can be used to log in. Several methods require ClickHouse 24.9 or later. Mutually exclusive with the other identification methods, e.g. password_sha256_hash_wo or ssl_certificate_cn.",
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"type": schema.StringAttribute{
+							Required:    true,
+							Description: "Type of identification method. One of sha256_password, bcrypt_password or ssl_certificate.",
+							Validators: []validator.String{
+								stringvalidator.OneOf(
+									dbops.AuthTypeSHA256Password,
+									dbops.AuthTypeBcryptPassword,
+									dbops.AuthTypeSSLCertificate,
+								),
+							},
+						},
+						"password_sha256_hash_wo": schema.StringAttribute{
+							Optional:    true,
+							Description: "SHA256 hash of the password (write-only). Required for sha256_password, must be null for the other types.",
+							Validators: []validator.String{
+								stringvalidator.RegexMatches(regexp.MustCompile(`^[a-fA-F0-9]{64}$`), "password_sha256_hash must be a valid SHA256 hash"),
+							},
+							WriteOnly: true,
+						},
+						"password_bcrypt_hash_wo": schema.StringAttribute{
+							Optional:    true,
+							Description: "Bcrypt hash of the password (write-only). Required for bcrypt_password, must be null for the other types.",
+							Validators: []validator.String{
+								stringvalidator.RegexMatches(regexp.MustCompile(`^\$2[aby]\$\d{2}\$[./A-Za-z0-9]{53}$`), "password_bcrypt_hash must be a valid bcrypt hash"),
+							},
+							WriteOnly: true,
+						},
+						"ssl_certificate_cn": schema.StringAttribute{
+							Optional:    true,
+							Description: "CN of the SSL certificate. Required for ssl_certificate, must be null for the other types.",
+						},
+					},
 				},
 			},
 			"default_role": schema.StringAttribute{
 				Optional:    true,
-				Description: "Default role to assign at creation time.",
+				Description: "Default role of the user. Changing it replaces all the default roles of the user, and requires the new role to be granted to the user already.",
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
 			"settings_profile": schema.StringAttribute{
 				Optional:    true,
-				Description: "Settings profile to assign at creation time.",
+				Description: "Settings profile to assign to the user. Changing it replaces the previous profile in place, leaving other profiles associated to the user untouched. Removing it drops the previous profile from the user.",
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("settings_profiles")),
+				},
+			},
+			"settings_profiles": schema.SetAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "All the settings profiles of the user, associated when the user is created. Changing it adds and removes profiles in place. When null, the profiles are not managed by this attribute. Don't use it together with settings_profile or clickhousedbops_settings_profile_association resources for the same user.",
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+					setvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
+			"auth_type": schema.StringAttribute{
+				Computed:    true,
+				Description: "Authentication method of the user as reported by ClickHouse, e.g. 'sha256_password', 'bcrypt_password' or 'ssl_certificate'. The first one when the user has several methods. The password hash is never read back.",
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
+			"host": schema.ListNestedAttribute{
+				Optional:    true,
+				Description: "Hosts the user is allowed to connect from. When null, hosts are not managed by this resource and the user can connect from any host when created.",
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"type": schema.StringAttribute{
+							Required:    true,
+							Description: "Type of host restriction. One of LOCAL, IP, NAME, REGEXP, LIKE, ANY or NONE. ANY and NONE can't be combined with other hosts.",
+							Validators: []validator.String{
+								stringvalidator.OneOf(
+									dbops.HostTypeLocal,
+									dbops.HostTypeIP,
+									dbops.HostTypeName,
+									dbops.HostTypeRegexp,
+									dbops.HostTypeLike,
+									dbops.HostTypeAny,
+									dbops.HostTypeNone,
+								),
+							},
+						},
+						"values": schema.ListAttribute{
+							ElementType: types.StringType,
+							Optional:    true,
+							Description: "IP addresses or subnets, host names, regular expressions or LIKE patterns the user can connect from, depending on 'type'. Required for IP, NAME, REGEXP and LIKE, must be null for LOCAL, ANY and NONE.",
+							Validators: []validator.List{
+								listvalidator.SizeAtLeast(1),
+								listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+							},
+						},
+					},
+				},
+			},
+			"grantees": schema.SetAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "Users and roles the user can grant its privileges and roles to, or a single ANY or NONE. When null, grantees are not managed by this resource and the user can grant to anyone when created. Don't use it together with a clickhousedbops_user_grantees resource for the same user.",
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+					setvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
+			"valid_until": schema.StringAttribute{
+				Optional:    true,
+				Description: "Expiration time of the user's credentials, as an RFC3339 timestamp such as '2030-01-01T00:00:00Z'. When null, the credentials never expire.",
+			},
+			"expired": schema.BoolAttribute{
+				Computed:    true,
+				Description: "Whether the user's credentials have expired, i.e. the VALID UNTIL time set on the user is in the past. Always false when the user has no expiration.",
+			},
+			"comment": schema.StringAttribute{
+				Optional:    true,
+				Description: "Comment of the user, for example to record the team owning it. Changing it alters the user in place. When null, the comment is not managed by this resource. Comments on users are only supported by recent ClickHouse versions: with older ones, this attribute is ignored and a warning is reported during plan",
+			},
+			"prevent_destroy_on_drift": schema.BoolAttribute{
+				Optional:    true,
+				Description: "When true, refreshing fails instead of planning to replace the user when it was changed outside of Terraform in a way that can only be fixed by creating it again, e.g. a password set on a no_password user. The user then needs to be reviewed, and fixed or removed from the state manually. Defaults to false.",
+			},
+			"revoke_grants_on_destroy": schema.BoolAttribute{
+				Optional:    true,
+				Description: "When true, the privileges and roles granted to the user are revoked before dropping it, including the ones not managed by Terraform. Defaults to false.",
+			},
 		},
 		MarkdownDescription: userResourceDescription,
 	}
 }
 
+func (r *Resource) ConfigValidators(_ context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{authMethodsValidator{}}
+}
+
 func (r *Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Changing or dropping a user kept in a read-only user directory, e.g. users.xml, would fail on apply.
+	if r.client != nil && !req.State.Raw.IsNull() && !req.Plan.Raw.Equal(req.State.Raw) {
+		var name, clusterName types.String
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("name"), &name)...)
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("cluster_name"), &clusterName)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		storage, err := r.client.ReadOnlyAccessStorage(ctx, dbops.AccessEntityUser, name.ValueString(), clusterName.ValueStringPointer())
+		if err != nil {
+			resp.Diagnostics.AddError("Error Checking the Access Storage of the User", fmt.Sprintf("%+v\n", err))
+			return
+		}
+		if storage != "" {
+			resp.Diagnostics.AddError(
+				"User Kept in a Read-Only Access Storage",
+				fmt.Sprintf("The user %q is kept in the read-only %q user directory, so it can't be changed or destroyed with SQL queries. Change it in the server configuration instead, or remove it from the Terraform state.", name.ValueString(), storage),
+			)
+			return
+		}
+	}
+
 	if req.Plan.Raw.IsNull() {
 		// If the entire plan is null, the resource is planned for destruction.
 		return
@@ -125,25 +309,38 @@ func (r *Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanReques
 		return
 	}
 
-	passSet := !cfg.PasswordSha256Hash.IsNull() && !cfg.PasswordSha256Hash.IsUnknown()
-	cnSet := !cfg.SSLCertificateCN.IsNull() && !cfg.SSLCertificateCN.IsUnknown()
+	// Exactly one identification method is enforced by authMethodsValidator.
+	if !cfg.Authentications.IsNull() {
+		resp.Diagnostics.Append(validateAuthentications(ctx, cfg.Authentications)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
 
-	if (passSet && cnSet) || (!passSet && !cnSet) {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("ssl_certificate_cn"),
-			"Invalid Authentication Configuration",
-			"Exactly one of 'ssl_certificate_cn' or 'password_sha256_hash_wo' must be specified.",
-		)
+	if _, err := parseValidUntil(cfg.ValidUntil); err != nil {
 		resp.Diagnostics.AddAttributeError(
-			path.Root("password_sha256_hash_wo"),
-			"Invalid Authentication Configuration",
-			"Exactly one of 'ssl_certificate_cn' or 'password_sha256_hash_wo' must be specified.",
+			path.Root("valid_until"),
+			"Invalid Expiration Time",
+			fmt.Sprintf("'valid_until' must be an RFC3339 timestamp such as '2030-01-01T00:00:00Z': %s", err),
 		)
+	}
+
+	hosts, diags := hostsFromModel(ctx, cfg.Hosts)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(validateHosts(hosts)...)
+	resp.Diagnostics.Append(validateGrantees(cfg.Grantees)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
 	if r.client != nil {
-		isReplicatedStorage, err := r.client.IsReplicatedStorage(ctx)
+		var clusterName types.String
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("cluster_name"), &clusterName)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		isReplicatedStorage, err := r.client.IsReplicatedStorage(ctx, clusterName.ValueStringPointer())
 		if err != nil {
 			resp.Diagnostics.AddError(
 				"Error Checking if service is using replicated storage",
@@ -152,6 +349,25 @@ func (r *Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanReques
 			return
 		}
 
+		if !cfg.Comment.IsNull() {
+			supported, err := r.client.SupportsUserComment(ctx, clusterName.ValueStringPointer())
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error Checking if service supports comments on users",
+					fmt.Sprintf("%+v\n", err),
+				)
+				return
+			}
+
+			if !supported {
+				resp.Diagnostics.AddAttributeWarning(
+					path.Root("comment"),
+					"User comments not supported",
+					"Your ClickHouse version doesn't support comments on users, the 'comment' attribute will be ignored.",
+				)
+			}
+		}
+
 		if isReplicatedStorage {
 			var config User
 			diags := req.Config.Get(ctx, &config)
@@ -161,10 +377,10 @@ func (r *Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanReques
 			}
 
 			// User cannot specify 'cluster_name' or apply will fail.
-			if !config.ClusterName.IsNull() {
+			if !config.ClusterName.IsNull() || r.client.DefaultClusterName() != nil {
 				resp.Diagnostics.AddWarning(
 					"Invalid configuration",
-					"Your ClickHouse cluster seems to be using Replicated storage for users, please remove the 'cluster_name' attribute from your User resource definition if you encounter any errors.",
+					"Your ClickHouse cluster seems to be using Replicated storage for users, please remove the 'cluster_name' attribute from your User resource definition, or the 'default_cluster' provider attribute, if you encounter any errors.",
 				)
 			}
 		}
@@ -194,9 +410,21 @@ func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp
 	u := dbops.User{
 		Name:               plan.Name.ValueString(),
 		PasswordSha256Hash: config.PasswordSha256Hash.ValueString(),
+		PasswordBcryptHash: config.PasswordBcryptHash.ValueString(),
+		PasswordPlaintext:  config.PasswordPlaintext.ValueString(),
+		NoPassword:         plan.NoPassword.ValueBool(),
+		Comment:            plan.Comment.ValueStringPointer(),
 		SSLCertificateCN:   plan.SSLCertificateCN.ValueString(),
 	}
 
+	// Password hashes are write-only, so the identification methods are only complete in the configuration.
+	authentications, diags := authenticationsFromModel(ctx, config.Authentications)
+	if diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+	u.Authentications = authentications
+
 	if !plan.DefaultRole.IsNull() && !plan.DefaultRole.IsUnknown() {
 		u.DefaultRole = plan.DefaultRole.ValueString()
 	}
@@ -205,6 +433,34 @@ func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp
 		u.SettingsProfile = plan.SettingsProfile.ValueString()
 	}
 
+	settingsProfiles, diags := settingsProfilesFromModel(ctx, plan.SettingsProfiles)
+	if diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+	u.SettingsProfiles = settingsProfiles
+
+	hosts, diags := hostsFromModel(ctx, plan.Hosts)
+	if diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+	u.Hosts = hosts
+
+	grantees, diags := granteesFromModel(ctx, plan.Grantees)
+	if diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+	u.Grantees = grantees
+
+	validUntil, err := parseValidUntil(plan.ValidUntil)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid Expiration Time", fmt.Sprintf("%+v\n", err))
+		return
+	}
+	u.ValidUntil = validUntil
+
 	createdUser, err := r.client.CreateUser(ctx, u, plan.ClusterName.ValueStringPointer())
 	if err != nil {
 		resp.Diagnostics.AddError("Error Creating ClickHouse User", fmt.Sprintf("%+v\n", err))
@@ -217,7 +473,18 @@ func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp
 		Name:                      types.StringValue(createdUser.Name),
 		DefaultRole:               plan.DefaultRole,
 		SettingsProfile:           plan.SettingsProfile,
+		SettingsProfiles:          plan.SettingsProfiles,
 		PasswordSha256HashVersion: plan.PasswordSha256HashVersion,
+		NoPassword:                plan.NoPassword,
+		Expired:                   types.BoolValue(createdUser.Expired),
+		AuthType:                  types.StringValue(createdUser.AuthType),
+		Hosts:                     plan.Hosts,
+		ValidUntil:                plan.ValidUntil,
+		Authentications:           plan.Authentications,
+		Grantees:                  plan.Grantees,
+		Comment:                   plan.Comment,
+		PreventDestroyOnDrift:     plan.PreventDestroyOnDrift,
+		RevokeGrantsOnDestroy:     plan.RevokeGrantsOnDestroy,
 	}
 
 	state.SSLCertificateCN = types.StringNull()
@@ -249,30 +516,91 @@ func (r *Resource) Read(ctx context.Context, req resource.ReadRequest, resp *res
 		return
 	}
 
+	if user.Partial {
+		// Only the name could be read: the other attributes are kept as they are, rather than reported as drift.
+		state.Name = types.StringValue(user.Name)
+		state.ID = types.StringValue(user.Name)
+		if diags := resp.State.Set(ctx, &state); diags.HasError() {
+			resp.Diagnostics.Append(diags...)
+		}
+		return
+	}
+
+	// Checked against the previously known authentication method, before it's refreshed.
+	expected := expectedAuthType(state)
+
 	state.Name = types.StringValue(user.Name)
 	state.ID = types.StringValue(user.Name)
-	if user.SSLCertificateCN != "" {
+	state.Expired = types.BoolValue(user.Expired)
+	state.AuthType = types.StringValue(user.AuthType)
+	if !state.Authentications.IsNull() {
+		// The certificate CN is tracked in the 'authentication' attribute instead.
+		if len(user.Authentications) > 0 {
+			authentications, diags := authenticationsFromModel(ctx, state.Authentications)
+			if diags.HasError() {
+				resp.Diagnostics.Append(diags...)
+				return
+			}
+			if !sameAuthentications(authentications, user.Authentications) {
+				state.Authentications = authenticationsToModel(user.Authentications)
+			}
+		}
+	} else if user.SSLCertificateCN != "" {
 		state.SSLCertificateCN = types.StringValue(user.SSLCertificateCN)
 	} else if state.SSLCertificateCN.IsUnknown() {
 		// rare case on first refresh; make it explicitly null once
 		state.SSLCertificateCN = types.StringNull()
+	} else if user.AuthType == authTypeNoPassword && state.NoPassword.IsNull() {
+		// e.g. imported users.
+		state.NoPassword = types.BoolValue(true)
 	}
 
-	if len(user.SettingsProfiles) == 0 {
-		state.SettingsProfile = types.StringNull()
-	} else if !state.SettingsProfile.IsNull() && !state.SettingsProfile.IsUnknown() {
-		// Preserve planned value when still associated; otherwise mirror the first profile returned
-		// by ClickHouse so Terraform detects the drift.
-		wanted := state.SettingsProfile.ValueString()
-		found := false
-		for _, profile := range user.SettingsProfiles {
-			if profile == wanted {
-				found = true
-				break
+	// Flag authentication methods changed out of band. When a password is expected, clear its version
+	// so Terraform plans to set the configured password again.
+	if expected != "" && user.AuthType != expected {
+		resp.Diagnostics.AddWarning(
+			"ClickHouse User Authentication Method Drift",
+			fmt.Sprintf("User %q is expected to use the %q authentication method, but it is using %q.", user.Name, expected, user.AuthType),
+		)
+		switch expected {
+		case authTypeSHA256Password, authTypeBcryptPassword, authTypePlaintextPassword:
+			state.PasswordSha256HashVersion = types.Int32Null()
+		case authTypeNoPassword:
+			if state.PreventDestroyOnDrift.ValueBool() {
+				resp.Diagnostics.AddError(
+					"ClickHouse User Changed Outside of Terraform",
+					fmt.Sprintf("User %q was changed outside of Terraform, and planning no_password again would replace it. Review the user, then fix it or remove it from the Terraform state, or set prevent_destroy_on_drift to false to replace it.", user.Name),
+				)
+				return
 			}
+			// Planning no_password again replaces the user.
+			state.NoPassword = types.BoolValue(false)
 		}
-		if !found {
-			state.SettingsProfile = types.StringValue(user.SettingsProfiles[0])
+	}
+
+	// Other profiles may be associated to the user by clickhousedbops_settings_profile_association resources
+	// or out of band, and are ignored as long as the managed one is still associated.
+	state.SettingsProfile = managedSettingsProfile(state.SettingsProfile, user.SettingsProfiles)
+	state.SettingsProfiles = settingsProfilesFromServer(state.SettingsProfiles, user.SettingsProfiles)
+
+	state.ValidUntil = validUntilFromServer(state.ValidUntil, user.ValidUntil)
+	state.DefaultRole = defaultRoleFromServer(state.DefaultRole, user.DefaultRoles)
+	state.Grantees = granteesFromServer(state.Grantees, user.Grantees)
+
+	// The comment is only tracked when managed by this resource and supported by the server.
+	if !state.Comment.IsNull() && user.Comment != nil {
+		state.Comment = types.StringValue(*user.Comment)
+	}
+
+	// Hosts are only tracked when managed by this resource, and were read successfully.
+	if !state.Hosts.IsNull() && user.Hosts != nil {
+		hosts, diags := hostsFromModel(ctx, state.Hosts)
+		if diags.HasError() {
+			resp.Diagnostics.Append(diags...)
+			return
+		}
+		if !dbops.SameHosts(hosts, user.Hosts) {
+			state.Hosts = hostsToModel(user.Hosts)
 		}
 	}
 
@@ -296,11 +624,78 @@ func (r *Resource) Update(ctx context.Context, req resource.UpdateRequest, resp
 		ID:               state.ID.ValueString(),
 		Name:             plan.Name.ValueString(),
 		SSLCertificateCN: plan.SSLCertificateCN.ValueString(),
-		// DefaultRole changes are not handled via ALTER; keep as is for now.
+		Comment:          plan.Comment.ValueStringPointer(),
 	}
 
-	if !plan.SettingsProfile.IsNull() && !plan.SettingsProfile.IsUnknown() {
-		u.SettingsProfile = plan.SettingsProfile.ValueString()
+	// Changing the default role replaces all the default roles of the user.
+	if !plan.DefaultRole.IsUnknown() && !plan.DefaultRole.Equal(state.DefaultRole) {
+		u.DefaultRoles = make([]string, 0)
+		if !plan.DefaultRole.IsNull() {
+			u.DefaultRoles = append(u.DefaultRoles, plan.DefaultRole.ValueString())
+		}
+	}
+
+	// Password hashes are write-only, so they're only available in the configuration.
+	// Bumping their version changes the password in place, preserving grants and settings profiles.
+	if !plan.PasswordSha256HashVersion.Equal(state.PasswordSha256HashVersion) {
+		var sha256Password, bcryptPassword, plaintextPassword types.String
+		if diags := req.Config.GetAttribute(ctx, path.Root("password_sha256_hash_wo"), &sha256Password); diags.HasError() {
+			resp.Diagnostics.Append(diags...)
+			return
+		}
+		if diags := req.Config.GetAttribute(ctx, path.Root("password_bcrypt_hash_wo"), &bcryptPassword); diags.HasError() {
+			resp.Diagnostics.Append(diags...)
+			return
+		}
+		if diags := req.Config.GetAttribute(ctx, path.Root("password_plaintext_wo"), &plaintextPassword); diags.HasError() {
+			resp.Diagnostics.Append(diags...)
+			return
+		}
+		u.PasswordSha256Hash = sha256Password.ValueString()
+		u.PasswordBcryptHash = bcryptPassword.ValueString()
+		u.PasswordPlaintext = plaintextPassword.ValueString()
+	}
+
+	// All the identification methods are set again when any of them changed, or the password version was bumped.
+	if !plan.Authentications.IsNull() && (!plan.Authentications.Equal(state.Authentications) || !plan.PasswordSha256HashVersion.Equal(state.PasswordSha256HashVersion)) {
+		var config User
+		if diags := req.Config.Get(ctx, &config); diags.HasError() {
+			resp.Diagnostics.Append(diags...)
+			return
+		}
+		authentications, diags := authenticationsFromModel(ctx, config.Authentications)
+		if diags.HasError() {
+			resp.Diagnostics.Append(diags...)
+			return
+		}
+		u.Authentications = authentications
+	}
+
+	hosts, diags := hostsFromModel(ctx, plan.Hosts)
+	if diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+	u.Hosts = hosts
+
+	grantees, diags := granteesFromModel(ctx, plan.Grantees)
+	if diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+	u.Grantees = grantees
+
+	validUntil, err := parseValidUntil(plan.ValidUntil)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid Expiration Time", fmt.Sprintf("%+v\n", err))
+		return
+	}
+	u.ValidUntil = validUntil
+
+	settingsProfiles, diags := settingsProfilesFromModel(ctx, plan.SettingsProfiles)
+	if diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
 	}
 
 	updated, err := r.client.UpdateUser(ctx, u, plan.ClusterName.ValueStringPointer())
@@ -309,12 +704,53 @@ func (r *Resource) Update(ctx context.Context, req resource.UpdateRequest, resp
 		return
 	}
 
+	if !plan.SettingsProfile.Equal(state.SettingsProfile) {
+		updated, err = r.client.UpdateUserSettingsProfile(ctx, updated.Name, state.SettingsProfile.ValueStringPointer(), plan.SettingsProfile.ValueStringPointer(), plan.ClusterName.ValueStringPointer())
+		if err != nil {
+			resp.Diagnostics.AddError("Error Updating ClickHouse User Settings Profile", fmt.Sprintf("%+v\n", err))
+			return
+		}
+	}
+
+	if settingsProfiles != nil && !plan.SettingsProfiles.Equal(state.SettingsProfiles) {
+		// Profiles associated out of band are removed as well, since the attribute lists all of them.
+		for _, p := range updated.SettingsProfiles {
+			if !slices.Contains(settingsProfiles, p) {
+				updated, err = r.client.UpdateUserSettingsProfile(ctx, updated.Name, &p, nil, plan.ClusterName.ValueStringPointer())
+				if err != nil {
+					resp.Diagnostics.AddError("Error Updating ClickHouse User Settings Profiles", fmt.Sprintf("%+v\n", err))
+					return
+				}
+			}
+		}
+		for _, p := range settingsProfiles {
+			updated, err = r.client.UpdateUserSettingsProfile(ctx, updated.Name, nil, &p, plan.ClusterName.ValueStringPointer())
+			if err != nil {
+				resp.Diagnostics.AddError("Error Updating ClickHouse User Settings Profiles", fmt.Sprintf("%+v\n", err))
+				return
+			}
+		}
+	}
+
 	state.Name = types.StringValue(updated.Name)
 	state.ID = types.StringValue(updated.Name)
-	// keep DefaultRole from plan in state
+	state.Expired = types.BoolValue(updated.Expired)
+	state.AuthType = types.StringValue(updated.AuthType)
+	state.PasswordSha256HashVersion = plan.PasswordSha256HashVersion
+	state.NoPassword = plan.NoPassword
 	state.DefaultRole = plan.DefaultRole
 	state.SettingsProfile = plan.SettingsProfile
-	if updated.SSLCertificateCN != "" {
+	state.SettingsProfiles = plan.SettingsProfiles
+	state.Hosts = plan.Hosts
+	state.Grantees = plan.Grantees
+	state.Comment = plan.Comment
+	state.PreventDestroyOnDrift = plan.PreventDestroyOnDrift
+	state.RevokeGrantsOnDestroy = plan.RevokeGrantsOnDestroy
+	state.ValidUntil = plan.ValidUntil
+	state.Authentications = plan.Authentications
+	if !plan.Authentications.IsNull() {
+		state.SSLCertificateCN = types.StringNull()
+	} else if updated.SSLCertificateCN != "" {
 		state.SSLCertificateCN = types.StringValue(updated.SSLCertificateCN)
 	} else if !plan.SSLCertificateCN.IsNull() && !plan.SSLCertificateCN.IsUnknown() {
 		state.SSLCertificateCN = plan.SSLCertificateCN
@@ -332,12 +768,41 @@ func (r *Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp
 		return
 	}
 
+	if state.RevokeGrantsOnDestroy.ValueBool() {
+		if err := r.revokeGrants(ctx, state.ID.ValueString(), state.ClusterName.ValueStringPointer()); err != nil {
+			resp.Diagnostics.AddError("Error Revoking ClickHouse User Grants", fmt.Sprintf("%+v\n", err))
+			return
+		}
+	}
+
 	if err := r.client.DeleteUser(ctx, state.ID.ValueString(), state.ClusterName.ValueStringPointer()); err != nil {
 		resp.Diagnostics.AddError("Error Deleting ClickHouse User", fmt.Sprintf("%+v\n", err))
 		return
 	}
 }
 
+// revokeGrants revokes all the privileges and roles granted to the user.
+func (r *Resource) revokeGrants(ctx context.Context, userName string, clusterName *string) error {
+	grants, err := r.client.ListUserGrants(ctx, userName, clusterName)
+	if err != nil {
+		return errors.WithMessage(err, "error listing user grants")
+	}
+
+	for _, role := range grants.Roles {
+		if err := r.client.RevokeGrantRole(ctx, role, &userName, nil, clusterName); err != nil {
+			return errors.WithMessage(err, fmt.Sprintf("error revoking role %q", role))
+		}
+	}
+
+	for _, g := range grants.Privileges {
+		if err := r.client.RevokeGrantPrivilege(ctx, g.AccessType, g.DatabaseName, g.TableName, g.ColumnName, &userName, nil, clusterName); err != nil {
+			return errors.WithMessage(err, fmt.Sprintf("error revoking privilege %q", g.AccessType))
+		}
+	}
+
+	return nil
+}
+
 func (r *Resource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	// req.ID can either be in the form <cluster name>:<user ref> or just <user ref>
 	// user ref can either be the name or the UUID of the user.
@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/zclconf/go-cty/cty"
 
 	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
 	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/testutils/nilcompare"
@@ -17,6 +18,10 @@ import (
 const (
 	resourceType = "clickhousedbops_user"
 	resourceName = "foo"
+	profileName  = "profile1"
+
+	// bcrypt hash of "changeme".
+	bcryptHash = "$2a$12$Pq1hLsTMjwRCEdJIt1d0OOLZa/qwgE5xeptULjvuHP6t8e2vP0WbK"
 )
 
 func getUserByRef(ctx context.Context, c dbops.Client, ref string, clusterName *string) (*dbops.User, error) {
@@ -57,9 +62,26 @@ func TestUser_acceptance(t *testing.T) {
 		if !nilcompare.NilCompare(clusterName, attrs["cluster_name"]) {
 			return fmt.Errorf("wrong value for cluster_name attribute")
 		}
+		if cn, ok := attrs["ssl_certificate_cn"].(string); ok && cn != user.SSLCertificateCN {
+			return fmt.Errorf("expected ssl_certificate_cn to be %q, was %q", user.SSLCertificateCN, cn)
+		}
+		if profile, ok := attrs["settings_profile"].(string); ok && !user.HasSettingProfile(profile) {
+			return fmt.Errorf("expected settings profile %q to be associated to the user", profile)
+		}
+		if attrs["settings_profile"] == nil && user.HasSettingProfile(profileName) {
+			return fmt.Errorf("expected settings profile %q not to be associated to the user", profileName)
+		}
+		if attrs["comment"] != nil && user.Comment != nil && attrs["comment"].(string) != *user.Comment {
+			return fmt.Errorf("expected comment to be %q, was %q", *user.Comment, attrs["comment"].(string))
+		}
 		return nil
 	}
 
+	commentUserName := acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
+	profileUserName := acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
+	profileResource := resourcebuilder.New("clickhousedbops_settings_profile", profileName).WithStringAttribute("name", profileName)
+	sslUserName := acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
+
 	tests := []runner.TestCase{
 		{
 			Name:        "Create User using Native protocol on a single replica",
@@ -150,6 +172,323 @@ func TestUser_acceptance(t *testing.T) {
 			CheckNotExistsFunc:  checkNotExistsFunc,
 			CheckAttributesFunc: checkAttributesFunc,
 		},
+		{
+			Name:     "Create User restricted to hosts using Native protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "native",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithStringAttribute("name", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)).
+				WithFunction("password_sha256_hash_wo", "sha256", "changeme").
+				WithIntAttribute("password_sha256_hash_wo_version", 1).
+				WithListAttribute("host", []cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"type":   cty.StringVal("LOCAL"),
+						"values": cty.NullVal(cty.List(cty.String)),
+					}),
+					cty.ObjectVal(map[string]cty.Value{
+						"type":   cty.StringVal("IP"),
+						"values": cty.ListVal([]cty.Value{cty.StringVal("10.0.0.0/8")}),
+					}),
+				}).
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+		{
+			Name:     "Create User restricted to hosts using HTTP protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "http",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithStringAttribute("name", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)).
+				WithFunction("password_sha256_hash_wo", "sha256", "changeme").
+				WithIntAttribute("password_sha256_hash_wo_version", 1).
+				WithListAttribute("host", []cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"type":   cty.StringVal("LOCAL"),
+						"values": cty.NullVal(cty.List(cty.String)),
+					}),
+					cty.ObjectVal(map[string]cty.Value{
+						"type":   cty.StringVal("IP"),
+						"values": cty.ListVal([]cty.Value{cty.StringVal("10.0.0.0/8")}),
+					}),
+				}).
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+		{
+			Name:     "Create User with an expiration time using Native protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "native",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithStringAttribute("name", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)).
+				WithFunction("password_sha256_hash_wo", "sha256", "changeme").
+				WithIntAttribute("password_sha256_hash_wo_version", 1).
+				WithStringAttribute("valid_until", "2099-01-01T00:00:00Z").
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+		{
+			Name:     "Create User with an expiration time using HTTP protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "http",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithStringAttribute("name", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)).
+				WithFunction("password_sha256_hash_wo", "sha256", "changeme").
+				WithIntAttribute("password_sha256_hash_wo_version", 1).
+				WithStringAttribute("valid_until", "2099-01-01T00:00:00Z").
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+		{
+			Name:     "Create and update User comment using Native protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "native",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithStringAttribute("name", commentUserName).
+				WithFunction("password_sha256_hash_wo", "sha256", "changeme").
+				WithIntAttribute("password_sha256_hash_wo_version", 1).
+				WithStringAttribute("comment", "owner: team-data").
+				Build(),
+			UpdatedResource: resourcebuilder.New(resourceType, resourceName).
+				WithStringAttribute("name", commentUserName).
+				WithFunction("password_sha256_hash_wo", "sha256", "changeme").
+				WithIntAttribute("password_sha256_hash_wo_version", 1).
+				WithStringAttribute("comment", "owner: team-ops, managed by terraform").
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+		{
+			Name:     "Create User with a settings profile and remove it using Native protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "native",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithStringAttribute("name", profileUserName).
+				WithFunction("password_sha256_hash_wo", "sha256", "changeme").
+				WithIntAttribute("password_sha256_hash_wo_version", 1).
+				WithResourceFieldReference("settings_profile", "clickhousedbops_settings_profile", profileName, "name").
+				AddDependency(profileResource.Build()).
+				Build(),
+			// Removing the attribute drops the profile from the user.
+			UpdatedResource: resourcebuilder.New(resourceType, resourceName).
+				WithStringAttribute("name", profileUserName).
+				WithFunction("password_sha256_hash_wo", "sha256", "changeme").
+				WithIntAttribute("password_sha256_hash_wo_version", 1).
+				AddDependency(profileResource.Build()).
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+		{
+			Name:     "Create User revoking its grants on destroy using Native protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "native",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithStringAttribute("name", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)).
+				WithFunction("password_sha256_hash_wo", "sha256", "changeme").
+				WithIntAttribute("password_sha256_hash_wo_version", 1).
+				WithBoolAttribute("revoke_grants_on_destroy", true).
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+		{
+			Name:     "Create User with a bcrypt password using Native protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "native",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithStringAttribute("name", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)).
+				WithStringAttribute("password_bcrypt_hash_wo", bcryptHash).
+				WithIntAttribute("password_sha256_hash_wo_version", 1).
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+		{
+			Name:     "Create User with a bcrypt password using HTTP protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "http",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithStringAttribute("name", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)).
+				WithStringAttribute("password_bcrypt_hash_wo", bcryptHash).
+				WithIntAttribute("password_sha256_hash_wo_version", 1).
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+		{
+			Name:     "Create User with a plaintext password using Native protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "native",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithStringAttribute("name", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)).
+				WithStringAttribute("password_plaintext_wo", "changeme").
+				WithIntAttribute("password_sha256_hash_wo_version", 1).
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+		{
+			Name:     "Create User without password using HTTP protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "http",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithStringAttribute("name", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)).
+				WithBoolAttribute("no_password", true).
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+		{
+			Name:     "Create User with grantees using Native protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "native",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithStringAttribute("name", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)).
+				WithFunction("password_sha256_hash_wo", "sha256", "changeme").
+				WithIntAttribute("password_sha256_hash_wo_version", 1).
+				WithListAttribute("grantees", []cty.Value{cty.StringVal("default")}).
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+		{
+			Name:     "Create User with grantees using HTTP protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "http",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithStringAttribute("name", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)).
+				WithFunction("password_sha256_hash_wo", "sha256", "changeme").
+				WithIntAttribute("password_sha256_hash_wo_version", 1).
+				WithListAttribute("grantees", []cty.Value{cty.StringVal("default")}).
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+		{
+			Name:     "Create User with a password and an SSL certificate using Native protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "native",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithStringAttribute("name", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)).
+				WithIntAttribute("password_sha256_hash_wo_version", 1).
+				WithListAttribute("authentication", []cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"type":                    cty.StringVal("sha256_password"),
+						"password_sha256_hash_wo": cty.StringVal("057ba03d6c44104863dc7361fe4578965d1887360f90a0895882e58a6248fc86"),
+						"ssl_certificate_cn":      cty.NullVal(cty.String),
+					}),
+					cty.ObjectVal(map[string]cty.Value{
+						"type":                    cty.StringVal("ssl_certificate"),
+						"password_sha256_hash_wo": cty.NullVal(cty.String),
+						"ssl_certificate_cn":      cty.StringVal("foo.example.com"),
+					}),
+				}).
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+		{
+			Name:     "Create User with a password and an SSL certificate using HTTP protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "http",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithStringAttribute("name", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)).
+				WithIntAttribute("password_sha256_hash_wo_version", 1).
+				WithListAttribute("authentication", []cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"type":                    cty.StringVal("sha256_password"),
+						"password_sha256_hash_wo": cty.StringVal("057ba03d6c44104863dc7361fe4578965d1887360f90a0895882e58a6248fc86"),
+						"ssl_certificate_cn":      cty.NullVal(cty.String),
+					}),
+					cty.ObjectVal(map[string]cty.Value{
+						"type":                    cty.StringVal("ssl_certificate"),
+						"password_sha256_hash_wo": cty.NullVal(cty.String),
+						"ssl_certificate_cn":      cty.StringVal("foo.example.com"),
+					}),
+				}).
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+		{
+			Name:     "Create and update User SSL certificate CN using Native protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "native",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithStringAttribute("name", sslUserName).
+				WithStringAttribute("ssl_certificate_cn", "foo.example.com").
+				Build(),
+			UpdatedResource: resourcebuilder.New(resourceType, resourceName).
+				WithStringAttribute("name", sslUserName).
+				WithStringAttribute("ssl_certificate_cn", "bar.example.com").
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+		{
+			Name:     "Import User authenticating with SSL certificate using Native protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "native",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithStringAttribute("name", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)).
+				WithStringAttribute("ssl_certificate_cn", "foo.example.com").
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+			ImportStateVerify:   true,
+		},
+		{
+			Name:     "Import User authenticating with SSL certificate using HTTP protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "http",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithStringAttribute("name", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)).
+				WithStringAttribute("ssl_certificate_cn", "foo.example.com").
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+			ImportStateVerify:   true,
+		},
 	}
 
 	runner.RunTests(t, tests)
@@ -0,0 +1,35 @@
+package user
+
+import (
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// parseValidUntil returns the expiration time set in the 'valid_until' attribute, or nil if it is not set.
+func parseValidUntil(value types.String) (*time.Time, error) {
+	if value.IsNull() || value.IsUnknown() {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value.ValueString())
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// validUntilFromServer returns the value of 'valid_until' matching the expiration time read from the server.
+// The current value is kept when it is the same time, to preserve the timezone it was written with.
+func validUntilFromServer(current types.String, validUntil *time.Time) types.String {
+	if validUntil == nil {
+		return types.StringNull()
+	}
+
+	if t, err := parseValidUntil(current); err == nil && t != nil && t.Truncate(time.Second).Equal(validUntil.Truncate(time.Second)) {
+		return current
+	}
+
+	return types.StringValue(validUntil.UTC().Format(time.RFC3339))
+}
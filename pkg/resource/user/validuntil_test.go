@@ -0,0 +1,57 @@
+package user
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func Test_validUntilFromServer(t *testing.T) {
+	server := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		current    types.String
+		validUntil *time.Time
+		want       types.String
+	}{
+		{
+			name:       "Same time",
+			current:    types.StringValue("2030-01-01T00:00:00Z"),
+			validUntil: &server,
+			want:       types.StringValue("2030-01-01T00:00:00Z"),
+		},
+		{
+			name:       "Same time in another timezone",
+			current:    types.StringValue("2030-01-01T01:00:00+01:00"),
+			validUntil: &server,
+			want:       types.StringValue("2030-01-01T01:00:00+01:00"),
+		},
+		{
+			name:       "Changed out of band",
+			current:    types.StringValue("2031-01-01T00:00:00Z"),
+			validUntil: &server,
+			want:       types.StringValue("2030-01-01T00:00:00Z"),
+		},
+		{
+			name:       "Set out of band",
+			current:    types.StringNull(),
+			validUntil: &server,
+			want:       types.StringValue("2030-01-01T00:00:00Z"),
+		},
+		{
+			name:       "Removed out of band",
+			current:    types.StringValue("2030-01-01T00:00:00Z"),
+			validUntil: nil,
+			want:       types.StringNull(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validUntilFromServer(tt.current, tt.validUntil); !got.Equal(tt.want) {
+				t.Errorf("validUntilFromServer() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
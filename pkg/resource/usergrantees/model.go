@@ -0,0 +1,13 @@
+package usergrantees
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type UserGrantees struct {
+	ClusterName    types.String `tfsdk:"cluster_name"`
+	UserID         types.String `tfsdk:"user_id"`
+	Grantees       types.Set    `tfsdk:"grantees"`
+	GranteesAny    types.Bool   `tfsdk:"grantees_any"`
+	GranteesExcept types.Set    `tfsdk:"grantees_except"`
+}
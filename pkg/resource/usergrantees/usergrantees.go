@@ -0,0 +1,316 @@
+package usergrantees
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
+)
+
+//go:embed usergrantees.md
+var userGranteesResourceDescription string
+
+var (
+	_ resource.Resource                = &Resource{}
+	_ resource.ResourceWithConfigure   = &Resource{}
+	_ resource.ResourceWithImportState = &Resource{}
+	_ resource.ResourceWithModifyPlan  = &Resource{}
+)
+
+func NewResource() resource.Resource {
+	return &Resource{}
+}
+
+type Resource struct {
+	client dbops.Client
+}
+
+func (r *Resource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user_grantees"
+}
+
+func (r *Resource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"cluster_name": schema.StringAttribute{
+				Optional:    true,
+				Description: "Name of the cluster to create the resource into. If omitted, resource will be created on the replica hit by the query.\nThis field must be left null when using a ClickHouse Cloud cluster.\nWhen using a self hosted ClickHouse instance, this field should only be set when there is more than one replica and you are not using 'replicated' storage for user_directory.\n",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"user_id": schema.StringAttribute{
+				Required:    true,
+				Description: "ID of the user whose grantees are managed",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"grantees": schema.SetAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Names of the users and roles the user can grant its privileges and roles to. When neither grantees nor grantees_any are set, the user can't grant to anyone",
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+					setvalidator.ConflictsWith(path.MatchRoot("grantees_any")),
+				},
+			},
+			"grantees_any": schema.BoolAttribute{
+				Optional:    true,
+				Description: "When true, the user can grant its privileges and roles to any user and role, except the ones in grantees_except",
+			},
+			"grantees_except": schema.SetAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Names of the users and roles the user can't grant its privileges and roles to. Requires grantees_any to be true",
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+					setvalidator.AlsoRequires(path.MatchRoot("grantees_any")),
+				},
+			},
+		},
+		MarkdownDescription: userGranteesResourceDescription,
+	}
+}
+
+func (r *Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		// If the entire plan is null, the resource is planned for destruction.
+		return
+	}
+
+	if r.client != nil {
+		var clusterName types.String
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("cluster_name"), &clusterName)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		isReplicatedStorage, err := r.client.IsReplicatedStorage(ctx, clusterName.ValueStringPointer())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Checking if service is using replicated storage",
+				fmt.Sprintf("%+v\n", err),
+			)
+			return
+		}
+
+		if isReplicatedStorage {
+			var config UserGrantees
+			diags := req.Config.Get(ctx, &config)
+			resp.Diagnostics.Append(diags...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+
+			// UserGrantees cannot specify 'cluster_name' or apply will fail.
+			if !config.ClusterName.IsNull() || r.client.DefaultClusterName() != nil {
+				resp.Diagnostics.AddWarning(
+					"Invalid configuration",
+					"Your ClickHouse cluster is using Replicated storage, please remove the 'cluster_name' attribute from your resource definition, or the 'default_cluster' provider attribute, if you encounter any errors.",
+				)
+			}
+		}
+	}
+}
+
+func (r *Resource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	r.client = req.ProviderData.(dbops.Client)
+}
+
+func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan UserGrantees
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	grantees, diags := granteesFromModel(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.SetUserGrantees(ctx, plan.UserID.ValueString(), grantees, plan.ClusterName.ValueStringPointer())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Setting User Grantees",
+			fmt.Sprintf("%+v\n", err),
+		)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *Resource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state UserGrantees
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	grantees, err := r.client.GetUserGrantees(ctx, state.UserID.ValueString(), state.ClusterName.ValueStringPointer())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading User Grantees",
+			fmt.Sprintf("%+v\n", err),
+		)
+		return
+	}
+
+	if grantees == nil {
+		// User was deleted, so are its grantees.
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	modelFromApiResponse(&state, *grantees)
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *Resource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan UserGrantees
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	grantees, diags := granteesFromModel(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.SetUserGrantees(ctx, plan.UserID.ValueString(), grantees, plan.ClusterName.ValueStringPointer())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Setting User Grantees",
+			fmt.Sprintf("%+v\n", err),
+		)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state UserGrantees
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	existing, err := r.client.GetUserGrantees(ctx, state.UserID.ValueString(), state.ClusterName.ValueStringPointer())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading User Grantees",
+			fmt.Sprintf("%+v\n", err),
+		)
+		return
+	}
+
+	if existing == nil {
+		// User was deleted, nothing to restore.
+		return
+	}
+
+	// Restore the ClickHouse default, allowing the user to grant to anyone.
+	err = r.client.SetUserGrantees(ctx, state.UserID.ValueString(), dbops.UserGrantees{Any: true}, state.ClusterName.ValueStringPointer())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Resetting User Grantees",
+			fmt.Sprintf("%+v\n", err),
+		)
+		return
+	}
+}
+
+func (r *Resource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// req.ID can either be in the form <cluster name>:<user ref> or just <user ref>
+	// user ref can either be the user's name or the UUID
+
+	// Check if cluster name is specified
+	ref := req.ID
+	var clusterName *string
+	if strings.Contains(req.ID, ":") {
+		clusterName = &strings.Split(req.ID, ":")[0]
+		ref = strings.Split(req.ID, ":")[1]
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("user_id"), ref)...)
+
+	if clusterName != nil {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("cluster_name"), clusterName)...)
+	}
+}
+
+func modelFromApiResponse(state *UserGrantees, grantees dbops.UserGrantees) {
+	if grantees.Any {
+		state.GranteesAny = types.BoolValue(true)
+	} else if !state.GranteesAny.IsNull() {
+		state.GranteesAny = types.BoolValue(false)
+	}
+
+	state.Grantees = stringSetValue(grantees.Names)
+	state.GranteesExcept = stringSetValue(grantees.Except)
+}
+
+// granteesFromModel returns the dbops grantees matching the given model.
+func granteesFromModel(ctx context.Context, model UserGrantees) (dbops.UserGrantees, diag.Diagnostics) {
+	grantees := dbops.UserGrantees{
+		Any:    model.GranteesAny.ValueBool(),
+		Names:  make([]string, 0),
+		Except: make([]string, 0),
+	}
+
+	var diags diag.Diagnostics
+	if !model.Grantees.IsNull() && !model.Grantees.IsUnknown() {
+		diags.Append(model.Grantees.ElementsAs(ctx, &grantees.Names, false)...)
+	}
+	if !model.GranteesExcept.IsNull() && !model.GranteesExcept.IsUnknown() {
+		diags.Append(model.GranteesExcept.ElementsAs(ctx, &grantees.Except, false)...)
+	}
+
+	return grantees, diags
+}
+
+// stringSetValue returns a set with the given values, or a null set when there are none.
+func stringSetValue(values []string) types.Set {
+	if len(values) == 0 {
+		return types.SetNull(types.StringType)
+	}
+
+	elements := make([]attr.Value, 0)
+	for _, v := range values {
+		elements = append(elements, types.StringValue(v))
+	}
+
+	set, _ := types.SetValue(types.StringType, elements)
+	return set
+}
@@ -0,0 +1,127 @@
+package usergrantees_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/dbops"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/testutils/resourcebuilder"
+	"github.com/ClickHouse/terraform-provider-clickhousedbops/internal/testutils/runner"
+)
+
+const (
+	resourceType = "clickhousedbops_user_grantees"
+	resourceName = "foo"
+)
+
+func TestUserGrantees_acceptance(t *testing.T) {
+	user := resourcebuilder.
+		New("clickhousedbops_user", "user").
+		WithStringAttribute("name", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)).
+		WithFunction("password_sha256_hash_wo", "sha256", "test").
+		WithIntAttribute("password_sha256_hash_wo_version", 1)
+
+	checkNotExistsFunc := func(ctx context.Context, dbopsClient dbops.Client, clusterName *string, attrs map[string]string) (bool, error) {
+		userID := attrs["user_id"]
+		if userID == "" {
+			return false, fmt.Errorf("user_id attribute was not set")
+		}
+
+		grantees, err := dbopsClient.GetUserGrantees(ctx, userID, clusterName)
+		if err != nil {
+			return false, err
+		}
+
+		// Destroying the resource restores GRANTEES ANY.
+		return grantees != nil && (!grantees.Any || len(grantees.Except) > 0), nil
+	}
+
+	checkAttributesFunc := func(ctx context.Context, dbopsClient dbops.Client, clusterName *string, attrs map[string]interface{}) error {
+		userID := attrs["user_id"]
+		if userID == nil {
+			return fmt.Errorf("user_id attribute was not set")
+		}
+
+		grantees, err := dbopsClient.GetUserGrantees(ctx, userID.(string), clusterName)
+		if err != nil {
+			return err
+		}
+
+		if grantees == nil {
+			return fmt.Errorf("user %q was not found", userID.(string))
+		}
+
+		if anyGrantee, ok := attrs["grantees_any"].(bool); ok && anyGrantee != grantees.Any {
+			return fmt.Errorf("expected grantees_any to be %t, was %t", anyGrantee, grantees.Any)
+		}
+
+		for attrName, actual := range map[string][]string{"grantees": grantees.Names, "grantees_except": grantees.Except} {
+			expected := make([]string, 0)
+			if values, ok := attrs[attrName].([]interface{}); ok {
+				for _, v := range values {
+					expected = append(expected, v.(string))
+				}
+			}
+
+			slices.Sort(expected)
+			actual = slices.Sorted(slices.Values(actual))
+			if !slices.Equal(expected, actual) {
+				return fmt.Errorf("expected %s to be %v, was %v", attrName, expected, actual)
+			}
+		}
+
+		return nil
+	}
+
+	tests := []runner.TestCase{
+		{
+			Name:     "Grant to the default user only using Native protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "native",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithResourceFieldReference("user_id", "clickhousedbops_user", "user", "id").
+				WithListAttribute("grantees", []cty.Value{cty.StringVal("default")}).
+				AddDependency(user.Build()).
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+		{
+			Name:     "Grant to anyone but the default user using HTTP protocol on a cluster using replicated storage",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-replicated.xml"},
+			Protocol: "http",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithResourceFieldReference("user_id", "clickhousedbops_user", "user", "id").
+				WithBoolAttribute("grantees_any", true).
+				WithListAttribute("grantees_except", []cty.Value{cty.StringVal("default")}).
+				AddDependency(user.Build()).
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+		{
+			Name:     "Grant to no one using Native protocol on a single replica",
+			ChEnv:    map[string]string{"CONFIGFILE": "config-single.xml"},
+			Protocol: "native",
+			Resource: resourcebuilder.New(resourceType, resourceName).
+				WithResourceFieldReference("user_id", "clickhousedbops_user", "user", "id").
+				AddDependency(user.Build()).
+				Build(),
+			ResourceName:        resourceName,
+			ResourceAddress:     fmt.Sprintf("%s.%s", resourceType, resourceName),
+			CheckNotExistsFunc:  checkNotExistsFunc,
+			CheckAttributesFunc: checkAttributesFunc,
+		},
+	}
+
+	runner.RunTests(t, tests)
+}